@@ -0,0 +1,258 @@
+package bigquery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// QueryBillingData names the billing export query in the query cache
+const QueryBillingData = "billing_data"
+
+// cacheEntry is a materialized query result and when it was fetched
+type cacheEntry struct {
+	Table     string            `json:"table"`
+	Query     string            `json:"query"`
+	Days      int               `json:"days"`
+	FetchedAt time.Time         `json:"fetched_at"`
+	Rows      []models.CostData `json:"rows"`
+}
+
+// cacheCall is a fetch in progress that concurrent misses wait on
+type cacheCall struct {
+	table string
+	query string
+	days  int
+	done  chan struct{}
+	err   error
+}
+
+// QueryCache keeps materialized billing rows keyed by (table, query, days) for a
+// TTL, so a run does not scan the same data twice. Concurrent misses that an
+// in-flight fetch covers wait for it instead of querying again. With a directory
+// set, entries are also written to disk and consecutive runs within the TTL skip
+// BigQuery entirely.
+type QueryCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	dir      string
+	entries  map[string]cacheEntry
+	inflight []*cacheCall
+	minDays  map[string]int
+	now      func() time.Time
+}
+
+// NewQueryCache creates a new in-memory query cache
+func NewQueryCache(ttl time.Duration) *QueryCache {
+	return &QueryCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+		minDays: make(map[string]int),
+		now:     time.Now,
+	}
+}
+
+// SetDir persists entries as JSON files in dir, creating it if needed
+func (qc *QueryCache) SetDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create query cache directory: %v", err)
+	}
+	qc.dir = dir
+	return nil
+}
+
+// Widen makes every miss of query fetch at least days days. Monitors reading the
+// same query over different windows register their widest one, so whichever asks
+// first fetches rows for all of them and the others are served from the cache.
+func (qc *QueryCache) Widen(query string, days int) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+	if days > qc.minDays[query] {
+		qc.minDays[query] = days
+	}
+}
+
+// Fetch returns the rows of query on table over the last days days. On a miss it
+// calls fetch for the widened window and caches the result; an entry or fetch in
+// flight for the same table and query over more days also serves the request,
+// filtered to the requested window.
+func (qc *QueryCache) Fetch(table, query string, days int, fetch func(days int) ([]models.CostData, error)) ([]models.CostData, error) {
+	for {
+		qc.mu.Lock()
+		if rows, ok := qc.get(table, query, days); ok {
+			qc.mu.Unlock()
+			log.Printf("💾 Query cache hit for %s over %d days (%d rows)", query, days, len(rows))
+			return rows, nil
+		}
+
+		// Wait for a fetch in flight that covers the window, then look again
+		if call := qc.covering(table, query, days); call != nil {
+			qc.mu.Unlock()
+			<-call.done
+			if call.err != nil {
+				return nil, call.err
+			}
+			continue
+		}
+
+		window := days
+		if qc.minDays[query] > window {
+			window = qc.minDays[query]
+		}
+		call := &cacheCall{table: table, query: query, days: window, done: make(chan struct{})}
+		qc.inflight = append(qc.inflight, call)
+		qc.mu.Unlock()
+
+		rows, err := fetch(window)
+
+		qc.mu.Lock()
+		if err == nil {
+			qc.put(table, query, window, rows)
+		}
+		qc.remove(call)
+		qc.mu.Unlock()
+
+		call.err = err
+		close(call.done)
+		if err != nil {
+			return nil, err
+		}
+		if window == days {
+			return rows, nil
+		}
+		return qc.window(cacheEntry{Rows: rows}, days), nil
+	}
+}
+
+// Get returns the cached rows of query on table over the last days days, if fresh
+func (qc *QueryCache) Get(table, query string, days int) ([]models.CostData, bool) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+	return qc.get(table, query, days)
+}
+
+// get is Get with qc.mu held
+func (qc *QueryCache) get(table, query string, days int) ([]models.CostData, bool) {
+	if entry, ok := qc.lookup(table, query, days); ok {
+		return entry.Rows, true
+	}
+	if widened := qc.minDays[query]; widened > days {
+		if entry, ok := qc.lookup(table, query, widened); ok {
+			return qc.window(entry, days), true
+		}
+	}
+
+	// A wider window of the same query holds every row of a narrower one
+	for _, entry := range qc.entries {
+		if entry.Table != table || entry.Query != query || entry.Days <= days || !qc.fresh(entry) {
+			continue
+		}
+		return qc.window(entry, days), true
+	}
+	return nil, false
+}
+
+// window filters an entry's rows to the last days days
+func (qc *QueryCache) window(entry cacheEntry, days int) []models.CostData {
+	// The billing query windows on CURRENT_DATE(), which BigQuery evaluates in UTC
+	cutoff := qc.now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+	var rows []models.CostData
+	for _, row := range entry.Rows {
+		if row.Date >= cutoff {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// covering returns a fetch in flight for table and query over at least days days
+func (qc *QueryCache) covering(table, query string, days int) *cacheCall {
+	for _, call := range qc.inflight {
+		if call.table == table && call.query == query && call.days >= days {
+			return call
+		}
+	}
+	return nil
+}
+
+// remove drops a finished fetch from the in-flight list
+func (qc *QueryCache) remove(call *cacheCall) {
+	for i, inflight := range qc.inflight {
+		if inflight == call {
+			qc.inflight = append(qc.inflight[:i], qc.inflight[i+1:]...)
+			return
+		}
+	}
+}
+
+// Put caches the rows of query on table over the last days days
+func (qc *QueryCache) Put(table, query string, days int, rows []models.CostData) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+	qc.put(table, query, days, rows)
+}
+
+// put is Put with qc.mu held
+func (qc *QueryCache) put(table, query string, days int, rows []models.CostData) {
+	entry := cacheEntry{
+		Table:     table,
+		Query:     query,
+		Days:      days,
+		FetchedAt: qc.now(),
+		Rows:      rows,
+	}
+	key := cacheKey(table, query, days)
+	qc.entries[key] = entry
+
+	if qc.dir == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = utils.WriteFileAtomic(qc.path(key), data)
+	}
+	if err != nil {
+		log.Printf("Warning: Failed to write query cache entry for %s: %v", query, err)
+	}
+}
+
+// lookup returns a fresh entry for exactly (table, query, days) from memory or disk
+func (qc *QueryCache) lookup(table, query string, days int) (cacheEntry, bool) {
+	key := cacheKey(table, query, days)
+	entry, ok := qc.entries[key]
+	if !ok && qc.dir != "" {
+		data, err := os.ReadFile(qc.path(key))
+		if err == nil && json.Unmarshal(data, &entry) == nil && entry.Table == table {
+			qc.entries[key] = entry
+			ok = true
+		}
+	}
+	if !ok || !qc.fresh(entry) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// fresh reports whether an entry is still within the TTL
+func (qc *QueryCache) fresh(entry cacheEntry) bool {
+	return qc.now().Sub(entry.FetchedAt) < qc.ttl
+}
+
+// path returns the file an entry is persisted in
+func (qc *QueryCache) path(key string) string {
+	return filepath.Join(qc.dir, key+".json")
+}
+
+// cacheKey derives a file-safe key from the table, query and window
+func cacheKey(table, query string, days int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d", table, query, days)))
+	return hex.EncodeToString(sum[:8])
+}
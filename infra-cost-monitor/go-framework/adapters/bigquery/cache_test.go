@@ -0,0 +1,189 @@
+package bigquery
+
+import (
+	"errors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var cacheTestNow = time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+
+// newTestCache returns a cache whose clock reads *now
+func newTestCache(ttl time.Duration, now *time.Time) *QueryCache {
+	qc := NewQueryCache(ttl)
+	qc.now = func() time.Time { return *now }
+	return qc
+}
+
+// countingFetch returns one row per day of the window it is asked for and counts its calls
+func countingFetch(calls *int32) func(days int) ([]models.CostData, error) {
+	return func(days int) ([]models.CostData, error) {
+		atomic.AddInt32(calls, 1)
+		var rows []models.CostData
+		for i := 0; i <= days; i++ {
+			rows = append(rows, models.CostData{Date: cacheTestNow.AddDate(0, 0, -i).Format("2006-01-02"), Cost: 1})
+		}
+		return rows, nil
+	}
+}
+
+func TestQueryCacheHitAndMiss(t *testing.T) {
+	now := cacheTestNow
+	qc := newTestCache(time.Hour, &now)
+	var calls int32
+
+	for i := 0; i < 2; i++ {
+		rows, err := qc.Fetch("p.d.t", QueryBillingData, 30, countingFetch(&calls))
+		if err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+		if len(rows) != 31 {
+			t.Fatalf("got %d rows, want 31", len(rows))
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("fetch called %d times, want 1", calls)
+	}
+
+	// A different table is a miss
+	if _, err := qc.Fetch("p.d.other", QueryBillingData, 30, countingFetch(&calls)); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("fetch called %d times after a new table, want 2", calls)
+	}
+
+	// So is an entry past the TTL
+	now = now.Add(2 * time.Hour)
+	if _, err := qc.Fetch("p.d.t", QueryBillingData, 30, countingFetch(&calls)); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("fetch called %d times after expiry, want 3", calls)
+	}
+}
+
+func TestQueryCacheWiderWindowServesNarrower(t *testing.T) {
+	now := cacheTestNow
+	qc := newTestCache(time.Hour, &now)
+	var calls int32
+
+	if _, err := qc.Fetch("p.d.t", QueryBillingData, 210, countingFetch(&calls)); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	rows, err := qc.Fetch("p.d.t", QueryBillingData, 90, countingFetch(&calls))
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("fetch called %d times, want 1", calls)
+	}
+	if len(rows) != 91 {
+		t.Fatalf("got %d rows, want 91", len(rows))
+	}
+}
+
+func TestQueryCacheWidenFetchesWidestWindowFirst(t *testing.T) {
+	now := cacheTestNow
+	qc := newTestCache(time.Hour, &now)
+	qc.Widen(QueryBillingData, 210)
+	var calls int32
+
+	rows, err := qc.Fetch("p.d.t", QueryBillingData, 90, countingFetch(&calls))
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(rows) != 91 {
+		t.Fatalf("got %d rows, want 91", len(rows))
+	}
+	if rows, err = qc.Fetch("p.d.t", QueryBillingData, 210, countingFetch(&calls)); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(rows) != 211 {
+		t.Fatalf("got %d rows, want 211", len(rows))
+	}
+	if calls != 1 {
+		t.Fatalf("fetch called %d times, want 1", calls)
+	}
+}
+
+func TestQueryCacheCoalescesConcurrentMisses(t *testing.T) {
+	now := cacheTestNow
+	qc := newTestCache(time.Hour, &now)
+	qc.Widen(QueryBillingData, 210)
+
+	var calls int32
+	release := make(chan struct{})
+	fetch := countingFetch(&calls)
+	blocking := func(days int) ([]models.CostData, error) {
+		<-release
+		return fetch(days)
+	}
+
+	var wg sync.WaitGroup
+	for _, days := range []int{210, 90, 90, 30} {
+		wg.Add(1)
+		go func(days int) {
+			defer wg.Done()
+			rows, err := qc.Fetch("p.d.t", QueryBillingData, days, blocking)
+			if err != nil {
+				t.Errorf("Fetch(%d): %v", days, err)
+			} else if len(rows) != days+1 {
+				t.Errorf("Fetch(%d) got %d rows, want %d", days, len(rows), days+1)
+			}
+		}(days)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("fetch called %d times, want 1", calls)
+	}
+}
+
+func TestQueryCacheFetchErrorIsNotCached(t *testing.T) {
+	now := cacheTestNow
+	qc := newTestCache(time.Hour, &now)
+	failed := errors.New("quota exceeded")
+
+	_, err := qc.Fetch("p.d.t", QueryBillingData, 30, func(int) ([]models.CostData, error) { return nil, failed })
+	if !errors.Is(err, failed) {
+		t.Fatalf("got error %v, want %v", err, failed)
+	}
+	var calls int32
+	if _, err := qc.Fetch("p.d.t", QueryBillingData, 30, countingFetch(&calls)); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("fetch called %d times after a failure, want 1", calls)
+	}
+}
+
+func TestQueryCachePersistsPerTable(t *testing.T) {
+	now := cacheTestNow
+	dir := t.TempDir()
+	var calls int32
+
+	first := newTestCache(time.Hour, &now)
+	if err := first.SetDir(dir); err != nil {
+		t.Fatalf("SetDir: %v", err)
+	}
+	if _, err := first.Fetch("p.d.t", QueryBillingData, 30, countingFetch(&calls)); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	second := newTestCache(time.Hour, &now)
+	if err := second.SetDir(dir); err != nil {
+		t.Fatalf("SetDir: %v", err)
+	}
+	if _, ok := second.Get("p.d.t", QueryBillingData, 30); !ok {
+		t.Fatal("entry written by the first cache was not read back")
+	}
+	if _, ok := second.Get("p.d.other", QueryBillingData, 30); ok {
+		t.Fatal("entry for another table was served")
+	}
+}
@@ -3,38 +3,109 @@ package bigquery
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/bigquery"
-	"google.golang.org/api/option"
 )
 
 // Client represents a BigQuery client
 type Client struct {
 	client *bigquery.Client
 	ctx    context.Context
+	config Config
+	retry  RetryPolicy
+
+	// billingColumns caches which optional billing export columns exist; columnsMu
+	// guards it, since monitors query concurrently
+	columnsMu      sync.Mutex
+	billingColumns map[string]bool
+}
+
+// Config locates the billing export the client queries. The export table path is
+// Dataset.Table.BillingExportTable, matching the BIGQUERY_* environment variables.
+type Config struct {
+	ProjectID          string
+	Dataset            string
+	Table              string
+	BillingExportTable string
+	Retry              RetryPolicy
+
+	// AllowPlaceholderProject skips the placeholder project check, for tests and
+	// emulators that accept any project ID
+	AllowPlaceholderProject bool
+}
+
+// PlaceholderProjectID is the example project ID from the setup docs, which is
+// never a real project
+const PlaceholderProjectID = "your-gcp-project-id"
+
+// ConfigFromEnv reads the client configuration from GOOGLE_CLOUD_PROJECT,
+// BIGQUERY_DATASET, BIGQUERY_TABLE and BIGQUERY_BILLING_EXPORT_TABLE
+func ConfigFromEnv() Config {
+	return Config{
+		ProjectID:          os.Getenv("GOOGLE_CLOUD_PROJECT"),
+		Dataset:            os.Getenv("BIGQUERY_DATASET"),
+		Table:              os.Getenv("BIGQUERY_TABLE"),
+		BillingExportTable: os.Getenv("BIGQUERY_BILLING_EXPORT_TABLE"),
+		Retry:              DefaultRetryPolicy,
+	}
+}
+
+// missing returns the names of the required fields that are empty
+func (cfg Config) missing() []string {
+	var missing []string
+	for _, field := range []struct{ name, value string }{
+		{"project ID (GOOGLE_CLOUD_PROJECT)", cfg.ProjectID},
+		{"dataset (BIGQUERY_DATASET)", cfg.Dataset},
+		{"table (BIGQUERY_TABLE)", cfg.Table},
+		{"billing export table (BIGQUERY_BILLING_EXPORT_TABLE)", cfg.BillingExportTable},
+	} {
+		if field.value == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	return missing
 }
 
-// NewClient creates a new BigQuery client
+// NewClient creates a new BigQuery client configured from the environment with
+// the default retry policy
 func NewClient() (*Client, error) {
-	ctx := context.Background()
+	return NewClientWithConfig(context.Background(), ConfigFromEnv())
+}
+
+// NewClientWithRetry creates a new BigQuery client configured from the environment
+// that retries transient query failures up to retry.MaxRetries times, backing off
+// from retry.BaseDelay
+func NewClientWithRetry(retry RetryPolicy) (*Client, error) {
+	cfg := ConfigFromEnv()
+	cfg.Retry = retry
+	return NewClientWithConfig(context.Background(), cfg)
+}
 
-	// Get project ID from environment or use default
-	projectID := os.Getenv("GOOGLE_CLOUD_PROJECT")
-	if projectID == "" {
-		projectID = "your-gcp-project-id" // Replace with your actual project ID
+// NewClientWithConfig creates a new BigQuery client for the given configuration.
+// ctx is used for operations that are not given their own context.
+func NewClientWithConfig(ctx context.Context, cfg Config) (*Client, error) {
+	if missing := cfg.missing(); len(missing) > 0 {
+		return nil, fmt.Errorf("BigQuery client is missing configuration: %s", strings.Join(missing, ", "))
+	}
+	if cfg.ProjectID == PlaceholderProjectID && !cfg.AllowPlaceholderProject {
+		return nil, fmt.Errorf("GOOGLE_CLOUD_PROJECT is set to the placeholder %q; set it to your GCP project ID", PlaceholderProjectID)
 	}
 
 	// Create client with default credentials
-	client, err := bigquery.NewClient(ctx, projectID)
+	client, err := bigquery.NewClient(ctx, cfg.ProjectID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create BigQuery client: %v", err)
+		return nil, classifyError("failed to create BigQuery client", err)
 	}
 
 	return &Client{
 		client: client,
 		ctx:    ctx,
+		config: cfg,
+		retry:  cfg.Retry,
 	}, nil
 }
 
@@ -43,14 +114,56 @@ func (c *Client) Close() error {
 	return c.client.Close()
 }
 
-// Query executes a BigQuery SQL query
-func (c *Client) Query(query string) (*bigquery.RowIterator, error) {
-	q := c.client.Query(query)
-	return q.Read(c.ctx)
+// Query executes a BigQuery SQL query; cancelling ctx cancels the query
+func (c *Client) Query(ctx context.Context, query string) (*bigquery.RowIterator, error) {
+	return c.namedQuery(ctx, "query", query)
+}
+
+// queryContext returns ctx, falling back to the client's context when ctx is nil
+func (c *Client) queryContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return c.ctx
+	}
+	return ctx
+}
+
+// namedQuery executes a query and records its job statistics under name for the
+// self-cost report. Transient failures re-run the query under the retry policy.
+func (c *Client) namedQuery(ctx context.Context, name, query string, params ...bigquery.QueryParameter) (*bigquery.RowIterator, error) {
+	ctx = c.queryContext(ctx)
+	var it *bigquery.RowIterator
+	err := c.withRetry(ctx, name, func() error {
+		q := c.client.Query(query)
+		q.Parameters = params
+		job, err := q.Run(ctx)
+		if err != nil {
+			return err
+		}
+		status, err := job.Wait(ctx)
+		if err != nil {
+			return err
+		}
+		if err := status.Err(); err != nil {
+			return err
+		}
+		recordUsage(name, job, status)
+
+		it, err = job.Read(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, classifyError("query", err)
+	}
+	return it, nil
 }
 
 // GetBillingData retrieves cost data from BigQuery billing export
-func (c *Client) GetBillingData(days int) (*bigquery.RowIterator, error) {
+func (c *Client) GetBillingData(ctx context.Context, days int) (*bigquery.RowIterator, error) {
+	table, err := c.billingTable()
+	if err != nil {
+		return nil, err
+	}
+	selectSQL, groupSQL := c.billingColumnSQL()
 	query := fmt.Sprintf(`
 		SELECT 
 			DATE(usage_start_time) as date,
@@ -61,58 +174,109 @@ func (c *Client) GetBillingData(days int) (*bigquery.RowIterator, error) {
 			location.location as region,
 			SUM(cost) as cost,
 			SUM(usage.amount) as usage_amount,
-			usage.unit as usage_unit
-		FROM `+"`%s.%s.%s`"+`
-		WHERE DATE(usage_start_time) >= DATE_SUB(CURRENT_DATE(), INTERVAL %d DAY)
+			usage.unit as usage_unit,
+			currency%s
+		FROM %s
+		WHERE DATE(usage_start_time) >= DATE_SUB(CURRENT_DATE(), INTERVAL @days DAY)
 		AND service.description NOT LIKE '%%Marketplace%%'
-		GROUP BY date, service, sku, project_id, project_name, region, usage_unit
+		GROUP BY date, service, sku, project_id, project_name, region, usage_unit, currency%s
 		ORDER BY date DESC, cost DESC
 	`, 
-		os.Getenv("BIGQUERY_DATASET"), 
-		os.Getenv("BIGQUERY_TABLE"), 
-		os.Getenv("BIGQUERY_BILLING_EXPORT_TABLE"),
-		days)
+		selectSQL,
+		table,
+		groupSQL)
 
-	return c.Query(query)
+	return c.namedQuery(ctx, fmt.Sprintf("billing_data_%dd", days), query,
+		bigquery.QueryParameter{Name: "days", Value: days})
 }
 
-// GetDailyCosts retrieves daily aggregated costs
-func (c *Client) GetDailyCosts(days int) (*bigquery.RowIterator, error) {
+// GetBillingDataSince retrieves cost data for usage that started after the given time
+func (c *Client) GetBillingDataSince(ctx context.Context, since time.Time) (*bigquery.RowIterator, error) {
+	table, err := c.billingTable()
+	if err != nil {
+		return nil, err
+	}
+	selectSQL, groupSQL := c.billingColumnSQL()
 	query := fmt.Sprintf(`
 		SELECT 
 			DATE(usage_start_time) as date,
+			service.description as service,
+			sku.description as sku,
+			project.id as project_id,
+			project.name as project_name,
+			location.location as region,
+			SUM(cost) as cost,
+			SUM(usage.amount) as usage_amount,
+			usage.unit as usage_unit,
+			currency%s
+		FROM %s
+		WHERE usage_start_time > @since
+		AND service.description NOT LIKE '%%Marketplace%%'
+		GROUP BY date, service, sku, project_id, project_name, region, usage_unit, currency%s
+		ORDER BY date DESC, cost DESC
+	`,
+		selectSQL,
+		table,
+		groupSQL)
+
+	return c.namedQuery(ctx, "billing_data_since", query,
+		bigquery.QueryParameter{Name: "since", Value: since.UTC()})
+}
+
+// GetDailyCosts retrieves daily aggregated costs, one row per day and currency
+func (c *Client) GetDailyCosts(ctx context.Context, days int) (*bigquery.RowIterator, error) {
+	table, err := c.billingTable()
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT 
+			DATE(usage_start_time) as date,
+			currency,
 			SUM(cost) as total_cost
-		FROM `+"`%s.%s.%s`"+`
-		WHERE DATE(usage_start_time) >= DATE_SUB(CURRENT_DATE(), INTERVAL %d DAY)
+		FROM %s
+		WHERE DATE(usage_start_time) >= DATE_SUB(CURRENT_DATE(), INTERVAL @days DAY)
 		AND service.description NOT LIKE '%%Marketplace%%'
-		GROUP BY date
+		GROUP BY date, currency
 		ORDER BY date DESC
 	`,
-		os.Getenv("BIGQUERY_DATASET"), 
-		os.Getenv("BIGQUERY_TABLE"), 
-		os.Getenv("BIGQUERY_BILLING_EXPORT_TABLE"),
-		days)
+		table)
 
-	return c.Query(query)
+	return c.namedQuery(ctx, fmt.Sprintf("daily_costs_%dd", days), query,
+		bigquery.QueryParameter{Name: "days", Value: days})
 }
 
 // GetServiceCosts retrieves costs by service
-func (c *Client) GetServiceCosts(days int) (*bigquery.RowIterator, error) {
+func (c *Client) GetServiceCosts(ctx context.Context, days int) (*bigquery.RowIterator, error) {
+	table, err := c.billingTable()
+	if err != nil {
+		return nil, err
+	}
 	query := fmt.Sprintf(`
 		SELECT 
 			service.description as service,
 			SUM(cost) as total_cost,
 			COUNT(DISTINCT DATE(usage_start_time)) as days
-		FROM `+"`%s.%s.%s`"+`
-		WHERE DATE(usage_start_time) >= DATE_SUB(CURRENT_DATE(), INTERVAL %d DAY)
+		FROM %s
+		WHERE DATE(usage_start_time) >= DATE_SUB(CURRENT_DATE(), INTERVAL @days DAY)
 		AND service.description NOT LIKE '%%Marketplace%%'
 		GROUP BY service
 		ORDER BY total_cost DESC
 	`,
-		os.Getenv("BIGQUERY_DATASET"), 
-		os.Getenv("BIGQUERY_TABLE"), 
-		os.Getenv("BIGQUERY_BILLING_EXPORT_TABLE"),
-		days)
+		table)
 
-	return c.Query(query)
-} 
\ No newline at end of file
+	return c.namedQuery(ctx, fmt.Sprintf("service_costs_%dd", days), query,
+		bigquery.QueryParameter{Name: "days", Value: days})
+}
+
+// CheckBillingTable verifies the billing export table exists and is queryable without scanning any rows
+func (c *Client) CheckBillingTable(ctx context.Context) error {
+	table, err := c.billingTable()
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("SELECT cost FROM %s LIMIT 0", table)
+
+	_, err = c.namedQuery(ctx, "check_billing_table", query)
+	return err
+}
@@ -0,0 +1,76 @@
+package bigquery
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNewClientWithConfigNamesMissingSettings(t *testing.T) {
+	valid := Config{ProjectID: "my-project", Dataset: "my-project", Table: "billing", BillingExportTable: "export"}
+	tests := []struct {
+		name   string
+		change func(cfg *Config)
+		want   []string
+	}{
+		{"missing project", func(cfg *Config) { cfg.ProjectID = "" }, []string{"GOOGLE_CLOUD_PROJECT"}},
+		{"placeholder project", func(cfg *Config) { cfg.ProjectID = PlaceholderProjectID }, []string{"GOOGLE_CLOUD_PROJECT", PlaceholderProjectID}},
+		{"missing dataset", func(cfg *Config) { cfg.Dataset = "" }, []string{"BIGQUERY_DATASET"}},
+		{"missing tables", func(cfg *Config) { cfg.Table, cfg.BillingExportTable = "", "" }, []string{"BIGQUERY_TABLE", "BIGQUERY_BILLING_EXPORT_TABLE"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.change(&cfg)
+			_, err := NewClientWithConfig(context.Background(), cfg)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not name %s", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewClientReadsEnvironment(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	t.Setenv("BIGQUERY_DATASET", "my-project")
+	t.Setenv("BIGQUERY_TABLE", "billing")
+	t.Setenv("BIGQUERY_BILLING_EXPORT_TABLE", "export")
+
+	_, err := NewClient()
+	if err == nil || !strings.Contains(err.Error(), "GOOGLE_CLOUD_PROJECT") {
+		t.Errorf("got error %v, want one naming GOOGLE_CLOUD_PROJECT", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "my-project")
+	t.Setenv("BIGQUERY_DATASET", "finance")
+	t.Setenv("BIGQUERY_TABLE", "billing")
+	t.Setenv("BIGQUERY_BILLING_EXPORT_TABLE", "export")
+
+	want := Config{ProjectID: "my-project", Dataset: "finance", Table: "billing", BillingExportTable: "export", Retry: DefaultRetryPolicy}
+	if got := ConfigFromEnv(); got != want {
+		t.Errorf("ConfigFromEnv() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClientsResolveTheirOwnBillingTable(t *testing.T) {
+	// The environment names a third table that neither client may fall back to
+	t.Setenv("BIGQUERY_DATASET", "env-project")
+	t.Setenv("BIGQUERY_TABLE", "env")
+	t.Setenv("BIGQUERY_BILLING_EXPORT_TABLE", "env_export")
+
+	first := &Client{config: Config{Dataset: "project-a", Table: "billing", BillingExportTable: "export_a"}}
+	second := &Client{config: Config{Dataset: "project-b", Table: "billing", BillingExportTable: "export_b"}}
+	for client, want := range map[*Client]string{first: "`project-a.billing.export_a`", second: "`project-b.billing.export_b`"} {
+		if got, err := client.billingTable(); err != nil || got != want {
+			t.Errorf("billingTable() = %q, %v, want %q", got, err, want)
+		}
+	}
+}
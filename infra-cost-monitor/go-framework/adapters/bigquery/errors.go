@@ -0,0 +1,85 @@
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Sentinel error kinds returned by the BigQuery adapter. Use errors.Is to test
+// the kind, and errors.As with *googleapi.Error to inspect the underlying API error.
+var (
+	ErrAuth          = errors.New("bigquery: authentication or permission failure")
+	ErrTableNotFound = errors.New("bigquery: table not found")
+	ErrQuotaExceeded = errors.New("bigquery: quota or rate limit exceeded")
+	ErrQueryTimeout  = errors.New("bigquery: query timed out")
+)
+
+// Error is a classified BigQuery adapter error
+type Error struct {
+	Op   string
+	Kind error
+	Err  error
+}
+
+// Error returns the error message
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v: %v", e.Op, e.Kind, e.Err)
+}
+
+// Unwrap exposes both the error kind and the underlying error to errors.Is/As
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// classifyError wraps err in a typed Error when its kind can be determined
+func classifyError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if kind := errorKind(err); kind != nil {
+		return &Error{Op: op, Kind: kind, Err: err}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// errorKind maps an error to one of the sentinel kinds, or nil if unknown
+func errorKind(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrQueryTimeout
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+
+	for _, item := range apiErr.Errors {
+		switch item.Reason {
+		case "quotaExceeded", "rateLimitExceeded":
+			return ErrQuotaExceeded
+		case "notFound":
+			return ErrTableNotFound
+		case "accessDenied", "authError", "forbidden":
+			return ErrAuth
+		case "timeout":
+			return ErrQueryTimeout
+		}
+	}
+
+	switch apiErr.Code {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuth
+	case http.StatusNotFound:
+		return ErrTableNotFound
+	case http.StatusTooManyRequests:
+		return ErrQuotaExceeded
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrQueryTimeout
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"unauthorized", &googleapi.Error{Code: http.StatusUnauthorized}, ErrAuth},
+		{"forbidden", &googleapi.Error{Code: http.StatusForbidden}, ErrAuth},
+		{"access denied reason", &googleapi.Error{Code: http.StatusBadRequest, Errors: []googleapi.ErrorItem{{Reason: "accessDenied"}}}, ErrAuth},
+		{"not found", &googleapi.Error{Code: http.StatusNotFound}, ErrTableNotFound},
+		{"not found reason", &googleapi.Error{Code: http.StatusBadRequest, Errors: []googleapi.ErrorItem{{Reason: "notFound"}}}, ErrTableNotFound},
+		{"too many requests", &googleapi.Error{Code: http.StatusTooManyRequests}, ErrQuotaExceeded},
+		{"quota reason", &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "quotaExceeded"}}}, ErrQuotaExceeded},
+		{"gateway timeout", &googleapi.Error{Code: http.StatusGatewayTimeout}, ErrQueryTimeout},
+		{"deadline exceeded", fmt.Errorf("job wait: %w", context.DeadlineExceeded), ErrQueryTimeout},
+		{"unknown API error", &googleapi.Error{Code: http.StatusBadRequest}, nil},
+		{"not an API error", errors.New("boom"), nil},
+	}
+
+	kinds := []error{ErrAuth, ErrTableNotFound, ErrQuotaExceeded, ErrQueryTimeout}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError("query", tt.err)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("classified error %v does not wrap %v", err, tt.err)
+			}
+			for _, kind := range kinds {
+				if got, want := errors.Is(err, kind), kind == tt.kind; got != want {
+					t.Errorf("errors.Is(%v, %v) = %v, want %v", err, kind, got, want)
+				}
+			}
+		})
+	}
+
+	if classifyError("query", nil) != nil {
+		t.Error("classifyError(nil) is not nil")
+	}
+}
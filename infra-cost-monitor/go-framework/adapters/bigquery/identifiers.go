@@ -0,0 +1,32 @@
+package bigquery
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// identifierPattern matches project, dataset and table names that are safe to
+// interpolate into a backtick-quoted table path. Identifiers can't be passed as
+// query parameters, so anything else (backticks, semicolons, whitespace) is rejected.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+// tablePath validates the identifiers and returns the quoted `project.dataset.table` path
+func tablePath(project, dataset, table string) (string, error) {
+	for _, identifier := range []string{project, dataset, table} {
+		if !identifierPattern.MatchString(identifier) {
+			return "", fmt.Errorf("invalid BigQuery identifier %q", identifier)
+		}
+	}
+	return fmt.Sprintf("`%s.%s.%s`", project, dataset, table), nil
+}
+
+// billingTable returns the validated path of the billing export table
+func (c *Client) billingTable() (string, error) {
+	return tablePath(c.config.Dataset, c.config.Table, c.config.BillingExportTable)
+}
+
+// BillingTableID returns the unquoted path of the billing export table, which
+// identifies its rows in the query cache
+func (c *Client) BillingTableID() string {
+	return fmt.Sprintf("%s.%s.%s", c.config.Dataset, c.config.Table, c.config.BillingExportTable)
+}
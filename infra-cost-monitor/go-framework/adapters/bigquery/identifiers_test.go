@@ -0,0 +1,56 @@
+package bigquery
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTablePath(t *testing.T) {
+	tests := []struct {
+		name                    string
+		project, dataset, table string
+		want                    string
+		valid                   bool
+	}{
+		{"plain identifiers", "my-project", "billing", "gcp_billing_export_v1_0123", "`my-project.billing.gcp_billing_export_v1_0123`", true},
+		{"domain-scoped project", "example.com:my-project", "billing", "export", "`example.com:my-project.billing.export`", true},
+		{"backtick in dataset", "my-project", "billing`; DROP TABLE x; --", "export", "", false},
+		{"backtick in table", "my-project", "billing", "export`", "", false},
+		{"semicolon in project", "my-project;", "billing", "export", "", false},
+		{"whitespace in table", "my-project", "billing", "export table", "", false},
+		{"empty dataset", "my-project", "", "export", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tablePath(tt.project, tt.dataset, tt.table)
+			if (err == nil) != tt.valid {
+				t.Fatalf("tablePath error = %v, want valid %v", err, tt.valid)
+			}
+			if got != tt.want {
+				t.Errorf("tablePath = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueriesRejectInvalidIdentifiers(t *testing.T) {
+	// No BigQuery client is set: an invalid identifier must fail before any query runs
+	c := &Client{config: Config{Dataset: "my-project", Table: "billing;", BillingExportTable: "export"}}
+	ctx := context.Background()
+
+	queries := map[string]func() error{
+		"GetBillingData":      func() error { _, err := c.GetBillingData(ctx, 30); return err },
+		"GetBillingDataSince": func() error { _, err := c.GetBillingDataSince(ctx, time.Now()); return err },
+		"GetDailyCosts":       func() error { _, err := c.GetDailyCosts(ctx, 30); return err },
+		"GetServiceCosts":     func() error { _, err := c.GetServiceCosts(ctx, 30); return err },
+	}
+	for name, query := range queries {
+		t.Run(name, func(t *testing.T) {
+			if err := query(); err == nil {
+				t.Error("query with an invalid identifier succeeded")
+			}
+		})
+	}
+}
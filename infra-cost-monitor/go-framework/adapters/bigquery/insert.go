@@ -0,0 +1,87 @@
+package bigquery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// anomalyRow is the BigQuery row written for each anomaly
+type anomalyRow struct {
+	RunID        string  `bigquery:"run_id"`
+	AnomalyID    string  `bigquery:"anomaly_id"`
+	Date         string  `bigquery:"date"`
+	TestName     string  `bigquery:"test_name"`
+	Type         string  `bigquery:"type"`
+	Service      string  `bigquery:"service"`
+	SKU          string  `bigquery:"sku"`
+	CompositeKey string  `bigquery:"composite_key"`
+	CostImpact   float64 `bigquery:"cost_impact"`
+	Description  string  `bigquery:"description"`
+	Severity     string  `bigquery:"severity"`
+	DetectedAt   string  `bigquery:"detected_at"`
+}
+
+// AnomalyInsertID returns the deterministic insertId for an anomaly within a run,
+// derived from its ID, or its stable ID when none is assigned. Retrying an insert
+// for the same run produces the same IDs, so BigQuery's best-effort deduplication
+// suppresses the duplicate rows.
+func AnomalyInsertID(runID string, anomaly models.Anomaly) string {
+	hash := sha256.Sum256([]byte(runID + "\x00" + anomalyID(anomaly)))
+	return hex.EncodeToString(hash[:16])
+}
+
+// anomalyID returns the anomaly's ID, or its stable ID when none is assigned
+func anomalyID(anomaly models.Anomaly) string {
+	if anomaly.ID != "" {
+		return anomaly.ID
+	}
+	return anomaly.StableID()
+}
+
+// newAnomalyRow returns the row written for an anomaly within a run
+func newAnomalyRow(runID string, anomaly models.Anomaly) anomalyRow {
+	return anomalyRow{
+		RunID:        runID,
+		AnomalyID:    anomalyID(anomaly),
+		Date:         anomaly.Date,
+		TestName:     anomaly.TestName,
+		Type:         anomaly.Type,
+		Service:      anomaly.Service,
+		SKU:          anomaly.SKU,
+		CompositeKey: anomaly.CompositeKey,
+		CostImpact:   anomaly.CostImpact,
+		Description:  anomaly.Description,
+		Severity:     anomaly.Severity,
+		DetectedAt:   anomaly.DetectedAt,
+	}
+}
+
+// InsertAnomalies writes anomalies to a BigQuery table, retrying transient failures
+// under the client's retry policy
+func (c *Client) InsertAnomalies(dataset, table, runID string, anomalies []models.Anomaly) error {
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	savers := make([]*bigquery.StructSaver, len(anomalies))
+	for i, anomaly := range anomalies {
+		savers[i] = &bigquery.StructSaver{
+			Struct:   newAnomalyRow(runID, anomaly),
+			InsertID: AnomalyInsertID(runID, anomaly),
+		}
+	}
+
+	inserter := c.client.Dataset(dataset).Table(table).Inserter()
+	err := c.withRetry(c.ctx, "anomaly insert", func() error {
+		return inserter.Put(c.ctx, savers)
+	})
+	if err != nil {
+		return classifyError("failed to insert anomalies", err)
+	}
+	log.Printf("✅ Inserted %d anomalies into %s.%s (run %s)", len(anomalies), dataset, table, runID)
+	return nil
+}
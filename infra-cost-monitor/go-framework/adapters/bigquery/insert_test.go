@@ -0,0 +1,98 @@
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestAnomalyInsertID(t *testing.T) {
+	anomaly := models.Anomaly{TestName: "spike", Type: "spike", Date: "2024-03-01", Service: "Compute", SKU: "VM"}
+	withID := anomaly
+	withID.ID = withID.StableID()
+
+	if AnomalyInsertID("run-1", anomaly) != AnomalyInsertID("run-1", withID) {
+		t.Error("insert ID without an assigned ID does not fall back to the stable ID")
+	}
+	if AnomalyInsertID("run-1", withID) == AnomalyInsertID("run-2", withID) {
+		t.Error("insert IDs of different runs collide")
+	}
+
+	otherDate := anomaly
+	otherDate.Date = "2024-03-02"
+	if AnomalyInsertID("run-1", anomaly) == AnomalyInsertID("run-1", otherDate) {
+		t.Error("insert IDs of anomalies on different dates collide")
+	}
+
+	otherSKU := anomaly
+	otherSKU.SKU = "GPU"
+	if AnomalyInsertID("run-1", anomaly) == AnomalyInsertID("run-1", otherSKU) {
+		t.Error("insert IDs of anomalies on different SKUs collide")
+	}
+}
+
+func TestNewAnomalyRowCarriesTheAnomalyID(t *testing.T) {
+	anomaly := models.Anomaly{ID: "a1b2c3", TestName: "spike", Date: "2024-03-01", Service: "Compute"}
+	if row := newAnomalyRow("run-1", anomaly); row.AnomalyID != "a1b2c3" || row.RunID != "run-1" {
+		t.Errorf("row = %+v, want anomaly_id a1b2c3 in run-1", row)
+	}
+
+	anomaly.ID = ""
+	if row := newAnomalyRow("run-1", anomaly); row.AnomalyID != anomaly.StableID() {
+		t.Errorf("row anomaly_id = %q without an assigned ID, want the stable ID %q", row.AnomalyID, anomaly.StableID())
+	}
+}
+
+func TestWithRetryRetriesOnlyTransientErrors(t *testing.T) {
+	c := &Client{retry: RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}}
+	tests := []struct {
+		name  string
+		err   error
+		calls int
+	}{
+		{"rate limited", &googleapi.Error{Code: http.StatusTooManyRequests}, 4},
+		{"backend error", &googleapi.Error{Code: http.StatusBadRequest, Errors: []googleapi.ErrorItem{{Reason: "backendError"}}}, 4},
+		{"unavailable", &googleapi.Error{Code: http.StatusServiceUnavailable}, 4},
+		{"not found", &googleapi.Error{Code: http.StatusNotFound}, 1},
+		{"forbidden", &googleapi.Error{Code: http.StatusForbidden}, 1},
+		{"not an API error", errors.New("row too large"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := c.withRetry(context.Background(), "test", func() error {
+				calls++
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+			if calls != tt.calls {
+				t.Errorf("op called %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestWithRetryStopsWhenContextIsDone(t *testing.T) {
+	c := &Client{retry: RetryPolicy{MaxRetries: 3, BaseDelay: time.Hour}}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := c.withRetry(ctx, "test", func() error {
+		calls++
+		cancel()
+		return &googleapi.Error{Code: http.StatusServiceUnavailable}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Errorf("op called %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,65 @@
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"google.golang.org/api/iterator"
+)
+
+// RateTable is a currency rate source backed by a BigQuery table with columns
+// date, from_currency, to_currency and rate. The latest rate on or before the
+// requested day is used, so weekly or monthly rate tables work too.
+type RateTable struct {
+	client  *Client
+	ctx     context.Context
+	dataset string
+	table   string
+}
+
+// NewRateTable creates a new rate source reading from dataset.table in the client's
+// project; cancelling ctx cancels outstanding rate queries
+func NewRateTable(ctx context.Context, client *Client, dataset, table string) *RateTable {
+	return &RateTable{
+		client:  client,
+		ctx:     ctx,
+		dataset: dataset,
+		table:   table,
+	}
+}
+
+// Rate returns units of to per unit of from on day
+func (rt *RateTable) Rate(from, to string, day time.Time) (float64, error) {
+	table, err := tablePath(rt.client.client.Project(), rt.dataset, rt.table)
+	if err != nil {
+		return 0, err
+	}
+	query := fmt.Sprintf(`
+		SELECT rate
+		FROM %s
+		WHERE from_currency = @from AND to_currency = @to AND date <= @day
+		ORDER BY date DESC
+		LIMIT 1
+	`, table)
+
+	it, err := rt.client.namedQuery(rt.ctx, "exchange_rate", query,
+		bigquery.QueryParameter{Name: "from", Value: from},
+		bigquery.QueryParameter{Name: "to", Value: to},
+		bigquery.QueryParameter{Name: "day", Value: civil.DateOf(day)})
+	if err != nil {
+		return 0, err
+	}
+
+	var row struct {
+		Rate float64 `bigquery:"rate"`
+	}
+	if err := it.Next(&row); err == iterator.Done {
+		return 0, fmt.Errorf("no exchange rate in %s.%s from %s to %s on or before %s", rt.dataset, rt.table, from, to, day.Format("2006-01-02"))
+	} else if err != nil {
+		return 0, classifyError("exchange rate", err)
+	}
+	return row.Rate, nil
+}
@@ -0,0 +1,81 @@
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// RetryPolicy controls how queries failing with transient errors are retried
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+}
+
+// DefaultRetryPolicy is used by NewClient
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  time.Second,
+}
+
+// withRetry runs op, retrying retryable errors with exponential backoff and jitter.
+// It never sleeps past the context deadline and stops as soon as ctx is done.
+func (c *Client) withRetry(ctx context.Context, name string, op func() error) error {
+	err := op()
+	for attempt := 1; attempt <= c.retry.MaxRetries && err != nil && retryable(err); attempt++ {
+		delay := c.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return err
+		}
+		log.Printf("Warning: BigQuery %s failed, retrying in %v (%d/%d): %v", name, delay, attempt, c.retry.MaxRetries, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		err = op()
+	}
+	return err
+}
+
+// backoff returns an exponential delay with jitter for the given retry
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.retry.BaseDelay << uint(attempt-1)
+	if delay <= 0 {
+		return 0
+	}
+
+	// Jitter between half and the full delay spreads out concurrent retries
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// retryable reports whether err is a transient BigQuery failure: rate limiting or
+// a backend error. Invalid queries, missing tables and auth errors are not retried.
+func retryable(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	for _, item := range apiErr.Errors {
+		switch item.Reason {
+		case "rateLimitExceeded", "backendError", "internalError":
+			return true
+		}
+	}
+
+	switch apiErr.Code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// fakeRowSource stands in for a query job read, failing with err for its first
+// failures calls and then returning rows
+type fakeRowSource struct {
+	failures int
+	err      error
+	rows     []string
+	calls    int
+}
+
+func (s *fakeRowSource) read() ([]string, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, s.err
+	}
+	return s.rows, nil
+}
+
+// readWithRetry reads src under c's retry policy
+func readWithRetry(ctx context.Context, c *Client, src *fakeRowSource) ([]string, error) {
+	var rows []string
+	err := c.withRetry(ctx, "test", func() error {
+		var err error
+		rows, err = src.read()
+		return err
+	})
+	return rows, err
+}
+
+func TestWithRetrySucceedsAfterTransientFailures(t *testing.T) {
+	c := &Client{retry: RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}}
+	src := &fakeRowSource{failures: 2, err: &googleapi.Error{Code: http.StatusServiceUnavailable}, rows: []string{"a", "b"}}
+
+	rows, err := readWithRetry(context.Background(), c, src)
+	if err != nil {
+		t.Fatalf("got error %v, want success", err)
+	}
+	if len(rows) != 2 {
+		t.Errorf("got %d rows, want 2", len(rows))
+	}
+	if src.calls != 3 {
+		t.Errorf("source read %d times, want 3", src.calls)
+	}
+}
+
+func TestWithRetryGivesUpAfterMaxRetries(t *testing.T) {
+	c := &Client{retry: RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond}}
+	unavailable := &googleapi.Error{Code: http.StatusServiceUnavailable}
+	src := &fakeRowSource{failures: 2, err: unavailable}
+
+	if _, err := readWithRetry(context.Background(), c, src); !errors.Is(err, unavailable) {
+		t.Errorf("got error %v, want %v", err, unavailable)
+	}
+	if src.calls != 2 {
+		t.Errorf("source read %d times, want 2", src.calls)
+	}
+}
+
+func TestWithRetryDoesNotRetryPastDeadline(t *testing.T) {
+	c := &Client{retry: RetryPolicy{MaxRetries: 3, BaseDelay: time.Minute}}
+	unavailable := &googleapi.Error{Code: http.StatusServiceUnavailable}
+	src := &fakeRowSource{failures: 2, err: unavailable}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := readWithRetry(ctx, c, src); !errors.Is(err, unavailable) {
+		t.Errorf("got error %v, want %v", err, unavailable)
+	}
+	if src.calls != 1 {
+		t.Errorf("source read %d times, want 1", src.calls)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("waited %v for a backoff past the deadline", elapsed)
+	}
+}
+
+func TestBackoffIsExponentialWithJitter(t *testing.T) {
+	c := &Client{retry: RetryPolicy{BaseDelay: 100 * time.Millisecond}}
+	for attempt := 1; attempt <= 4; attempt++ {
+		full := c.retry.BaseDelay << uint(attempt-1)
+		for i := 0; i < 50; i++ {
+			if delay := c.backoff(attempt); delay < full/2 || delay > full {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", attempt, delay, full/2, full)
+			}
+		}
+	}
+
+	if delay := (&Client{}).backoff(1); delay != 0 {
+		t.Errorf("backoff with no base delay = %v, want 0", delay)
+	}
+}
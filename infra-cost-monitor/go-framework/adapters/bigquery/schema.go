@@ -0,0 +1,90 @@
+package bigquery
+
+import (
+	"log"
+	"sort"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// optionalBillingColumns are top-level columns that only some billing export
+// variants (standard, detailed, resource) provide
+var optionalBillingColumns = []string{"cost_type", "credits", "resource", "price", "tags"}
+
+// TableSchema returns the schema of a table in the client's project without querying it
+func (c *Client) TableSchema(dataset, table string) (bigquery.Schema, error) {
+	return c.tableSchema(c.client.Project(), dataset, table)
+}
+
+func (c *Client) tableSchema(project, dataset, table string) (bigquery.Schema, error) {
+	metadata, err := c.client.DatasetInProject(project, dataset).Table(table).Metadata(c.ctx)
+	if err != nil {
+		return nil, classifyError("table schema", err)
+	}
+	return metadata.Schema, nil
+}
+
+// BillingColumns returns which optional columns the billing export table has. The
+// schema is read once; a failed read is retried on the next call.
+func (c *Client) BillingColumns() (map[string]bool, error) {
+	c.columnsMu.Lock()
+	defer c.columnsMu.Unlock()
+	if c.billingColumns != nil {
+		return c.billingColumns, nil
+	}
+
+	schema, err := c.tableSchema(c.config.Dataset, c.config.Table, c.config.BillingExportTable)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[string]bool)
+	for _, field := range schema {
+		present[field.Name] = true
+	}
+
+	columns := make(map[string]bool)
+	for _, name := range optionalBillingColumns {
+		columns[name] = present[name]
+	}
+	c.billingColumns = columns
+	return columns, nil
+}
+
+// AvailableBillingColumns returns the optional billing columns that are present, sorted
+func AvailableBillingColumns(columns map[string]bool) []string {
+	var available []string
+	for name, present := range columns {
+		if present {
+			available = append(available, name)
+		}
+	}
+	sort.Strings(available)
+	return available
+}
+
+// billingColumnSQL returns the extra SELECT and GROUP BY fragments for the
+// optional columns present in the billing export, so queries never reference
+// a column the export variant lacks
+func (c *Client) billingColumnSQL() (string, string) {
+	columns, err := c.BillingColumns()
+	if err != nil {
+		// Fall back to the standard export columns
+		log.Printf("Warning: Could not read billing export schema, assuming standard columns: %v", err)
+		columns = map[string]bool{"cost_type": true}
+	}
+
+	selectSQL, groupSQL := "", ""
+	if columns["cost_type"] {
+		selectSQL += ",\n\t\t\tcost_type"
+		groupSQL += ", cost_type"
+	} else {
+		selectSQL += ",\n\t\t\t'' as cost_type"
+	}
+	if columns["credits"] {
+		selectSQL += ",\n\t\t\tSUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0)) as credits"
+	} else {
+		selectSQL += ",\n\t\t\t0.0 as credits"
+	}
+	return selectSQL, groupSQL
+}
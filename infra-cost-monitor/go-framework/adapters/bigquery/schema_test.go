@@ -0,0 +1,63 @@
+package bigquery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBillingColumnSQLAdaptsToTheExportVariant(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns map[string]bool
+		selects []string
+		group   string
+	}{
+		{
+			"detailed export",
+			map[string]bool{"cost_type": true, "credits": true},
+			[]string{"\t\t\tcost_type", "FROM UNNEST(credits) c"},
+			", cost_type",
+		},
+		{
+			"no optional columns",
+			map[string]bool{"cost_type": false, "credits": false},
+			[]string{"'' as cost_type", "0.0 as credits"},
+			"",
+		},
+		{
+			"credits without cost_type",
+			map[string]bool{"credits": true},
+			[]string{"'' as cost_type", "FROM UNNEST(credits) c"},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{billingColumns: tt.columns}
+			selectSQL, groupSQL := client.billingColumnSQL()
+			for _, fragment := range tt.selects {
+				if !strings.Contains(selectSQL, fragment) {
+					t.Errorf("SELECT fragment %q does not contain %q", selectSQL, fragment)
+				}
+			}
+			if groupSQL != tt.group {
+				t.Errorf("GROUP BY fragment = %q, want %q", groupSQL, tt.group)
+			}
+			if !tt.columns["credits"] && strings.Contains(selectSQL, "UNNEST(credits)") {
+				t.Errorf("SELECT fragment %q references the missing credits column", selectSQL)
+			}
+		})
+	}
+}
+
+func TestAvailableBillingColumns(t *testing.T) {
+	columns := map[string]bool{"tags": true, "cost_type": true, "credits": false, "resource": true}
+	if got, want := AvailableBillingColumns(columns), []string{"cost_type", "resource", "tags"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AvailableBillingColumns() = %v, want %v", got, want)
+	}
+	if got := AvailableBillingColumns(map[string]bool{"credits": false}); len(got) != 0 {
+		t.Errorf("AvailableBillingColumns() = %v, want none", got)
+	}
+}
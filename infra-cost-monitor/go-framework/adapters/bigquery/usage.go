@@ -0,0 +1,64 @@
+package bigquery
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"sync"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// bytesPerTiB is the unit BigQuery on-demand query pricing is quoted in
+const bytesPerTiB = 1 << 40
+
+// usage records the bytes processed and billed by every query run in this
+// process, across all clients, so the monitor can report its own footprint
+var usage = struct {
+	sync.Mutex
+	queries []models.QueryUsage
+}{}
+
+// recordUsage records the statistics of a completed query job
+func recordUsage(name string, job *bigquery.Job, status *bigquery.JobStatus) {
+	if status == nil || status.Statistics == nil {
+		return
+	}
+
+	entry := models.QueryUsage{
+		Name:           name,
+		JobID:          job.ID(),
+		BytesProcessed: status.Statistics.TotalBytesProcessed,
+	}
+	if details, ok := status.Statistics.Details.(*bigquery.QueryStatistics); ok {
+		entry.BytesBilled = details.TotalBytesBilled
+		entry.CacheHit = details.CacheHit
+	}
+
+	usage.Lock()
+	defer usage.Unlock()
+	usage.queries = append(usage.queries, entry)
+}
+
+// SelfCost returns the queries run so far with their estimated on-demand cost
+// at the given price per TiB billed
+func SelfCost(pricePerTiB float64) models.SelfCostReport {
+	usage.Lock()
+	defer usage.Unlock()
+
+	report := models.SelfCostReport{
+		PricePerTiB: pricePerTiB,
+		Queries:     make([]models.QueryUsage, len(usage.queries)),
+	}
+	var heaviest int64 = -1
+	for i, query := range usage.queries {
+		query.EstimatedCost = float64(query.BytesBilled) / bytesPerTiB * pricePerTiB
+		report.Queries[i] = query
+		report.TotalBytesProcessed += query.BytesProcessed
+		report.TotalBytesBilled += query.BytesBilled
+		report.EstimatedCost += query.EstimatedCost
+		if query.BytesBilled > heaviest {
+			heaviest = query.BytesBilled
+			report.HeaviestQuery = query.Name
+		}
+	}
+	return report
+}
@@ -0,0 +1,73 @@
+package bigquery
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// resetUsage clears the recorded query usage before and after a test
+func resetUsage(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		usage.Lock()
+		usage.queries = nil
+		usage.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// queryStatus returns the status of a finished query job
+func queryStatus(processed, billed int64, cacheHit bool) *bigquery.JobStatus {
+	return &bigquery.JobStatus{
+		State: bigquery.Done,
+		Statistics: &bigquery.JobStatistics{
+			TotalBytesProcessed: processed,
+			Details:             &bigquery.QueryStatistics{TotalBytesBilled: billed, CacheHit: cacheHit},
+		},
+	}
+}
+
+func TestSelfCostSumsRecordedQueries(t *testing.T) {
+	resetUsage(t)
+	job := &bigquery.Job{}
+	recordUsage("daily", job, queryStatus(3<<40, 2<<40, false))
+	recordUsage("dimensional", job, queryStatus(5<<40, 4<<40, false))
+	recordUsage("mtd", job, queryStatus(1<<40, 0, true))
+	recordUsage("no statistics", job, &bigquery.JobStatus{State: bigquery.Done})
+	recordUsage("no status", job, nil)
+
+	report := SelfCost(6.25)
+
+	if len(report.Queries) != 3 {
+		t.Fatalf("got %d queries, want the 3 with statistics", len(report.Queries))
+	}
+	if report.TotalBytesProcessed != 9<<40 || report.TotalBytesBilled != 6<<40 {
+		t.Errorf("totals = %d processed, %d billed, want %d and %d", report.TotalBytesProcessed, report.TotalBytesBilled, int64(9<<40), int64(6<<40))
+	}
+	if report.EstimatedCost != 37.5 || report.PricePerTiB != 6.25 {
+		t.Errorf("estimated cost = %v at %v per TiB, want 37.5 at 6.25", report.EstimatedCost, report.PricePerTiB)
+	}
+	if report.HeaviestQuery != "dimensional" {
+		t.Errorf("heaviest query = %q, want dimensional", report.HeaviestQuery)
+	}
+
+	wantCosts := map[string]float64{"daily": 12.5, "dimensional": 25, "mtd": 0}
+	for _, query := range report.Queries {
+		if query.EstimatedCost != wantCosts[query.Name] {
+			t.Errorf("%s estimated cost = %v, want %v", query.Name, query.EstimatedCost, wantCosts[query.Name])
+		}
+		if query.CacheHit != (query.Name == "mtd") {
+			t.Errorf("%s cache hit = %v", query.Name, query.CacheHit)
+		}
+	}
+}
+
+func TestSelfCostWithNoQueries(t *testing.T) {
+	resetUsage(t)
+	report := SelfCost(6.25)
+	if report.EstimatedCost != 0 || report.HeaviestQuery != "" || len(report.Queries) != 0 {
+		t.Errorf("report = %+v, want an empty report", report)
+	}
+}
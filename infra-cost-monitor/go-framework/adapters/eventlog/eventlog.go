@@ -0,0 +1,133 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Event types recorded in the log
+const (
+	EventDetected   = "detected"
+	EventSuppressed = "suppressed"
+	EventNotified   = "notified"
+)
+
+// Notification outcomes
+const (
+	OutcomeSent    = "sent"
+	OutcomeFailed  = "failed"
+	OutcomeSkipped = "skipped"
+)
+
+// Event is a single line of the event log
+type Event struct {
+	Time      string `json:"time"`
+	RunID     string `json:"run_id"`
+	Event     string `json:"event"`
+	AnomalyID string `json:"anomaly_id"`
+	Detector  string `json:"detector,omitempty"`
+	Severity  string `json:"severity,omitempty"`
+	Notifier  string `json:"notifier,omitempty"`
+	Outcome   string `json:"outcome,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Log is an append-only JSON lines log of detections and notifications.
+// A nil *Log discards all events, so callers need not check whether logging is enabled.
+type Log struct {
+	runID string
+	mu    sync.Mutex
+	file  *os.File
+}
+
+// Open opens the event log for appending, creating it if needed
+func Open(path, runID string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create event log directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %v", err)
+	}
+
+	return &Log{
+		runID: runID,
+		file:  file,
+	}, nil
+}
+
+// Close closes the event log
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// Detected records that an anomaly was detected
+func (l *Log) Detected(anomaly models.Anomaly) error {
+	if l == nil {
+		return nil
+	}
+	return l.write(l.newEvent(EventDetected, anomaly))
+}
+
+// Suppressed records that an anomaly was dropped because it was acked or snoozed
+func (l *Log) Suppressed(anomaly models.Anomaly) error {
+	if l == nil {
+		return nil
+	}
+	return l.write(l.newEvent(EventSuppressed, anomaly))
+}
+
+// Notified records the outcome of sending anomalies through a notifier
+func (l *Log) Notified(notifier, outcome string, anomalies []models.Anomaly, notifyErr error) error {
+	if l == nil {
+		return nil
+	}
+	for _, anomaly := range anomalies {
+		event := l.newEvent(EventNotified, anomaly)
+		event.Notifier = notifier
+		event.Outcome = outcome
+		if notifyErr != nil {
+			event.Error = notifyErr.Error()
+		}
+		if err := l.write(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Log) newEvent(eventType string, anomaly models.Anomaly) Event {
+	return Event{
+		Time:      time.Now().UTC().Format(time.RFC3339),
+		RunID:     l.runID,
+		Event:     eventType,
+		AnomalyID: anomaly.ID,
+		Detector:  anomaly.Type,
+		Severity:  anomaly.Severity,
+	}
+}
+
+// write appends one event as a JSON line
+func (l *Log) write(event Event) error {
+	if l == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(line, '\n'))
+	return err
+}
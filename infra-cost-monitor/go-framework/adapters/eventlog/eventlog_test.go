@@ -0,0 +1,68 @@
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNilLogDiscardsEvents(t *testing.T) {
+	var events *Log
+	anomaly := models.Anomaly{ID: "a1"}
+	if err := events.Detected(anomaly); err != nil {
+		t.Errorf("Detected on a nil log: %v", err)
+	}
+	if err := events.Notified("slack", "sent", []models.Anomaly{anomaly}, nil); err != nil {
+		t.Errorf("Notified on a nil log: %v", err)
+	}
+	if err := events.Close(); err != nil {
+		t.Errorf("Close on a nil log: %v", err)
+	}
+}
+
+func TestLogAppendsEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "events.jsonl")
+	events, err := Open(path, "run-1")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	anomaly := models.Anomaly{ID: "a1", Type: "spike", Severity: models.SeverityHigh}
+	if err := events.Detected(anomaly); err != nil {
+		t.Fatalf("Detected: %v", err)
+	}
+	if err := events.Notified("slack", "failed", []models.Anomaly{anomaly}, errors.New("timeout")); err != nil {
+		t.Fatalf("Notified: %v", err)
+	}
+	if err := events.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+
+	var logged []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid event line %q: %v", scanner.Text(), err)
+		}
+		logged = append(logged, event)
+	}
+	if len(logged) != 2 {
+		t.Fatalf("got %d events, want 2", len(logged))
+	}
+	if logged[0].Event != EventDetected || logged[0].RunID != "run-1" || logged[0].AnomalyID != "a1" {
+		t.Errorf("got %+v, want a detected event for a1 in run-1", logged[0])
+	}
+	if logged[1].Event != EventNotified || logged[1].Notifier != "slack" || logged[1].Error != "timeout" {
+		t.Errorf("got %+v, want a failed slack notification", logged[1])
+	}
+}
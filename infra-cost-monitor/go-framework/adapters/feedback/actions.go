@@ -0,0 +1,196 @@
+package feedback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/state"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRequestAge rejects replayed Slack requests older than this
+const maxRequestAge = 5 * time.Minute
+
+// defaultSnooze is used when a snooze action does not specify a duration
+const defaultSnooze = 24 * time.Hour
+
+// ActionHandler receives ack/snooze actions from Slack interactive buttons.
+// Button values take the form "ack:<anomaly_id>" or "snooze:<anomaly_id>:<duration>".
+type ActionHandler struct {
+	store         *state.Store
+	signingSecret string
+	now           func() time.Time
+}
+
+// NewActionHandler creates a new action handler backed by the state store
+func NewActionHandler(store *state.Store, signingSecret string) *ActionHandler {
+	return &ActionHandler{
+		store:         store,
+		signingSecret: signingSecret,
+		now:           time.Now,
+	}
+}
+
+// interactionPayload is the subset of the Slack interaction payload we use
+type interactionPayload struct {
+	User struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// ServeHTTP validates the request signature and records the requested action
+func (ah *ActionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := ah.verifySignature(r.Header, body); err != nil {
+		log.Printf("Warning: Rejected feedback request: %v", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	var payload interactionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	// Record the actions in one locked read-modify-write of the state file, so
+	// concurrent requests cannot drop each other's actions
+	var messages []string
+	var invalid error
+	err = ah.store.Update(func(st *state.State) error {
+		messages = nil
+		for _, action := range payload.Actions {
+			recorded, err := ah.parseAction(action.Value, payload.User.Name, st.Anomalies)
+			if err != nil {
+				invalid = err
+				return err
+			}
+			st.Actions[recorded.AnomalyID] = recorded
+			messages = append(messages, describeAction(recorded))
+		}
+		return nil
+	})
+	if invalid != nil {
+		http.Error(w, invalid.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating state store: %v", err)
+		http.Error(w, "failed to persist action", http.StatusInternalServerError)
+		return
+	}
+
+	for _, message := range messages {
+		log.Printf("✅ %s", message)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"response_type": "in_channel",
+		"text":          strings.Join(messages, "\n"),
+	})
+}
+
+// verifySignature checks the Slack v0 request signature and timestamp
+func (ah *ActionHandler) verifySignature(header http.Header, body []byte) error {
+	if ah.signingSecret == "" {
+		return fmt.Errorf("no signing secret configured")
+	}
+
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid request timestamp %q", timestamp)
+	}
+	age := ah.now().Sub(time.Unix(seconds, 0))
+	if age > maxRequestAge || age < -maxRequestAge {
+		return fmt.Errorf("request timestamp outside allowed window")
+	}
+
+	mac := hmac.New(sha256.New, []byte(ah.signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// parseAction converts a button value into an anomaly action, resolving the
+// anomaly's key from the remembered anomalies
+func (ah *ActionHandler) parseAction(value, user string, anomalies map[string]string) (state.AnomalyAction, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || parts[1] == "" {
+		return state.AnomalyAction{}, fmt.Errorf("invalid action value %q", value)
+	}
+
+	key, known := anomalies[parts[1]]
+	if !known {
+		return state.AnomalyAction{}, fmt.Errorf("unknown anomaly %s", parts[1])
+	}
+
+	action := state.AnomalyAction{
+		AnomalyID: parts[1],
+		Key:       key,
+		User:      user,
+		At:        ah.now(),
+	}
+
+	switch parts[0] {
+	case state.ActionAck:
+		action.Action = state.ActionAck
+	case state.ActionSnooze:
+		duration := defaultSnooze
+		if len(parts) > 2 {
+			parsed, err := time.ParseDuration(parts[2])
+			if err != nil || parsed <= 0 {
+				return state.AnomalyAction{}, fmt.Errorf("invalid snooze duration %q", parts[2])
+			}
+			duration = parsed
+		}
+		action.Action = state.ActionSnooze
+		action.Until = action.At.Add(duration)
+	default:
+		return state.AnomalyAction{}, fmt.Errorf("unknown action %q", parts[0])
+	}
+
+	return action, nil
+}
+
+// describeAction returns a human-readable confirmation of an action
+func describeAction(action state.AnomalyAction) string {
+	if action.Action == state.ActionSnooze {
+		return fmt.Sprintf("%s snoozed %s until %s", action.User, action.Key, action.Until.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s acknowledged anomaly %s (%s)", action.User, action.AnomalyID, action.Key)
+}
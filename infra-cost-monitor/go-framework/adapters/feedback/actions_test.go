@@ -0,0 +1,161 @@
+package feedback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"infra-cost-monitor/go-framework/state"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testSecret = "signing-secret"
+
+var testNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// sign returns the Slack v0 signature of body at timestamp
+func sign(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := "payload=%7B%7D"
+	now := strconv.FormatInt(testNow.Unix(), 10)
+	stale := strconv.FormatInt(testNow.Add(-10*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		valid     bool
+	}{
+		{"valid", testSecret, now, sign(testSecret, now, body), true},
+		{"wrong secret", testSecret, now, sign("other", now, body), false},
+		{"tampered body", testSecret, now, sign(testSecret, now, body+"x"), false},
+		{"stale timestamp", testSecret, stale, sign(testSecret, stale, body), false},
+		{"invalid timestamp", testSecret, "soon", sign(testSecret, "soon", body), false},
+		{"missing signature", testSecret, now, "", false},
+		{"no secret configured", "", now, sign("", now, body), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewActionHandler(nil, tt.secret)
+			handler.now = func() time.Time { return testNow }
+
+			header := http.Header{}
+			header.Set("X-Slack-Request-Timestamp", tt.timestamp)
+			header.Set("X-Slack-Signature", tt.signature)
+			err := handler.verifySignature(header, []byte(body))
+			if (err == nil) != tt.valid {
+				t.Errorf("verifySignature() error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestServeHTTPRecordsSignedAck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	store.RememberAnomaly("a1", "Compute")
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	handler := NewActionHandler(store, testSecret)
+	handler.now = func() time.Time { return testNow }
+
+	body := url.Values{"payload": {`{"user":{"name":"alex"},"actions":[{"value":"ack:a1"}]}`}}.Encode()
+	timestamp := strconv.FormatInt(testNow.Unix(), 10)
+
+	// An unsigned request is rejected and records nothing
+	request := httptest.NewRequest(http.MethodPost, "/slack/actions", strings.NewReader(body))
+	request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned request got status %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/slack/actions", strings.NewReader(body))
+	request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	request.Header.Set("X-Slack-Signature", sign(testSecret, timestamp, body))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("signed request got status %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+
+	saved, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !saved.Suppressed("a1", "Compute", testNow) {
+		t.Error("signed ack was not persisted")
+	}
+}
+
+func TestServeHTTPConcurrentAcksAreAllPersisted(t *testing.T) {
+	const requests = 20
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for i := 0; i < requests; i++ {
+		store.RememberAnomaly(fmt.Sprintf("a%d", i), fmt.Sprintf("Service%d", i))
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	handler := NewActionHandler(store, testSecret)
+	handler.now = func() time.Time { return testNow }
+	timestamp := strconv.FormatInt(testNow.Unix(), 10)
+
+	var wg sync.WaitGroup
+	codes := make([]int, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			payload := fmt.Sprintf(`{"user":{"name":"alex"},"actions":[{"value":"ack:a%d"}]}`, i)
+			body := url.Values{"payload": {payload}}.Encode()
+			request := httptest.NewRequest(http.MethodPost, "/slack/actions", strings.NewReader(body))
+			request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+			request.Header.Set("X-Slack-Signature", sign(testSecret, timestamp, body))
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			codes[i] = recorder.Code
+		}(i)
+	}
+	wg.Wait()
+
+	saved, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for i := 0; i < requests; i++ {
+		if codes[i] != http.StatusOK {
+			t.Errorf("request %d got status %d, want %d", i, codes[i], http.StatusOK)
+		}
+		if !saved.Suppressed(fmt.Sprintf("a%d", i), fmt.Sprintf("Service%d", i), testNow) {
+			t.Errorf("ack for a%d was lost", i)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package feedback
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/adapters/metrics"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Verdicts accepted by the label endpoint
+const (
+	VerdictTruePositive  = "true_positive"
+	VerdictFalsePositive = "false_positive"
+)
+
+// labelRequest is the JSON body of a label request
+type labelRequest struct {
+	AnomalyID string `json:"anomaly_id"`
+	Verdict   string `json:"verdict"`
+	User      string `json:"user"`
+}
+
+// LabelHandler records true/false positive verdicts on past anomalies by ID.
+// Requests must carry "Authorization: Bearer <token>".
+type LabelHandler struct {
+	store *state.Store
+	token string
+	now   func() time.Time
+}
+
+// NewLabelHandler creates a new label handler backed by the state store
+func NewLabelHandler(store *state.Store, token string) *LabelHandler {
+	return &LabelHandler{
+		store: store,
+		token: token,
+		now:   time.Now,
+	}
+}
+
+// ServeHTTP validates the token and persists the verdict
+func (lh *LabelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if lh.token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(lh.token)) != 1 {
+		log.Println("Warning: Rejected label request: invalid token")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	var request labelRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if request.Verdict != VerdictTruePositive && request.Verdict != VerdictFalsePositive {
+		http.Error(w, fmt.Sprintf("verdict must be %s or %s", VerdictTruePositive, VerdictFalsePositive), http.StatusBadRequest)
+		return
+	}
+
+	// Record the label in one locked read-modify-write of the state file, so
+	// concurrent requests cannot drop each other's labels
+	var detector string
+	var unknown bool
+	err = lh.store.Update(func(st *state.State) error {
+		var known bool
+		detector, known = st.AnomalyDetectors[request.AnomalyID]
+		if !known {
+			unknown = true
+			return fmt.Errorf("unknown anomaly %s", request.AnomalyID)
+		}
+		st.Labels[request.AnomalyID] = state.AnomalyLabel{
+			AnomalyID:    request.AnomalyID,
+			Detector:     detector,
+			TruePositive: request.Verdict == VerdictTruePositive,
+			User:         request.User,
+			At:           lh.now(),
+		}
+		return nil
+	})
+	if unknown {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating state store: %v", err)
+		http.Error(w, "failed to persist label", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ %s labeled anomaly %s (%s) as %s", request.User, request.AnomalyID, detector, request.Verdict)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DetectorPrecision computes per-detector precision from the verdicts recorded since the given time.
+// It feeds the run summary and /metrics only; the tree has no Simulate what-if
+// tool, so replaying labels through detector settings is out of scope here.
+func DetectorPrecision(labels []state.AnomalyLabel, since time.Time) []models.DetectorPrecision {
+	byDetector := make(map[string]*models.DetectorPrecision)
+	for _, label := range labels {
+		if label.At.Before(since) {
+			continue
+		}
+		stats, exists := byDetector[label.Detector]
+		if !exists {
+			stats = &models.DetectorPrecision{Detector: label.Detector}
+			byDetector[label.Detector] = stats
+		}
+		if label.TruePositive {
+			stats.TruePositives++
+		} else {
+			stats.FalsePositives++
+		}
+	}
+
+	result := make([]models.DetectorPrecision, 0, len(byDetector))
+	for _, stats := range byDetector {
+		stats.Precision = float64(stats.TruePositives) / float64(stats.TruePositives+stats.FalsePositives)
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Detector < result[j].Detector
+	})
+	return result
+}
+
+// MetricsHandler exposes per-detector feedback counts and precision, computed
+// from the labels in the state store on each scrape
+type MetricsHandler struct {
+	mu        sync.Mutex
+	store     *state.Store
+	window    time.Duration
+	precision *metrics.Precision
+	handler   http.Handler
+}
+
+// NewMetricsHandler creates a new metrics handler computing precision over the window
+func NewMetricsHandler(store *state.Store, window time.Duration) *MetricsHandler {
+	registry := prometheus.NewRegistry()
+	return &MetricsHandler{
+		store:     store,
+		window:    window,
+		precision: metrics.NewPrecision(registry),
+		handler:   promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}
+}
+
+// ServeHTTP writes the current detector metrics
+func (mh *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := mh.store.Reload(); err != nil {
+		log.Printf("Error reloading state store: %v", err)
+		http.Error(w, "state unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	// Hold the gauges steady until this scrape has been written
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+	mh.precision.Set(DetectorPrecision(mh.store.Labels(), time.Now().Add(-mh.window)))
+	mh.handler.ServeHTTP(w, r)
+}
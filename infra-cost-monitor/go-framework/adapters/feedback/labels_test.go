@@ -0,0 +1,204 @@
+package feedback
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testToken = "label-token"
+
+// newLabelStore returns a saved store that remembers the given anomaly IDs as spike anomalies
+func newLabelStore(t *testing.T, ids ...string) (*state.Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, id := range ids {
+		store.RememberDetector(id, "spike")
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return store, path
+}
+
+// postLabel sends a label request through the handler and returns the status code
+func postLabel(handler *LabelHandler, token, body string) int {
+	request := httptest.NewRequest(http.MethodPost, "/labels", strings.NewReader(body))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func TestLabelHandler(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		want  int
+	}{
+		{"true positive", testToken, `{"anomaly_id":"a1","verdict":"true_positive","user":"alex"}`, http.StatusNoContent},
+		{"false positive", testToken, `{"anomaly_id":"a1","verdict":"false_positive","user":"alex"}`, http.StatusNoContent},
+		{"missing token", "", `{"anomaly_id":"a1","verdict":"true_positive"}`, http.StatusUnauthorized},
+		{"wrong token", "other", `{"anomaly_id":"a1","verdict":"true_positive"}`, http.StatusUnauthorized},
+		{"invalid JSON", testToken, `{`, http.StatusBadRequest},
+		{"unknown verdict", testToken, `{"anomaly_id":"a1","verdict":"maybe"}`, http.StatusBadRequest},
+		{"unknown anomaly", testToken, `{"anomaly_id":"a2","verdict":"true_positive"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, path := newLabelStore(t, "a1")
+			handler := NewLabelHandler(store, testToken)
+			handler.now = func() time.Time { return testNow }
+
+			if got := postLabel(handler, tt.token, tt.body); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+
+			saved, err := state.Load(path)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			labels := saved.Labels()
+			if tt.want != http.StatusNoContent {
+				if len(labels) != 0 {
+					t.Errorf("rejected request persisted labels %+v", labels)
+				}
+				return
+			}
+			if len(labels) != 1 {
+				t.Fatalf("persisted %d labels, want 1", len(labels))
+			}
+			label := labels[0]
+			wantTruePositive := tt.name == "true positive"
+			if label.AnomalyID != "a1" || label.Detector != "spike" || label.TruePositive != wantTruePositive || label.User != "alex" || !label.At.Equal(testNow) {
+				t.Errorf("persisted label %+v, want a1/spike/%v by alex at %v", label, wantTruePositive, testNow)
+			}
+		})
+	}
+}
+
+func TestLabelHandlerConcurrentLabelsAreAllPersisted(t *testing.T) {
+	const requests = 20
+
+	ids := make([]string, requests)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("a%d", i)
+	}
+	store, path := newLabelStore(t, ids...)
+	handler := NewLabelHandler(store, testToken)
+
+	var wg sync.WaitGroup
+	codes := make([]int, requests)
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			codes[i] = postLabel(handler, testToken, fmt.Sprintf(`{"anomaly_id":%q,"verdict":"true_positive"}`, id))
+		}(i, id)
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusNoContent {
+			t.Errorf("request %d got status %d, want %d", i, code, http.StatusNoContent)
+		}
+	}
+	saved, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := len(saved.Labels()); got != requests {
+		t.Errorf("persisted %d labels, want %d", got, requests)
+	}
+}
+
+func TestDetectorPrecision(t *testing.T) {
+	since := testNow.Add(-7 * 24 * time.Hour)
+	label := func(detector string, truePositive bool, at time.Time) state.AnomalyLabel {
+		return state.AnomalyLabel{Detector: detector, TruePositive: truePositive, At: at}
+	}
+
+	tests := []struct {
+		name   string
+		labels []state.AnomalyLabel
+		want   []models.DetectorPrecision
+	}{
+		{"no labels", nil, []models.DetectorPrecision{}},
+		{
+			"precision by detector, sorted",
+			[]state.AnomalyLabel{
+				label("spike", true, testNow),
+				label("spike", true, testNow),
+				label("spike", true, testNow),
+				label("spike", false, testNow),
+				label("drop", false, testNow),
+			},
+			[]models.DetectorPrecision{
+				{Detector: "drop", FalsePositives: 1, Precision: 0},
+				{Detector: "spike", TruePositives: 3, FalsePositives: 1, Precision: 0.75},
+			},
+		},
+		{
+			"labels before the window are ignored",
+			[]state.AnomalyLabel{
+				label("spike", false, since.Add(-time.Second)),
+				label("spike", true, since),
+				label("drop", false, since.Add(-time.Hour)),
+			},
+			[]models.DetectorPrecision{{Detector: "spike", TruePositives: 1, Precision: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectorPrecision(tt.labels, since)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectorPrecision() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsHandlerServesPrecision(t *testing.T) {
+	store, _ := newLabelStore(t, "a1", "a2")
+	now := time.Now()
+	store.RecordLabel(state.AnomalyLabel{AnomalyID: "a1", Detector: "spike", TruePositive: true, At: now})
+	store.RecordLabel(state.AnomalyLabel{AnomalyID: "a2", Detector: "spike", TruePositive: false, At: now})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewMetricsHandler(store, 24*time.Hour).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(recorder.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	for _, want := range []string{
+		"# TYPE cost_monitor_detector_labeled_anomalies gauge",
+		`cost_monitor_detector_labeled_anomalies{detector="spike",verdict="true_positive"} 1`,
+		`cost_monitor_detector_labeled_anomalies{detector="spike",verdict="false_positive"} 1`,
+		`cost_monitor_detector_precision{detector="spike"} 0.5`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+}
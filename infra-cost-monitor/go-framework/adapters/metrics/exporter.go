@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Exporter exposes the results of the latest monitor run to Prometheus: gauges for
+// the daily cost (total and by service and project), the MTD cost, the forecast
+// month-end cost, the anomalies detected, by severity, and detector precision. The gauges live in the
+// exporter's own registry, so several exporters never share state.
+type Exporter struct {
+	registry *prometheus.Registry
+	handler  http.Handler
+
+	dailyCost          prometheus.Gauge
+	dailyCostByService *prometheus.GaugeVec
+	mtdCost            prometheus.Gauge
+	forecast           *prometheus.GaugeVec
+	anomalies          *prometheus.GaugeVec
+	precision          *Precision
+}
+
+// NewExporter creates a new metrics exporter
+func NewExporter() *Exporter {
+	e := &Exporter{
+		registry: prometheus.NewRegistry(),
+		dailyCost: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cost_monitor_daily_cost",
+			Help: "Total cost of the latest billing day.",
+		}),
+		dailyCostByService: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cost_monitor_daily_cost_by_service",
+			Help: "Cost of the latest billing day by service and project.",
+		}, []string{"service", "project"}),
+		mtdCost: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cost_monitor_mtd_cost",
+			Help: "Month-to-date cost of the current month.",
+		}),
+		// A vector without labels, so the gauge is only exported once a run has a forecast
+		forecast: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cost_monitor_forecast_month_end_cost",
+			Help: "Projected full-month cost of the current month.",
+		}, nil),
+		anomalies: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cost_monitor_anomalies",
+			Help: "Anomalies detected by the latest run, by severity.",
+		}, []string{"severity"}),
+	}
+	e.registry.MustRegister(e.dailyCost, e.dailyCostByService, e.mtdCost, e.forecast, e.anomalies)
+	e.precision = NewPrecision(e.registry)
+	e.handler = promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+	return e
+}
+
+// Observe records a run, replacing the values of the previous one
+func (e *Exporter) Observe(summary models.Summary, compositeData []models.CostData, anomalies []models.Anomaly) {
+	// Break down the latest day of the composite data
+	var latest string
+	for _, cost := range compositeData {
+		if cost.Date > latest {
+			latest = cost.Date
+		}
+	}
+	e.dailyCostByService.Reset()
+	for _, cost := range compositeData {
+		if cost.Date == latest {
+			e.dailyCostByService.WithLabelValues(cost.Service, cost.ProjectID).Add(cost.Cost)
+		}
+	}
+
+	e.dailyCost.Set(summary.CurrentDateCost)
+	e.mtdCost.Set(summary.CurrentMonthCost)
+	e.forecast.Reset()
+	if summary.MonthEndForecast != nil {
+		e.forecast.WithLabelValues().Set(summary.MonthEndForecast.Projected)
+	}
+
+	bySeverity := make(map[string]int)
+	for _, anomaly := range anomalies {
+		bySeverity[anomaly.Severity]++
+	}
+	for _, severity := range []string{models.SeverityLow, models.SeverityMedium, models.SeverityHigh, models.SeverityCritical} {
+		e.anomalies.WithLabelValues(severity).Set(float64(bySeverity[severity]))
+	}
+	e.precision.Set(summary.DetectorPrecision)
+}
+
+// Registry returns the registry holding the exporter's metrics
+func (e *Exporter) Registry() *prometheus.Registry {
+	return e.registry
+}
+
+// ServeHTTP serves the current metrics for scraping
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.handler.ServeHTTP(w, r)
+}
+
+// WriteTextfile writes the current metrics to a file, for scheduled runs scraped
+// through the node exporter's textfile collector. The file is replaced atomically.
+func (e *Exporter) WriteTextfile(path string) error {
+	return prometheus.WriteToTextfile(path, e.registry)
+}
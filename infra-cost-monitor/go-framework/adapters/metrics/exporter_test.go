@@ -0,0 +1,206 @@
+package metrics
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// sample is one gathered series
+type sample struct {
+	name   string
+	kind   dto.MetricType
+	labels map[string]string
+	value  float64
+}
+
+// gather collects the exporter's series from its registry
+func gather(t *testing.T, e *Exporter) []sample {
+	t.Helper()
+	families, err := e.Registry().Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	var samples []sample
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			s := sample{name: family.GetName(), kind: family.GetType(), labels: make(map[string]string)}
+			for _, pair := range metric.GetLabel() {
+				s.labels[pair.GetName()] = pair.GetValue()
+			}
+			switch family.GetType() {
+			case dto.MetricType_GAUGE:
+				s.value = metric.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				s.value = metric.GetCounter().GetValue()
+			}
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// labelKeys returns the sorted label names of a sample joined by commas
+func labelKeys(s sample) string {
+	var keys []string
+	for key := range s.labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func TestExporterMetricNamesAndLabels(t *testing.T) {
+	e := NewExporter()
+	summary := models.Summary{
+		CurrentDateCost:  300,
+		CurrentMonthCost: 4500,
+		MonthEndForecast: &models.MonthEndForecast{Projected: 9000},
+	}
+	composite := []models.CostData{
+		{Date: "2024-05-14", Service: "Compute", ProjectID: "prod", Cost: 999},
+		{Date: "2024-05-15", Service: "Compute", ProjectID: "prod", Cost: 100},
+		{Date: "2024-05-15", Service: "Compute", ProjectID: "prod", Cost: 50},
+		{Date: "2024-05-15", Service: "Storage", ProjectID: "dev", Cost: 150},
+	}
+	anomalies := []models.Anomaly{{Severity: models.SeverityHigh}, {Severity: models.SeverityHigh}, {Severity: models.SeverityLow}}
+	e.Observe(models.Summary{CurrentDateCost: 1}, []models.CostData{{Date: "2024-05-15", Service: "Network", ProjectID: "prod", Cost: 7}}, anomalies)
+	e.Observe(summary, composite, anomalies[:1])
+
+	// Every gauge holds the latest run's values, with nothing left over from the first run
+	want := map[string]struct {
+		labels string
+		values map[string]float64
+	}{
+		"cost_monitor_daily_cost":              {"", map[string]float64{"": 300}},
+		"cost_monitor_daily_cost_by_service":   {"project,service", map[string]float64{"Compute/prod": 150, "Storage/dev": 150}},
+		"cost_monitor_mtd_cost":                {"", map[string]float64{"": 4500}},
+		"cost_monitor_forecast_month_end_cost": {"", map[string]float64{"": 9000}},
+		"cost_monitor_anomalies":               {"severity", map[string]float64{models.SeverityLow: 0, models.SeverityMedium: 0, models.SeverityHigh: 1, models.SeverityCritical: 0}},
+	}
+
+	got := make(map[string]map[string]float64)
+	for _, s := range gather(t, e) {
+		expected, known := want[s.name]
+		if !known {
+			t.Errorf("unexpected metric %s", s.name)
+			continue
+		}
+		if s.kind != dto.MetricType_GAUGE {
+			t.Errorf("%s is a %v, want a gauge", s.name, s.kind)
+		}
+		if keys := labelKeys(s); keys != expected.labels {
+			t.Errorf("%s has labels %q, want %q", s.name, keys, expected.labels)
+		}
+		series := s.labels["severity"]
+		if s.labels["service"] != "" {
+			series = s.labels["service"] + "/" + s.labels["project"]
+		}
+		if got[s.name] == nil {
+			got[s.name] = make(map[string]float64)
+		}
+		got[s.name][series] = s.value
+	}
+
+	for name, expected := range want {
+		for series, value := range expected.values {
+			actual, exists := got[name][series]
+			if !exists {
+				t.Errorf("%s{%s} is missing", name, series)
+			} else if actual != value {
+				t.Errorf("%s{%s} = %v, want %v", name, series, actual, value)
+			}
+		}
+		if len(got[name]) != len(expected.values) {
+			t.Errorf("%s has %d series, want %d", name, len(got[name]), len(expected.values))
+		}
+	}
+}
+
+func TestExporterOmitsForecastWithoutOne(t *testing.T) {
+	e := NewExporter()
+	e.Observe(models.Summary{MonthEndForecast: &models.MonthEndForecast{Projected: 10}}, nil, nil)
+	e.Observe(models.Summary{CurrentDateCost: 1}, nil, nil)
+	for _, s := range gather(t, e) {
+		if s.name == "cost_monitor_forecast_month_end_cost" {
+			t.Error("forecast gauge exported for a run without a forecast")
+		}
+	}
+}
+
+func TestExporterEscapesLabelValues(t *testing.T) {
+	e := NewExporter()
+	service := `Cloud "SQL" \ Backups`
+	e.Observe(models.Summary{}, []models.CostData{{Date: "2024-05-15", Service: service, ProjectID: "prod", Cost: 5}}, nil)
+
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := recorder.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	want := `cost_monitor_daily_cost_by_service{project="prod",service="Cloud \"SQL\" \\ Backups"} 5`
+	if !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("scrape does not hold %s:\n%s", want, recorder.Body)
+	}
+}
+
+func TestWriteTextfile(t *testing.T) {
+	e := NewExporter()
+	e.Observe(models.Summary{CurrentDateCost: 42}, nil, nil)
+	path := filepath.Join(t.TempDir(), "cost_monitor.prom")
+	if err := e.WriteTextfile(path); err != nil {
+		t.Fatalf("WriteTextfile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(data), "cost_monitor_daily_cost 42\n") {
+		t.Errorf("textfile does not hold the daily cost gauge:\n%s", data)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the textfile", len(entries))
+	}
+}
+
+func TestExporterExportsDetectorPrecision(t *testing.T) {
+	e := NewExporter()
+	e.Observe(models.Summary{DetectorPrecision: []models.DetectorPrecision{
+		{Detector: "stale", TruePositives: 1, Precision: 1},
+	}}, nil, nil)
+	e.Observe(models.Summary{DetectorPrecision: []models.DetectorPrecision{
+		{Detector: "spike", TruePositives: 3, FalsePositives: 1, Precision: 0.75},
+	}}, nil, nil)
+
+	got := make(map[string]float64)
+	for _, s := range gather(t, e) {
+		switch s.name {
+		case "cost_monitor_detector_precision":
+			got["precision/"+s.labels["detector"]] = s.value
+		case "cost_monitor_detector_labeled_anomalies":
+			if s.kind != dto.MetricType_GAUGE {
+				t.Errorf("%s is a %v, want a gauge", s.name, s.kind)
+			}
+			got[s.labels["verdict"]+"/"+s.labels["detector"]] = s.value
+		}
+	}
+	want := map[string]float64{"precision/spike": 0.75, "true_positive/spike": 3, "false_positive/spike": 1}
+	if len(got) != len(want) {
+		t.Errorf("got series %v, want %v", got, want)
+	}
+	for series, value := range want {
+		if got[series] != value {
+			t.Errorf("%s = %v, want %v", series, got[series], value)
+		}
+	}
+}
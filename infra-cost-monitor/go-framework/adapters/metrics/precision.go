@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Verdict label values, matching the verdicts accepted by the feedback label endpoint
+const (
+	verdictTruePositive  = "true_positive"
+	verdictFalsePositive = "false_positive"
+)
+
+// Precision exposes per-detector feedback: the anomalies users labeled over the
+// precision window, by verdict, and the share that were true positives
+type Precision struct {
+	labeled   *prometheus.GaugeVec
+	precision *prometheus.GaugeVec
+}
+
+// NewPrecision creates the precision gauges and registers them
+func NewPrecision(registerer prometheus.Registerer) *Precision {
+	p := &Precision{
+		labeled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cost_monitor_detector_labeled_anomalies",
+			Help: "Anomalies labeled by users over the precision window, by detector and verdict.",
+		}, []string{"detector", "verdict"}),
+		precision: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cost_monitor_detector_precision",
+			Help: "Share of labeled anomalies that were true positives, by detector.",
+		}, []string{"detector"}),
+	}
+	registerer.MustRegister(p.labeled, p.precision)
+	return p
+}
+
+// Set replaces the gauges with the given per-detector stats
+func (p *Precision) Set(stats []models.DetectorPrecision) {
+	p.labeled.Reset()
+	p.precision.Reset()
+	for _, stat := range stats {
+		p.labeled.WithLabelValues(stat.Detector, verdictTruePositive).Set(float64(stat.TruePositives))
+		p.labeled.WithLabelValues(stat.Detector, verdictFalsePositive).Set(float64(stat.FalsePositives))
+		p.precision.WithLabelValues(stat.Detector).Set(stat.Precision)
+	}
+}
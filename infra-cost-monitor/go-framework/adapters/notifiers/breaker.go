@@ -0,0 +1,81 @@
+package notifiers
+
+import (
+	"errors"
+	"fmt"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a send is skipped because the circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitBreaker stops sending to a notifier after consecutive failures. The
+// breaker opens after threshold failures, skips sends until cooldown has
+// passed, then half-opens to let one send test recovery. Its state is kept in
+// the state store so a failing endpoint stays skipped across runs.
+type CircuitBreaker struct {
+	notifier  Notifier
+	store     *state.Store
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+// NewCircuitBreaker wraps a notifier in a circuit breaker persisted in store
+func NewCircuitBreaker(notifier Notifier, store *state.Store, threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &CircuitBreaker{
+		notifier:  notifier,
+		store:     store,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Name returns the name of the wrapped notifier
+func (cb *CircuitBreaker) Name() string {
+	return cb.notifier.Name()
+}
+
+// Unwrap returns the wrapped notifier
+func (cb *CircuitBreaker) Unwrap() Notifier {
+	return cb.notifier
+}
+
+// Notify sends through the wrapped notifier unless the breaker is open
+func (cb *CircuitBreaker) Notify(anomalies []models.Anomaly) error {
+	breaker := cb.store.Breaker(cb.Name())
+	open := breaker.Failures >= cb.threshold
+	if open && cb.now().Before(breaker.OpenedAt.Add(cb.cooldown)) {
+		log.Printf("⛔ Circuit open for %s, skipping %d notifications until %s", cb.Name(), len(anomalies), breaker.OpenedAt.Add(cb.cooldown).Format(time.RFC3339))
+		return ErrCircuitOpen
+	}
+	if open {
+		log.Printf("🔌 Circuit half-open for %s, testing recovery", cb.Name())
+	}
+
+	err := cb.notifier.Notify(anomalies)
+	if err == nil {
+		if breaker.Failures > 0 {
+			log.Printf("✅ Circuit closed for %s", cb.Name())
+		}
+		cb.store.SetBreaker(cb.Name(), state.BreakerState{})
+		return nil
+	}
+
+	breaker.Failures++
+	if breaker.Failures >= cb.threshold {
+		// Opening, or re-opening after a failed half-open test, restarts the cooldown
+		breaker.OpenedAt = cb.now()
+		log.Printf("⛔ Circuit opened for %s after %d consecutive failures", cb.Name(), breaker.Failures)
+	}
+	cb.store.SetBreaker(cb.Name(), breaker)
+	return fmt.Errorf("%s failed (%d consecutive): %v", cb.Name(), breaker.Failures, err)
+}
@@ -0,0 +1,107 @@
+package notifiers
+
+import (
+	"errors"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensSkipsAndRecovers(t *testing.T) {
+	const threshold = 3
+	cooldown := time.Hour
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "state.json")
+	anomalies := []models.Anomaly{{ID: "a1"}}
+
+	store, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	notifier := &flakyNotifier{failures: threshold + 1}
+	breaker := NewCircuitBreaker(notifier, store, threshold, cooldown)
+	breaker.now = func() time.Time { return start }
+
+	// Consecutive failures open the breaker
+	for i := 1; i <= threshold; i++ {
+		err := breaker.Notify(anomalies)
+		if err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("failure %d returned %v, want the notifier's error", i, err)
+		}
+	}
+	if got := store.Breaker("flaky"); got.Failures != threshold || !got.OpenedAt.Equal(start) {
+		t.Fatalf("breaker state = %+v, want %d failures opened at %v", got, threshold, start)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// The next run loads the open breaker and skips sends until the cooldown passes
+	store, err = state.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	breaker = NewCircuitBreaker(notifier, store, threshold, cooldown)
+	breaker.now = func() time.Time { return start.Add(cooldown - time.Minute) }
+	if err := breaker.Notify(anomalies); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("send during the cooldown returned %v, want ErrCircuitOpen", err)
+	}
+	if len(notifier.batches) != threshold {
+		t.Fatalf("notifier called %d times, want the open breaker to skip it", len(notifier.batches))
+	}
+
+	// A failed half-open test re-opens the breaker and restarts the cooldown
+	reopened := start.Add(cooldown)
+	breaker.now = func() time.Time { return reopened }
+	if err := breaker.Notify(anomalies); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("half-open send returned %v, want the notifier's error", err)
+	}
+	if got := store.Breaker("flaky"); !got.OpenedAt.Equal(reopened) {
+		t.Fatalf("breaker opened at %v after a failed half-open test, want %v", got.OpenedAt, reopened)
+	}
+	breaker.now = func() time.Time { return reopened.Add(cooldown - time.Minute) }
+	if err := breaker.Notify(anomalies); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("send during the restarted cooldown returned %v, want ErrCircuitOpen", err)
+	}
+
+	// Past the cooldown one send is let through and, succeeding, closes the breaker
+	breaker.now = func() time.Time { return reopened.Add(cooldown) }
+	if err := breaker.Notify(anomalies); err != nil {
+		t.Fatalf("half-open send returned %v, want it let through", err)
+	}
+	if len(notifier.batches) != threshold+2 {
+		t.Errorf("notifier called %d times, want %d", len(notifier.batches), threshold+2)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	saved, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := saved.Breaker("flaky"); got.Failures != 0 || !got.OpenedAt.IsZero() {
+		t.Errorf("persisted breaker state = %+v, want it closed", got)
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	notifier := &flakyNotifier{failures: 2}
+	breaker := NewCircuitBreaker(notifier, store, 3, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		breaker.Notify([]models.Anomaly{{ID: "a1"}})
+	}
+	if got := store.Breaker("flaky"); got.Failures != 0 {
+		t.Errorf("breaker has %d failures after a success, want 0", got.Failures)
+	}
+	notifier.failures = 10
+	if err := breaker.Notify([]models.Anomaly{{ID: "a1"}}); errors.Is(err, ErrCircuitOpen) {
+		t.Error("breaker opened after one failure following a success")
+	}
+}
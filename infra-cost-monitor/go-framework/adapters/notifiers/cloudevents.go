@@ -0,0 +1,150 @@
+package notifiers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// CloudEvents attributes used for anomaly events
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsType        = "com.costmonitor.anomaly"
+)
+
+// cloudEvent is a CloudEvents v1.0 envelope in structured mode
+type cloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	Type            string         `json:"type"`
+	Source          string         `json:"source"`
+	ID              string         `json:"id"`
+	Subject         string         `json:"subject"`
+	Time            string         `json:"time"`
+	DataContentType string         `json:"datacontenttype"`
+	Data            models.Anomaly `json:"data"`
+}
+
+// CloudEventsNotifier emits each anomaly as a CloudEvent, either to an HTTP
+// endpoint in binary content mode or appended to a file sink as JSON lines
+type CloudEventsNotifier struct {
+	name     string
+	url      string
+	sinkPath string
+	source   string
+	client   *http.Client
+}
+
+// NewCloudEventsNotifier creates a new CloudEvents notifier; set url for HTTP delivery or sinkPath for a file sink
+func NewCloudEventsNotifier(name, url, sinkPath, source string) (*CloudEventsNotifier, error) {
+	if url == "" && sinkPath == "" {
+		return nil, fmt.Errorf("cloudevents notifier %s needs a url or a sink path", name)
+	}
+	if source == "" {
+		source = "/cost-monitor"
+	}
+
+	return &CloudEventsNotifier{
+		name:     name,
+		url:      url,
+		sinkPath: sinkPath,
+		source:   source,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns the notifier name
+func (cn *CloudEventsNotifier) Name() string {
+	return cn.name
+}
+
+// Notify emits one CloudEvent per anomaly
+func (cn *CloudEventsNotifier) Notify(anomalies []models.Anomaly) error {
+	for _, anomaly := range anomalies {
+		event := cn.newEvent(anomaly)
+
+		var err error
+		if cn.url != "" {
+			err = cn.sendBinary(event)
+		} else {
+			err = cn.appendToSink(event)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to emit CloudEvent %s: %v", event.ID, err)
+		}
+	}
+
+	log.Printf("✅ %s: emitted %d CloudEvents", cn.name, len(anomalies))
+	return nil
+}
+
+// newEvent wraps an anomaly in a CloudEvents envelope
+func (cn *CloudEventsNotifier) newEvent(anomaly models.Anomaly) cloudEvent {
+	id := anomaly.ID
+	if id == "" {
+		id = anomaly.StableID()
+	}
+
+	return cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		Type:            cloudEventsType,
+		Source:          cn.source,
+		ID:              id,
+		Subject:         anomaly.Key(),
+		Time:            time.Now().UTC().Format(time.RFC3339),
+		DataContentType: "application/json",
+		Data:            anomaly,
+	}
+}
+
+// sendBinary posts the event in HTTP binary content mode: attributes as ce- headers, data as the body
+func (cn *CloudEventsNotifier) sendBinary(event cloudEvent) error {
+	body, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, cn.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", event.DataContentType)
+	req.Header.Set("ce-specversion", event.SpecVersion)
+	req.Header.Set("ce-type", event.Type)
+	req.Header.Set("ce-source", event.Source)
+	req.Header.Set("ce-id", event.ID)
+	req.Header.Set("ce-subject", event.Subject)
+	req.Header.Set("ce-time", event.Time)
+
+	resp, err := cn.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// appendToSink appends the structured-mode event to the sink file
+func (cn *CloudEventsNotifier) appendToSink(event cloudEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(cn.sinkPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
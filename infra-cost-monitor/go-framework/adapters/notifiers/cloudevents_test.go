@@ -0,0 +1,136 @@
+package notifiers
+
+import (
+	"bufio"
+	"encoding/json"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cloudEventAnomalies returns an anomaly with an ID and a composite key, and one with neither
+func cloudEventAnomalies() []models.Anomaly {
+	return []models.Anomaly{
+		{ID: "a1", Date: "2024-03-01", Service: "Compute", CompositeKey: "Compute|VM|proj-1|us-east1", Severity: models.SeverityHigh, CostImpact: 250},
+		{Date: "2024-03-01", Service: "Storage", TestName: "spike", Severity: models.SeverityMedium},
+	}
+}
+
+func TestCloudEventsNotifierSendsBinaryMode(t *testing.T) {
+	var headers []http.Header
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("ReadAll: %v", err)
+		}
+		headers = append(headers, r.Header.Clone())
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier, err := NewCloudEventsNotifier("events", server.URL, "", "")
+	if err != nil {
+		t.Fatalf("NewCloudEventsNotifier: %v", err)
+	}
+	anomalies := cloudEventAnomalies()
+	if err := notifier.Notify(anomalies); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if len(headers) != 2 {
+		t.Fatalf("got %d requests, want one per anomaly", len(headers))
+	}
+	wantHeaders := map[string]string{
+		"Content-Type":   "application/json",
+		"Ce-Specversion": "1.0",
+		"Ce-Type":        "com.costmonitor.anomaly",
+		"Ce-Source":      "/cost-monitor",
+		"Ce-Id":          "a1",
+		"Ce-Subject":     "Compute|VM|proj-1|us-east1",
+	}
+	for name, want := range wantHeaders {
+		if got := headers[0].Get(name); got != want {
+			t.Errorf("header %s = %q, want %q", name, got, want)
+		}
+	}
+	if headers[0].Get("Ce-Time") == "" {
+		t.Error("request has no ce-time header")
+	}
+	if got, want := headers[1].Get("Ce-Id"), anomalies[1].StableID(); got != want {
+		t.Errorf("ce-id of an anomaly without an ID = %q, want its stable ID %q", got, want)
+	}
+	if got := headers[1].Get("Ce-Subject"); got != "Storage" {
+		t.Errorf("ce-subject without a composite key = %q, want the service", got)
+	}
+
+	var data models.Anomaly
+	if err := json.Unmarshal(bodies[0], &data); err != nil {
+		t.Fatalf("body is not the anomaly as JSON: %v", err)
+	}
+	if data.ID != "a1" || data.CostImpact != 250 {
+		t.Errorf("body = %+v, want the first anomaly", data)
+	}
+}
+
+func TestCloudEventsNotifierFailsOnErrorStatus(t *testing.T) {
+	server, _ := captureServer(t, http.StatusInternalServerError)
+	notifier, err := NewCloudEventsNotifier("events", server.URL, "", "/billing")
+	if err != nil {
+		t.Fatalf("NewCloudEventsNotifier: %v", err)
+	}
+	if err := notifier.Notify(cloudEventAnomalies()); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Notify() error = %v, want the unexpected status", err)
+	}
+}
+
+func TestCloudEventsNotifierAppendsToSink(t *testing.T) {
+	sink := filepath.Join(t.TempDir(), "events.jsonl")
+	notifier, err := NewCloudEventsNotifier("events", "", sink, "/billing")
+	if err != nil {
+		t.Fatalf("NewCloudEventsNotifier: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := notifier.Notify(cloudEventAnomalies()[:1]); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+
+	file, err := os.Open(sink)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+	var events []cloudEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event cloudEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q is not a structured-mode event: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("sink has %d events, want both runs appended", len(events))
+	}
+	event := events[0]
+	if event.SpecVersion != "1.0" || event.Type != "com.costmonitor.anomaly" || event.Source != "/billing" || event.ID != "a1" || event.Subject != "Compute|VM|proj-1|us-east1" {
+		t.Errorf("event = %+v", event)
+	}
+	if event.DataContentType != "application/json" || event.Data.ID != "a1" {
+		t.Errorf("event data = %q %+v, want the anomaly as JSON", event.DataContentType, event.Data)
+	}
+}
+
+func TestNewCloudEventsNotifierNeedsADestination(t *testing.T) {
+	if _, err := NewCloudEventsNotifier("events", "", "", ""); err == nil {
+		t.Error("NewCloudEventsNotifier() with no url or sink returned no error")
+	}
+}
@@ -0,0 +1,42 @@
+package notifiers
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/state"
+	"os"
+)
+
+// FromConfig builds a notifier from its configuration, gated to its severity band.
+// Wrappers are applied in order inside the gate, so they only see in-band sends.
+// The state store lets notifiers that resolve alerts across runs remember what they
+// sent; it may be nil when nothing will be resolved.
+func FromConfig(cfg config.NotifierConfig, store *state.Store, wrappers ...func(Notifier) Notifier) (Notifier, error) {
+	var notifier Notifier
+	var err error
+
+	switch cfg.Type {
+	case "cloudevents":
+		notifier, err = NewCloudEventsNotifier(cfg.Name, cfg.URL, cfg.Path, cfg.Source)
+	case "opsgenie":
+		notifier, err = NewOpsgenieNotifier(cfg.Name, cfg.URL, os.Getenv(cfg.APIKeyEnv))
+	case "pagerduty":
+		notifier, err = NewPagerDutyNotifier(cfg.Name, cfg.URL, cfg.RoutingKey, store)
+	case "webhook":
+		notifier, err = NewWebhookNotifier(cfg.Name, cfg.URL, cfg.Template, cfg.ContentType, cfg.Headers, os.Getenv(cfg.SigningSecretEnv))
+	case "slack":
+		notifier, err = NewSlackNotifier(cfg.Name, cfg.URL)
+	case "sheets":
+		notifier, err = NewSheetsNotifier(cfg.Name, cfg.SpreadsheetID, cfg.Sheet)
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, wrap := range wrappers {
+		notifier = wrap(notifier)
+	}
+
+	return NewSeverityGate(notifier, cfg.MinSeverity, cfg.MaxSeverity)
+}
@@ -0,0 +1,101 @@
+package notifiers
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+)
+
+// Notifier delivers anomalies to an external channel
+type Notifier interface {
+	Name() string
+	Notify(anomalies []models.Anomaly) error
+}
+
+// Resolver is implemented by notifiers that can close alerts for anomalies
+// that are no longer detected
+type Resolver interface {
+	Resolve(anomalyIDs []string) error
+}
+
+// unwrapper is implemented by notifiers that wrap another notifier
+type unwrapper interface {
+	Unwrap() Notifier
+}
+
+// AsResolver finds a Resolver in a chain of wrapped notifiers
+func AsResolver(notifier Notifier) (Resolver, bool) {
+	for notifier != nil {
+		if resolver, ok := notifier.(Resolver); ok {
+			return resolver, true
+		}
+		wrapper, ok := notifier.(unwrapper)
+		if !ok {
+			break
+		}
+		notifier = wrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// SeverityGate wraps a notifier so it only receives anomalies within a severity band
+type SeverityGate struct {
+	notifier Notifier
+	minRank  int
+	maxRank  int
+}
+
+// NewSeverityGate creates a severity gate; empty bounds default to LOW and CRITICAL
+func NewSeverityGate(notifier Notifier, minSeverity, maxSeverity string) (*SeverityGate, error) {
+	if minSeverity == "" {
+		minSeverity = models.SeverityLow
+	}
+	if maxSeverity == "" {
+		maxSeverity = models.SeverityCritical
+	}
+
+	minRank := models.SeverityRank(minSeverity)
+	if minRank < 0 {
+		return nil, fmt.Errorf("unknown min severity %q", minSeverity)
+	}
+	maxRank := models.SeverityRank(maxSeverity)
+	if maxRank < 0 {
+		return nil, fmt.Errorf("unknown max severity %q", maxSeverity)
+	}
+	if minRank > maxRank {
+		return nil, fmt.Errorf("min severity %s is above max severity %s", minSeverity, maxSeverity)
+	}
+
+	return &SeverityGate{
+		notifier: notifier,
+		minRank:  minRank,
+		maxRank:  maxRank,
+	}, nil
+}
+
+// Name returns the name of the wrapped notifier
+func (sg *SeverityGate) Name() string {
+	return sg.notifier.Name()
+}
+
+// Unwrap returns the wrapped notifier
+func (sg *SeverityGate) Unwrap() Notifier {
+	return sg.notifier
+}
+
+// Notify forwards only the anomalies whose severity falls within the band
+func (sg *SeverityGate) Notify(anomalies []models.Anomaly) error {
+	var inBand []models.Anomaly
+	for _, anomaly := range anomalies {
+		rank := models.SeverityRank(anomaly.Severity)
+		if rank >= sg.minRank && rank <= sg.maxRank {
+			inBand = append(inBand, anomaly)
+		}
+	}
+
+	if len(inBand) == 0 {
+		log.Printf("🔕 %s: no anomalies within severity band", sg.Name())
+		return nil
+	}
+	return sg.notifier.Notify(inBand)
+}
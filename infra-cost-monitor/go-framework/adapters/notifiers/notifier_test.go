@@ -0,0 +1,92 @@
+package notifiers
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"strings"
+	"testing"
+)
+
+// severityBatch returns one anomaly per canonical severity plus one with an unknown severity
+func severityBatch() []models.Anomaly {
+	return []models.Anomaly{
+		{ID: "low", Severity: models.SeverityLow},
+		{ID: "medium", Severity: models.SeverityMedium},
+		{ID: "high", Severity: "high"},
+		{ID: "critical", Severity: models.SeverityCritical},
+		{ID: "unknown", Severity: "URGENT"},
+	}
+}
+
+func TestSeverityGateForwardsTheBand(t *testing.T) {
+	tests := []struct {
+		name string
+		min  string
+		max  string
+		want string
+	}{
+		{"default band", "", "", "low,medium,high,critical"},
+		{"min only", models.SeverityHigh, "", "high,critical"},
+		{"max only", "", models.SeverityMedium, "low,medium"},
+		{"min equals max", models.SeverityHigh, models.SeverityHigh, "high"},
+		{"lowercase bounds", "medium", "high", "medium,high"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &flakyNotifier{}
+			gate, err := NewSeverityGate(notifier, tt.min, tt.max)
+			if err != nil {
+				t.Fatalf("NewSeverityGate: %v", err)
+			}
+			if err := gate.Notify(severityBatch()); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+			if len(notifier.batches) != 1 {
+				t.Fatalf("notifier called %d times, want once", len(notifier.batches))
+			}
+			var ids []string
+			for _, anomaly := range notifier.batches[0] {
+				ids = append(ids, anomaly.ID)
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("forwarded %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverityGateSkipsAnEmptyBand(t *testing.T) {
+	notifier := &flakyNotifier{}
+	gate, err := NewSeverityGate(notifier, models.SeverityCritical, models.SeverityCritical)
+	if err != nil {
+		t.Fatalf("NewSeverityGate: %v", err)
+	}
+	if err := gate.Notify([]models.Anomaly{{ID: "low", Severity: models.SeverityLow}, {ID: "unknown", Severity: ""}}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(notifier.batches) != 0 {
+		t.Errorf("notifier called with %v, want no call when nothing is in band", notifier.batches)
+	}
+}
+
+func TestNewSeverityGateRejectsInvalidBands(t *testing.T) {
+	tests := []struct {
+		name string
+		min  string
+		max  string
+		want string
+	}{
+		{"unknown min", "URGENT", "", "unknown min severity"},
+		{"unknown max", "", "SEVERE", "unknown max severity"},
+		{"inverted band", models.SeverityHigh, models.SeverityLow, "above max severity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSeverityGate(&flakyNotifier{}, tt.min, tt.max)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewSeverityGate() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
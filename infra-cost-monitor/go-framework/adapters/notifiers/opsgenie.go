@@ -0,0 +1,167 @@
+package notifiers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// opsgenieDefaultURL is the Opsgenie API base URL; EU accounts use https://api.eu.opsgenie.com
+const opsgenieDefaultURL = "https://api.opsgenie.com"
+
+// opsgenieAlert is the body of an Opsgenie Alerts API create request
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details"`
+}
+
+// OpsgenieNotifier creates an Opsgenie alert per anomaly, aliased by the anomaly
+// ID so repeated runs deduplicate and resolved anomalies can close their alert
+type OpsgenieNotifier struct {
+	name    string
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewOpsgenieNotifier creates a new Opsgenie notifier; an empty baseURL uses the default API
+func NewOpsgenieNotifier(name, baseURL, apiKey string) (*OpsgenieNotifier, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("opsgenie notifier %s needs an API key", name)
+	}
+	if baseURL == "" {
+		baseURL = opsgenieDefaultURL
+	}
+
+	return &OpsgenieNotifier{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns the notifier name
+func (on *OpsgenieNotifier) Name() string {
+	return on.name
+}
+
+// Notify creates one Opsgenie alert per anomaly
+func (on *OpsgenieNotifier) Notify(anomalies []models.Anomaly) error {
+	for _, anomaly := range anomalies {
+		if err := on.post("/v2/alerts", newOpsgenieAlert(anomaly)); err != nil {
+			return fmt.Errorf("failed to create Opsgenie alert for %s: %v", opsgenieAlias(anomaly), err)
+		}
+	}
+
+	log.Printf("✅ %s: created %d Opsgenie alerts", on.name, len(anomalies))
+	return nil
+}
+
+// Resolve closes the Opsgenie alerts of anomalies that are no longer detected
+func (on *OpsgenieNotifier) Resolve(anomalyIDs []string) error {
+	for _, id := range anomalyIDs {
+		path := "/v2/alerts/" + url.PathEscape(id) + "/close?identifierType=alias"
+		body := map[string]string{"source": "cost-monitor", "note": "Anomaly no longer detected"}
+		if err := on.post(path, body); err != nil {
+			return fmt.Errorf("failed to close Opsgenie alert %s: %v", id, err)
+		}
+	}
+
+	if len(anomalyIDs) > 0 {
+		log.Printf("✅ %s: closed %d resolved Opsgenie alerts", on.name, len(anomalyIDs))
+	}
+	return nil
+}
+
+// post sends a JSON request to the Alerts API; Opsgenie accepts requests asynchronously with 202
+func (on *OpsgenieNotifier) post(path string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, on.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+on.apiKey)
+
+	resp, err := on.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newOpsgenieAlert maps an anomaly to an Opsgenie alert
+func newOpsgenieAlert(anomaly models.Anomaly) opsgenieAlert {
+	message := anomaly.Description
+	if len(message) > 130 {
+		// Opsgenie truncates messages at 130 characters; the full text stays in the description
+		message = message[:127] + "..."
+	}
+
+	details := map[string]string{
+		"cost_impact": fmt.Sprintf("%.2f", anomaly.CostImpact),
+		"service":     anomaly.Service,
+		"date":        anomaly.Date,
+		"test":        anomaly.TestName,
+	}
+	if anomaly.ProjectID != "" {
+		details["project"] = anomaly.ProjectID
+	}
+	if anomaly.SKU != "" {
+		details["sku"] = anomaly.SKU
+	}
+
+	return opsgenieAlert{
+		Message:     message,
+		Alias:       opsgenieAlias(anomaly),
+		Description: anomaly.Description,
+		Priority:    opsgeniePriority(anomaly.Severity),
+		Source:      "cost-monitor",
+		Tags:        []string{"cost-anomaly", anomaly.Type},
+		Details:     details,
+	}
+}
+
+// opsgenieAlias returns the dedup alias for an anomaly
+func opsgenieAlias(anomaly models.Anomaly) string {
+	if anomaly.ID != "" {
+		return anomaly.ID
+	}
+	return anomaly.StableID()
+}
+
+// opsgeniePriority maps a severity to an Opsgenie priority, P1 being the most urgent
+func opsgeniePriority(severity string) string {
+	switch strings.ToUpper(severity) {
+	case models.SeverityCritical:
+		return "P1"
+	case models.SeverityHigh:
+		return "P2"
+	case models.SeverityMedium:
+		return "P3"
+	case models.SeverityLow:
+		return "P4"
+	}
+	return "P5"
+}
@@ -0,0 +1,144 @@
+package notifiers
+
+import (
+	"encoding/json"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// opsgenieRequest is a request received by the fake Opsgenie API
+type opsgenieRequest struct {
+	path          string
+	authorization string
+	body          []byte
+}
+
+// opsgenieServer records the requests posted to it and answers with status
+func opsgenieServer(t *testing.T, status int) (*httptest.Server, *[]opsgenieRequest) {
+	t.Helper()
+	var requests []opsgenieRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("ReadAll: %v", err)
+		}
+		requests = append(requests, opsgenieRequest{path: r.URL.RequestURI(), authorization: r.Header.Get("Authorization"), body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestOpsgenieNotifierCreatesAliasedAlerts(t *testing.T) {
+	server, requests := opsgenieServer(t, http.StatusAccepted)
+	notifier, err := NewOpsgenieNotifier("opsgenie", server.URL+"/", "secret-key")
+	if err != nil {
+		t.Fatalf("NewOpsgenieNotifier: %v", err)
+	}
+
+	anomalies := []models.Anomaly{
+		{ID: "a1", Service: "Compute", ProjectID: "prod", SKU: "VM", Date: "2024-03-01", Type: "daily_spike", TestName: "spike", Severity: models.SeverityCritical, CostImpact: 1500, Description: strings.Repeat("x", 200)},
+		{Service: "Storage", Date: "2024-03-01", Severity: models.SeverityLow, Description: "Storage rose"},
+	}
+	if err := notifier.Notify(anomalies); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests, want one alert per anomaly", len(*requests))
+	}
+	var alerts []opsgenieAlert
+	for _, request := range *requests {
+		if request.path != "/v2/alerts" || request.authorization != "GenieKey secret-key" {
+			t.Errorf("request to %s with authorization %q, want /v2/alerts with the API key", request.path, request.authorization)
+		}
+		var alert opsgenieAlert
+		if err := json.Unmarshal(request.body, &alert); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	first := alerts[0]
+	if first.Alias != "a1" || first.Priority != "P1" || first.Source != "cost-monitor" {
+		t.Errorf("alert = %+v, want alias a1 at P1", first)
+	}
+	if len(first.Message) != 130 || !strings.HasSuffix(first.Message, "...") || first.Description != anomalies[0].Description {
+		t.Errorf("message has %d characters, want it truncated to 130 with the full text in the description", len(first.Message))
+	}
+	wantDetails := map[string]string{"cost_impact": "1500.00", "service": "Compute", "project": "prod", "sku": "VM", "date": "2024-03-01", "test": "spike"}
+	for key, want := range wantDetails {
+		if first.Details[key] != want {
+			t.Errorf("detail %s = %q, want %q", key, first.Details[key], want)
+		}
+	}
+
+	second := alerts[1]
+	if second.Alias != anomalies[1].StableID() || second.Priority != "P4" {
+		t.Errorf("alert = %+v, want the stable ID as alias at P4", second)
+	}
+	if _, exists := second.Details["project"]; exists {
+		t.Errorf("details = %v, want no project for an anomaly without one", second.Details)
+	}
+}
+
+func TestOpsgeniePriority(t *testing.T) {
+	tests := map[string]string{
+		models.SeverityCritical: "P1",
+		models.SeverityHigh:     "P2",
+		"medium":                "P3",
+		models.SeverityLow:      "P4",
+		"":                      "P5",
+	}
+	for severity, want := range tests {
+		if got := opsgeniePriority(severity); got != want {
+			t.Errorf("opsgeniePriority(%q) = %s, want %s", severity, got, want)
+		}
+	}
+}
+
+func TestOpsgenieNotifierClosesResolvedAlertsByAlias(t *testing.T) {
+	server, requests := opsgenieServer(t, http.StatusAccepted)
+	notifier, err := NewOpsgenieNotifier("opsgenie", server.URL, "secret-key")
+	if err != nil {
+		t.Fatalf("NewOpsgenieNotifier: %v", err)
+	}
+	if err := notifier.Resolve([]string{"a1", "daily|Compute/VM"}); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	want := []string{
+		"/v2/alerts/a1/close?identifierType=alias",
+		"/v2/alerts/daily%7CCompute%2FVM/close?identifierType=alias",
+	}
+	if len(*requests) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(*requests), len(want))
+	}
+	for i, request := range *requests {
+		if request.path != want[i] {
+			t.Errorf("close request %d to %s, want %s", i, request.path, want[i])
+		}
+	}
+}
+
+func TestOpsgenieNotifierErrors(t *testing.T) {
+	if _, err := NewOpsgenieNotifier("opsgenie", "", ""); err == nil {
+		t.Error("NewOpsgenieNotifier() without an API key returned no error")
+	}
+
+	server, _ := opsgenieServer(t, http.StatusUnauthorized)
+	notifier, err := NewOpsgenieNotifier("opsgenie", server.URL, "wrong-key")
+	if err != nil {
+		t.Fatalf("NewOpsgenieNotifier: %v", err)
+	}
+	if err := notifier.Notify([]models.Anomaly{{ID: "a1"}}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Notify() error = %v, want the unexpected status", err)
+	}
+	if err := notifier.Resolve([]string{"a1"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Resolve() error = %v, want the unexpected status", err)
+	}
+}
@@ -0,0 +1,203 @@
+package notifiers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pagerDutyDefaultURL is the PagerDuty Events API v2 enqueue endpoint
+const pagerDutyDefaultURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutyEvent is a PagerDuty Events API v2 request
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes the incident of a trigger event
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// PagerDutyNotifier pages on-call for HIGH and CRITICAL anomalies through the
+// Events API v2. Incidents are deduplicated by service and date, so repeated runs
+// update rather than duplicate them, and are resolved once no anomaly for that
+// service and date fires any more. The dedup key of each paged anomaly is kept
+// in the state store so a later run can resolve it.
+type PagerDutyNotifier struct {
+	name       string
+	url        string
+	routingKey string
+	store      *state.Store
+	client     *http.Client
+
+	// triggered holds the dedup keys paged by this run, which must stay open
+	triggered map[string]bool
+}
+
+// NewPagerDutyNotifier creates a new PagerDuty notifier; an empty url uses the
+// default endpoint and a nil store disables resolving
+func NewPagerDutyNotifier(name, url, routingKey string, store *state.Store) (*PagerDutyNotifier, error) {
+	if routingKey == "" {
+		return nil, fmt.Errorf("pagerduty notifier %s needs a routing key", name)
+	}
+	if url == "" {
+		url = pagerDutyDefaultURL
+	}
+
+	return &PagerDutyNotifier{
+		name:       name,
+		url:        url,
+		routingKey: routingKey,
+		store:      store,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		triggered:  make(map[string]bool),
+	}, nil
+}
+
+// Name returns the notifier name
+func (pn *PagerDutyNotifier) Name() string {
+	return pn.name
+}
+
+// Notify sends a trigger event for each HIGH or CRITICAL anomaly
+func (pn *PagerDutyNotifier) Notify(anomalies []models.Anomaly) error {
+	paged := 0
+	for _, anomaly := range anomalies {
+		if models.SeverityRank(anomaly.Severity) < models.SeverityRank(models.SeverityHigh) {
+			continue
+		}
+
+		dedupKey := PagerDutyDedupKey(anomaly)
+		event := pagerDutyEvent{
+			RoutingKey:  pn.routingKey,
+			EventAction: "trigger",
+			DedupKey:    dedupKey,
+			Payload:     newPagerDutyPayload(anomaly),
+		}
+		if err := pn.send(event); err != nil {
+			return fmt.Errorf("failed to page for %s on %s: %v", anomaly.Service, anomaly.Date, err)
+		}
+
+		pn.triggered[dedupKey] = true
+		if pn.store != nil && anomaly.ID != "" {
+			pn.store.RememberDedupKey(anomaly.ID, dedupKey)
+		}
+		paged++
+	}
+
+	log.Printf("✅ %s: sent %d PagerDuty trigger events", pn.name, paged)
+	return nil
+}
+
+// Resolve resolves the incidents of anomalies that are no longer detected,
+// unless another anomaly for the same service and date was paged this run
+func (pn *PagerDutyNotifier) Resolve(anomalyIDs []string) error {
+	if pn.store == nil {
+		return nil
+	}
+
+	resolved := make(map[string]bool)
+	for _, id := range anomalyIDs {
+		dedupKey, exists := pn.store.DedupKey(id)
+		if !exists || pn.triggered[dedupKey] || resolved[dedupKey] {
+			continue
+		}
+
+		event := pagerDutyEvent{
+			RoutingKey:  pn.routingKey,
+			EventAction: "resolve",
+			DedupKey:    dedupKey,
+		}
+		if err := pn.send(event); err != nil {
+			return fmt.Errorf("failed to resolve PagerDuty incident %s: %v", dedupKey, err)
+		}
+		resolved[dedupKey] = true
+	}
+
+	if len(resolved) > 0 {
+		log.Printf("✅ %s: resolved %d PagerDuty incidents", pn.name, len(resolved))
+	}
+	return nil
+}
+
+// send posts an event; the Events API accepts events asynchronously with 202
+func (pn *PagerDutyNotifier) send(event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	resp, err := pn.client.Post(pn.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PagerDutyDedupKey returns the incident dedup key of an anomaly, stable across
+// runs for the same service and date
+func PagerDutyDedupKey(anomaly models.Anomaly) string {
+	hash := sha256.Sum256([]byte(anomaly.Service + "|" + anomaly.Date))
+	return "cost-monitor-" + hex.EncodeToString(hash[:8])
+}
+
+// newPagerDutyPayload maps an anomaly to a trigger event payload
+func newPagerDutyPayload(anomaly models.Anomaly) *pagerDutyPayload {
+	summary := anomaly.Description
+	if len(summary) > 1024 {
+		// The Events API rejects summaries over 1024 characters
+		summary = summary[:1021] + "..."
+	}
+
+	details := map[string]string{
+		"anomaly_id":  anomaly.ID,
+		"cost_impact": fmt.Sprintf("%.2f", anomaly.CostImpact),
+		"date":        anomaly.Date,
+		"test":        anomaly.TestName,
+	}
+	if anomaly.ProjectID != "" {
+		details["project"] = anomaly.ProjectID
+	}
+	if anomaly.SKU != "" {
+		details["sku"] = anomaly.SKU
+	}
+
+	return &pagerDutyPayload{
+		Summary:       summary,
+		Source:        "cost-monitor",
+		Severity:      pagerDutySeverity(anomaly.Severity),
+		Component:     anomaly.Service,
+		Class:         anomaly.Type,
+		CustomDetails: details,
+	}
+}
+
+// pagerDutySeverity maps a severity to a PagerDuty event severity
+func pagerDutySeverity(severity string) string {
+	if strings.ToUpper(severity) == models.SeverityCritical {
+		return "critical"
+	}
+	return "error"
+}
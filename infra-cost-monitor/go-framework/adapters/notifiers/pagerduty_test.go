@@ -0,0 +1,121 @@
+package notifiers
+
+import (
+	"encoding/json"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+// decodeEvents decodes the PagerDuty events captured by a test server
+func decodeEvents(t *testing.T, bodies [][]byte) []pagerDutyEvent {
+	t.Helper()
+	events := make([]pagerDutyEvent, len(bodies))
+	for i, body := range bodies {
+		if err := json.Unmarshal(body, &events[i]); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+	}
+	return events
+}
+
+func TestPagerDutyNotifierPagesHighSeverity(t *testing.T) {
+	server, bodies := captureServer(t, http.StatusAccepted)
+	notifier, err := NewPagerDutyNotifier("pagerduty", server.URL, "routing-key", nil)
+	if err != nil {
+		t.Fatalf("NewPagerDutyNotifier: %v", err)
+	}
+
+	anomalies := []models.Anomaly{
+		{ID: "a1", Service: "Compute", Date: "2024-03-01", Severity: models.SeverityCritical, Type: "daily_spike", TestName: "spike", Description: "Compute spiked", CostImpact: 1500, SKU: "VM"},
+		{ID: "a2", Service: "Storage", Date: "2024-03-01", Severity: models.SeverityHigh, Description: "Storage spiked"},
+		{ID: "a3", Service: "Network", Date: "2024-03-01", Severity: models.SeverityMedium, Description: "Network rose"},
+	}
+	if err := notifier.Notify(anomalies); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	events := decodeEvents(t, *bodies)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want one per HIGH or CRITICAL anomaly", len(events))
+	}
+	event := events[0]
+	if event.RoutingKey != "routing-key" || event.EventAction != "trigger" || event.DedupKey != PagerDutyDedupKey(anomalies[0]) {
+		t.Errorf("event = %+v", event)
+	}
+	payload := event.Payload
+	if payload == nil {
+		t.Fatal("trigger event has no payload")
+	}
+	if payload.Summary != "Compute spiked" || payload.Severity != "critical" || payload.Component != "Compute" || payload.Class != "daily_spike" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.CustomDetails["cost_impact"] != "1500.00" || payload.CustomDetails["sku"] != "VM" || payload.CustomDetails["anomaly_id"] != "a1" {
+		t.Errorf("custom details = %v", payload.CustomDetails)
+	}
+	if events[1].Payload.Severity != "error" {
+		t.Errorf("HIGH anomaly severity = %q, want error", events[1].Payload.Severity)
+	}
+}
+
+func TestPagerDutyDedupKeyIsStable(t *testing.T) {
+	anomaly := models.Anomaly{ID: "a1", Service: "Compute", Date: "2024-03-01", Severity: models.SeverityHigh, Description: "first run"}
+	rerun := anomaly
+	rerun.ID, rerun.Severity, rerun.Description, rerun.CostImpact = "b7", models.SeverityCritical, "second run", 99
+
+	if PagerDutyDedupKey(anomaly) != PagerDutyDedupKey(rerun) {
+		t.Error("dedup key changed between runs of the same service and date")
+	}
+	otherDay := anomaly
+	otherDay.Date = "2024-03-02"
+	otherService := anomaly
+	otherService.Service = "Storage"
+	if PagerDutyDedupKey(anomaly) == PagerDutyDedupKey(otherDay) || PagerDutyDedupKey(anomaly) == PagerDutyDedupKey(otherService) {
+		t.Error("dedup keys of different services or dates collide")
+	}
+}
+
+func TestPagerDutyNotifierResolvesAnomaliesThatStopFiring(t *testing.T) {
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	compute := models.Anomaly{ID: "a1", Service: "Compute", Date: "2024-03-01", Severity: models.SeverityHigh}
+	storage := models.Anomaly{ID: "a2", Service: "Storage", Date: "2024-03-01", Severity: models.SeverityHigh}
+
+	// The first run pages both
+	server, bodies := captureServer(t, http.StatusAccepted)
+	first, err := NewPagerDutyNotifier("pagerduty", server.URL, "routing-key", store)
+	if err != nil {
+		t.Fatalf("NewPagerDutyNotifier: %v", err)
+	}
+	if err := first.Notify([]models.Anomaly{compute, storage}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	// The next run still sees Compute; Storage stopped firing
+	second, err := NewPagerDutyNotifier("pagerduty", server.URL, "routing-key", store)
+	if err != nil {
+		t.Fatalf("NewPagerDutyNotifier: %v", err)
+	}
+	*bodies = nil
+	if err := second.Notify([]models.Anomaly{compute}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if err := second.Resolve([]string{"a2"}); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	events := decodeEvents(t, *bodies)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want a trigger and a resolve", len(events))
+	}
+	if events[0].EventAction != "trigger" || events[0].DedupKey != PagerDutyDedupKey(compute) {
+		t.Errorf("first event = %+v, want a trigger for Compute", events[0])
+	}
+	if events[1].EventAction != "resolve" || events[1].DedupKey != PagerDutyDedupKey(storage) || events[1].Payload != nil {
+		t.Errorf("second event = %+v, want a resolve for Storage", events[1])
+	}
+}
@@ -0,0 +1,48 @@
+package notifiers
+
+import (
+	"errors"
+	"infra-cost-monitor/go-framework/adapters/eventlog"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+)
+
+// Recorder writes the outcome of every send to the event log
+type Recorder struct {
+	notifier Notifier
+	events   *eventlog.Log
+}
+
+// NewRecorder wraps a notifier so its sends are recorded in the event log
+func NewRecorder(notifier Notifier, events *eventlog.Log) *Recorder {
+	return &Recorder{
+		notifier: notifier,
+		events:   events,
+	}
+}
+
+// Name returns the name of the wrapped notifier
+func (r *Recorder) Name() string {
+	return r.notifier.Name()
+}
+
+// Unwrap returns the wrapped notifier
+func (r *Recorder) Unwrap() Notifier {
+	return r.notifier
+}
+
+// Notify sends through the wrapped notifier and records the outcome
+func (r *Recorder) Notify(anomalies []models.Anomaly) error {
+	err := r.notifier.Notify(anomalies)
+
+	outcome := eventlog.OutcomeSent
+	if errors.Is(err, ErrCircuitOpen) {
+		outcome = eventlog.OutcomeSkipped
+	} else if err != nil {
+		outcome = eventlog.OutcomeFailed
+	}
+	if logErr := r.events.Notified(r.Name(), outcome, anomalies, err); logErr != nil {
+		log.Printf("Warning: Failed to write event log: %v", logErr)
+	}
+	return err
+}
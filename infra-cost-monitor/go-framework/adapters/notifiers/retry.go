@@ -0,0 +1,109 @@
+package notifiers
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how failed notifications are retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Concurrency int
+}
+
+// RetryPool is a bounded worker pool for notifier retries, shared by all notifiers
+// so recovery after an outage neither runs serially nor floods the endpoints
+type RetryPool struct {
+	policy RetryPolicy
+	slots  chan struct{}
+	sleep  func(time.Duration)
+}
+
+// NewRetryPool creates a retry pool with the given policy
+func NewRetryPool(policy RetryPolicy) *RetryPool {
+	if policy.Concurrency < 1 {
+		policy.Concurrency = 1
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	return &RetryPool{
+		policy: policy,
+		slots:  make(chan struct{}, policy.Concurrency),
+		sleep:  time.Sleep,
+	}
+}
+
+// Wrap returns a notifier that retries failed sends through the pool
+func (rp *RetryPool) Wrap(notifier Notifier) Notifier {
+	return &retryingNotifier{
+		pool:     rp,
+		notifier: notifier,
+	}
+}
+
+// retry re-runs send with backoff until it succeeds or attempts are exhausted.
+// The first attempt has already been made by the caller.
+func (rp *RetryPool) retry(send func() error) error {
+	rp.slots <- struct{}{}
+	defer func() { <-rp.slots }()
+
+	var err error
+	for attempt := 2; attempt <= rp.policy.MaxAttempts; attempt++ {
+		rp.sleep(rp.backoff(attempt))
+		if err = send(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// backoff returns an exponential delay with jitter for the given attempt
+func (rp *RetryPool) backoff(attempt int) time.Duration {
+	delay := rp.policy.BaseDelay << uint(attempt-2)
+	if rp.policy.MaxDelay > 0 && (delay > rp.policy.MaxDelay || delay <= 0) {
+		delay = rp.policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Jitter between half and the full delay avoids a thundering herd on recovery
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// retryingNotifier retries a notifier's failed sends through a shared pool
+type retryingNotifier struct {
+	pool     *RetryPool
+	notifier Notifier
+}
+
+// Name returns the name of the wrapped notifier
+func (rn *retryingNotifier) Name() string {
+	return rn.notifier.Name()
+}
+
+// Unwrap returns the wrapped notifier
+func (rn *retryingNotifier) Unwrap() Notifier {
+	return rn.notifier
+}
+
+// Notify sends all anomalies at once and, on failure, retries the same batch
+// through the pool, so a recovered endpoint still receives one message
+func (rn *retryingNotifier) Notify(anomalies []models.Anomaly) error {
+	err := rn.notifier.Notify(anomalies)
+	if err == nil || rn.pool.policy.MaxAttempts < 2 {
+		return err
+	}
+
+	log.Printf("Warning: %s failed, retrying %d anomalies: %v", rn.Name(), len(anomalies), err)
+	return rn.pool.retry(func() error {
+		return rn.notifier.Notify(anomalies)
+	})
+}
@@ -0,0 +1,162 @@
+package notifiers
+
+import (
+	"errors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyNotifier fails its first failures calls and records every batch it is sent.
+// Calls after the first take hold, tracking how many run at once.
+type flakyNotifier struct {
+	mu       sync.Mutex
+	failures int
+	batches  [][]models.Anomaly
+	hold     time.Duration
+	inFlight *inFlight
+}
+
+// inFlight counts concurrent calls and remembers the peak
+type inFlight struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (fn *flakyNotifier) Name() string {
+	return "flaky"
+}
+
+func (fn *flakyNotifier) Notify(anomalies []models.Anomaly) error {
+	fn.mu.Lock()
+	fn.batches = append(fn.batches, anomalies)
+	retry := len(fn.batches) > 1
+	fail := len(fn.batches) <= fn.failures
+	fn.mu.Unlock()
+
+	if retry && fn.inFlight != nil {
+		fn.inFlight.mu.Lock()
+		fn.inFlight.current++
+		if fn.inFlight.current > fn.inFlight.peak {
+			fn.inFlight.peak = fn.inFlight.current
+		}
+		fn.inFlight.mu.Unlock()
+		time.Sleep(fn.hold)
+		fn.inFlight.mu.Lock()
+		fn.inFlight.current--
+		fn.inFlight.mu.Unlock()
+	}
+
+	if fail {
+		return errors.New("endpoint unavailable")
+	}
+	return nil
+}
+
+// noSleepPool returns a retry pool that records its backoff delays instead of sleeping
+func noSleepPool(policy RetryPolicy) (*RetryPool, *[]time.Duration) {
+	pool := NewRetryPool(policy)
+	var mu sync.Mutex
+	var delays []time.Duration
+	pool.sleep = func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		delays = append(delays, d)
+	}
+	return pool, &delays
+}
+
+func TestRetryResendsTheFailedBatchAsAUnit(t *testing.T) {
+	pool, delays := noSleepPool(RetryPolicy{MaxAttempts: 4, BaseDelay: time.Second})
+	notifier := &flakyNotifier{failures: 2}
+	anomalies := []models.Anomaly{{ID: "a1"}, {ID: "a2"}, {ID: "a3"}}
+
+	if err := pool.Wrap(notifier).Notify(anomalies); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(notifier.batches) != 3 {
+		t.Fatalf("notifier called %d times, want 3 (two failures, then success)", len(notifier.batches))
+	}
+	for i, batch := range notifier.batches {
+		if len(batch) != len(anomalies) {
+			t.Errorf("call %d sent %d anomalies, want the whole batch of %d", i, len(batch), len(anomalies))
+		}
+	}
+	if len(*delays) != 2 {
+		t.Errorf("slept %d times, want once before each retry", len(*delays))
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	pool, _ := noSleepPool(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second})
+	notifier := &flakyNotifier{failures: 10}
+
+	if err := pool.Wrap(notifier).Notify([]models.Anomaly{{ID: "a1"}}); err == nil {
+		t.Fatal("Notify succeeded against an endpoint that never recovers")
+	}
+	if len(notifier.batches) != 3 {
+		t.Errorf("notifier called %d times, want MaxAttempts 3", len(notifier.batches))
+	}
+}
+
+func TestBackoffGrowsExponentiallyWithJitter(t *testing.T) {
+	pool := NewRetryPool(RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	tests := []struct {
+		attempt int
+		full    time.Duration
+	}{
+		{2, 100 * time.Millisecond},
+		{3, 200 * time.Millisecond},
+		{4, 400 * time.Millisecond},
+		{5, 800 * time.Millisecond},
+		{6, time.Second},
+		{40, time.Second},
+	}
+
+	for _, tt := range tests {
+		seen := make(map[time.Duration]bool)
+		for i := 0; i < 200; i++ {
+			delay := pool.backoff(tt.attempt)
+			if delay < tt.full/2 || delay > tt.full {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", tt.attempt, delay, tt.full/2, tt.full)
+			}
+			seen[delay] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("backoff(%d) returned the same delay 200 times, want jitter", tt.attempt)
+		}
+	}
+
+	if delay := NewRetryPool(RetryPolicy{MaxAttempts: 2}).backoff(2); delay != 0 {
+		t.Errorf("backoff without a base delay = %v, want 0", delay)
+	}
+}
+
+func TestRetryPoolBoundsConcurrentRetries(t *testing.T) {
+	const notifiers = 8
+	pool, _ := noSleepPool(RetryPolicy{MaxAttempts: 2, Concurrency: 2})
+	counter := &inFlight{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < notifiers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			notifier := &flakyNotifier{failures: 1, hold: 20 * time.Millisecond, inFlight: counter}
+			if err := pool.Wrap(notifier).Notify([]models.Anomaly{{ID: "a1"}}); err != nil {
+				t.Errorf("Notify: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if counter.peak > 2 {
+		t.Errorf("%d retries ran at once, want at most Concurrency 2", counter.peak)
+	}
+	if counter.peak < 2 {
+		t.Errorf("retries peaked at %d concurrent, want the pool's 2 slots used", counter.peak)
+	}
+}
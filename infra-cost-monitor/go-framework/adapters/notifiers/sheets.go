@@ -0,0 +1,118 @@
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// sheetsBatchSize is the number of rows sent per append request, keeping large
+// runs within the Sheets API per-request and per-minute write quotas
+const sheetsBatchSize = 500
+
+// sheetsHeader is the header row written to an empty tab
+var sheetsHeader = []interface{}{"date", "service", "severity", "cost_impact", "description", "run_timestamp", "anomaly_id"}
+
+// SheetsNotifier appends each run's anomalies as rows to a tab of a shared Google Sheet
+type SheetsNotifier struct {
+	name          string
+	spreadsheetID string
+	sheet         string
+	service       *sheets.Service
+	ctx           context.Context
+	headerChecked bool
+}
+
+// NewSheetsNotifier creates a new Sheets notifier using application default credentials;
+// an empty sheet appends to the first tab
+func NewSheetsNotifier(name, spreadsheetID, sheet string) (*SheetsNotifier, error) {
+	if spreadsheetID == "" {
+		return nil, fmt.Errorf("sheets notifier %s needs a spreadsheet ID", name)
+	}
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+
+	ctx := context.Background()
+	service, err := sheets.NewService(ctx, option.WithScopes(sheets.SpreadsheetsScope))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sheets client: %v", err)
+	}
+
+	return &SheetsNotifier{
+		name:          name,
+		spreadsheetID: spreadsheetID,
+		sheet:         sheet,
+		service:       service,
+		ctx:           ctx,
+	}, nil
+}
+
+// Name returns the notifier name
+func (sn *SheetsNotifier) Name() string {
+	return sn.name
+}
+
+// Notify appends one row per anomaly, writing the header row first if the tab is empty
+func (sn *SheetsNotifier) Notify(anomalies []models.Anomaly) error {
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	var rows [][]interface{}
+	if !sn.headerChecked {
+		empty, err := sn.tabEmpty()
+		if err != nil {
+			return err
+		}
+		if empty {
+			rows = append(rows, sheetsHeader)
+		}
+		sn.headerChecked = true
+	}
+
+	runTimestamp := time.Now().Format(time.RFC3339)
+	for _, anomaly := range anomalies {
+		rows = append(rows, []interface{}{
+			anomaly.Date,
+			anomaly.Service,
+			anomaly.Severity,
+			anomaly.CostImpact,
+			anomaly.Description,
+			runTimestamp,
+			anomaly.ID,
+		})
+	}
+
+	for start := 0; start < len(rows); start += sheetsBatchSize {
+		end := start + sheetsBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		_, err := sn.service.Spreadsheets.Values.Append(sn.spreadsheetID, sn.sheet+"!A1", &sheets.ValueRange{Values: rows[start:end]}).
+			ValueInputOption("RAW").
+			InsertDataOption("INSERT_ROWS").
+			Context(sn.ctx).
+			Do()
+		if err != nil {
+			return fmt.Errorf("failed to append rows to sheet %s: %v", sn.sheet, err)
+		}
+	}
+
+	log.Printf("✅ %s: appended %d anomalies to sheet %s", sn.name, len(anomalies), sn.sheet)
+	return nil
+}
+
+// tabEmpty reports whether the tab has no header row yet
+func (sn *SheetsNotifier) tabEmpty() (bool, error) {
+	resp, err := sn.service.Spreadsheets.Values.Get(sn.spreadsheetID, sn.sheet+"!A1:G1").Context(sn.ctx).Do()
+	if err != nil {
+		return false, fmt.Errorf("failed to read header of sheet %s: %v", sn.sheet, err)
+	}
+	return len(resp.Values) == 0, nil
+}
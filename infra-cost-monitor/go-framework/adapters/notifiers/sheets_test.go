@@ -0,0 +1,101 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// sheetsServer fakes the Sheets values API for a tab holding existing rows,
+// recording the rows sent by each append request and the number of header reads
+func sheetsServer(t *testing.T, existing [][]interface{}) (*SheetsNotifier, *[][][]interface{}, *int) {
+	t.Helper()
+	var appends [][][]interface{}
+	reads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			reads++
+			json.NewEncoder(w).Encode(sheets.ValueRange{Values: existing})
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, ":append") || r.URL.Query().Get("valueInputOption") != "RAW" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var body sheets.ValueRange
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Decode: %v", err)
+		}
+		appends = append(appends, body.Values)
+		fmt.Fprint(w, `{}`)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	service, err := sheets.NewService(ctx, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("sheets.NewService: %v", err)
+	}
+	notifier := &SheetsNotifier{name: "sheets", spreadsheetID: "sheet-id", sheet: "Anomalies", service: service, ctx: ctx}
+	return notifier, &appends, &reads
+}
+
+// manyAnomalies returns n anomalies with distinct IDs
+func manyAnomalies(n int) []models.Anomaly {
+	anomalies := make([]models.Anomaly, n)
+	for i := range anomalies {
+		anomalies[i] = models.Anomaly{ID: fmt.Sprintf("a%d", i), Date: "2024-05-01", Service: "Compute", Severity: models.SeverityHigh, CostImpact: 10}
+	}
+	return anomalies
+}
+
+func TestSheetsNotifierWritesHeaderToEmptyTabAndBatches(t *testing.T) {
+	notifier, appends, reads := sheetsServer(t, nil)
+
+	if err := notifier.Notify(manyAnomalies(sheetsBatchSize + 1)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(*appends) != 2 || len((*appends)[0]) != sheetsBatchSize || len((*appends)[1]) != 2 {
+		t.Fatalf("append batches = %d, want %d rows then 2", len(*appends), sheetsBatchSize)
+	}
+	if header := (*appends)[0][0]; fmt.Sprint(header) != fmt.Sprint(sheetsHeader) {
+		t.Errorf("first row = %v, want the header %v", header, sheetsHeader)
+	}
+	if last := (*appends)[1][1]; last[0] != "2024-05-01" || last[6] != fmt.Sprintf("a%d", sheetsBatchSize) {
+		t.Errorf("last row = %v, want the last anomaly", last)
+	}
+
+	if err := notifier.Notify(manyAnomalies(1)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if *reads != 1 || len((*appends)[2]) != 1 {
+		t.Errorf("second run read the header %d times and appended %v, want one read and no second header", *reads, (*appends)[2])
+	}
+}
+
+func TestSheetsNotifierSkipsHeaderOnExistingTab(t *testing.T) {
+	notifier, appends, _ := sheetsServer(t, [][]interface{}{sheetsHeader})
+
+	if err := notifier.Notify(manyAnomalies(2)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(*appends) != 1 || len((*appends)[0]) != 2 {
+		t.Errorf("appended %v, want just the two anomaly rows", *appends)
+	}
+	if err := notifier.Notify(nil); err != nil || len(*appends) != 1 {
+		t.Errorf("Notify(nil) = %v and appended again, want nothing sent", err)
+	}
+}
+
+func TestNewSheetsNotifierNeedsSpreadsheetID(t *testing.T) {
+	if _, err := NewSheetsNotifier("sheets", "", "Anomalies"); err == nil {
+		t.Error("NewSheetsNotifier() with no spreadsheet ID returned no error")
+	}
+}
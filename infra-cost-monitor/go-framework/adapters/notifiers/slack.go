@@ -0,0 +1,169 @@
+package notifiers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// slackMessage is the body of a Slack incoming webhook request
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// slackAttachment is one alert or anomaly within a Slack message
+type slackAttachment struct {
+	Color      string        `json:"color"`
+	Title      string        `json:"title"`
+	Text       string        `json:"text"`
+	Fields     []slackField  `json:"fields,omitempty"`
+	Footer     string        `json:"footer,omitempty"`
+	CallbackID string        `json:"callback_id,omitempty"`
+	Actions    []slackAction `json:"actions,omitempty"`
+}
+
+// slackField is a short labelled value in an attachment
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// slackAction is an interactive button on an attachment; Slack posts its value
+// to the app's interactivity URL, served by the feedback action handler
+type slackAction struct {
+	Name  string `json:"name"`
+	Text  string `json:"text"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// anomalyActionsCallback identifies anomaly attachments in interaction payloads
+const anomalyActionsCallback = "anomaly_actions"
+
+// SlackNotifier posts trigger alerts and anomalies to a Slack incoming webhook,
+// batching everything from one call into a single message with one attachment each
+type SlackNotifier struct {
+	name       string
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackNotifier creates a new Slack notifier for the webhook URL
+func NewSlackNotifier(name, webhookURL string) (*SlackNotifier, error) {
+	if webhookURL == "" {
+		return nil, fmt.Errorf("slack notifier %s needs a webhook URL", name)
+	}
+
+	return &SlackNotifier{
+		name:       name,
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns the notifier name
+func (sn *SlackNotifier) Name() string {
+	return sn.name
+}
+
+// Send posts the alerts raised by the MTD triggers as a single message
+func (sn *SlackNotifier) Send(alerts []models.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	message := slackMessage{Text: fmt.Sprintf(":rotating_light: %d cost alerts triggered", len(alerts))}
+	for _, alert := range alerts {
+		message.Attachments = append(message.Attachments, slackAttachment{
+			Color: slackColor(alert.Severity),
+			Title: fmt.Sprintf("[%s] %s", alert.Severity, alert.Type),
+			Text:  alert.Message,
+			Fields: []slackField{
+				{Title: "Cost impact", Value: fmt.Sprintf("%.2f", alert.CostImpact), Short: true},
+			},
+			Footer: alert.Time,
+		})
+	}
+	if err := sn.post(message); err != nil {
+		return fmt.Errorf("failed to send %d alerts to Slack: %w", len(alerts), err)
+	}
+
+	log.Printf("✅ %s: sent %d alerts to Slack", sn.name, len(alerts))
+	return nil
+}
+
+// Notify posts the anomalies as a single message
+func (sn *SlackNotifier) Notify(anomalies []models.Anomaly) error {
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	message := slackMessage{Text: fmt.Sprintf(":mag: %d cost anomalies detected", len(anomalies))}
+	for _, anomaly := range anomalies {
+		message.Attachments = append(message.Attachments, slackAttachment{
+			Color: slackColor(anomaly.Severity),
+			Title: fmt.Sprintf("[%s] %s %s", anomaly.Severity, anomaly.Service, anomaly.Date),
+			Text:  anomaly.Description,
+			Fields: []slackField{
+				{Title: "Cost impact", Value: fmt.Sprintf("%.2f", anomaly.CostImpact), Short: true},
+				{Title: "Test", Value: anomaly.TestName, Short: true},
+			},
+			Footer:     anomaly.ID,
+			CallbackID: anomalyActionsCallback,
+			Actions:    anomalyActions(anomaly.ID),
+		})
+	}
+	if err := sn.post(message); err != nil {
+		return fmt.Errorf("failed to send %d anomalies to Slack: %w", len(anomalies), err)
+	}
+
+	log.Printf("✅ %s: sent %d anomalies to Slack", sn.name, len(anomalies))
+	return nil
+}
+
+// post sends a message to the webhook, which answers 200 on success
+func (sn *SlackNotifier) post(message slackMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	resp, err := sn.client.Post(sn.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// anomalyActions returns the ack and snooze buttons for an anomaly, in the
+// "ack:<id>" and "snooze:<id>:<duration>" form the action handler parses
+func anomalyActions(anomalyID string) []slackAction {
+	return []slackAction{
+		{Name: "ack", Text: "Acknowledge", Type: "button", Value: "ack:" + anomalyID},
+		{Name: "snooze", Text: "Snooze 1 day", Type: "button", Value: "snooze:" + anomalyID + ":24h"},
+		{Name: "snooze", Text: "Snooze 7 days", Type: "button", Value: "snooze:" + anomalyID + ":168h"},
+	}
+}
+
+// slackColor maps a severity to an attachment color
+func slackColor(severity string) string {
+	switch strings.ToUpper(severity) {
+	case models.SeverityCritical, models.SeverityHigh:
+		return "danger"
+	case models.SeverityMedium:
+		return "warning"
+	}
+	return "#439FE0"
+}
@@ -0,0 +1,148 @@
+package notifiers
+
+import (
+	"encoding/json"
+	"errors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// captureServer records the bodies posted to it and answers with status
+func captureServer(t *testing.T, status int) (*httptest.Server, *[][]byte) {
+	t.Helper()
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("ReadAll: %v", err)
+		}
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+func TestSlackNotifierBatchesAlerts(t *testing.T) {
+	server, bodies := captureServer(t, http.StatusOK)
+	notifier, err := NewSlackNotifier("slack", server.URL)
+	if err != nil {
+		t.Fatalf("NewSlackNotifier: %v", err)
+	}
+
+	alerts := []models.Alert{
+		{Type: "cost_spike", Severity: models.SeverityHigh, Message: "Daily cost spike detected", CostImpact: 1234.5},
+		{Type: "monthly_drop", Severity: models.SeverityMedium, Message: "Monthly cost drop detected", CostImpact: -99},
+	}
+	if err := notifier.Send(alerts); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(*bodies) != 1 {
+		t.Fatalf("got %d requests, want one batched message", len(*bodies))
+	}
+
+	var message slackMessage
+	if err := json.Unmarshal((*bodies)[0], &message); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(message.Attachments) != 2 {
+		t.Fatalf("got %d attachments, want 2", len(message.Attachments))
+	}
+	first := message.Attachments[0]
+	if first.Title != "[HIGH] cost_spike" || first.Text != "Daily cost spike detected" || first.Color != "danger" {
+		t.Errorf("first attachment = %+v", first)
+	}
+	if len(first.Fields) == 0 || first.Fields[0].Value != "1234.50" {
+		t.Errorf("first attachment fields = %+v, want the cost impact", first.Fields)
+	}
+	if message.Attachments[1].Color != "warning" {
+		t.Errorf("second attachment color = %q, want warning", message.Attachments[1].Color)
+	}
+}
+
+func TestSlackNotifierNoAlertsSendsNothing(t *testing.T) {
+	server, bodies := captureServer(t, http.StatusOK)
+	notifier, err := NewSlackNotifier("slack", server.URL)
+	if err != nil {
+		t.Fatalf("NewSlackNotifier: %v", err)
+	}
+	if err := notifier.Send(nil); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(*bodies) != 0 {
+		t.Errorf("got %d requests, want none", len(*bodies))
+	}
+}
+
+func TestSlackNotifierWrapsNon200(t *testing.T) {
+	server, _ := captureServer(t, http.StatusForbidden)
+	notifier, err := NewSlackNotifier("slack", server.URL)
+	if err != nil {
+		t.Fatalf("NewSlackNotifier: %v", err)
+	}
+
+	err = notifier.Send([]models.Alert{{Type: "cost_spike", Severity: models.SeverityHigh}})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("got error %v, want one reporting status 403", err)
+	}
+	if errors.Unwrap(err) == nil {
+		t.Error("error does not wrap the underlying failure")
+	}
+}
+
+func TestNewSlackNotifierNeedsWebhookURL(t *testing.T) {
+	if _, err := NewSlackNotifier("slack", ""); err == nil {
+		t.Error("got no error for an empty webhook URL")
+	}
+}
+
+func TestSlackNotifierAddsAnomalyButtons(t *testing.T) {
+	server, bodies := captureServer(t, http.StatusOK)
+	notifier, err := NewSlackNotifier("slack", server.URL)
+	if err != nil {
+		t.Fatalf("NewSlackNotifier: %v", err)
+	}
+
+	anomalies := []models.Anomaly{{ID: "abc123", Service: "Compute", Severity: models.SeverityHigh, Date: "2024-03-01"}}
+	if err := notifier.Notify(anomalies); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(*bodies) != 1 {
+		t.Fatalf("got %d requests, want one", len(*bodies))
+	}
+
+	var message slackMessage
+	if err := json.Unmarshal((*bodies)[0], &message); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(message.Attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(message.Attachments))
+	}
+	attachment := message.Attachments[0]
+	if attachment.CallbackID == "" {
+		t.Error("attachment has no callback_id, so Slack will not make its buttons interactive")
+	}
+
+	var values []string
+	for _, action := range attachment.Actions {
+		if action.Type != "button" {
+			t.Errorf("action %q has type %q, want button", action.Name, action.Type)
+		}
+		values = append(values, action.Value)
+	}
+	want := []string{"ack:abc123", "snooze:abc123:24h", "snooze:abc123:168h"}
+	if strings.Join(values, ",") != strings.Join(want, ",") {
+		t.Errorf("button values = %v, want %v", values, want)
+	}
+}
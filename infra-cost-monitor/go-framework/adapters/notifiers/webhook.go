@@ -0,0 +1,134 @@
+package notifiers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+// webhookDefaultTemplate posts the anomalies as a JSON array
+const webhookDefaultTemplate = `{{json .Anomalies}}`
+
+// WebhookSignatureHeader carries the hex HMAC-SHA256 of the body, prefixed with "sha256="
+const WebhookSignatureHeader = "X-Signature-256"
+
+// webhookData is the data the body template is executed with
+type webhookData struct {
+	Anomalies []models.Anomaly
+	Count     int
+	Time      string
+}
+
+// webhookFuncs are the functions available to body templates
+var webhookFuncs = template.FuncMap{
+	// json renders a value as JSON, for embedding strings and lists safely
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+}
+
+// WebhookNotifier posts anomalies to an arbitrary URL with a body rendered from a
+// text/template, so Teams, internal systems and the like need no new code. The
+// template receives .Anomalies, .Count and .Time. When a secret is set the body
+// is signed with HMAC-SHA256 in the X-Signature-256 header.
+type WebhookNotifier struct {
+	name        string
+	url         string
+	body        *template.Template
+	contentType string
+	headers     map[string]string
+	secret      string
+	client      *http.Client
+}
+
+// NewWebhookNotifier creates a new webhook notifier; an empty body template posts
+// the anomalies as JSON and an empty content type defaults to application/json
+func NewWebhookNotifier(name, url, bodyTemplate, contentType string, headers map[string]string, secret string) (*WebhookNotifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook notifier %s needs a url", name)
+	}
+	if bodyTemplate == "" {
+		bodyTemplate = webhookDefaultTemplate
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	body, err := template.New(name).Funcs(webhookFuncs).Parse(bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template of webhook notifier %s: %v", name, err)
+	}
+
+	return &WebhookNotifier{
+		name:        name,
+		url:         url,
+		body:        body,
+		contentType: contentType,
+		headers:     headers,
+		secret:      secret,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns the notifier name
+func (wn *WebhookNotifier) Name() string {
+	return wn.name
+}
+
+// Notify renders the template over all anomalies and posts it in a single request
+func (wn *WebhookNotifier) Notify(anomalies []models.Anomaly) error {
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	err := wn.body.Execute(&body, webhookData{
+		Anomalies: anomalies,
+		Count:     len(anomalies),
+		Time:      time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render webhook body: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, wn.url, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", wn.contentType)
+	for name, value := range wn.headers {
+		req.Header.Set(name, value)
+	}
+	if wn.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookBody(body.Bytes(), wn.secret))
+	}
+
+	resp, err := wn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	log.Printf("✅ %s: posted %d anomalies to webhook", wn.name, len(anomalies))
+	return nil
+}
+
+// SignWebhookBody returns the hex HMAC-SHA256 of body under secret, for receivers to verify
+func SignWebhookBody(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,96 @@
+package notifiers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// capturedRequest is a request received by a webhook test server
+type capturedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookServer records the requests posted to it
+func webhookServer(t *testing.T) (*httptest.Server, *[]capturedRequest) {
+	t.Helper()
+	var requests []capturedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("ReadAll: %v", err)
+		}
+		requests = append(requests, capturedRequest{header: r.Header, body: body})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+var webhookAnomalies = []models.Anomaly{
+	{ID: "a1", Service: "Compute", Severity: models.SeverityHigh, CostImpact: 1500, Description: "Compute spiked"},
+	{ID: "a2", Service: "Storage", Severity: models.SeverityLow, CostImpact: 12.5, Description: "Storage \"rose\""},
+}
+
+func TestWebhookNotifierRendersTemplate(t *testing.T) {
+	server, requests := webhookServer(t)
+	tmpl := `{"count": {{.Count}}, "items": [{{range $i, $a := .Anomalies}}{{if $i}}, {{end}}{"service": {{json $a.Service}}, "severity": "{{$a.Severity}}", "impact": {{printf "%.2f" $a.CostImpact}}, "text": {{json $a.Description}}}{{end}}]}`
+	notifier, err := NewWebhookNotifier("teams", server.URL, tmpl, "application/vnd.custom+json", map[string]string{"X-Team": "finops"}, "")
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier: %v", err)
+	}
+	if err := notifier.Notify(webhookAnomalies); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*requests))
+	}
+	req := (*requests)[0]
+	want := `{"count": 2, "items": [{"service": "Compute", "severity": "HIGH", "impact": 1500.00, "text": "Compute spiked"}, {"service": "Storage", "severity": "LOW", "impact": 12.50, "text": "Storage \"rose\""}]}`
+	if string(req.body) != want {
+		t.Errorf("body = %s\nwant %s", req.body, want)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/vnd.custom+json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := req.header.Get("X-Team"); got != "finops" {
+		t.Errorf("X-Team = %q, want finops", got)
+	}
+	if got := req.header.Get(WebhookSignatureHeader); got != "" {
+		t.Errorf("unsigned webhook sent signature %q", got)
+	}
+}
+
+func TestWebhookNotifierSignsBody(t *testing.T) {
+	server, requests := webhookServer(t)
+	notifier, err := NewWebhookNotifier("internal", server.URL, "", "", nil, "s3cret")
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier: %v", err)
+	}
+	if err := notifier.Notify(webhookAnomalies); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req := (*requests)[0]
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(req.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.header.Get(WebhookSignatureHeader); got != want {
+		t.Errorf("%s = %q, want %q", WebhookSignatureHeader, got, want)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+}
+
+func TestNewWebhookNotifierRejectsBadTemplate(t *testing.T) {
+	if _, err := NewWebhookNotifier("bad", "http://example.invalid", "{{.Anomalies", "", nil, ""); err == nil {
+		t.Error("got no error for an unparseable template")
+	}
+}
@@ -0,0 +1,116 @@
+package reports
+
+import (
+	"encoding/json"
+	"infra-cost-monitor/go-framework/adapters/metrics"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Handler serves the reports written by the last run from its output directory.
+// Each request reads the files afresh, so a new run is picked up without a restart.
+type Handler struct {
+	dir        string
+	jsonOutput *utils.JSONOutput
+}
+
+// NewHandler creates a new report handler reading from the output directory
+func NewHandler(dir string) *Handler {
+	return &Handler{
+		dir:        dir,
+		jsonOutput: utils.NewJSONOutput(),
+	}
+}
+
+// Register mounts /daily, /mtd, /anomalies, /summary and /metrics on the mux
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/daily", h.serveDaily)
+	mux.HandleFunc("/mtd", h.serveMTD)
+	mux.HandleFunc("/anomalies", h.serveAnomalies)
+	mux.HandleFunc("/summary", h.serveSummary)
+	mux.HandleFunc("/metrics", h.serveMetrics)
+}
+
+// serveDaily serves the daily totals, newest first
+func (h *Handler) serveDaily(w http.ResponseWriter, r *http.Request) {
+	dailyTotals, err := h.jsonOutput.LoadDailyTotals(h.path("daily_total_data.json"))
+	h.respond(w, r, dailyTotals, err)
+}
+
+// serveMTD serves the month-to-date costs, newest month first
+func (h *Handler) serveMTD(w http.ResponseWriter, r *http.Request) {
+	mtdData, err := h.jsonOutput.LoadMTDData(h.path("mtd_data.json"))
+	h.respond(w, r, mtdData, err)
+}
+
+// serveAnomalies serves the anomalies detected by the last run
+func (h *Handler) serveAnomalies(w http.ResponseWriter, r *http.Request) {
+	anomalies, err := h.jsonOutput.LoadAnomalies(h.path("anomalies.json"))
+	h.respond(w, r, anomalies, err)
+}
+
+// serveSummary serves the summary of the last run
+func (h *Handler) serveSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.jsonOutput.LoadSummary(h.path("summary.json"))
+	h.respond(w, r, summary, err)
+}
+
+// serveMetrics serves the last run's metrics in the Prometheus text format
+func (h *Handler) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.jsonOutput.LoadSummary(h.path("summary.json"))
+	if err != nil {
+		h.respond(w, r, nil, err)
+		return
+	}
+	anomalies, err := h.jsonOutput.LoadAnomalies(h.path("anomalies.json"))
+	if err != nil {
+		h.respond(w, r, nil, err)
+		return
+	}
+
+	// The composite data may have been written gzip-compressed
+	compositePath := h.path("composite_data.json")
+	if _, err := os.Stat(compositePath); os.IsNotExist(err) {
+		compositePath += ".gz"
+	}
+	compositeData, err := h.jsonOutput.LoadCompositeData(compositePath)
+	if err != nil {
+		log.Printf("Warning: Serving metrics without the service breakdown: %v", err)
+	}
+
+	exporter := metrics.NewExporter()
+	exporter.Observe(summary, compositeData, anomalies)
+	exporter.ServeHTTP(w, r)
+}
+
+// path returns the path of a report in the output directory
+func (h *Handler) path(name string) string {
+	return filepath.Join(h.dir, name)
+}
+
+// respond writes the report as JSON, or a 404 when the last run has not written it
+func (h *Handler) respond(w http.ResponseWriter, r *http.Request, report interface{}, err error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if os.IsNotExist(err) {
+		http.Error(w, "report not available; run the monitor first", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading report for %s: %v", r.URL.Path, err)
+		http.Error(w, "failed to load report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Printf("Error writing report for %s: %v", r.URL.Path, err)
+	}
+}
@@ -0,0 +1,152 @@
+package reports
+
+import (
+	"encoding/json"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// newReportServer writes a run's reports to a temporary output directory and
+// serves them
+func newReportServer(t *testing.T, daily []models.DailyCost, mtd []models.MTDCost, anomalies []models.Anomaly, summary models.Summary) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	out := utils.NewJSONOutput()
+	for name, save := range map[string]func(string) error{
+		"daily_total_data.json": func(path string) error { return out.SaveDailyTotals(daily, path) },
+		"mtd_data.json":         func(path string) error { return out.SaveMTDData(mtd, path) },
+		"anomalies.json":        func(path string) error { return out.SaveAnomalies(anomalies, path) },
+		"summary.json":          func(path string) error { return out.SaveSummary(summary, path) },
+	} {
+		if err := save(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("saving %s: %v", name, err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	NewHandler(dir).Register(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// getJSON fetches path and decodes the body into v, rejecting fields the model does not have
+func getJSON(t *testing.T, server *httptest.Server, path string, v interface{}) {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s status = %d, want %d", path, resp.StatusCode, http.StatusOK)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET %s Content-Type = %q, want application/json", path, ct)
+	}
+	decoder := json.NewDecoder(resp.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		t.Fatalf("GET %s does not decode as %T: %v", path, v, err)
+	}
+}
+
+func TestHandlerServesReportsAsModels(t *testing.T) {
+	daily := []models.DailyCost{{Date: "2024-05-02", TotalCost: 200, Currency: "INR"}, {Date: "2024-05-01", TotalCost: 100, Currency: "INR"}}
+	mtd := []models.MTDCost{{Month: "2024-05", Cost: 300, Days: 2, Currency: "INR"}}
+	anomalies := []models.Anomaly{{ID: "a1", Date: "2024-05-02", TestName: "Daily Spike Detector", Type: "daily_spike", Service: "daily_total", Severity: models.SeverityHigh, CostImpact: 100}}
+	summary := models.Summary{TotalAnomalies: 1, TotalCostImpact: 100, CurrentMonthCost: 300, CurrentMonthDays: 2, CurrentDateCost: 200}
+	server := newReportServer(t, daily, mtd, anomalies, summary)
+
+	tests := []struct {
+		path string
+		got  interface{}
+		want interface{}
+	}{
+		{"/daily", &[]models.DailyCost{}, &daily},
+		{"/mtd", &[]models.MTDCost{}, &mtd},
+		{"/anomalies", &[]models.Anomaly{}, &anomalies},
+		{"/summary", &models.Summary{}, &summary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			getJSON(t, server, tt.path, tt.got)
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("GET %s = %+v, want %+v", tt.path, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerServesMetrics(t *testing.T) {
+	summary := models.Summary{
+		CurrentDateCost:   200,
+		CurrentMonthCost:  300,
+		DetectorPrecision: []models.DetectorPrecision{{Detector: "spike", TruePositives: 3, FalsePositives: 1, Precision: 0.75}},
+	}
+	anomalies := []models.Anomaly{{Date: "2024-05-02", TestName: "Daily Spike Detector", Severity: models.SeverityHigh}}
+	server := newReportServer(t, nil, nil, anomalies, summary)
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	for _, want := range []string{"cost_monitor_daily_cost 200\n", "cost_monitor_mtd_cost 300\n", `cost_monitor_anomalies{severity="HIGH"} 1`, `cost_monitor_detector_precision{detector="spike"} 0.75`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(t.TempDir()).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		status int
+	}{
+		{"report not written yet", http.MethodGet, http.StatusNotFound},
+		{"method other than GET", http.MethodPost, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+"/summary", nil)
+			if err != nil {
+				t.Fatalf("NewRequest: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%s /summary: %v", tt.method, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
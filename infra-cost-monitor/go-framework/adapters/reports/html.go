@@ -0,0 +1,201 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// topN is how many entries each breakdown table shows
+const topN = 10
+
+// chartWidth is the width in pixels of the longest bar in a breakdown chart
+const chartWidth = 320
+
+// BreakdownRow is one entry of a cost breakdown table
+type BreakdownRow struct {
+	Name       string
+	Cost       float64
+	Percentage float64
+	BarWidth   float64
+}
+
+// Breakdown is a titled top-N cost breakdown
+type Breakdown struct {
+	Title string
+	Rows  []BreakdownRow
+}
+
+// reportData is what the HTML template renders
+type reportData struct {
+	GeneratedAt string
+	Month       string
+	Summary     models.Summary
+	Breakdowns  []Breakdown
+	Anomalies   []models.Anomaly
+}
+
+// ReportGenerator renders a self-contained HTML dashboard of a run: the summary
+// figures, the top services, projects and regions of the latest month, and the
+// anomalies. Styles and charts are inline, so the file can be mailed or attached.
+type ReportGenerator struct {
+	tmpl *template.Template
+	now  func() time.Time
+}
+
+// NewReportGenerator creates a new HTML report generator
+func NewReportGenerator() *ReportGenerator {
+	return &ReportGenerator{
+		tmpl: template.Must(template.New("report").Funcs(template.FuncMap{
+			"money":    func(v float64) string { return fmt.Sprintf("%.2f", v) },
+			"percent":  func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+			"severity": strings.ToLower,
+		}).Parse(reportTemplate)),
+		now: time.Now,
+	}
+}
+
+// Render writes the HTML report to w
+func (rg *ReportGenerator) Render(w io.Writer, summary models.Summary, compositeData []models.CostData, anomalies []models.Anomaly) error {
+	month := latestMonth(compositeData)
+	data := reportData{
+		GeneratedAt: rg.now().Format("2006-01-02 15:04:05"),
+		Month:       month,
+		Summary:     summary,
+		Anomalies:   anomalies,
+		Breakdowns: []Breakdown{
+			breakdown("Top services", month, compositeData, func(cost models.CostData) string { return cost.Service }),
+			breakdown("Top projects", month, compositeData, func(cost models.CostData) string { return cost.ProjectID }),
+			breakdown("Top regions", month, compositeData, func(cost models.CostData) string { return cost.Region }),
+		},
+	}
+	return rg.tmpl.Execute(w, data)
+}
+
+// WriteFile renders the HTML report to path
+func (rg *ReportGenerator) WriteFile(path string, summary models.Summary, compositeData []models.CostData, anomalies []models.Anomaly) error {
+	log.Printf("💾 Saving HTML report to %s", path)
+
+	var buf bytes.Buffer
+	if err := rg.Render(&buf, summary, compositeData, anomalies); err != nil {
+		return fmt.Errorf("failed to render HTML report: %v", err)
+	}
+	return utils.WriteFileAtomic(path, buf.Bytes())
+}
+
+// latestMonth returns the newest month (YYYY-MM) in the composite data
+func latestMonth(compositeData []models.CostData) string {
+	latest := ""
+	for _, cost := range compositeData {
+		if len(cost.Date) >= 7 && cost.Date[:7] > latest {
+			latest = cost.Date[:7]
+		}
+	}
+	return latest
+}
+
+// breakdown totals the month's cost by the dimension and keeps the top entries
+func breakdown(title, month string, compositeData []models.CostData, dimension func(models.CostData) string) Breakdown {
+	totals := make(map[string]float64)
+	var total float64
+	for _, cost := range compositeData {
+		if !strings.HasPrefix(cost.Date, month) {
+			continue
+		}
+		name := dimension(cost)
+		if name == "" {
+			name = "(none)"
+		}
+		totals[name] += cost.Cost
+		total += cost.Cost
+	}
+
+	rows := make([]BreakdownRow, 0, len(totals))
+	for name, cost := range totals {
+		rows = append(rows, BreakdownRow{Name: name, Cost: cost})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Cost != rows[j].Cost {
+			return rows[i].Cost > rows[j].Cost
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	if len(rows) > topN {
+		rows = rows[:topN]
+	}
+
+	for i := range rows {
+		if total > 0 {
+			rows[i].Percentage = rows[i].Cost / total * 100
+		}
+		if rows[0].Cost > 0 && rows[i].Cost > 0 {
+			rows[i].BarWidth = rows[i].Cost / rows[0].Cost * chartWidth
+		}
+	}
+	return Breakdown{Title: title, Rows: rows}
+}
+
+// reportTemplate is the self-contained HTML dashboard
+const reportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Cost Monitor Report{{if .Summary.RunID}} - {{.Summary.RunID}}{{end}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+.meta { color: #666; margin-bottom: 2em; }
+.cards { display: flex; flex-wrap: wrap; gap: 1em; margin-bottom: 2em; }
+.card { border: 1px solid #ddd; border-radius: 6px; padding: 1em 1.5em; min-width: 160px; }
+.card .label { color: #666; font-size: 0.85em; }
+.card .value { font-size: 1.5em; font-weight: 600; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { text-align: left; padding: 0.4em 0.8em; border-bottom: 1px solid #eee; vertical-align: middle; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.severity { font-weight: 600; padding: 0.1em 0.5em; border-radius: 4px; }
+.severity.low { background: #e8f5e9; }
+.severity.medium { background: #fff8e1; }
+.severity.high { background: #ffe0b2; }
+.severity.critical { background: #ffcdd2; }
+</style>
+</head>
+<body>
+<h1>Cost Monitor Report</h1>
+<div class="meta">Generated {{.GeneratedAt}}{{if .Summary.RunID}} &middot; run {{.Summary.RunID}}{{end}}</div>
+
+<div class="cards">
+<div class="card"><div class="label">Latest day</div><div class="value">{{money .Summary.CurrentDateCost}}</div></div>
+<div class="card"><div class="label">Month to date ({{.Summary.CurrentMonthDays}} days)</div><div class="value">{{money .Summary.CurrentMonthCost}}</div></div>
+<div class="card"><div class="label">Last month ({{.Summary.LastMonthDays}} days)</div><div class="value">{{money .Summary.LastMonthCost}}</div></div>
+{{with .Summary.MonthEndForecast}}<div class="card"><div class="label">Projected month end</div><div class="value">{{money .Projected}}</div></div>{{end}}
+<div class="card"><div class="label">Anomalies</div><div class="value">{{.Summary.TotalAnomalies}}</div></div>
+<div class="card"><div class="label">Anomaly cost impact</div><div class="value">{{money .Summary.TotalCostImpact}}</div></div>
+</div>
+
+{{range .Breakdowns}}
+<h2>{{.Title}}{{if $.Month}} ({{$.Month}}){{end}}</h2>
+{{if .Rows}}
+<table>
+<tr><th>Name</th><th>Cost</th><th>Share</th><th></th></tr>
+{{range .Rows}}<tr><td>{{.Name}}</td><td class="num">{{money .Cost}}</td><td class="num">{{percent .Percentage}}</td><td><svg width="{{printf "%.0f" .BarWidth}}" height="12"><rect width="{{printf "%.1f" .BarWidth}}" height="12" fill="#4a90d9"></rect></svg></td></tr>
+{{end}}</table>
+{{else}}<p>No cost data.</p>{{end}}
+{{end}}
+
+<h2>Anomalies</h2>
+{{if .Anomalies}}
+<table>
+<tr><th>Date</th><th>Severity</th><th>Test</th><th>Description</th><th>Cost impact</th></tr>
+{{range .Anomalies}}<tr><td>{{.Date}}</td><td><span class="severity {{severity .Severity}}">{{.Severity}}</span></td><td>{{.TestName}}</td><td>{{.Description}}</td><td class="num">{{money .CostImpact}}</td></tr>
+{{end}}</table>
+{{else}}<p>No anomalies detected.</p>{{end}}
+</body>
+</html>
+`
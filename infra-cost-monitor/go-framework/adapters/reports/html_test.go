@@ -0,0 +1,146 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestGenerator returns a report generator whose clock is fixed
+func newTestGenerator() *ReportGenerator {
+	rg := NewReportGenerator()
+	rg.now = func() time.Time { return time.Date(2024, 5, 10, 7, 30, 0, 0, time.UTC) }
+	return rg
+}
+
+// reportFixture is a run's summary, composite data and anomalies
+func reportFixture() (models.Summary, []models.CostData, []models.Anomaly) {
+	summary := models.Summary{
+		RunID:            "run-42",
+		TotalAnomalies:   2,
+		TotalCostImpact:  1234.5,
+		CurrentMonthCost: 45678.9,
+		CurrentMonthDays: 10,
+		LastMonthCost:    98765.43,
+		LastMonthDays:    30,
+		CurrentDateCost:  4567.89,
+		MonthEndForecast: &models.MonthEndForecast{Month: "2024-05", Projected: 141604.59},
+	}
+	compositeData := []models.CostData{
+		{Date: "2024-05-09", Service: "Compute Engine", ProjectID: "prod", Region: "us-central1", Cost: 300},
+		{Date: "2024-05-10", Service: "Compute Engine", ProjectID: "prod", Region: "us-central1", Cost: 300},
+		{Date: "2024-05-10", Service: "BigQuery", ProjectID: "analytics", Region: "eu", Cost: 400},
+		// Last month is left out of the breakdowns
+		{Date: "2024-04-30", Service: "Cloud Spanner", ProjectID: "legacy", Region: "asia-east1", Cost: 99999},
+	}
+	anomalies := []models.Anomaly{
+		{Date: "2024-05-10", TestName: "Daily Spike Detector", Severity: models.SeverityCritical, CostImpact: 1000, Description: "Daily cost rose 120.0% (2000.00 -> 4400.00)"},
+		{Date: "2024-05-10", TestName: "Usage Spike Detector", Severity: models.SeverityLow, CostImpact: 234.5, Description: "Usage of <VM> & disks rose 60.0%"},
+	}
+	return summary, compositeData, anomalies
+}
+
+func TestRenderIncludesKeyFiguresAndAnomalies(t *testing.T) {
+	summary, compositeData, anomalies := reportFixture()
+	var buf bytes.Buffer
+	if err := newTestGenerator().Render(&buf, summary, compositeData, anomalies); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	html := buf.String()
+
+	for _, want := range []string{
+		"<title>Cost Monitor Report - run-42</title>",
+		"Generated 2024-05-10 07:30:00",
+		">4567.89<",
+		"Month to date (10 days)</div><div class=\"value\">45678.90<",
+		"Last month (30 days)</div><div class=\"value\">98765.43<",
+		"Projected month end</div><div class=\"value\">141604.59<",
+		"Anomaly cost impact</div><div class=\"value\">1234.50<",
+		"Top services (2024-05)",
+		"Top projects (2024-05)",
+		"Top regions (2024-05)",
+		`<span class="severity critical">CRITICAL</span>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+
+	// Descriptions are HTML-escaped
+	for _, description := range []string{
+		"Daily cost rose 120.0% (2000.00 -&gt; 4400.00)",
+		"Usage of &lt;VM&gt; &amp; disks rose 60.0%",
+	} {
+		if !strings.Contains(html, description) {
+			t.Errorf("report does not contain the anomaly description %q", description)
+		}
+	}
+	if strings.Contains(html, "<VM>") {
+		t.Error("report contains an unescaped anomaly description")
+	}
+	if strings.Contains(html, "Cloud Spanner") {
+		t.Error("report breaks down last month's cost")
+	}
+	if strings.Contains(html, "<link") || strings.Contains(html, "<script") {
+		t.Error("report is not self-contained")
+	}
+}
+
+func TestRenderWithoutDataOrAnomalies(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestGenerator().Render(&buf, models.Summary{}, nil, nil); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	html := buf.String()
+	for _, want := range []string{"<title>Cost Monitor Report</title>", "No cost data.", "No anomalies detected."} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+	if strings.Contains(html, "Projected month end") {
+		t.Error("report shows a forecast the summary does not have")
+	}
+}
+
+func TestBreakdownKeepsTheTopEntries(t *testing.T) {
+	var compositeData []models.CostData
+	for i := 1; i <= topN+5; i++ {
+		compositeData = append(compositeData, models.CostData{Date: "2024-05-10", Service: fmt.Sprintf("service-%02d", i), Cost: float64(i)})
+	}
+	compositeData = append(compositeData, models.CostData{Date: "2024-05-10", Cost: 1000})
+
+	result := breakdown("Top services", "2024-05", compositeData, func(cost models.CostData) string { return cost.Service })
+	if len(result.Rows) != topN {
+		t.Fatalf("got %d rows, want %d", len(result.Rows), topN)
+	}
+	top := result.Rows[0]
+	if top.Name != "(none)" || top.BarWidth != chartWidth {
+		t.Errorf("top row = %+v, want the unnamed service at full chart width", top)
+	}
+	if second := result.Rows[1]; second.Name != "service-15" || second.BarWidth != 15.0/1000*chartWidth {
+		t.Errorf("second row = %+v, want service-15 scaled against the top row", second)
+	}
+	if percentage := top.Percentage; percentage != 1000.0/1120*100 {
+		t.Errorf("top row share = %v%%, want its share of every service's cost", percentage)
+	}
+}
+
+func TestWriteFileSavesTheReport(t *testing.T) {
+	summary, compositeData, anomalies := reportFixture()
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := newTestGenerator().WriteFile(path, summary, compositeData, anomalies); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "<!DOCTYPE html>") || !strings.Contains(string(data), "Daily Spike Detector") {
+		t.Errorf("%s does not hold the rendered report", path)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackageBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the binary")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	// main.go and demo.go are compiled together, so a second main would fail here
+	binary := filepath.Join(t.TempDir(), "cosmos")
+	if output, err := exec.Command(goTool, "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, output)
+	}
+	if _, err := os.Stat(binary); err != nil {
+		t.Errorf("Stat: %v", err)
+	}
+}
+
+func TestDemoSubcommandFailsWithoutBigQuery(t *testing.T) {
+	for _, name := range []string{"GOOGLE_CLOUD_PROJECT", "BIGQUERY_DATASET", "BIGQUERY_TABLE", "BIGQUERY_BILLING_EXPORT_TABLE"} {
+		t.Setenv(name, "")
+	}
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	if code := runDemo([]string{"--days", "7"}); code != exitFatal {
+		t.Errorf("exit code = %d, want %d", code, exitFatal)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/currency"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/monitors"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+)
+
+// compositeKeyDaily is one line of composite_key_daily.jsonl
+type compositeKeyDaily struct {
+	Key   string             `json:"key"`
+	Daily map[string]float64 `json:"daily"`
+}
+
+// runChunkedAggregation streams dimensional rows into bounded aggregates and
+// writes the per-key daily costs to path as JSON lines, one key at a time. It
+// returns the daily totals of the rows it aggregated.
+func runChunkedAggregation(cfg config.ProcessingConfig, dimensionalMonitor *monitors.DimensionalMonitor, converter *currency.Converter, path string) ([]models.DailyCost, error) {
+	log.Println("🧮 Aggregating dimensional costs in chunked mode...")
+	aggregator := utils.NewChunkedAggregator(cfg.BatchSize, cfg.SpillDir, cfg.Partitions)
+
+	err := dimensionalMonitor.ForEachCostRow(func(cost models.CostData) error {
+		amount, err := converter.Convert(cost.Cost, cost.Currency, cost.Date)
+		if err != nil {
+			return err
+		}
+		cost.Cost = amount
+		return aggregator.Add(cost)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := utils.CreateAtomic(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Abort()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	keys := 0
+	err = aggregator.EachKey(func(key string, daily map[string]float64) error {
+		keys++
+		return encoder.Encode(compositeKeyDaily{Key: key, Daily: daily})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+	if err := file.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Aggregated %d rows into %d composite keys", aggregator.Rows(), keys)
+	return aggregator.DailyTotals(), nil
+}
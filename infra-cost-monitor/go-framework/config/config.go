@@ -0,0 +1,763 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/links"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"os"
+	"time"
+)
+
+// DefaultPath is the default location of the monitor configuration file
+const DefaultPath = "config/monitor_config.json"
+
+// DefaultAllocationRulesPath is the default location of the allocation rules file
+const DefaultAllocationRulesPath = "config/allocation_rules.json"
+
+// Config holds the cost monitor configuration
+type Config struct {
+	RunID     string           `json:"run_id"`
+	Timezone  string           `json:"timezone"`
+	StatePath string           `json:"state_path"`
+	EventLog  string           `json:"event_log"`
+	Allowlist Allowlist        `json:"allowlist"`
+	NewSKU    NewSKUConfig     `json:"new_sku"`
+	Notifiers []NotifierConfig `json:"notifiers"`
+	Retry     RetryConfig      `json:"notifier_retry"`
+	Breaker   BreakerConfig    `json:"notifier_breaker"`
+
+	// SlackWebhookURL posts the MTD trigger alerts to a Slack incoming webhook when set
+	SlackWebhookURL string `json:"slack_webhook_url"`
+
+	BigQueryRetry BigQueryRetryConfig `json:"bigquery_retry"`
+	// RunTimeoutMinutes cancels outstanding BigQuery operations once a run exceeds
+	// it; 0 disables the timeout
+	RunTimeoutMinutes int `json:"run_timeout_minutes"`
+
+	AnomalyTable AnomalyTableConfig `json:"anomaly_table"`
+	Onboarding   OnboardingConfig   `json:"onboarding"`
+	Currency     CurrencyConfig     `json:"currency"`
+	Forecast     ForecastConfig     `json:"forecast"`
+	ServiceBands ServiceBandsConfig `json:"service_bands"`
+	Budgets      BudgetsConfig      `json:"budgets"`
+
+	// AllocationRulesPath is the JSON file of shared-cost allocation rules; shared
+	// costs are not allocated when it is empty, or when it is the default path and
+	// the file does not exist
+	AllocationRulesPath string `json:"allocation_rules_path"`
+
+	// Detectors enables/disables tests and registered detectors by name
+	// (daily_total, daily_composite, daily_spike, ...); unlisted ones run with defaults
+	Detectors map[string]detectors.Settings `json:"detectors"`
+
+	// SplitByCostType additionally runs detectors on each charge type's daily series
+	SplitByCostType bool `json:"split_by_cost_type"`
+
+	// Summation selects how totals and breakdowns are accumulated: float,
+	// kahan (compensated) or minor_units (integer cents); the latter two round to 2 decimals
+	Summation string `json:"summation"`
+
+	// NegativeCosts selects how negative-cost rows (refunds, credit line items) are
+	// treated: net (summed in), exclude (dropped) or separate (own charge type, kept
+	// out of detection); both exclude and separate report their total in the summary
+	NegativeCosts string `json:"negative_costs"`
+
+	// DailyBaseline selects what daily costs are compared against: raw (the day
+	// before) or weekday (the same weekday in previous weeks, for spend with a
+	// weekly cycle), which also enables the weekday_baseline detector
+	DailyBaseline string `json:"daily_baseline"`
+
+	// ServiceThresholds are the per-service spike thresholds, keyed by service name
+	// or glob pattern, with a default for services matching none
+	ServiceThresholds detectors.ServiceThresholds `json:"service_thresholds"`
+
+	// SeverityBands bands percentage-based anomalies into LOW, MEDIUM, HIGH and CRITICAL
+	SeverityBands models.SeverityBands `json:"severity_bands"`
+
+	// MinHistoryDays is the history percentile tests need to run normally; down to
+	// MinHistoryFloorDays they still run, flagging anomalies with LOW confidence
+	MinHistoryDays      int `json:"min_history_days"`
+	MinHistoryFloorDays int `json:"min_history_floor_days"`
+
+	// MTDMinDaysElapsed suppresses month-over-month spike alerts until the current
+	// month has this many days of data; daily detection is unaffected
+	MTDMinDaysElapsed int `json:"mtd_min_days_elapsed"`
+
+	// DropPercentage is how far, in percent, the latest daily cost or monthly run
+	// rate must fall below the previous one to flag a drop; independent of spike thresholds
+	DropPercentage float64 `json:"drop_percentage"`
+
+	// SummaryPercentiles are the daily cost percentiles (0-1) reported in the summary
+	SummaryPercentiles []float64 `json:"summary_percentiles"`
+
+	Feedback    FeedbackConfig    `json:"feedback"`
+	Serve       ServeConfig       `json:"serve"`
+	Attribution AttributionConfig `json:"attribution"`
+	UnitCost    UnitCostConfig    `json:"unit_cost"`
+
+	DataQuality DataQualityConfig `json:"data_quality"`
+	Output      OutputConfig      `json:"output"`
+	Incremental IncrementalConfig `json:"incremental"`
+	Vendors     VendorsConfig     `json:"vendors"`
+	Processing  ProcessingConfig  `json:"processing"`
+	SelfCost    SelfCostConfig    `json:"self_cost"`
+	QueryCache  QueryCacheConfig  `json:"query_cache"`
+
+	// DashboardLinks are URL templates rendered onto each anomaly for triage
+	DashboardLinks []links.Template `json:"dashboard_links"`
+}
+
+// Allowlist holds entries that are expected and should not raise anomalies
+type Allowlist struct {
+	SKUs []string `json:"skus"`
+
+	// Projects are no-alert projects: counted in totals but skipped during detection
+	Projects []string `json:"projects"`
+}
+
+// NewSKUConfig configures detection of newly-created SKUs
+type NewSKUConfig struct {
+	Enabled bool    `json:"enabled"`
+	MinCost float64 `json:"min_cost"`
+}
+
+// Grace modes for anomalies in a newly-onboarded project
+const (
+	GraceModeSuppress  = "suppress"
+	GraceModeDowngrade = "downgrade"
+)
+
+// OnboardingConfig lists project onboarding dates (YYYY-MM-DD) and the grace
+// period after onboarding during which anomalies are suppressed or downgraded
+type OnboardingConfig struct {
+	GraceDays int               `json:"grace_days"`
+	Mode      string            `json:"mode"`
+	Projects  map[string]string `json:"projects"`
+}
+
+// Exchange rate sources for currency conversion
+const (
+	RateSourceStatic   = "static"
+	RateSourceHTTP     = "http"
+	RateSourceBigQuery = "bigquery"
+)
+
+// CurrencyConfig configures conversion of billing rows into a base currency.
+// Rates are units of the base currency per unit of each source currency and are
+// used by the static source; the http source calls RatesURL (with {from}, {to} and
+// {date} placeholders) and the bigquery source reads RatesDataset.RatesTable.
+type CurrencyConfig struct {
+	Base   string             `json:"base"`
+	Rates  map[string]float64 `json:"rates"`
+	Source string             `json:"source"`
+
+	RatesURL     string `json:"rates_url,omitempty"`
+	RatesDataset string `json:"rates_dataset,omitempty"`
+	RatesTable   string `json:"rates_table,omitempty"`
+}
+
+// Tolerance modes for comparing actuals to a forecast
+const (
+	ToleranceAbsolute   = "absolute"
+	TolerancePercentage = "percentage"
+)
+
+// ForecastConfig configures comparison of actuals to a user-provided forecast CSV.
+// Variance is reported over every forecast day; only the most recent LookbackDays
+// days are checked against the tolerance.
+type ForecastConfig struct {
+	Path          string  `json:"path"`
+	ToleranceMode string  `json:"tolerance_mode"`
+	Tolerance     float64 `json:"tolerance"`
+	LookbackDays  int     `json:"lookback_days"`
+}
+
+// Band is an expected daily cost range; a zero Max leaves the band open-ended
+type Band struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ServiceBandsConfig maps services to the daily cost range they are expected to
+// stay within. The most recent LookbackDays days are checked on each run.
+type ServiceBandsConfig struct {
+	LookbackDays int             `json:"lookback_days"`
+	Services     map[string]Band `json:"services"`
+}
+
+// BudgetsConfig configures budget alerts. Budgets are read from Path, a JSON array
+// of {project_id, amount, currency, period}; each of Percentages alerts once per
+// project and month when month-to-date spend crosses it.
+type BudgetsConfig struct {
+	Path        string    `json:"path"`
+	Percentages []float64 `json:"percentages"`
+}
+
+// AttributionConfig controls root-cause drill-down for total-level anomalies.
+// Dimensions are drilled in order (service, project, sku, region, cost_type),
+// keeping the TopK contributors by absolute change at each level.
+type AttributionConfig struct {
+	Dimensions []string `json:"dimensions"`
+	TopK       int      `json:"top_k"`
+}
+
+// FeedbackConfig configures the ack/snooze webhook server. The Slack signing
+// secret is read from SLACK_SIGNING_SECRET and the label endpoint's bearer token
+// from FEEDBACK_API_TOKEN. Detector precision is computed over PrecisionWindowDays.
+type FeedbackConfig struct {
+	ListenAddr          string `json:"listen_addr"`
+	PrecisionWindowDays int    `json:"precision_window_days"`
+}
+
+// ServeConfig configures serve mode, which serves the last run's reports over HTTP
+type ServeConfig struct {
+	ListenAddr string `json:"listen_addr"`
+}
+
+// UnitCostConfig configures unit cost (cost per usage unit) trending per SKU.
+// WorseningThreshold is the percentage rise over the window that flags a SKU.
+type UnitCostConfig struct {
+	Enabled            bool    `json:"enabled"`
+	MinDays            int     `json:"min_days"`
+	WorseningThreshold float64 `json:"worsening_threshold"`
+}
+
+// Actions taken when a data quality check fails
+const (
+	DataQualityWarn  = "warn"
+	DataQualityAbort = "abort"
+)
+
+// Completeness criteria deciding whether a day's billing data has finished loading
+const (
+	CompletenessNone    = "none"
+	CompletenessNextDay = "next_day"
+	CompletenessSettled = "settled"
+)
+
+// DataQualityConfig configures the pre-flight data quality checks. The latest
+// day's row count and cost are compared to the average of the preceding
+// RollingDays days and must reach the given ratios.
+//
+// Completeness defers alerting on days whose data may still be loading: next_day
+// treats a day as complete once the following day has data, settled once it is
+// at least SettleDays old; none evaluates every day.
+type DataQualityConfig struct {
+	Enabled        bool    `json:"enabled"`
+	OnFailure      string  `json:"on_failure"`
+	MaxLagDays     int     `json:"max_lag_days"`
+	RollingDays    int     `json:"rolling_days"`
+	MinVolumeRatio float64 `json:"min_volume_ratio"`
+	MinCostRatio   float64 `json:"min_cost_ratio"`
+	Completeness   string  `json:"completeness"`
+	SettleDays     int     `json:"settle_days"`
+}
+
+// RetryConfig configures the worker pool shared by all notifiers for retrying failed sends
+type RetryConfig struct {
+	MaxAttempts int `json:"max_attempts"`
+	BaseDelayMs int `json:"base_delay_ms"`
+	MaxDelayMs  int `json:"max_delay_ms"`
+	Concurrency int `json:"concurrency"`
+}
+
+// BigQueryRetryConfig configures retries of BigQuery queries failing with
+// transient errors (rate limits, backend errors)
+type BigQueryRetryConfig struct {
+	MaxRetries  int `json:"max_retries"`
+	BaseDelayMs int `json:"base_delay_ms"`
+}
+
+// Negative cost handling modes
+const (
+	NegativeCostsNet      = "net"
+	NegativeCostsExclude  = "exclude"
+	NegativeCostsSeparate = "separate"
+)
+
+// Daily baseline modes
+const (
+	DailyBaselineRaw     = "raw"
+	DailyBaselineWeekday = "weekday"
+)
+
+// Processing modes for dimensional billing data
+const (
+	ProcessingMemory  = "memory"
+	ProcessingChunked = "chunked"
+)
+
+// ProcessingConfig selects how dimensional rows are aggregated. Chunked mode
+// streams rows into per-key aggregates in batches of BatchSize, spilling them
+// to SpillDir across Partitions files when set, so memory stays bounded.
+type ProcessingConfig struct {
+	Mode       string `json:"mode"`
+	BatchSize  int    `json:"batch_size"`
+	SpillDir   string `json:"spill_dir"`
+	Partitions int    `json:"partitions"`
+}
+
+// SelfCostConfig configures the report of the monitor's own BigQuery query cost,
+// estimated from bytes billed at the on-demand price per TiB
+type SelfCostConfig struct {
+	Enabled     bool    `json:"enabled"`
+	PricePerTiB float64 `json:"price_per_tib"`
+}
+
+// QueryCacheConfig caches materialized billing rows for TTLMinutes so a run does
+// not scan the same data twice; 0 disables it. With Dir set, entries persist on
+// disk and runs within the TTL skip BigQuery.
+type QueryCacheConfig struct {
+	TTLMinutes int    `json:"ttl_minutes"`
+	Dir        string `json:"dir"`
+}
+
+// Detection modes across vendors
+const (
+	DetectionPerVendor = "per_vendor"
+	DetectionMerged    = "merged"
+)
+
+// VendorsConfig configures how multiple cloud vendors are combined. DetectionMode
+// runs detectors on each vendor's series separately or on the merged series.
+type VendorsConfig struct {
+	DetectionMode string `json:"detection_mode"`
+
+	// AWS adds AWS Cost Explorer data alongside GCP
+	AWS VendorConfig `json:"aws"`
+
+	// Azure adds Azure Cost Management data for Scope (such as
+	// /subscriptions/<id>) alongside GCP
+	Azure VendorConfig `json:"azure"`
+}
+
+// VendorConfig enables an additional cloud vendor's cost data
+type VendorConfig struct {
+	Enabled bool   `json:"enabled"`
+	Scope   string `json:"scope,omitempty"`
+}
+
+// IncrementalConfig configures --since-last-run mode. OverlapDays re-scans that
+// many days before the watermark so late-arriving charges are not missed.
+type IncrementalConfig struct {
+	OverlapDays int `json:"overlap_days"`
+}
+
+// OutputConfig configures the files written alongside the default outputs
+type OutputConfig struct {
+	// SplitAnomaliesBySeverity also writes anomalies_<severity>.json per severity present
+	SplitAnomaliesBySeverity bool `json:"split_anomalies_by_severity"`
+
+	// GzipComposite writes the composite data as composite_data.json.gz at
+	// GzipLevel (1 fastest to 9 smallest; 0 uses the gzip default)
+	GzipComposite bool `json:"gzip_composite"`
+	GzipLevel     int  `json:"gzip_level"`
+
+	// CSV also writes the composite data, daily totals, MTD data and anomalies as CSV
+	CSV bool `json:"csv"`
+
+	WideCSV WideCSVConfig `json:"wide_csv"`
+
+	// MetricsTextfile writes the run's Prometheus metrics to this path, for the
+	// node exporter's textfile collector; empty disables it
+	MetricsTextfile string `json:"metrics_textfile"`
+
+	// HTMLReport writes a self-contained HTML dashboard of the run to this path;
+	// empty disables it
+	HTMLReport string `json:"html_report"`
+}
+
+// WideCSVConfig configures the date x dimension CSV for pivot tables. Dimension
+// is one of service, project, sku, region or cost_type; columns beyond
+// MaxColumns are summed into "Other".
+type WideCSVConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Dimension  string `json:"dimension"`
+	MaxColumns int    `json:"max_columns"`
+}
+
+// BreakerConfig configures the per-notifier circuit breaker: it opens after
+// FailureThreshold consecutive failed runs and half-opens after CooldownMinutes
+type BreakerConfig struct {
+	FailureThreshold int `json:"failure_threshold"`
+	CooldownMinutes  int `json:"cooldown_minutes"`
+}
+
+// AnomalyTableConfig configures writing anomalies back to BigQuery
+type AnomalyTableConfig struct {
+	Enabled bool   `json:"enabled"`
+	Dataset string `json:"dataset"`
+	Table   string `json:"table"`
+}
+
+// NotifierConfig configures a single notification channel. MinSeverity and
+// MaxSeverity restrict the channel to a severity band, compared using the
+// canonical order LOW < MEDIUM < HIGH < CRITICAL.
+type NotifierConfig struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	MinSeverity string `json:"min_severity"`
+	MaxSeverity string `json:"max_severity"`
+
+	URL    string `json:"url,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Source string `json:"source,omitempty"`
+
+	// APIKeyEnv names the environment variable holding the notifier's API key
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+
+	// Template, ContentType and Headers shape webhook requests; the body template
+	// receives .Anomalies, .Count and .Time. SigningSecretEnv names the environment
+	// variable holding the HMAC-SHA256 signing secret.
+	Template         string            `json:"template,omitempty"`
+	ContentType      string            `json:"content_type,omitempty"`
+	Headers          map[string]string `json:"headers,omitempty"`
+	SigningSecretEnv string            `json:"signing_secret_env,omitempty"`
+
+	// RoutingKey is the PagerDuty Events API v2 integration key
+	RoutingKey string `json:"routing_key,omitempty"`
+
+	// SpreadsheetID and Sheet select the Google Sheet tab anomalies are appended to
+	SpreadsheetID string `json:"spreadsheet_id,omitempty"`
+	Sheet         string `json:"sheet,omitempty"`
+}
+
+// Default returns the configuration used when no config file is present
+func Default() *Config {
+	return &Config{
+		Timezone:  "UTC",
+		Summation: models.SummationFloat,
+		StatePath: "data/monitor_state.json",
+		EventLog:  "data/event_log.jsonl",
+
+		AllocationRulesPath: DefaultAllocationRulesPath,
+
+		NegativeCosts:       NegativeCostsNet,
+		DailyBaseline:       DailyBaselineRaw,
+		SeverityBands:       models.DefaultSeverityBands(),
+		ServiceThresholds:   detectors.DefaultServiceThresholds(),
+		MTDMinDaysElapsed:   5,
+		DropPercentage:      50,
+		MinHistoryDays:      90,
+		MinHistoryFloorDays: 30,
+		SummaryPercentiles:  []float64{0.5, 0.9, 0.95, 0.99},
+
+		NewSKU: NewSKUConfig{
+			Enabled: true,
+			MinCost: 10.0,
+		},
+		Onboarding: OnboardingConfig{
+			GraceDays: 14,
+			Mode:      GraceModeDowngrade,
+		},
+		Retry: RetryConfig{
+			MaxAttempts: 3,
+			BaseDelayMs: 1000,
+			MaxDelayMs:  30000,
+			Concurrency: 4,
+		},
+		BigQueryRetry: BigQueryRetryConfig{
+			MaxRetries:  3,
+			BaseDelayMs: 1000,
+		},
+		ServiceBands: ServiceBandsConfig{
+			LookbackDays: 1,
+		},
+		Budgets: BudgetsConfig{
+			Percentages: []float64{50, 80, 100},
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold: 3,
+			CooldownMinutes:  60,
+		},
+		Feedback: FeedbackConfig{
+			ListenAddr:          ":8085",
+			PrecisionWindowDays: 30,
+		},
+		Serve: ServeConfig{
+			ListenAddr: ":8080",
+		},
+		Attribution: AttributionConfig{
+			Dimensions: []string{"service", "project", "sku"},
+			TopK:       5,
+		},
+		UnitCost: UnitCostConfig{
+			Enabled:            true,
+			MinDays:            7,
+			WorseningThreshold: 10.0,
+		},
+		DataQuality: DataQualityConfig{
+			Enabled:        true,
+			OnFailure:      DataQualityWarn,
+			MaxLagDays:     2,
+			RollingDays:    7,
+			MinVolumeRatio: 0.5,
+			MinCostRatio:   0.5,
+			Completeness:   CompletenessNone,
+			SettleDays:     1,
+		},
+		Currency: CurrencyConfig{
+			Source: RateSourceStatic,
+		},
+		Forecast: ForecastConfig{
+			ToleranceMode: TolerancePercentage,
+			Tolerance:     15.0,
+			LookbackDays:  1,
+		},
+		Output: OutputConfig{
+			WideCSV: WideCSVConfig{
+				Dimension:  "service",
+				MaxColumns: 20,
+			},
+		},
+		Incremental: IncrementalConfig{
+			OverlapDays: 3,
+		},
+		Vendors: VendorsConfig{
+			DetectionMode: DetectionPerVendor,
+		},
+		Processing: ProcessingConfig{
+			Mode:       ProcessingMemory,
+			BatchSize:  100000,
+			Partitions: 16,
+		},
+		SelfCost: SelfCostConfig{
+			Enabled:     true,
+			PricePerTiB: 6.25,
+		},
+		QueryCache: QueryCacheConfig{
+			TTLMinutes: 60,
+		},
+	}
+}
+
+// Load loads configuration from a JSON file, falling back to defaults if it does not exist
+func Load(filename string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Re-runs of the same logical run can pin the run ID so inserts deduplicate
+	if runID := os.Getenv("COST_MONITOR_RUN_ID"); runID != "" {
+		cfg.RunID = runID
+	}
+	if cfg.RunID == "" {
+		cfg.RunID = time.Now().UTC().Format("20060102T150405Z")
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration for errors that should stop the monitor at startup
+func (c *Config) Validate() error {
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %v", c.Timezone, err)
+	}
+	switch c.Summation {
+	case models.SummationFloat, models.SummationKahan, models.SummationMinorUnits:
+	default:
+		return fmt.Errorf("unknown summation %q", c.Summation)
+	}
+	switch c.Currency.Source {
+	case RateSourceStatic:
+	case RateSourceHTTP:
+		if c.Currency.RatesURL == "" {
+			return fmt.Errorf("currency.rates_url is required for the http rate source")
+		}
+	case RateSourceBigQuery:
+		if c.Currency.RatesDataset == "" || c.Currency.RatesTable == "" {
+			return fmt.Errorf("currency.rates_dataset and currency.rates_table are required for the bigquery rate source")
+		}
+	default:
+		return fmt.Errorf("unknown currency source %q", c.Currency.Source)
+	}
+	switch c.NegativeCosts {
+	case NegativeCostsNet, NegativeCostsExclude, NegativeCostsSeparate:
+	default:
+		return fmt.Errorf("unknown negative_costs mode %q", c.NegativeCosts)
+	}
+	if c.QueryCache.TTLMinutes < 0 {
+		return fmt.Errorf("query_cache.ttl_minutes must not be negative")
+	}
+	if c.DailyBaseline != DailyBaselineRaw && c.DailyBaseline != DailyBaselineWeekday {
+		return fmt.Errorf("unknown daily_baseline mode %q", c.DailyBaseline)
+	}
+	if err := c.SeverityBands.Validate(); err != nil {
+		return err
+	}
+	if err := c.ServiceThresholds.Validate(); err != nil {
+		return err
+	}
+	switch c.DataQuality.Completeness {
+	case CompletenessNone, CompletenessNextDay, CompletenessSettled:
+	default:
+		return fmt.Errorf("unknown data_quality.completeness %q", c.DataQuality.Completeness)
+	}
+	if c.MinHistoryFloorDays < 1 || c.MinHistoryFloorDays > c.MinHistoryDays {
+		return fmt.Errorf("min_history_floor_days must be between 1 and min_history_days (%d)", c.MinHistoryDays)
+	}
+	if c.RunTimeoutMinutes < 0 {
+		return fmt.Errorf("run_timeout_minutes must not be negative")
+	}
+	if c.BigQueryRetry.MaxRetries < 0 || c.BigQueryRetry.BaseDelayMs < 0 {
+		return fmt.Errorf("bigquery_retry max_retries and base_delay_ms must not be negative")
+	}
+	if c.DropPercentage <= 0 || c.DropPercentage > 100 {
+		return fmt.Errorf("drop_percentage must be in (0, 100]")
+	}
+	if c.MTDMinDaysElapsed < 0 {
+		return fmt.Errorf("mtd_min_days_elapsed must not be negative")
+	}
+	for _, p := range c.SummaryPercentiles {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("summary_percentiles: %g is not between 0 and 1", p)
+		}
+	}
+	if c.SelfCost.PricePerTiB < 0 {
+		return fmt.Errorf("self_cost.price_per_tib must not be negative")
+	}
+	if c.Onboarding.Mode != GraceModeSuppress && c.Onboarding.Mode != GraceModeDowngrade {
+		return fmt.Errorf("onboarding: unknown mode %q", c.Onboarding.Mode)
+	}
+	for project, date := range c.Onboarding.Projects {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("onboarding: invalid date %q for project %s", date, project)
+		}
+	}
+	if c.DataQuality.OnFailure != DataQualityWarn && c.DataQuality.OnFailure != DataQualityAbort {
+		return fmt.Errorf("data_quality: unknown on_failure %q", c.DataQuality.OnFailure)
+	}
+	if c.Forecast.ToleranceMode != ToleranceAbsolute && c.Forecast.ToleranceMode != TolerancePercentage {
+		return fmt.Errorf("forecast: unknown tolerance_mode %q", c.Forecast.ToleranceMode)
+	}
+	if c.Forecast.Tolerance < 0 {
+		return fmt.Errorf("forecast: tolerance must not be negative")
+	}
+	if c.Forecast.Path != "" && c.Forecast.LookbackDays < 1 {
+		return fmt.Errorf("forecast: lookback_days must be at least 1")
+	}
+	if c.Vendors.DetectionMode != DetectionPerVendor && c.Vendors.DetectionMode != DetectionMerged {
+		return fmt.Errorf("vendors: unknown detection_mode %q", c.Vendors.DetectionMode)
+	}
+	if c.Vendors.Azure.Enabled && c.Vendors.Azure.Scope == "" {
+		return fmt.Errorf("vendors: azure needs a scope")
+	}
+	if c.Processing.Mode != ProcessingMemory && c.Processing.Mode != ProcessingChunked {
+		return fmt.Errorf("processing: unknown mode %q", c.Processing.Mode)
+	}
+	if c.Output.GzipLevel < 0 || c.Output.GzipLevel > 9 {
+		return fmt.Errorf("output: gzip_level must be between 0 and 9")
+	}
+	if !validDimension(c.Output.WideCSV.Dimension) {
+		return fmt.Errorf("output: unknown wide_csv dimension %q", c.Output.WideCSV.Dimension)
+	}
+	for _, dimension := range c.Attribution.Dimensions {
+		if !validDimension(dimension) {
+			return fmt.Errorf("attribution: unknown dimension %q", dimension)
+		}
+	}
+	if len(c.ServiceBands.Services) > 0 && c.ServiceBands.LookbackDays < 1 {
+		return fmt.Errorf("service_bands: lookback_days must be at least 1")
+	}
+	for service, band := range c.ServiceBands.Services {
+		if band.Min < 0 || (band.Max > 0 && band.Min > band.Max) {
+			return fmt.Errorf("service_bands: invalid band [%.2f, %.2f] for %s", band.Min, band.Max, service)
+		}
+	}
+	for _, percentage := range c.Budgets.Percentages {
+		if percentage <= 0 {
+			return fmt.Errorf("budgets: percentages must be positive")
+		}
+	}
+	for _, template := range c.DashboardLinks {
+		if err := template.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Incremental.OverlapDays < 0 {
+		return fmt.Errorf("incremental: overlap_days must not be negative")
+	}
+
+	for _, notifier := range c.Notifiers {
+		minRank, maxRank := 0, models.SeverityRank(models.SeverityCritical)
+		if notifier.MinSeverity != "" {
+			minRank = models.SeverityRank(notifier.MinSeverity)
+			if minRank < 0 {
+				return fmt.Errorf("notifier %s: unknown min_severity %q", notifier.Name, notifier.MinSeverity)
+			}
+		}
+		if notifier.MaxSeverity != "" {
+			maxRank = models.SeverityRank(notifier.MaxSeverity)
+			if maxRank < 0 {
+				return fmt.Errorf("notifier %s: unknown max_severity %q", notifier.Name, notifier.MaxSeverity)
+			}
+		}
+		if minRank > maxRank {
+			return fmt.Errorf("notifier %s: min_severity %s is above max_severity %s", notifier.Name, notifier.MinSeverity, notifier.MaxSeverity)
+		}
+	}
+	return nil
+}
+
+// validDimension reports whether a cost dimension can be pivoted or drilled into
+func validDimension(dimension string) bool {
+	switch dimension {
+	case "service", "project", "sku", "region", "cost_type":
+		return true
+	}
+	return false
+}
+
+// TestEnabled reports whether the named test or detector is enabled
+func (c *Config) TestEnabled(name string) bool {
+	settings, configured := c.Detectors[name]
+	return !configured || settings.Enabled
+}
+
+// SetDefaultEnabled enables or disables a detector unless the configuration already lists it
+func (c *Config) SetDefaultEnabled(detector string, enabled bool) {
+	if c.Detectors == nil {
+		c.Detectors = make(map[string]detectors.Settings)
+	}
+	if _, configured := c.Detectors[detector]; !configured {
+		c.Detectors[detector] = detectors.Settings{Enabled: enabled}
+	}
+}
+
+// SetDefaultParam sets a detector parameter unless the configuration already sets it
+func (c *Config) SetDefaultParam(detector, name string, value float64) {
+	if c.Detectors == nil {
+		c.Detectors = make(map[string]detectors.Settings)
+	}
+	settings, configured := c.Detectors[detector]
+	if !configured {
+		settings.Enabled = true
+	}
+	if _, exists := settings.Params[name]; exists {
+		return
+	}
+	if settings.Params == nil {
+		settings.Params = make(detectors.Params)
+	}
+	settings.Params[name] = value
+	c.Detectors[detector] = settings
+}
+
+// HasSKU reports whether the SKU is allowlisted
+func (a Allowlist) HasSKU(sku string) bool {
+	for _, allowed := range a.SKUs {
+		if allowed == sku {
+			return true
+		}
+	}
+	return false
+}
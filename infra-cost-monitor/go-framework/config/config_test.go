@@ -0,0 +1,140 @@
+package config
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/links"
+	"strings"
+	"testing"
+)
+
+func TestValidateNotifierSeverityBands(t *testing.T) {
+	tests := []struct {
+		name    string
+		min     string
+		max     string
+		wantErr string
+	}{
+		{"no band", "", "", ""},
+		{"min equals max", "HIGH", "HIGH", ""},
+		{"lowercase band", "medium", "critical", ""},
+		{"max only", "", "LOW", ""},
+		{"inverted band", "CRITICAL", "LOW", "min_severity CRITICAL is above max_severity LOW"},
+		{"unknown min", "URGENT", "", "unknown min_severity"},
+		{"unknown max", "LOW", "SEVERE", "unknown max_severity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Notifiers = []NotifierConfig{{Name: "oncall", Type: "slack", MinSeverity: tt.min, MaxSeverity: tt.max}}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "oncall") {
+				t.Errorf("Validate() = %v, want an error for notifier oncall containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsInvalidDashboardLinks(t *testing.T) {
+	cfg := Default()
+	cfg.DashboardLinks = []links.Template{{Name: "grafana", URL: "https://grafana.example.com/?region={region}"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unknown placeholder") {
+		t.Errorf("Validate() = %v, want the unknown placeholder rejected", err)
+	}
+
+	cfg.DashboardLinks[0].URL = "https://grafana.example.com/?service={service}"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want a valid template accepted", err)
+	}
+}
+
+func TestValidateAttributionDimensions(t *testing.T) {
+	cfg := Default()
+	cfg.Attribution.Dimensions = []string{"service", "project", "sku"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want service, project and sku accepted", err)
+	}
+
+	cfg.Attribution.Dimensions = []string{"service", "team"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `unknown dimension "team"`) {
+		t.Errorf("Validate() = %v, want the unknown dimension rejected", err)
+	}
+}
+
+func TestValidateCurrencyRateSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency CurrencyConfig
+		want     string
+	}{
+		{"static", CurrencyConfig{Source: RateSourceStatic}, ""},
+		{"http", CurrencyConfig{Source: RateSourceHTTP, RatesURL: "https://rates.example.com/{from}/{to}/{date}"}, ""},
+		{"http without a URL", CurrencyConfig{Source: RateSourceHTTP}, "rates_url is required"},
+		{"bigquery", CurrencyConfig{Source: RateSourceBigQuery, RatesDataset: "finance", RatesTable: "fx"}, ""},
+		{"bigquery without a table", CurrencyConfig{Source: RateSourceBigQuery, RatesDataset: "finance"}, "rates_table are required"},
+		{"unknown source", CurrencyConfig{Source: "ecb"}, `unknown currency source "ecb"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Currency = tt.currency
+			err := cfg.Validate()
+			if tt.want == "" && err != nil {
+				t.Errorf("Validate() = %v, want no error", err)
+			}
+			if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsNegativeMTDMinDaysElapsed(t *testing.T) {
+	cfg := Default()
+	cfg.MTDMinDaysElapsed = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "mtd_min_days_elapsed") {
+		t.Errorf("Validate() = %v, want the negative minimum rejected", err)
+	}
+}
+
+func TestSetDefaultParamKeepsConfiguredValues(t *testing.T) {
+	cfg := Default()
+	cfg.Detectors = map[string]detectors.Settings{
+		"monthly_spike": {Enabled: false, Params: detectors.Params{"min_days_elapsed": 10}},
+	}
+	cfg.SetDefaultParam("monthly_spike", "min_days_elapsed", 5)
+	cfg.SetDefaultParam("monthly_spike", "percentage", 30)
+	cfg.SetDefaultParam("daily_spike", "percentage", 40)
+
+	monthly := cfg.Detectors["monthly_spike"]
+	if monthly.Enabled || monthly.Params["min_days_elapsed"] != 10 || monthly.Params["percentage"] != 30 {
+		t.Errorf("monthly_spike = %+v, want the configured settings kept and the missing param defaulted", monthly)
+	}
+	daily := cfg.Detectors["daily_spike"]
+	if !daily.Enabled || daily.Params["percentage"] != 40 {
+		t.Errorf("daily_spike = %+v, want an unconfigured detector enabled with the default", daily)
+	}
+}
+
+func TestValidateDataQualityCompleteness(t *testing.T) {
+	for _, completeness := range []string{CompletenessNone, CompletenessNextDay, CompletenessSettled} {
+		cfg := Default()
+		cfg.DataQuality.Completeness = completeness
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with %s = %v, want no error", completeness, err)
+		}
+	}
+
+	cfg := Default()
+	cfg.DataQuality.Completeness = "eventually"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `unknown data_quality.completeness "eventually"`) {
+		t.Errorf("Validate() = %v, want the unknown criterion rejected", err)
+	}
+}
@@ -1,42 +1,51 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"flag"
 	"log"
 
 	"infra-cost-monitor/go-framework/adapters/bigquery"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
 	"infra-cost-monitor/go-framework/vendors/gcp/monitors"
 )
 
-// Demo function to test BigQuery connection and basic functionality
-func demo() {
+// runDemo tests the BigQuery connection and each monitor, returning the process exit code
+func runDemo(args []string) int {
+	flags := flag.NewFlagSet("demo", flag.ExitOnError)
+	days := flags.Int("days", 90, "days of daily and dimensional history to fetch")
+	flags.Parse(args)
+
 	log.Println("🧪 Running Go Framework Demo")
 	log.Println("=============================")
+	ctx := context.Background()
 
 	// Test BigQuery connection
 	log.Println("1. Testing BigQuery connection...")
 	client, err := bigquery.NewClient()
 	if err != nil {
 		log.Printf("❌ BigQuery connection failed: %v", err)
-		return
+		return 1
 	}
 	defer client.Close()
 	log.Println("✅ BigQuery connection successful")
 
-	// Test daily monitor
-	log.Println("2. Testing daily monitor...")
-	dailyMonitor := monitors.NewDailyMonitor(client)
-	dailyCosts, err := dailyMonitor.GetDailyCosts()
+	mtdMonitor := monitors.NewMTDMonitor(client)
+	dimensionalMonitor := monitors.NewDimensionalMonitor(client)
+	costProvider := monitors.NewGCPProvider(client, mtdMonitor, dimensionalMonitor)
+
+	// Test daily costs
+	log.Println("2. Testing daily costs...")
+	dailyCosts, err := costProvider.DailyCosts(ctx, *days)
 	if err != nil {
-		log.Printf("❌ Daily monitor failed: %v", err)
+		log.Printf("❌ Daily costs failed: %v", err)
 	} else {
-		log.Printf("✅ Daily monitor successful - %d records", len(dailyCosts))
+		log.Printf("✅ Daily costs successful - %d records", len(dailyCosts))
 	}
 
 	// Test MTD monitor
 	log.Println("3. Testing MTD monitor...")
-	mtdMonitor := monitors.NewMTDMonitor(client)
-	mtdCosts, err := mtdMonitor.GetMTDCosts()
+	mtdCosts, err := costProvider.MTDCosts(ctx)
 	if err != nil {
 		log.Printf("❌ MTD monitor failed: %v", err)
 	} else {
@@ -45,17 +54,19 @@ func demo() {
 
 	// Test dimensional monitor
 	log.Println("4. Testing dimensional monitor...")
-	dimensionalMonitor := monitors.NewDimensionalMonitor(client)
-	dimensionalCosts, err := dimensionalMonitor.GetDimensionalCosts()
+	dimensionalCosts, err := costProvider.DimensionalCosts(ctx, *days)
 	if err != nil {
 		log.Printf("❌ Dimensional monitor failed: %v", err)
 	} else {
 		log.Printf("✅ Dimensional monitor successful - %d records", len(dimensionalCosts))
 	}
 
+	// Test daily monitor on what was fetched
+	log.Println("5. Testing daily monitor...")
+	anomalies := models.NewAnomalyCollection()
+	monitors.NewDailyMonitor(models.NewCostDataProcessor(dailyCosts, dimensionalCosts)).RunDailyTests(anomalies)
+	log.Printf("✅ Daily monitor successful - %d anomalies", anomalies.Len())
+
 	log.Println("🎉 Demo completed successfully!")
+	return 0
 }
-
-func main() {
-	demo()
-} 
\ No newline at end of file
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+)
+
+// runDiff compares the composite data of two saved runs, returning the process exit code
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	output := flags.String("output", "", "write the diff as JSON to this file instead of printing it")
+	top := flags.Int("top", 20, "number of entries to print, largest change first")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: diff [flags] <previous composite_data.json> <current composite_data.json>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	jsonOutput := utils.NewJSONOutput()
+	prev, err := jsonOutput.LoadCompositeData(flags.Arg(0))
+	if err != nil {
+		log.Printf("❌ Failed to load %s: %v", flags.Arg(0), err)
+		return 1
+	}
+	curr, err := jsonOutput.LoadCompositeData(flags.Arg(1))
+	if err != nil {
+		log.Printf("❌ Failed to load %s: %v", flags.Arg(1), err)
+		return 1
+	}
+
+	diff := utils.Diff(prev, curr)
+	if *output != "" {
+		if err := jsonOutput.SaveCostDiff(diff, *output); err != nil {
+			log.Printf("❌ Failed to write cost diff: %v", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Printf("total %.2f -> %.2f (%+.2f): %d added, %d removed, %d changed, %d unchanged\n",
+		diff.PreviousTotal, diff.CurrentTotal, diff.Delta, diff.Added, diff.Removed, diff.Changed, diff.Unchanged)
+	for i, entry := range diff.Entries {
+		if i == *top {
+			fmt.Printf("  ... %d more\n", len(diff.Entries)-*top)
+			break
+		}
+		fmt.Printf("  %-8s %12.2f -> %12.2f  %+12.2f  %s\n", entry.Status, entry.PreviousCost, entry.CurrentCost, entry.Delta, entry.CompositeKey)
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+)
+
+func TestDiffSubcommandWritesJSON(t *testing.T) {
+	dir := t.TempDir()
+	jsonOutput := utils.NewJSONOutput()
+	prevPath, currPath := filepath.Join(dir, "prev.json"), filepath.Join(dir, "curr.json")
+	if err := jsonOutput.SaveCompositeData([]models.CostData{{Date: "2024-05-09", Service: "Compute", Cost: 10}}, prevPath); err != nil {
+		t.Fatalf("SaveCompositeData: %v", err)
+	}
+	if err := jsonOutput.SaveCompositeData([]models.CostData{{Date: "2024-05-10", Service: "Storage", Cost: 4}}, currPath); err != nil {
+		t.Fatalf("SaveCompositeData: %v", err)
+	}
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	outPath := filepath.Join(dir, "diff.json")
+	if code := runDiff([]string{"--output", outPath, prevPath, currPath}); code != exitOK {
+		t.Fatalf("exit code = %d, want %d", code, exitOK)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var diff models.CostDiff
+	if err := json.Unmarshal(data, &diff); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if diff.Added != 1 || diff.Removed != 1 || diff.Delta != -6 {
+		t.Errorf("diff = %+v, want Storage added, Compute removed and a delta of -6", diff)
+	}
+
+	if code := runDiff([]string{prevPath, filepath.Join(dir, "missing.json")}); code != exitFatal {
+		t.Errorf("exit code with a missing file = %d, want %d", code, exitFatal)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"infra-cost-monitor/go-framework/adapters/feedback"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/state"
+)
+
+// runFeedbackServer serves the Slack ack/snooze webhook, the true/false positive
+// label endpoint and per-detector precision metrics
+func runFeedbackServer(cfg *config.Config) error {
+	store, err := state.Load(cfg.StatePath)
+	if err != nil {
+		return fatalf("failed to load state store: %v", err)
+	}
+
+	addr := cfg.Feedback.ListenAddr
+	mux := http.NewServeMux()
+	mux.Handle("/slack/actions", feedback.NewActionHandler(store, os.Getenv("SLACK_SIGNING_SECRET")))
+	mux.Handle("/feedback/labels", feedback.NewLabelHandler(store, os.Getenv("FEEDBACK_API_TOKEN")))
+	window := time.Duration(cfg.Feedback.PrecisionWindowDays) * 24 * time.Hour
+	mux.Handle("/metrics", feedback.NewMetricsHandler(store, window))
+
+	log.Printf("👂 Feedback server listening on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		return fatalf("feedback server failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output formats of a monitoring run
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// maxDays bounds the lookback window a run may fetch
+const maxDays = 3650
+
+// runOptions are the command-line options of a monitoring run
+type runOptions struct {
+	days         int
+	outputDir    string
+	percentile   float64
+	currency     string
+	mock         bool
+	sinceLastRun bool
+	format       string
+}
+
+// parseRunFlags parses and validates the flags of a monitoring run
+func parseRunFlags(args []string) (*runOptions, error) {
+	opts := &runOptions{}
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	flags.IntVar(&opts.days, "days", 90, fmt.Sprintf("days of daily and dimensional history to fetch (1-%d)", maxDays))
+	flags.StringVar(&opts.outputDir, "output-dir", "mock-data/output", "directory reports are written to, created if missing")
+	flags.Float64Var(&opts.percentile, "percentile", 0.99, "percentile (0-1 exclusive) the daily percentile tests compare against")
+	flags.StringVar(&opts.currency, "currency", "", "base currency (ISO 4217) to convert and report costs in; overrides currency.base")
+	flags.BoolVar(&opts.mock, "mock", false, "read cost data from mock-data/input fixtures instead of BigQuery")
+	flags.BoolVar(&opts.sinceLastRun, "since-last-run", false, "only fetch usage since the last successful run's watermark")
+	flags.StringVar(&opts.format, "format", formatJSON, "report format: json, or csv to also write CSV reports")
+	flags.Parse(args)
+
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	opts.currency = strings.ToUpper(opts.currency)
+	return opts, nil
+}
+
+// validate checks the options are within range
+func (o *runOptions) validate() error {
+	if o.days < 1 || o.days > maxDays {
+		return fmt.Errorf("--days must be between 1 and %d, got %d", maxDays, o.days)
+	}
+	if o.outputDir == "" {
+		return fmt.Errorf("--output-dir must not be empty")
+	}
+	if o.percentile <= 0 || o.percentile >= 1 {
+		return fmt.Errorf("--percentile must be between 0 and 1 exclusive, got %g", o.percentile)
+	}
+	if o.currency != "" && len(o.currency) != 3 {
+		return fmt.Errorf("--currency must be a 3-letter ISO 4217 code, got %q", o.currency)
+	}
+	if o.format != formatJSON && o.format != formatCSV {
+		return fmt.Errorf("--format must be %s or %s, got %q", formatJSON, formatCSV, o.format)
+	}
+	return nil
+}
+
+// ensureOutputDir creates the output directory if it does not exist
+func (o *runOptions) ensureOutputDir() error {
+	if err := os.MkdirAll(o.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %v", o.outputDir, err)
+	}
+	return nil
+}
+
+// outputPath returns the path of a report in the output directory
+func (o *runOptions) outputPath(name string) string {
+	return filepath.Join(o.outputDir, name)
+}
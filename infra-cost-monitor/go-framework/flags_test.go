@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRunFlags(t *testing.T) {
+	opts, err := parseRunFlags([]string{"--days", "30", "--output-dir", "reports", "--percentile", "0.95", "--currency", "usd", "--mock", "--format", "csv"})
+	if err != nil {
+		t.Fatalf("parseRunFlags: %v", err)
+	}
+	want := runOptions{days: 30, outputDir: "reports", percentile: 0.95, currency: "USD", mock: true, format: formatCSV}
+	if *opts != want {
+		t.Errorf("options = %+v, want %+v", *opts, want)
+	}
+	if got := opts.outputPath("summary.json"); got != filepath.Join("reports", "summary.json") {
+		t.Errorf("outputPath = %s, want reports/summary.json", got)
+	}
+}
+
+func TestParseRunFlagsDefaults(t *testing.T) {
+	opts, err := parseRunFlags(nil)
+	if err != nil {
+		t.Fatalf("parseRunFlags: %v", err)
+	}
+	want := runOptions{days: 90, outputDir: "mock-data/output", percentile: 0.99, format: formatJSON}
+	if *opts != want {
+		t.Errorf("options = %+v, want %+v", *opts, want)
+	}
+}
+
+func TestParseRunFlagsValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{"zero days", []string{"--days", "0"}, "--days must be between 1 and 3650"},
+		{"too many days", []string{"--days", "3651"}, "--days must be between 1 and 3650"},
+		{"empty output dir", []string{"--output-dir", ""}, "--output-dir must not be empty"},
+		{"zero percentile", []string{"--percentile", "0"}, "--percentile must be between 0 and 1"},
+		{"percentile of one", []string{"--percentile", "1"}, "--percentile must be between 0 and 1"},
+		{"percentage instead of fraction", []string{"--percentile", "99"}, "--percentile must be between 0 and 1"},
+		{"currency name", []string{"--currency", "dollar"}, "3-letter ISO 4217 code"},
+		{"unknown format", []string{"--format", "xml"}, "--format must be json or csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRunFlags(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestEnsureOutputDirCreatesMissingDirectories(t *testing.T) {
+	opts := &runOptions{outputDir: filepath.Join(t.TempDir(), "runs", "today")}
+	if err := opts.ensureOutputDir(); err != nil {
+		t.Fatalf("ensureOutputDir: %v", err)
+	}
+	if info, err := os.Stat(opts.outputDir); err != nil || !info.IsDir() {
+		t.Errorf("Stat(%s) = %v, %v, want a directory", opts.outputDir, info, err)
+	}
+
+	// An existing directory is left as it is
+	if err := opts.ensureOutputDir(); err != nil {
+		t.Errorf("ensureOutputDir on an existing directory: %v", err)
+	}
+}
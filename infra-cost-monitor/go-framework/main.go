@@ -1,78 +1,334 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"infra-cost-monitor/go-framework/adapters/bigquery"
+	"infra-cost-monitor/go-framework/adapters/eventlog"
+	"infra-cost-monitor/go-framework/adapters/feedback"
+	"infra-cost-monitor/go-framework/adapters/metrics"
+	"infra-cost-monitor/go-framework/adapters/notifiers"
+	"infra-cost-monitor/go-framework/adapters/reports"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/aws"
+	"infra-cost-monitor/go-framework/vendors/azure"
+	"infra-cost-monitor/go-framework/vendors/gcp/allocation"
+	"infra-cost-monitor/go-framework/vendors/gcp/currency"
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/links"
 	"infra-cost-monitor/go-framework/vendors/gcp/models"
 	"infra-cost-monitor/go-framework/vendors/gcp/monitors"
 	"infra-cost-monitor/go-framework/vendors/gcp/triggers"
 	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"infra-cost-monitor/go-framework/vendors/mock"
+	"infra-cost-monitor/go-framework/vendors/provider"
+
+	"golang.org/x/sync/errgroup"
 )
 
 func main() {
 	log.Println("🚀 Starting GCP Cost Monitor (Go Framework)")
 	log.Println("=============================================")
 
-	// Initialize BigQuery client
-	client, err := bigquery.NewClient()
+	// Validate mode checks configuration and connectivity, then exits
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
+
+	// Demo mode exercises the BigQuery connection and each monitor, then exits
+	if len(os.Args) > 1 && os.Args[1] == "demo" {
+		os.Exit(runDemo(os.Args[2:]))
+	}
+
+	// Trend mode analyzes saved run outputs without querying BigQuery
+	if len(os.Args) > 1 && os.Args[1] == "trend" {
+		os.Exit(runTrend(os.Args[2:]))
+	}
+
+	// Diff mode compares the composite data of two saved runs
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
+
+	if err := run(os.Args[1:]); err != nil {
+		log.Printf("❌ %v", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// run loads the configuration and runs the monitoring pipeline, or the serve and
+// feedback-server modes. A step that fails without invalidating the run is logged
+// and the run carries on; the failed steps are returned once it has finished.
+func run(args []string) error {
+	// Load configuration
+	cfg, err := config.Load(config.DefaultPath)
+	if err != nil {
+		return fatalf("failed to load configuration: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fatalf("invalid configuration: %v", err)
+	}
+	log.Printf("🆔 Run ID: %s", cfg.RunID)
+
+	report := models.RunReport{
+		RunID:     cfg.RunID,
+		StartedAt: time.Now().Format(time.RFC3339),
+	}
+	jsonOutput := utils.NewJSONOutput()
+
+	// Feedback server mode receives ack/snooze actions instead of running detection
+	if len(args) > 0 && args[0] == "feedback-server" {
+		return runFeedbackServer(cfg)
+	}
+
+	// Serve mode serves the reports a run wrote to --output-dir over HTTP instead
+	// of running detection
+	if len(args) > 0 && args[0] == "serve" {
+		opts, err := parseRunFlags(args[1:])
+		if err != nil {
+			return fatalf("invalid arguments: %v", err)
+		}
+		return runServe(cfg, opts.outputDir)
+	}
+
+	// Flags of a monitoring run; the subcommands above parse their own
+	opts, err := parseRunFlags(args)
 	if err != nil {
-		log.Fatalf("Failed to initialize BigQuery client: %v", err)
+		return fatalf("invalid arguments: %v", err)
+	}
+	if err := opts.ensureOutputDir(); err != nil {
+		return fatalf("%v", err)
+	}
+	failures := &runFailures{}
+	if opts.currency != "" {
+		cfg.Currency.Base = opts.currency
+	}
+	if opts.format == formatCSV {
+		cfg.Output.CSV = true
+	}
+
+	// Load persisted state (seen SKUs, acks and snoozes)
+	store, err := state.Load(cfg.StatePath)
+	if err != nil {
+		return fatalf("failed to load state store: %v", err)
+	}
+
+	// Open the append-only event log of detections and notifications
+	var events *eventlog.Log
+	if cfg.EventLog != "" {
+		events, err = eventlog.Open(cfg.EventLog, cfg.RunID)
+		if err != nil {
+			log.Printf("Warning: Event log disabled: %v", err)
+		} else {
+			defer events.Close()
+		}
+	}
+
+	// Cancel BigQuery operations on SIGINT/SIGTERM and once the run timeout elapses
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if cfg.RunTimeoutMinutes > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.RunTimeoutMinutes)*time.Minute)
+		defer cancel()
+	}
+
+	// Mock mode runs the whole pipeline offline against fixtures in mock-data/input
+	mockMode := opts.mock
+
+	// Initialize BigQuery client
+	var client *bigquery.Client
+	if mockMode {
+		log.Printf("🧪 Mock mode: reading cost data from %s instead of BigQuery", mock.DefaultDir)
+	} else {
+		client, err = bigquery.NewClientWithRetry(bigquery.RetryPolicy{
+			MaxRetries: cfg.BigQueryRetry.MaxRetries,
+			BaseDelay:  time.Duration(cfg.BigQueryRetry.BaseDelayMs) * time.Millisecond,
+		})
+		if err != nil {
+			return fatalf("failed to initialize BigQuery client: %v", err)
+		}
+		defer client.Close()
 	}
-	defer client.Close()
 
 	// Initialize monitors
-	dailyMonitor := monitors.NewDailyMonitor(client)
 	mtdMonitor := monitors.NewMTDMonitor(client)
+	mtdMonitor.SetContext(ctx)
+	if location, err := time.LoadLocation(cfg.Timezone); err == nil {
+		mtdMonitor.SetLocation(location)
+	}
 	dimensionalMonitor := monitors.NewDimensionalMonitor(client)
+	dimensionalMonitor.SetContext(ctx)
+
+	// Billing rows are materialized once over the widest window any monitor reads
+	// and shared by the monitors that scan them
+	if cfg.QueryCache.TTLMinutes > 0 {
+		queryCache := bigquery.NewQueryCache(time.Duration(cfg.QueryCache.TTLMinutes) * time.Minute)
+		if cfg.QueryCache.Dir != "" {
+			if err := queryCache.SetDir(cfg.QueryCache.Dir); err != nil {
+				log.Printf("Warning: Query cache stays in memory: %v", err)
+			}
+		}
+		mtdMonitor.SetCache(queryCache)
+		dimensionalMonitor.SetCache(queryCache)
+	}
+
+	// Incremental runs only fetch usage since the last successful run, minus an overlap
+	// for late data. The daily and dimensional fetches are narrowed to it; the MTD fetch
+	// is not, as month-to-date totals summed from part of the month would understate it.
+	fetchDays := opts.days
+	if opts.sinceLastRun {
+		if watermark, err := time.Parse("2006-01-02", store.Watermark()); err == nil {
+			since := watermark.AddDate(0, 0, -cfg.Incremental.OverlapDays)
+			log.Printf("⏩ Since last run: watermark %s, re-scanning from %s", store.Watermark(), since.Format("2006-01-02"))
+			dimensionalMonitor.SetSince(since)
+			fetchDays = daysSince(since, time.Now(), opts.days)
+		} else {
+			log.Println("⏩ Since last run: no watermark yet, running a full fetch")
+		}
+	}
 
 	// Initialize triggers
 	mtdTriggers := triggers.NewMTDTriggers()
+	mtdTriggers.SetAttribution(cfg.Attribution.Dimensions, cfg.Attribution.TopK)
+	mtdTriggers.SetMinDaysElapsed(cfg.MTDMinDaysElapsed)
+	mtdTriggers.SetDropPercentage(cfg.DropPercentage)
 
 	// Initialize data processor
 	processor := utils.NewDataProcessor()
+	processor.SetSummation(cfg.Summation)
+	processor.SetPercentiles(cfg.SummaryPercentiles)
+	dimensionalMonitor.SetSummation(cfg.Summation)
 
 	// Run cost monitoring
 	log.Println("📊 Fetching cost data from BigQuery...")
 	
-	// Get daily costs
-	dailyCosts, err := dailyMonitor.GetDailyCosts()
-	if err != nil {
-		log.Printf("Warning: Failed to get daily costs: %v", err)
+	// Fetch through the vendor-agnostic provider; chunked mode streams rows from the monitor directly
+	var costProvider provider.CostProvider = monitors.NewGCPProvider(client, mtdMonitor, dimensionalMonitor)
+	if mockMode {
+		costProvider = mock.NewProvider(mock.DefaultDir)
 	}
 
-	// Get MTD costs
-	mtdCosts, err := mtdMonitor.GetMTDCosts()
-	if err != nil {
-		log.Printf("Warning: Failed to get MTD costs: %v", err)
+	// Mixed-currency billing rows are converted into the base currency before aggregation
+	converter := currency.NewConverter(cfg.Currency.Base, cfg.Currency.Rates)
+	switch cfg.Currency.Source {
+	case config.RateSourceHTTP:
+		converter.SetRateSource(currency.NewHTTPRates(cfg.Currency.RatesURL))
+	case config.RateSourceBigQuery:
+		if !mockMode {
+			converter.SetRateSource(bigquery.NewRateTable(ctx, client, cfg.Currency.RatesDataset, cfg.Currency.RatesTable))
+		}
+	}
+	if cfg.Currency.Base != "" {
+		mtdMonitor.SetConverter(converter)
 	}
 
-	// Get dimensional costs
-	dimensionalCosts, err := dimensionalMonitor.GetDimensionalCosts()
-	if err != nil {
-		log.Printf("Warning: Failed to get dimensional costs: %v", err)
+	// Get dimensional costs, converting them into the base currency
+	var chunkedDaily []models.DailyCost
+	fetchDimensional := func() []models.CostData {
+		if cfg.Processing.Mode == config.ProcessingChunked && !mockMode {
+			// Chunked mode keeps only bounded per-key aggregates; row-level steps see no rows
+			var err error
+			chunkedDaily, err = runChunkedAggregation(cfg.Processing, dimensionalMonitor, converter, opts.outputPath("composite_key_daily.jsonl"))
+			if err != nil {
+				failures.fail("running chunked aggregation", err)
+			}
+			return nil
+		}
+
+		dimensionalCosts, err := costProvider.DimensionalCosts(ctx, fetchDays)
+		if err != nil {
+			failures.fail("getting dimensional costs", err)
+		}
+		dimensionalCosts, err = converter.Normalize(dimensionalCosts)
+		if err != nil {
+			failures.fail("normalizing currencies", err)
+		}
+		return dimensionalCosts
+	}
+	dailyCosts, mtdCosts, dimensionalCosts := fetchCosts(ctx, costProvider, fetchDays, failures, fetchDimensional)
+
+	// Rejected credentials or a missing billing export fail every later query too
+	if err := failures.fatalErr(); err != nil {
+		return err
+	}
+
+	// Without any cost data there is nothing to detect anomalies in or to report on
+	if len(dailyCosts) == 0 && len(mtdCosts) == 0 && len(dimensionalCosts) == 0 {
+		return fatalf("no cost data was fetched")
 	}
 
+	// Net, exclude or separate refund and credit rows before they reach any baseline
+	negativeCosts := utils.NewNegativeCostHandler(cfg.NegativeCosts)
+	dimensionalCosts = negativeCosts.Apply(dimensionalCosts)
+
 	// Process and aggregate data
 	compositeData := processor.ProcessCompositeData(dailyCosts, mtdCosts, dimensionalCosts)
+	compositeData = utils.TagVendor(compositeData, "gcp")
 
 	// Generate output files
 	log.Println("💾 Generating output files...")
 
 	// Save composite data
-	compositeJSON, err := json.MarshalIndent(compositeData, "", "  ")
-	if err != nil {
-		log.Printf("Error marshaling composite data: %v", err)
+	if cfg.Output.GzipComposite {
+		if cfg.Output.GzipLevel != 0 {
+			jsonOutput.SetGzipLevel(cfg.Output.GzipLevel)
+		}
+		if err := jsonOutput.SaveCompositeData(compositeData, opts.outputPath("composite_data.json.gz")); err != nil {
+			failures.fail("writing composite data", err)
+		} else {
+			log.Println("✅ Saved composite_data.json.gz")
+		}
 	} else {
-		err = os.WriteFile("mock-data/output/composite_data.json", compositeJSON, 0644)
+		compositeJSON, err := json.MarshalIndent(compositeData, "", "  ")
 		if err != nil {
-			log.Printf("Error writing composite data: %v", err)
+			failures.fail("marshaling composite data", err)
 		} else {
-			log.Println("✅ Saved composite_data.json")
+			err = utils.WriteFileAtomic(opts.outputPath("composite_data.json"), compositeJSON)
+			if err != nil {
+				failures.fail("writing composite data", err)
+			} else {
+				log.Println("✅ Saved composite_data.json")
+			}
+		}
+	}
+
+	// Allocate shared costs when allocation rules are configured. The raw view is
+	// composite_data.json; the allocated view and per-project totals are written
+	// beside it. Only the default rules file may be missing.
+	if cfg.AllocationRulesPath != "" {
+		rules, err := allocation.LoadRules(cfg.AllocationRulesPath)
+		if err == nil {
+			engine, err := allocation.NewEngine(rules)
+			if err != nil {
+				failures.fail("loading allocation rules", err)
+			} else {
+				result := engine.Allocate(compositeData)
+				if err := jsonOutput.SaveCompositeData(result.Allocated, opts.outputPath("allocated_data.json")); err != nil {
+					failures.fail("writing allocated data", err)
+				}
+				if err := jsonOutput.SaveProjectAllocations(result.Projects, opts.outputPath("project_allocation.json")); err != nil {
+					failures.fail("writing project allocations", err)
+				}
+			}
+		} else if !os.IsNotExist(err) || cfg.AllocationRulesPath != config.DefaultAllocationRulesPath {
+			failures.fail("loading allocation rules", err)
+		}
+	}
+
+	// Save a date x dimension CSV for spreadsheet pivots
+	if cfg.Output.WideCSV.Enabled {
+		csvOutput := utils.NewCSVOutput(cfg.Output.WideCSV.Dimension, cfg.Output.WideCSV.MaxColumns)
+		if err := csvOutput.SaveWideCSV(compositeData, opts.outputPath("wide_costs.csv")); err != nil {
+			failures.fail("writing wide CSV", err)
 		}
 	}
 
@@ -80,11 +336,11 @@ func main() {
 	dailyTotals := processor.ProcessDailyTotals(dailyCosts)
 	dailyJSON, err := json.MarshalIndent(dailyTotals, "", "  ")
 	if err != nil {
-		log.Printf("Error marshaling daily totals: %v", err)
+		failures.fail("marshaling daily totals", err)
 	} else {
-		err = os.WriteFile("mock-data/output/daily_total_data.json", dailyJSON, 0644)
+		err = utils.WriteFileAtomic(opts.outputPath("daily_total_data.json"), dailyJSON)
 		if err != nil {
-			log.Printf("Error writing daily totals: %v", err)
+			failures.fail("writing daily totals", err)
 		} else {
 			log.Println("✅ Saved daily_total_data.json")
 		}
@@ -93,59 +349,471 @@ func main() {
 	// Save MTD data
 	mtdJSON, err := json.MarshalIndent(mtdCosts, "", "  ")
 	if err != nil {
-		log.Printf("Error marshaling MTD data: %v", err)
+		failures.fail("marshaling MTD data", err)
 	} else {
-		err = os.WriteFile("mock-data/output/mtd_data.json", mtdJSON, 0644)
+		err = utils.WriteFileAtomic(opts.outputPath("mtd_data.json"), mtdJSON)
 		if err != nil {
-			log.Printf("Error writing MTD data: %v", err)
+			failures.fail("writing MTD data", err)
 		} else {
 			log.Println("✅ Saved mtd_data.json")
 		}
 	}
 
-	// Generate anomalies
-	anomalies := processor.DetectAnomalies(dailyCosts, mtdCosts)
+	// Verify the billing export looks complete before running detection on it
+	checker := utils.NewDataQualityChecker(cfg.DataQuality)
+	if cfg.DataQuality.Enabled {
+		report.DataQuality = checker.Check(dailyCosts, compositeData)
+		if !checker.Passed(report.DataQuality) {
+			if cfg.DataQuality.OnFailure == config.DataQualityAbort {
+				report.Aborted = true
+				report.FinishedAt = time.Now().Format(time.RFC3339)
+				if err := jsonOutput.SaveRunReport(report, opts.outputPath("run_report.json")); err != nil {
+					log.Printf("Error writing run report: %v", err)
+				}
+				return fatalf("data quality checks failed - aborting detection")
+			}
+			log.Println("⚠️  Data quality checks failed - results may contain false alerts")
+		}
+	}
+
+	// Generate anomalies by running the enabled detectors
+	log.Println("🔍 Detecting anomalies...")
+	registry := detectors.NewDefaultRegistry()
+	registry.Register("service_spike", &detectors.ServiceSpikeDetector{Thresholds: cfg.ServiceThresholds})
+	models.SetSeverityBands(cfg.SeverityBands)
+	cfg.SetDefaultParam("monthly_spike", "min_days_elapsed", float64(cfg.MTDMinDaysElapsed))
+	cfg.SetDefaultParam("daily_drop", "percentage", cfg.DropPercentage)
+	cfg.SetDefaultParam("monthly_drop", "percentage", cfg.DropPercentage)
+	cfg.SetDefaultParam("monthly_drop", "min_days_elapsed", float64(cfg.MTDMinDaysElapsed))
+	cfg.SetDefaultParam("daily_percentile", "percentile", opts.percentile)
+	cfg.SetDefaultParam("daily_percentile", "min_history", float64(cfg.MinHistoryDays))
+	cfg.SetDefaultParam("daily_percentile", "min_history_floor", float64(cfg.MinHistoryFloorDays))
+	if cfg.DailyBaseline == config.DailyBaselineWeekday {
+		cfg.SetDefaultParam("daily_spike", "weekday", 1)
+	} else {
+		cfg.SetDefaultEnabled("weekday_baseline", false)
+	}
+	report.TestsRun, report.TestsDisabled = registry.Plan(cfg.Detectors)
+
+	// No-alert projects stay in the totals but are taken out of every detection input
+	noAlert := utils.NewNoAlertFilter(cfg.Allowlist.Projects)
+	detectionSeries := noAlert.Series(negativeCosts.Series(detectors.Series{
+		Daily:     dailyCosts,
+		MTD:       mtdCosts,
+		Composite: compositeData,
+	}))
+	detectionCosts := noAlert.Costs(negativeCosts.Costs(dimensionalCosts))
+	if len(cfg.Allowlist.Projects) > 0 {
+		log.Printf("🔕 Excluding %d no-alert projects from detection", len(cfg.Allowlist.Projects))
+	}
+
+	// Only alert on days whose data is complete; deferred days are recorded in the run report
+	detectionSeries, report.DeferredDays = checker.DeferIncomplete(detectionSeries)
+
+	// The daily monitor's percentile tests run on the same detection series
+	dailyMonitor := monitors.NewDailyMonitor(models.NewCostDataProcessor(detectionSeries.Daily, detectionSeries.Composite))
+	dailyMonitor.SetPercentile(opts.percentile)
+	dailyMonitor.SetMinHistory(cfg.MinHistoryDays, cfg.MinHistoryFloorDays)
+	for _, name := range []string{"daily_total", "daily_composite"} {
+		if !cfg.TestEnabled(name) {
+			dailyMonitor.DisableTests(name)
+		}
+	}
+
+	// Format amounts in the base currency, or in the billing currency when there is only one
+	if cfg.Currency.Base != "" {
+		dailyMonitor.SetCurrency(cfg.Currency.Base)
+	} else if codes := currency.Currencies(dimensionalCosts); len(codes) == 1 {
+		dailyMonitor.SetCurrency(codes[0])
+	}
+	dailyTestsRun, dailyTestsDisabled := dailyMonitor.TestStatuses()
+	report.TestsRun = append(report.TestsRun, dailyTestsRun...)
+	report.TestsDisabled = append(report.TestsDisabled, dailyTestsDisabled...)
+
+	vendorSeries := map[string]detectors.Series{
+		"gcp": detectionSeries,
+	}
+	if cfg.Vendors.AWS.Enabled && !mockMode {
+		awsProvider, err := aws.NewProvider(ctx)
+		if err == nil {
+			vendorSeries[awsProvider.Name()], err = fetchVendorSeries(ctx, awsProvider, processor, opts.days)
+		}
+		if err != nil {
+			failures.fail("fetching AWS cost data", err)
+			delete(vendorSeries, "aws")
+		}
+	}
+	if cfg.Vendors.Azure.Enabled && !mockMode {
+		azureProvider, err := azure.NewProvider(cfg.Vendors.Azure.Scope)
+		if err == nil {
+			vendorSeries[azureProvider.Name()], err = fetchVendorSeries(ctx, azureProvider, processor, opts.days)
+		}
+		if err != nil {
+			failures.fail("fetching Azure cost data", err)
+			delete(vendorSeries, "azure")
+		}
+	}
+	// Collect anomalies from every detector, merging duplicate reports of the same spike
+	collection := models.NewAnomalyCollection()
+	if cfg.Vendors.DetectionMode == config.DetectionMerged {
+		collection.AddAnomalies(registry.Run(utils.MergeSeries(vendorSeries), cfg.Detectors))
+	} else {
+		for _, vendor := range utils.SortedVendors(vendorSeries) {
+			for _, anomaly := range registry.Run(vendorSeries[vendor], cfg.Detectors) {
+				anomaly.Vendor = vendor
+				anomaly.ID = anomaly.StableID()
+				collection.AddAnomaly(anomaly)
+			}
+		}
+	}
+
+	dailyMonitor.RunDailyTests(collection)
+
+	// Run detectors per charge type so a credit ending is distinguishable from usage growth
+	if cfg.SplitByCostType {
+		for costType, costTypeSeries := range processor.ProcessCostTypeTotals(detectionSeries.Composite) {
+			log.Printf("🔍 Detecting anomalies for charge type %s...", costType)
+			for _, anomaly := range registry.Run(detectors.Series{Daily: costTypeSeries}, cfg.Detectors) {
+				anomaly.CostType = costType
+				anomaly.Description = fmt.Sprintf("[%s] %s", costType, anomaly.Description)
+				anomaly.ID = anomaly.StableID()
+				collection.AddAnomaly(anomaly)
+			}
+		}
+	}
+
+	// Detect newly-created SKUs against the persisted state
+	if cfg.NewSKU.Enabled {
+		newSKUMonitor := monitors.NewNewSKUMonitor(store, cfg.Allowlist, cfg.NewSKU.MinCost)
+		collection.AddAnomalies(newSKUMonitor.Detect(detectionCosts))
+	}
+
+	// Compare actuals to the team-provided forecast series
+	if cfg.Forecast.Path != "" {
+		forecast, err := utils.LoadForecastCSV(cfg.Forecast.Path)
+		if err != nil {
+			failures.fail("loading forecast", err)
+		} else {
+			variances, forecastAnomalies := monitors.NewForecastMonitor(cfg.Forecast).Compare(detectionSeries.Daily, forecast)
+			collection.AddAnomalies(forecastAnomalies)
+			if err := jsonOutput.SaveForecastVariance(variances, opts.outputPath("forecast_variance.json")); err != nil {
+				failures.fail("writing forecast variance", err)
+			}
+		}
+	}
+
+	// Flag well-understood services that leave their expected daily band
+	if len(cfg.ServiceBands.Services) > 0 {
+		collection.AddAnomalies(monitors.NewBandMonitor(cfg.ServiceBands).Check(detectionSeries.Composite))
+	}
+
+	// Trend unit economics per SKU
+	if cfg.UnitCost.Enabled {
+		unitCostMonitor := monitors.NewUnitCostMonitor(cfg.UnitCost)
+		trends, unitCostAnomalies := unitCostMonitor.Analyze(detectionCosts)
+		collection.AddAnomalies(unitCostAnomalies)
+		if err := jsonOutput.SaveUnitCostTrends(unitCostMonitor.GroupTrendsByUnit(trends), opts.outputPath("unit_cost_trends.json")); err != nil {
+			failures.fail("writing unit cost trends", err)
+		}
+	}
+
+	// Drill total-level anomalies down to the dimensions that drove them
+	anomalies := collection.All()
+	for i, anomaly := range anomalies {
+		switch anomaly.Service {
+		case "daily_total":
+			anomalies[i].Attribution = mtdTriggers.TriggerDailyRootCause(anomaly, detectionSeries.Composite)
+			anomalies[i].RootCause = mtdTriggers.RootCause(anomaly, detectionSeries.Composite)
+		case "monthly_total":
+			anomalies[i].Attribution = mtdTriggers.TriggerMTDRootCause(anomaly, detectionSeries.Composite)
+			anomalies[i].RootCause = mtdTriggers.RootCause(anomaly, detectionSeries.Composite)
+		}
+	}
+	anomalies = noAlert.Anomalies(anomalies)
+
+	// Suppress or downgrade anomalies for projects still in their onboarding grace window
+	anomalies = utils.NewGraceRamp(cfg.Onboarding).Apply(anomalies)
+
+	// Drop anomalies that have been acked or snoozed; IDs are assigned at detection time
+	var activeAnomalies []models.Anomaly
+	for _, anomaly := range anomalies {
+		if anomaly.ID == "" {
+			anomaly.ID = anomaly.StableID()
+		}
+		store.RememberAnomaly(anomaly.ID, anomaly.Key())
+		store.RememberDetector(anomaly.ID, anomaly.Detector())
+		if err := events.Detected(anomaly); err != nil {
+			log.Printf("Warning: Failed to write event log: %v", err)
+		}
+		if store.Suppressed(anomaly.ID, anomaly.Key(), time.Now()) {
+			if err := events.Suppressed(anomaly); err != nil {
+				log.Printf("Warning: Failed to write event log: %v", err)
+			}
+			log.Printf("🔕 Skipping acked/snoozed anomaly %s: %s", anomaly.ID, anomaly.Description)
+			continue
+		}
+		activeAnomalies = append(activeAnomalies, anomaly)
+	}
+	anomalies = activeAnomalies
+
+	// Attach dashboard links so outputs and notifications point straight at the data
+	anomalies = links.Annotate(anomalies, cfg.DashboardLinks)
+
+	if err := store.Save(); err != nil {
+		log.Printf("Warning: Failed to save state store: %v", err)
+	}
+
+	// Write anomalies back to BigQuery; insert IDs derived from the anomaly IDs keep
+	// retried inserts idempotent
+	if cfg.AnomalyTable.Enabled && !mockMode {
+		if err := client.InsertAnomalies(cfg.AnomalyTable.Dataset, cfg.AnomalyTable.Table, cfg.RunID, anomalies); err != nil {
+			failures.fail("inserting anomalies into BigQuery", err)
+		}
+	}
+
+	// Deliver anomalies to each configured notifier, retrying failures through a shared
+	// pool and skipping notifiers whose circuit breaker is open
+	retryPool := notifiers.NewRetryPool(notifiers.RetryPolicy{
+		MaxAttempts: cfg.Retry.MaxAttempts,
+		BaseDelay:   time.Duration(cfg.Retry.BaseDelayMs) * time.Millisecond,
+		MaxDelay:    time.Duration(cfg.Retry.MaxDelayMs) * time.Millisecond,
+		Concurrency: cfg.Retry.Concurrency,
+	})
+	currentIDs := make([]string, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		currentIDs = append(currentIDs, anomaly.ID)
+	}
+	resolvedIDs := store.ResolveOpenAnomalies(currentIDs)
+	breaker := func(notifier notifiers.Notifier) notifiers.Notifier {
+		cooldown := time.Duration(cfg.Breaker.CooldownMinutes) * time.Minute
+		return notifiers.NewCircuitBreaker(notifier, store, cfg.Breaker.FailureThreshold, cooldown)
+	}
+	recorder := func(notifier notifiers.Notifier) notifiers.Notifier {
+		return notifiers.NewRecorder(notifier, events)
+	}
+	for _, notifierConfig := range cfg.Notifiers {
+		notifier, err := notifiers.FromConfig(notifierConfig, store, retryPool.Wrap, breaker, recorder)
+		if err != nil {
+			failures.fail("configuring notifier "+notifierConfig.Name, err)
+			continue
+		}
+		if err := notifier.Notify(anomalies); err != nil {
+			failures.fail("notifying "+notifier.Name(), err)
+		}
+		if resolver, ok := notifiers.AsResolver(notifier); ok && len(resolvedIDs) > 0 {
+			if err := resolver.Resolve(resolvedIDs); err != nil {
+				failures.fail("resolving alerts in "+notifier.Name(), err)
+			}
+		}
+	}
+
 	anomaliesJSON, err := json.MarshalIndent(anomalies, "", "  ")
 	if err != nil {
-		log.Printf("Error marshaling anomalies: %v", err)
+		failures.fail("marshaling anomalies", err)
 	} else {
-		err = os.WriteFile("mock-data/output/anomalies.json", anomaliesJSON, 0644)
+		err = utils.WriteFileAtomic(opts.outputPath("anomalies.json"), anomaliesJSON)
 		if err != nil {
-			log.Printf("Error writing anomalies: %v", err)
+			failures.fail("writing anomalies", err)
 		} else {
 			log.Printf("✅ Saved anomalies.json (%d anomalies detected)", len(anomalies))
 		}
 	}
+	if cfg.Output.CSV {
+		csvOutput := utils.NewCSVOutput("", 0)
+		if err := csvOutput.SaveCompositeData(compositeData, opts.outputPath("composite_data.csv")); err != nil {
+			failures.fail("writing composite data CSV", err)
+		}
+		if err := csvOutput.SaveDailyTotals(dailyTotals, opts.outputPath("daily_total_data.csv")); err != nil {
+			failures.fail("writing daily totals CSV", err)
+		}
+		if err := csvOutput.SaveMTDData(mtdCosts, opts.outputPath("mtd_data.csv")); err != nil {
+			failures.fail("writing MTD data CSV", err)
+		}
+		if err := csvOutput.SaveAnomalies(anomalies, opts.outputPath("anomalies.csv")); err != nil {
+			failures.fail("writing anomalies CSV", err)
+		}
+	}
+	if cfg.Output.SplitAnomaliesBySeverity {
+		if err := jsonOutput.SaveAnomaliesBySeverity(anomalies, opts.outputDir); err != nil {
+			failures.fail("writing anomalies by severity", err)
+		}
+	}
 
 	// Generate summary
 	summary := processor.GenerateSummary(compositeData, dailyTotals, mtdCosts, anomalies)
+	summary.RunID = cfg.RunID
+	noAlert.Annotate(&summary, compositeData)
+	negativeCosts.Annotate(&summary)
+	precisionSince := time.Now().AddDate(0, 0, -cfg.Feedback.PrecisionWindowDays)
+	summary.DetectorPrecision = feedback.DetectorPrecision(store.Labels(), precisionSince)
+	mtdMonitor.SetDailyCosts(detectionSeries.Daily)
+	monthEnd := mtdMonitor.ForecastMonthEnd(mtdCosts, time.Now())
+	if monthEnd.DaysElapsed > 0 {
+		log.Printf("🔮 %s projected to end at %.2f (%.2f - %.2f) after %d of %d days", monthEnd.Month, monthEnd.Projected, monthEnd.Lower, monthEnd.Upper, monthEnd.DaysElapsed, monthEnd.DaysInMonth)
+		summary.MonthEndForecast = &monthEnd
+	}
 	summaryJSON, err := json.MarshalIndent(summary, "", "  ")
 	if err != nil {
-		log.Printf("Error marshaling summary: %v", err)
+		failures.fail("marshaling summary", err)
 	} else {
-		err = os.WriteFile("mock-data/output/summary.json", summaryJSON, 0644)
+		err = utils.WriteFileAtomic(opts.outputPath("summary.json"), summaryJSON)
 		if err != nil {
-			log.Printf("Error writing summary: %v", err)
+			failures.fail("writing summary", err)
 		} else {
 			log.Println("✅ Saved summary.json")
 		}
 	}
 
+	// Export the run's metrics for Prometheus
+	if cfg.Output.MetricsTextfile != "" {
+		exporter := metrics.NewExporter()
+		exporter.Observe(summary, compositeData, anomalies)
+		if err := exporter.WriteTextfile(cfg.Output.MetricsTextfile); err != nil {
+			failures.fail("writing metrics", err)
+		}
+	}
+
+	// Render the HTML dashboard
+	if cfg.Output.HTMLReport != "" {
+		if err := reports.NewReportGenerator().WriteFile(cfg.Output.HTMLReport, summary, compositeData, anomalies); err != nil {
+			failures.fail("writing HTML report", err)
+		}
+	}
+
+	// Combine vendor summaries into one cross-vendor view
+	vendorSummaries := map[string]models.Summary{"gcp": summary}
+	vendorCosts := append([]models.CostData{}, compositeData...)
+	for vendor, series := range vendorSeries {
+		if vendor != "gcp" {
+			vendorSummaries[vendor] = vendorSummary(processor, vendor, series, anomalies)
+			vendorCosts = append(vendorCosts, series.Composite...)
+		}
+	}
+	multiSummary := utils.NewMultiVendorSummary(vendorSummaries, vendorCosts)
+	multiSummary.RunID = cfg.RunID
+	if err := jsonOutput.SaveMultiVendorSummary(multiSummary, opts.outputPath("multi_vendor_summary.json")); err != nil {
+		failures.fail("writing multi-vendor summary", err)
+	}
+
 	// Check for alerts
 	log.Println("🔔 Checking for alerts...")
-	alerts := mtdTriggers.CheckTriggers(dailyCosts, mtdCosts)
+	alerts := mtdTriggers.CheckTriggers(detectionSeries.Daily, detectionSeries.MTD)
+
+	// Check month-to-date spend against project budgets
+	if cfg.Budgets.Path != "" {
+		budgets, err := monitors.LoadBudgets(cfg.Budgets.Path)
+		if err != nil {
+			failures.fail("loading budgets", err)
+		} else {
+			budgetMonitor := monitors.NewBudgetMonitor(store, budgets, cfg.Budgets.Percentages)
+			alerts = append(alerts, budgetMonitor.Check(compositeData)...)
+		}
+	}
+
 	if len(alerts) > 0 {
 		log.Printf("⚠️  Found %d alerts", len(alerts))
 		for _, alert := range alerts {
 			log.Printf("   - %s: %s", alert.Type, alert.Message)
 		}
+		if cfg.SlackWebhookURL != "" {
+			slack, err := notifiers.NewSlackNotifier("slack-alerts", cfg.SlackWebhookURL)
+			if err == nil {
+				err = slack.Send(alerts)
+			}
+			if err != nil {
+				failures.fail("sending alerts to Slack", err)
+			}
+		}
 	} else {
 		log.Println("✅ No alerts triggered")
 	}
 
-	log.Println("🎉 Go framework completed successfully!")
-	log.Printf("📁 Output files saved to: mock-data/output/")
+	report.FinishedAt = time.Now().Format(time.RFC3339)
+	if err := jsonOutput.SaveRunReport(report, opts.outputPath("run_report.json")); err != nil {
+		failures.fail("writing run report", err)
+	}
+
+	// Report what the monitor's own queries cost to run
+	if cfg.SelfCost.Enabled {
+		selfCost := bigquery.SelfCost(cfg.SelfCost.PricePerTiB)
+		selfCost.RunID = cfg.RunID
+		log.Printf("💸 Monitor queries billed %d bytes (~%.4f estimated cost)", selfCost.TotalBytesBilled, selfCost.EstimatedCost)
+		if err := jsonOutput.SaveSelfCost(selfCost, opts.outputPath("self_cost.json")); err != nil {
+			failures.fail("writing self-cost report", err)
+		}
+	}
+
+	// Advance the watermark only once the run has succeeded without failed steps,
+	// so a partial run fetches the same usage again next time
+	if failures.count() == 0 {
+		for _, cost := range dimensionalCosts {
+			store.AdvanceWatermark(cost.Date)
+		}
+		for _, day := range chunkedDaily {
+			store.AdvanceWatermark(day.Date)
+		}
+	}
+	if err := store.Save(); err != nil {
+		failures.fail("saving state", err)
+	}
+
+	if failed := failures.count(); failed > 0 {
+		log.Printf("⚠️  Go framework completed with %d failed steps", failed)
+	} else {
+		log.Println("🎉 Go framework completed successfully!")
+	}
+	log.Printf("📁 Output files saved to: %s", opts.outputDir)
 	log.Printf("📊 Total records processed: %d", len(compositeData))
 	log.Printf("🔍 Anomalies detected: %d", len(anomalies))
 	log.Printf("🚨 Alerts triggered: %d", len(alerts))
-} 
\ No newline at end of file
+	return failures.err()
+}
+
+// daysSince returns the lookback in days that reaches back to the date of since
+// from the date of now, between 1 and maxDays, for fetches that take a day count
+func daysSince(since, now time.Time, maxDays int) int {
+	sinceDate := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(today.Sub(sinceDate).Hours() / 24)
+	if days < 1 {
+		return 1
+	}
+	if days > maxDays {
+		return maxDays
+	}
+	return days
+}
+
+// fetchCosts runs the daily, MTD and dimensional fetches concurrently on the shared
+// provider, as they are independent queries. A failed fetch is recorded in failures
+// and the run continues without it.
+func fetchCosts(ctx context.Context, costProvider provider.CostProvider, days int, failures *runFailures, fetchDimensional func() []models.CostData) ([]models.DailyCost, []models.MTDCost, []models.CostData) {
+	var (
+		fetches          errgroup.Group
+		dailyCosts       []models.DailyCost
+		mtdCosts         []models.MTDCost
+		dimensionalCosts []models.CostData
+	)
+	fetches.Go(func() error {
+		var err error
+		dailyCosts, err = costProvider.DailyCosts(ctx, days)
+		if err != nil {
+			failures.fail("getting daily costs", err)
+		}
+		return nil
+	})
+	fetches.Go(func() error {
+		var err error
+		mtdCosts, err = costProvider.MTDCosts(ctx)
+		if err != nil {
+			failures.fail("getting MTD costs", err)
+		}
+		return nil
+	})
+	fetches.Go(func() error {
+		dimensionalCosts = fetchDimensional()
+		return nil
+	})
+	fetches.Wait()
+	return dailyCosts, mtdCosts, dimensionalCosts
+}
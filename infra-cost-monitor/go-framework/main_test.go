@@ -0,0 +1,461 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files from the mock run output")
+
+// fixtureDir holds the mock cost data the offline pipeline reads
+const fixtureDir = "../mock-data/input"
+
+// runInTempDir runs the pipeline with args from a temporary working directory
+// holding the mock fixtures and, when configJSON is set, a configuration file,
+// and returns its exit code
+func runInTempDir(t *testing.T, configJSON string, fixtures bool, args ...string) int {
+	t.Helper()
+
+	fixtureAbs, err := filepath.Abs(fixtureDir)
+	if err != nil {
+		t.Fatalf("Abs: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	if err := os.MkdirAll("mock-data/input", 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if fixtures {
+		for _, name := range []string{"daily_costs.json", "mtd_costs.json", "dimensional_costs.json"} {
+			data, err := os.ReadFile(filepath.Join(fixtureAbs, name))
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join("mock-data/input", name), data, 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+		}
+	}
+	if configJSON != "" {
+		if err := os.MkdirAll("config", 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile("config/monitor_config.json", []byte(configJSON), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	return exitCode(run(args))
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		fixtures bool
+		args     []string
+		code     int
+	}{
+		{"successful mock run", "", true, []string{"--mock", "--output-dir", "out"}, exitOK},
+		{"invalid arguments", "", true, []string{"--mock", "--days", "0"}, exitFatal},
+		{"invalid configuration", `{"summation": "abacus"}`, true, []string{"--mock", "--output-dir", "out"}, exitFatal},
+		{"unreadable configuration", `{`, true, []string{"--mock", "--output-dir", "out"}, exitFatal},
+		{"no cost data", "", false, []string{"--mock", "--output-dir", "out"}, exitFatal},
+		{"failed step", `{"budgets": {"path": "config/missing_budgets.json"}}`, true, []string{"--mock", "--output-dir", "out"}, exitPartial},
+		{"missing allocation rules", `{"allocation_rules_path": "config/missing_rules.json"}`, true, []string{"--mock", "--output-dir", "out"}, exitPartial},
+		{"report server cannot listen", `{"serve": {"listen_addr": "bad:address:1"}}`, false, []string{"serve"}, exitFatal},
+		{"feedback server cannot listen", `{"feedback": {"listen_addr": "bad:address:1"}}`, false, []string{"feedback-server"}, exitFatal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runInTempDir(t, tt.config, tt.fixtures, tt.args...); got != tt.code {
+				t.Errorf("exit code = %d, want %d", got, tt.code)
+			}
+		})
+	}
+}
+
+func TestDataQualityAbortRecordsTheRunReport(t *testing.T) {
+	out := t.TempDir()
+	// The mock fixtures are long past any freshness limit
+	configJSON := `{"data_quality": {"enabled": true, "on_failure": "abort", "max_lag_days": 2}}`
+	if got := runInTempDir(t, configJSON, true, "--mock", "--output-dir", out); got != exitFatal {
+		t.Fatalf("exit code = %d, want %d", got, exitFatal)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "run_report.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var report models.RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !report.Aborted {
+		t.Error("run report does not record the abort")
+	}
+	failed := false
+	for _, check := range report.DataQuality {
+		if check.Name == "freshness" && !check.Passed {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Errorf("run report checks = %+v, want a failed freshness check", report.DataQuality)
+	}
+	if _, err := os.Stat(filepath.Join(out, "anomalies.json")); !os.IsNotExist(err) {
+		t.Errorf("anomalies.json written after the abort (stat error %v)", err)
+	}
+}
+
+func TestIncompleteDaysAreDeferredInTheRunReport(t *testing.T) {
+	out := t.TempDir()
+	// The latest mock day, 2025-07-08, has no following day of data
+	configJSON := `{"data_quality": {"completeness": "next_day"}}`
+	if got := runInTempDir(t, configJSON, true, "--mock", "--output-dir", out); got != exitOK {
+		t.Fatalf("exit code = %d, want %d", got, exitOK)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "run_report.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var report models.RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(report.DeferredDays) == 0 || report.DeferredDays[len(report.DeferredDays)-1] != "2025-07-08" {
+		t.Errorf("deferred days = %v, want the latest day 2025-07-08 deferred", report.DeferredDays)
+	}
+}
+
+func TestSplitAnomaliesBySeverityKeepsTheCombinedFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		split  bool
+	}{
+		{"split off by default", "", false},
+		{"split on", `{"output": {"split_anomalies_by_severity": true}}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := t.TempDir()
+			if got := runInTempDir(t, tt.config, true, "--mock", "--output-dir", out); got != exitOK {
+				t.Fatalf("exit code = %d, want %d", got, exitOK)
+			}
+
+			var combined []models.Anomaly
+			data, err := os.ReadFile(filepath.Join(out, "anomalies.json"))
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if err := json.Unmarshal(data, &combined); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+
+			split, err := filepath.Glob(filepath.Join(out, "anomalies_*.json"))
+			if err != nil {
+				t.Fatalf("Glob: %v", err)
+			}
+			if !tt.split {
+				if len(split) != 0 {
+					t.Errorf("wrote %v without the option", split)
+				}
+				return
+			}
+			total := 0
+			for _, path := range split {
+				var anomalies []models.Anomaly
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("ReadFile: %v", err)
+				}
+				if err := json.Unmarshal(data, &anomalies); err != nil {
+					t.Fatalf("Unmarshal %s: %v", path, err)
+				}
+				total += len(anomalies)
+			}
+			if len(combined) == 0 || total != len(combined) {
+				t.Errorf("split files hold %d anomalies, want the combined file's %d", total, len(combined))
+			}
+		})
+	}
+}
+
+func TestReportServerServesOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	summaryJSON, err := json.Marshal(models.Summary{CurrentDateCost: 42})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "summary.json"), summaryJSON, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	server := httptest.NewServer(newReportServer("", dir).Handler)
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/summary")
+	if err != nil {
+		t.Fatalf("GET /summary: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /summary status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var summary models.Summary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if summary.CurrentDateCost != 42 {
+		t.Errorf("served summary has daily cost %v, want the one in the output directory", summary.CurrentDateCost)
+	}
+}
+
+func TestDaysSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		since time.Time
+		want  int
+	}{
+		{"a week back", time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC), 7},
+		{"yesterday", time.Date(2024, 5, 9, 23, 0, 0, 0, time.UTC), 1},
+		{"today", time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), 1},
+		{"beyond the maximum", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daysSince(tt.since, now, 90); got != tt.want {
+				t.Errorf("daysSince(%v) = %d, want %d", tt.since, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSinceLastRunNarrowsDailyButNotMTD(t *testing.T) {
+	// countRecords returns how many records a JSON array report holds
+	countRecords := func(t *testing.T, path string) int {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		var records []json.RawMessage
+		if err := json.Unmarshal(data, &records); err != nil {
+			t.Fatalf("Unmarshal %s: %v", path, err)
+		}
+		return len(records)
+	}
+
+	full := t.TempDir()
+	if code := runInTempDir(t, "", true, "--mock", "--output-dir", full); code != exitOK {
+		t.Fatalf("full run exit code = %d, want %d", code, exitOK)
+	}
+
+	// A watermark three days back, with no overlap, leaves a three-day lookback
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	watermark := time.Now().AddDate(0, 0, -3).Format("2006-01-02")
+	if err := os.WriteFile(statePath, []byte(fmt.Sprintf(`{"watermark": %q}`, watermark)), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	config := fmt.Sprintf(`{"state_path": %q, "incremental": {"overlap_days": 0}}`, statePath)
+	incremental := t.TempDir()
+	if code := runInTempDir(t, config, true, "--mock", "--since-last-run", "--output-dir", incremental); code != exitOK {
+		t.Fatalf("incremental run exit code = %d, want %d", code, exitOK)
+	}
+
+	if got := countRecords(t, filepath.Join(incremental, "daily_total_data.json")); got != 3 {
+		t.Errorf("incremental run wrote %d daily totals, want the 3 days since the watermark", got)
+	}
+	fullMTD := countRecords(t, filepath.Join(full, "mtd_data.json"))
+	if got := countRecords(t, filepath.Join(incremental, "mtd_data.json")); got != fullMTD {
+		t.Errorf("incremental run wrote %d MTD records, want the full run's %d", got, fullMTD)
+	}
+}
+
+// slowProvider is a fakeProvider whose every fetch takes latency
+type slowProvider struct {
+	*fakeProvider
+	latency time.Duration
+}
+
+func (p *slowProvider) DailyCosts(ctx context.Context, days int) ([]models.DailyCost, error) {
+	time.Sleep(p.latency)
+	return p.fakeProvider.DailyCosts(ctx, days)
+}
+
+func (p *slowProvider) MTDCosts(ctx context.Context) ([]models.MTDCost, error) {
+	time.Sleep(p.latency)
+	return p.fakeProvider.MTDCosts(ctx)
+}
+
+func (p *slowProvider) DimensionalCosts(ctx context.Context, days int) ([]models.CostData, error) {
+	time.Sleep(p.latency)
+	return p.fakeProvider.DimensionalCosts(ctx, days)
+}
+
+func TestFetchCostsRunsFetchesConcurrently(t *testing.T) {
+	const latency = 200 * time.Millisecond
+	costProvider := &slowProvider{fakeProvider: newFakeProvider(), latency: latency}
+	fetchDimensional := func() []models.CostData {
+		costs, _ := costProvider.DimensionalCosts(context.Background(), 30)
+		return costs
+	}
+
+	failures := &runFailures{}
+	start := time.Now()
+	daily, mtd, dimensional := fetchCosts(context.Background(), costProvider, 30, failures, fetchDimensional)
+	elapsed := time.Since(start)
+
+	// Sequential fetches would take three times the latency
+	if elapsed >= 2*latency {
+		t.Errorf("fetches took %s, want roughly the slowest fetch's %s", elapsed, latency)
+	}
+	if len(daily) != 3 || len(mtd) != 1 || len(dimensional) != 3 {
+		t.Errorf("fetched %d days, %d months and %d rows, want 3, 1 and 3", len(daily), len(mtd), len(dimensional))
+	}
+	if failures.count() != 0 {
+		t.Errorf("recorded %d failures, want none", failures.count())
+	}
+}
+
+func TestFetchCostsContinuesPastAFailedFetch(t *testing.T) {
+	costProvider := newFakeProvider()
+	costProvider.err = errors.New("quota exceeded")
+	fetchDimensional := func() []models.CostData {
+		costs, _ := costProvider.DimensionalCosts(context.Background(), 30)
+		return costs
+	}
+
+	failures := &runFailures{}
+	_, mtd, dimensional := fetchCosts(context.Background(), costProvider, 30, failures, fetchDimensional)
+	if len(mtd) != 1 || len(dimensional) != 3 {
+		t.Errorf("fetched %d months and %d rows, want the MTD and dimensional costs despite the failed daily fetch", len(mtd), len(dimensional))
+	}
+	if failures.count() != 1 {
+		t.Errorf("recorded %d failures, want the daily fetch's", failures.count())
+	}
+	if err := failures.fatalErr(); err != nil {
+		t.Errorf("fatalErr() = %v, want a quota failure to leave the run partial", err)
+	}
+}
+
+// goldenFiles are the mock run outputs compared against testdata/golden. The run
+// report is left out: its data quality checks measure the fixtures' age.
+var goldenFiles = []string{
+	"anomalies.json",
+	"composite_data.json",
+	"daily_total_data.json",
+	"mtd_data.json",
+	"multi_vendor_summary.json",
+	"summary.json",
+	"unit_cost_trends.json",
+}
+
+// volatileFields vary between runs of the same input and are dropped before comparing
+var volatileFields = map[string]bool{
+	"run_id":      true,
+	"detected_at": true,
+	"started_at":  true,
+	"finished_at": true,
+}
+
+// normalizeOutput re-encodes a JSON output file without its volatile fields
+func normalizeOutput(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	var strip func(v interface{})
+	strip = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, value := range v {
+				if volatileFields[key] {
+					delete(v, key)
+					continue
+				}
+				strip(value)
+			}
+		case []interface{}:
+			for _, value := range v {
+				strip(value)
+			}
+		}
+	}
+	strip(v)
+
+	normalized, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent: %v", err)
+	}
+	return append(normalized, '\n')
+}
+
+func TestMockRunMatchesGoldenFiles(t *testing.T) {
+	golden, err := filepath.Abs(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatalf("Abs: %v", err)
+	}
+	out := t.TempDir()
+	if code := runInTempDir(t, "", true, "--mock", "--output-dir", out); code != exitOK {
+		t.Fatalf("exit code = %d, want %d", code, exitOK)
+	}
+
+	for _, name := range goldenFiles {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(out, name))
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			got := normalizeOutput(t, data)
+
+			path := filepath.Join(golden, name)
+			if *update {
+				if err := os.MkdirAll(golden, 0755); err != nil {
+					t.Fatalf("MkdirAll: %v", err)
+				}
+				if err := os.WriteFile(path, got, 0644); err != nil {
+					t.Fatalf("WriteFile: %v", err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v (run go test -run TestMockRunMatchesGoldenFiles -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from %s; rerun with -update if the change is intended", name, path)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"infra-cost-monitor/go-framework/adapters/bigquery"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Exit codes of a monitoring run. 2 is left to flag parsing, which exits with it
+// on invalid arguments.
+const (
+	exitOK      = 0
+	exitFatal   = 1
+	exitPartial = 3
+)
+
+// runError is a failed monitoring run. A fatal run produced no usable results,
+// e.g. its configuration was invalid or no cost data could be fetched; a partial
+// run completed, but some of its steps failed.
+type runError struct {
+	fatal bool
+	steps []string
+}
+
+// Error lists the failed steps
+func (e *runError) Error() string {
+	if e.fatal {
+		return "run failed: " + strings.Join(e.steps, "; ")
+	}
+	return fmt.Sprintf("run completed with %d failed steps: %s", len(e.steps), strings.Join(e.steps, "; "))
+}
+
+// fatalf returns a fatal run error
+func fatalf(format string, args ...interface{}) error {
+	return &runError{fatal: true, steps: []string{fmt.Sprintf(format, args...)}}
+}
+
+// exitCode maps the result of run to the process exit code
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var runErr *runError
+	if errors.As(err, &runErr) && !runErr.fatal {
+		return exitPartial
+	}
+	return exitFatal
+}
+
+// fatalCause reports whether err means no later step can succeed either: the
+// credentials are rejected or the billing export does not exist. Quota and
+// timeout failures are transient and leave the run partial.
+func fatalCause(err error) bool {
+	return errors.Is(err, bigquery.ErrAuth) || errors.Is(err, bigquery.ErrTableNotFound)
+}
+
+// runFailures accumulates the steps of a run that failed without stopping it.
+// It is safe for concurrent use by the fetch goroutines.
+type runFailures struct {
+	mu    sync.Mutex
+	steps []string
+	fatal bool
+}
+
+// fail logs a failed step and records it
+func (rf *runFailures) fail(step string, err error) {
+	log.Printf("Error %s: %v", step, err)
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	rf.steps = append(rf.steps, fmt.Sprintf("%s: %v", step, err))
+	if fatalCause(err) {
+		rf.fatal = true
+	}
+}
+
+// count returns how many steps have failed
+func (rf *runFailures) count() int {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return len(rf.steps)
+}
+
+// fatalErr returns a fatal run error listing the failed steps if any of them
+// failed with a fatal cause, or nil
+func (rf *runFailures) fatalErr() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if !rf.fatal {
+		return nil
+	}
+	return &runError{fatal: true, steps: append([]string(nil), rf.steps...)}
+}
+
+// err returns a run error listing the failed steps, or nil if none failed. It is
+// fatal if any step failed with a fatal cause, and partial otherwise.
+func (rf *runFailures) err() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if len(rf.steps) == 0 {
+		return nil
+	}
+	return &runError{fatal: rf.fatal, steps: append([]string(nil), rf.steps...)}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"infra-cost-monitor/go-framework/adapters/bigquery"
+	"testing"
+)
+
+func TestRunFailuresClassifyBigQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		fatal bool
+		code  int
+	}{
+		{"auth failure", fmt.Errorf("query: %w", bigquery.ErrAuth), true, exitFatal},
+		{"missing billing table", &bigquery.Error{Op: "query", Kind: bigquery.ErrTableNotFound, Err: errors.New("404")}, true, exitFatal},
+		{"quota exceeded", &bigquery.Error{Op: "query", Kind: bigquery.ErrQuotaExceeded, Err: errors.New("429")}, false, exitPartial},
+		{"query timeout", fmt.Errorf("query: %w", bigquery.ErrQueryTimeout), false, exitPartial},
+		{"other failure", errors.New("disk full"), false, exitPartial},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failures runFailures
+			failures.fail("getting daily costs", tt.err)
+
+			if got := failures.fatalErr() != nil; got != tt.fatal {
+				t.Errorf("fatalErr() != nil = %v, want %v", got, tt.fatal)
+			}
+			if got := exitCode(failures.err()); got != tt.code {
+				t.Errorf("exit code = %d, want %d", got, tt.code)
+			}
+		})
+	}
+
+	var none runFailures
+	if none.err() != nil || none.fatalErr() != nil || exitCode(none.err()) != exitOK {
+		t.Error("a run without failures is not successful")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"infra-cost-monitor/go-framework/adapters/reports"
+	"infra-cost-monitor/go-framework/config"
+)
+
+// runServe serves the daily, MTD, anomaly and summary reports a run wrote to
+// outputDir, and its metrics, over HTTP until SIGINT or SIGTERM, then drains
+// in-flight requests
+func runServe(cfg *config.Config, outputDir string) error {
+	server := newReportServer(cfg.Serve.ListenAddr, outputDir)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		log.Println("🛑 Shutting down report server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down report server: %v", err)
+		}
+	}()
+
+	log.Printf("🌐 Report server listening on %s, serving %s", server.Addr, outputDir)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fatalf("report server failed: %v", err)
+	}
+	log.Println("✅ Report server stopped")
+	return nil
+}
+
+// newReportServer creates the report server for the reports in outputDir
+func newReportServer(addr, outputDir string) *http.Server {
+	mux := http.NewServeMux()
+	reports.NewHandler(outputDir).Register(mux)
+
+	return &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+}
@@ -0,0 +1,398 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"os"
+	"sync"
+	"time"
+)
+
+// State is the data persisted between runs
+type State struct {
+	SKUsSeeded bool                     `json:"skus_seeded"`
+	SeenSKUs   map[string]string        `json:"seen_skus"`
+	Anomalies  map[string]string        `json:"anomalies"`
+	Actions    map[string]AnomalyAction `json:"actions"`
+
+	// Watermark is the latest usage date (YYYY-MM-DD) processed by a successful run
+	Watermark string `json:"watermark,omitempty"`
+
+	Breakers map[string]BreakerState `json:"breakers,omitempty"`
+
+	// OpenAnomalies are the IDs notified by the last run, used to resolve alerts
+	OpenAnomalies []string `json:"open_anomalies,omitempty"`
+
+	// AnomalyDetectors maps anomaly IDs to the detector that raised them
+	AnomalyDetectors map[string]string `json:"anomaly_detectors,omitempty"`
+
+	// Labels are true/false positive verdicts on past anomalies, keyed by anomaly ID
+	Labels map[string]AnomalyLabel `json:"labels,omitempty"`
+
+	// DedupKeys maps anomaly IDs to the PagerDuty incident they were paged under
+	DedupKeys map[string]string `json:"dedup_keys,omitempty"`
+
+	// BudgetAlerts records the budget bands already alerted, keyed by
+	// project|month|percentage, with the date the band was crossed
+	BudgetAlerts map[string]string `json:"budget_alerts,omitempty"`
+}
+
+// AnomalyLabel records whether a past anomaly was a true or false positive
+type AnomalyLabel struct {
+	AnomalyID    string    `json:"anomaly_id"`
+	Detector     string    `json:"detector"`
+	TruePositive bool      `json:"true_positive"`
+	User         string    `json:"user"`
+	At           time.Time `json:"at"`
+}
+
+// BreakerState is a notifier's circuit breaker state carried across runs
+type BreakerState struct {
+	Failures int       `json:"failures"`
+	OpenedAt time.Time `json:"opened_at,omitempty"`
+}
+
+// Actions that can be taken on an anomaly
+const (
+	ActionAck    = "ack"
+	ActionSnooze = "snooze"
+)
+
+// AnomalyAction records an acknowledgement or snooze of an anomaly.
+// Acks suppress the anomaly ID; snoozes suppress the anomaly's key until they expire.
+type AnomalyAction struct {
+	AnomalyID string    `json:"anomaly_id"`
+	Key       string    `json:"key"`
+	Action    string    `json:"action"`
+	User      string    `json:"user"`
+	At        time.Time `json:"at"`
+	Until     time.Time `json:"until,omitempty"`
+}
+
+// Store persists monitor state to a JSON file
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	state State
+
+	// fileMu serializes this process's read-modify-write cycles on the file;
+	// the file lock serializes them with other processes
+	fileMu sync.Mutex
+}
+
+// Load loads the state store from a JSON file, starting empty if it does not exist
+func Load(path string) (*Store, error) {
+	store := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &store.state); err != nil {
+			return nil, err
+		}
+	}
+
+	if store.state.SeenSKUs == nil {
+		store.state.SeenSKUs = make(map[string]string)
+	}
+	if store.state.Anomalies == nil {
+		store.state.Anomalies = make(map[string]string)
+	}
+	if store.state.Actions == nil {
+		store.state.Actions = make(map[string]AnomalyAction)
+	}
+	if store.state.Breakers == nil {
+		store.state.Breakers = make(map[string]BreakerState)
+	}
+	if store.state.AnomalyDetectors == nil {
+		store.state.AnomalyDetectors = make(map[string]string)
+	}
+	if store.state.Labels == nil {
+		store.state.Labels = make(map[string]AnomalyLabel)
+	}
+	if store.state.DedupKeys == nil {
+		store.state.DedupKeys = make(map[string]string)
+	}
+	if store.state.BudgetAlerts == nil {
+		store.state.BudgetAlerts = make(map[string]string)
+	}
+	return store, nil
+}
+
+// Reload re-reads the state file so changes written by other processes are picked up
+func (s *Store) Reload() error {
+	reloaded, err := Load(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = reloaded.state
+	return nil
+}
+
+// Save writes the state store to its JSON file. Under an exclusive lock on the
+// file, acks, snoozes and labels saved by another process since this store was
+// loaded, such as the feedback server, are merged in first so they are not
+// overwritten; the file is then replaced atomically.
+func (s *Store) Save() error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock state file: %v", err)
+	}
+	defer unlock()
+
+	onDisk, err := Load(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read state file for merge: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mergeFeedback(onDisk.state)
+	return s.write()
+}
+
+// Update re-reads the state file, applies change to it and writes it back, all
+// under the file lock, so concurrent updates from this or another process are
+// never lost. The store then holds the updated state, replacing any unsaved
+// changes. If change returns an error, nothing is written and the error is
+// returned.
+func (s *Store) Update(change func(*State) error) error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock state file: %v", err)
+	}
+	defer unlock()
+
+	onDisk, err := Load(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read state file: %v", err)
+	}
+	if err := change(&onDisk.state); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = onDisk.state
+	return s.write()
+}
+
+// write replaces the state file with the current state; s.mu must be held
+func (s *Store) write() error {
+	jsonData, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(s.path, jsonData)
+}
+
+// mergeFeedback takes the actions and labels of other that are missing here or
+// newer than ours; s.mu must be held
+func (s *Store) mergeFeedback(other State) {
+	for id, action := range other.Actions {
+		if ours, exists := s.state.Actions[id]; !exists || action.At.After(ours.At) {
+			s.state.Actions[id] = action
+		}
+	}
+	for id, label := range other.Labels {
+		if ours, exists := s.state.Labels[id]; !exists || label.At.After(ours.At) {
+			s.state.Labels[id] = label
+		}
+	}
+}
+
+// SKUsSeeded reports whether the seen-SKU set has been seeded by a previous run
+func (s *Store) SKUsSeeded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.SKUsSeeded
+}
+
+// SetSKUsSeeded marks the seen-SKU set as seeded
+func (s *Store) SetSKUsSeeded() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.SKUsSeeded = true
+}
+
+// SeenSKU reports whether the SKU key has been seen before
+func (s *Store) SeenSKU(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, seen := s.state.SeenSKUs[key]
+	return seen
+}
+
+// MarkSKUSeen records the date a SKU key was first seen
+func (s *Store) MarkSKUSeen(key, date string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, seen := s.state.SeenSKUs[key]; !seen {
+		s.state.SeenSKUs[key] = date
+	}
+}
+
+// Watermark returns the latest usage date processed by a successful run, if any
+func (s *Store) Watermark() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Watermark
+}
+
+// AdvanceWatermark moves the watermark forward to date; it never moves backwards
+func (s *Store) AdvanceWatermark(date string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if date > s.state.Watermark {
+		s.state.Watermark = date
+	}
+}
+
+// Breaker returns the circuit breaker state of the named notifier
+func (s *Store) Breaker(name string) BreakerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Breakers[name]
+}
+
+// SetBreaker records the circuit breaker state of the named notifier
+func (s *Store) SetBreaker(name string, breaker BreakerState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Breakers[name] = breaker
+}
+
+// ResolveOpenAnomalies replaces the open anomaly set with current and returns
+// the previously open IDs that are no longer detected
+func (s *Store) ResolveOpenAnomalies(current []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stillOpen := make(map[string]bool)
+	for _, id := range current {
+		stillOpen[id] = true
+	}
+
+	var resolved []string
+	for _, id := range s.state.OpenAnomalies {
+		if !stillOpen[id] {
+			resolved = append(resolved, id)
+		}
+	}
+	s.state.OpenAnomalies = current
+	return resolved
+}
+
+// RememberAnomaly records the key of a detected anomaly so actions on its ID can be resolved
+func (s *Store) RememberAnomaly(anomalyID, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Anomalies[anomalyID] = key
+}
+
+// AnomalyKey returns the key of a previously detected anomaly
+func (s *Store) AnomalyKey(anomalyID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, exists := s.state.Anomalies[anomalyID]
+	return key, exists
+}
+
+// RememberDetector records which detector raised an anomaly so feedback can be attributed to it
+func (s *Store) RememberDetector(anomalyID, detector string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.AnomalyDetectors[anomalyID] = detector
+}
+
+// AnomalyDetector returns the detector that raised a previously detected anomaly
+func (s *Store) AnomalyDetector(anomalyID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	detector, exists := s.state.AnomalyDetectors[anomalyID]
+	return detector, exists
+}
+
+// RememberDedupKey records the incident dedup key an anomaly was paged under so it can be resolved later
+func (s *Store) RememberDedupKey(anomalyID, dedupKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.DedupKeys[anomalyID] = dedupKey
+}
+
+// DedupKey returns the incident dedup key a previously detected anomaly was paged under
+func (s *Store) DedupKey(anomalyID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dedupKey, exists := s.state.DedupKeys[anomalyID]
+	return dedupKey, exists
+}
+
+// BudgetAlerted reports whether the budget band has already been alerted
+func (s *Store) BudgetAlerted(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, alerted := s.state.BudgetAlerts[key]
+	return alerted
+}
+
+// MarkBudgetAlerted records the date a budget band was alerted
+func (s *Store) MarkBudgetAlerted(key, date string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, alerted := s.state.BudgetAlerts[key]; !alerted {
+		s.state.BudgetAlerts[key] = date
+	}
+}
+
+// RecordLabel records a true/false positive verdict, replacing any earlier one for the anomaly
+func (s *Store) RecordLabel(label AnomalyLabel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Labels[label.AnomalyID] = label
+}
+
+// Labels returns all recorded verdicts
+func (s *Store) Labels() []AnomalyLabel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	labels := make([]AnomalyLabel, 0, len(s.state.Labels))
+	for _, label := range s.state.Labels {
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+// RecordAction records an ack or snooze for an anomaly, replacing any earlier action
+func (s *Store) RecordAction(action AnomalyAction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Actions[action.AnomalyID] = action
+}
+
+// Suppressed reports whether an anomaly has been acked, or its key snoozed past now
+func (s *Store) Suppressed(anomalyID, key string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if action, exists := s.state.Actions[anomalyID]; exists && action.Action == ActionAck {
+		return true
+	}
+	for _, action := range s.state.Actions {
+		if action.Action == ActionSnooze && action.Key == key && now.Before(action.Until) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveKeepsActionsSavedByAnotherProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// The monitoring run loads the state, then the feedback server records an ack
+	run, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	server, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	server.RecordAction(AnomalyAction{AnomalyID: "a1", Key: "Compute", Action: ActionAck, At: at})
+	server.RecordLabel(AnomalyLabel{AnomalyID: "a2", TruePositive: true, At: at})
+	if err := server.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// The run saves its own state afterwards without reloading
+	run.AdvanceWatermark("2024-03-01")
+	if err := run.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !saved.Suppressed("a1", "Compute", at) {
+		t.Error("ack recorded by the feedback server was overwritten")
+	}
+	if len(saved.Labels()) != 1 {
+		t.Error("label recorded by the feedback server was overwritten")
+	}
+	if saved.Watermark() != "2024-03-01" {
+		t.Errorf("watermark = %q, want the run's 2024-03-01", saved.Watermark())
+	}
+}
+
+func TestSaveKeepsNewerAction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	older, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	newer, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	newer.RecordAction(AnomalyAction{AnomalyID: "a1", Key: "Compute", Action: ActionAck, At: at.Add(time.Hour)})
+	if err := newer.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	older.RecordAction(AnomalyAction{AnomalyID: "a1", Key: "Compute", Action: ActionSnooze, At: at, Until: at.Add(time.Hour)})
+	if err := older.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !saved.Suppressed("a1", "Other", at.Add(48*time.Hour)) {
+		t.Error("newer ack was replaced by an older snooze")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+)
+
+// runTrend loads a metric from saved run outputs and reports its growth, returning the process exit code
+func runTrend(args []string) int {
+	flags := flag.NewFlagSet("trend", flag.ExitOnError)
+	dir := flags.String("dir", "mock-data/output", "directory of saved run outputs, searched recursively")
+	metric := flags.String("metric", utils.TrendDailyTotal, "metric to trend: "+strings.Join(utils.TrendMetrics, ", "))
+	days := flags.Int("days", 30, "number of days to include, ending at the newest saved date")
+	output := flags.String("output", "", "trend report file (default <dir>/trend_<metric>.json)")
+	flags.Parse(args)
+
+	known := false
+	for _, name := range utils.TrendMetrics {
+		known = known || name == *metric
+	}
+	if !known {
+		log.Printf("❌ Unknown metric %q (supported: %s)", *metric, strings.Join(utils.TrendMetrics, ", "))
+		return 2
+	}
+
+	log.Printf("📈 Trending %s over %d days from %s...", *metric, *days, *dir)
+	points, err := utils.LoadTrendSeries(*dir, *metric, *days)
+	if err != nil {
+		log.Printf("❌ Failed to load saved runs: %v", err)
+		return 1
+	}
+	if len(points) == 0 {
+		log.Printf("❌ No saved values for %s found in %s", *metric, *dir)
+		return 1
+	}
+
+	report := utils.AnalyzeTrend(*metric, points)
+	fmt.Printf("%s: %d points from %s to %s\n", report.Metric, len(report.Points), report.From, report.To)
+	fmt.Printf("  mean        %.2f\n", report.Mean)
+	fmt.Printf("  slope/day   %.2f\n", report.SlopePerDay)
+	fmt.Printf("  CAGR        %.2f%%\n", report.CAGR)
+	fmt.Printf("  volatility  %.2f%%\n", report.Volatility)
+
+	filename := *output
+	if filename == "" {
+		filename = filepath.Join(*dir, "trend_"+*metric+".json")
+	}
+	if err := utils.NewJSONOutput().SaveTrendReport(report, filename); err != nil {
+		log.Printf("❌ Failed to write trend report: %v", err)
+		return 1
+	}
+	return 0
+}
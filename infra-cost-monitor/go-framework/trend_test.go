@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+)
+
+func TestTrendSubcommandWritesReport(t *testing.T) {
+	dir := t.TempDir()
+	data, err := json.Marshal([]models.DailyCost{{Date: "2024-05-01", TotalCost: 100}, {Date: "2024-05-02", TotalCost: 120}})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "daily_total_data.json"), data, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	if code := runTrend([]string{"--dir", dir}); code != exitOK {
+		t.Fatalf("exit code = %d, want %d", code, exitOK)
+	}
+	saved, err := os.ReadFile(filepath.Join(dir, "trend_daily_total.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var report models.TrendReport
+	if err := json.Unmarshal(saved, &report); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(report.Points) != 2 || report.SlopePerDay != 20 {
+		t.Errorf("report = %+v, want two points rising 20 a day", report)
+	}
+
+	if code := runTrend([]string{"--dir", dir, "--metric", "margin"}); code != 2 {
+		t.Errorf("exit code with an unknown metric = %d, want 2", code)
+	}
+	if code := runTrend([]string{"--dir", t.TempDir()}); code != exitFatal {
+		t.Errorf("exit code with no saved runs = %d, want %d", code, exitFatal)
+	}
+}
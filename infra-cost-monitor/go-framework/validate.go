@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"infra-cost-monitor/go-framework/adapters/bigquery"
+	"infra-cost-monitor/go-framework/adapters/notifiers"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+)
+
+// requiredEnv lists the environment variables the monitor needs to query billing data
+var requiredEnv = []string{
+	"GOOGLE_CLOUD_PROJECT",
+	"BIGQUERY_DATASET",
+	"BIGQUERY_TABLE",
+	"BIGQUERY_BILLING_EXPORT_TABLE",
+}
+
+// validateQueryTimeout bounds the billing table check so an unreachable BigQuery
+// fails the check instead of hanging validation
+const validateQueryTimeout = time.Minute
+
+// validationReport collects pass/fail results for the validate subcommand
+type validationReport struct {
+	failures int
+}
+
+// check records and prints the result of a single validation check
+func (vr *validationReport) check(name string, err error) bool {
+	if err != nil {
+		vr.failures++
+		fmt.Printf("❌ FAIL  %s: %v\n", name, err)
+		return false
+	}
+	fmt.Printf("✅ PASS  %s\n", name)
+	return true
+}
+
+// runValidate checks configuration and connectivity, returning the process exit code
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := flags.String("config", config.DefaultPath, "path to the monitor configuration file")
+	sendTest := flags.Bool("send-test", false, "send a test message through each notifier")
+	flags.Parse(args)
+
+	log.Println("🩺 Validating configuration and connectivity...")
+	report := &validationReport{}
+
+	// Config must parse and validate
+	cfg, err := config.Load(*configPath)
+	if report.check("config parses ("+*configPath+")", err) {
+		report.check("config is valid", cfg.Validate())
+	}
+
+	// Required environment and secrets
+	for _, name := range requiredEnv {
+		report.check("env "+name, requireEnv(name))
+	}
+	if cfg != nil && cfg.Feedback.ListenAddr != "" {
+		if os.Getenv("SLACK_SIGNING_SECRET") == "" {
+			fmt.Println("⚠️  SKIP  env SLACK_SIGNING_SECRET not set; feedback-server will reject all requests")
+		}
+	}
+
+	// Billing table exists and is queryable
+	client, err := bigquery.NewClient()
+	if report.check("BigQuery client", err) {
+		ctx, cancel := context.WithTimeout(context.Background(), validateQueryTimeout)
+		report.check("billing table is queryable", client.CheckBillingTable(ctx))
+		cancel()
+		if columns, err := client.BillingColumns(); report.check("billing table schema", err) {
+			available := bigquery.AvailableBillingColumns(columns)
+			fmt.Printf("ℹ️  INFO  optional billing columns available: %v\n", available)
+		}
+		client.Close()
+	}
+
+	// Each notifier builds and its endpoint is reachable
+	if cfg != nil {
+		for _, notifierConfig := range cfg.Notifiers {
+			name := "notifier " + notifierConfig.Name
+			notifier, err := notifiers.FromConfig(notifierConfig, nil)
+			if !report.check(name+" builds", err) {
+				continue
+			}
+			report.check(name+" is reachable", checkReachable(notifierConfig))
+			if *sendTest {
+				report.check(name+" accepts a test message", notifier.Notify([]models.Anomaly{testAnomaly()}))
+			}
+		}
+	}
+
+	if report.failures > 0 {
+		fmt.Printf("\nValidation failed: %d check(s) failed\n", report.failures)
+		return 1
+	}
+	fmt.Println("\nValidation passed")
+	return 0
+}
+
+// requireEnv returns an error if the environment variable is unset
+func requireEnv(name string) error {
+	if os.Getenv(name) == "" {
+		return fmt.Errorf("not set")
+	}
+	return nil
+}
+
+// checkReachable verifies a notifier's URL answers or its sink directory exists
+func checkReachable(cfg config.NotifierConfig) error {
+	if cfg.URL != "" {
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Head(cfg.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+	if cfg.Path != "" {
+		info, err := os.Stat(filepath.Dir(cfg.Path))
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", filepath.Dir(cfg.Path))
+		}
+	}
+	return nil
+}
+
+// testAnomaly returns a clearly-labelled anomaly used for --send-test
+func testAnomaly() models.Anomaly {
+	now := time.Now()
+	return models.Anomaly{
+		ID:          "validate-test",
+		Date:        now.Format("2006-01-02"),
+		TestName:    "validate",
+		Type:        "test",
+		Service:     "cost-monitor",
+		Description: "Test message from cost monitor validate --send-test; no action needed",
+		Severity:    models.SeverityLow,
+		DetectedAt:  now.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/config"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckReachable(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.NotifierConfig
+		ok   bool
+	}{
+		{"answering url", config.NotifierConfig{URL: up.URL}, true},
+		{"unreachable url", config.NotifierConfig{URL: down.URL}, false},
+		{"sink in an existing directory", config.NotifierConfig{Path: filepath.Join(dir, "events.jsonl")}, true},
+		{"sink in a missing directory", config.NotifierConfig{Path: filepath.Join(dir, "missing", "events.jsonl")}, false},
+		{"sink under a file", config.NotifierConfig{Path: filepath.Join(file, "events.jsonl")}, false},
+		{"no endpoint", config.NotifierConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkReachable(tt.cfg); (err == nil) != tt.ok {
+				t.Errorf("checkReachable() error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+// validateInTempDir runs the validate subcommand against configJSON with stdout
+// and the log silenced, returning its exit code
+func validateInTempDir(t *testing.T, configJSON string, args ...string) int {
+	t.Helper()
+	for _, name := range requiredEnv {
+		t.Setenv(name, "")
+	}
+	path := filepath.Join(t.TempDir(), "monitor_config.json")
+	if err := os.WriteFile(path, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	stdout := os.Stdout
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer devNull.Close()
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	return runValidate(append([]string{"--config", path}, args...))
+}
+
+func TestRunValidateFailsOnMissingEnvironment(t *testing.T) {
+	if got := validateInTempDir(t, `{}`); got != 1 {
+		t.Errorf("exit code = %d, want 1 with no BigQuery environment", got)
+	}
+}
+
+func TestRunValidateSendsTestMessages(t *testing.T) {
+	sink := filepath.Join(t.TempDir(), "events.jsonl")
+	configJSON := fmt.Sprintf(`{"notifiers": [{"name": "events", "type": "cloudevents", "path": %q}]}`, sink)
+	validateInTempDir(t, configJSON, "--send-test")
+
+	file, err := os.Open(sink)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		t.Fatal("sink is empty, want the test message")
+	}
+	var event struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if event.ID != "validate-test" {
+		t.Errorf("sent event %q, want the validate test anomaly", event.ID)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"infra-cost-monitor/go-framework/vendors/provider"
+)
+
+// fetchVendorSeries fetches a vendor's daily, monthly and dimensional costs over
+// the last days days as a detection series, with its cost rows tagged by vendor
+func fetchVendorSeries(ctx context.Context, costProvider provider.CostProvider, processor *utils.DataProcessor, days int) (detectors.Series, error) {
+	var series detectors.Series
+
+	daily, err := costProvider.DailyCosts(ctx, days)
+	if err != nil {
+		return series, fmt.Errorf("failed to get %s daily costs: %v", costProvider.Name(), err)
+	}
+	mtd, err := costProvider.MTDCosts(ctx)
+	if err != nil {
+		return series, fmt.Errorf("failed to get %s MTD costs: %v", costProvider.Name(), err)
+	}
+	dimensional, err := costProvider.DimensionalCosts(ctx, days)
+	if err != nil {
+		return series, fmt.Errorf("failed to get %s dimensional costs: %v", costProvider.Name(), err)
+	}
+
+	series.Daily = daily
+	series.MTD = mtd
+	series.Composite = utils.TagVendor(processor.ProcessCompositeData(daily, mtd, dimensional), costProvider.Name())
+	return series, nil
+}
+
+// vendorSummary summarizes a vendor's series and the anomalies raised on it
+func vendorSummary(processor *utils.DataProcessor, vendor string, series detectors.Series, anomalies []models.Anomaly) models.Summary {
+	var vendorAnomalies []models.Anomaly
+	for _, anomaly := range anomalies {
+		if anomaly.Vendor == vendor {
+			vendorAnomalies = append(vendorAnomalies, anomaly)
+		}
+	}
+	return processor.GenerateSummary(series.Composite, series.Daily, series.MTD, vendorAnomalies)
+}
@@ -0,0 +1,330 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// costMetric is the Cost Explorer metric read as cost; unblended cost matches the invoice
+const costMetric = "UnblendedCost"
+
+// usageMetric is the Cost Explorer metric read as usage amount
+const usageMetric = "UsageQuantity"
+
+// dailyHistoryMonths is how far back Cost Explorer serves daily granularity
+const dailyHistoryMonths = 13
+
+// CostExplorerAPI is the subset of the Cost Explorer client used by the provider
+type CostExplorerAPI interface {
+	GetCostAndUsage(ctx context.Context, params *costexplorer.GetCostAndUsageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error)
+}
+
+// Provider is the provider.CostProvider for AWS, reading the Cost Explorer
+// GetCostAndUsage API. SERVICE maps to Service, USAGE_TYPE to SKU, LINKED_ACCOUNT
+// to ProjectID and the usage type's region prefix to Region.
+type Provider struct {
+	client CostExplorerAPI
+	now    func() time.Time
+}
+
+// NewProvider creates a new AWS provider using the default credential chain
+func NewProvider(ctx context.Context) (*Provider, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %v", err)
+	}
+	return NewProviderWithClient(costexplorer.NewFromConfig(cfg)), nil
+}
+
+// NewProviderWithClient creates a new AWS provider reading from the given client
+func NewProviderWithClient(client CostExplorerAPI) *Provider {
+	return &Provider{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Name returns the vendor name
+func (p *Provider) Name() string {
+	return "aws"
+}
+
+// DailyCosts returns the total cost per day over the last days days, newest first
+func (p *Provider) DailyCosts(ctx context.Context, days int) ([]models.DailyCost, error) {
+	log.Println("📊 Fetching AWS daily cost data...")
+
+	start, end := p.dailyPeriod(days)
+	var dailyCosts []models.DailyCost
+	err := p.query(ctx, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &types.DateInterval{Start: awssdk.String(start), End: awssdk.String(end)},
+		Granularity: types.GranularityDaily,
+		Metrics:     []string{costMetric},
+	}, func(result types.ResultByTime) error {
+		amount, currency, err := metricAmount(result.Total, costMetric)
+		if err != nil {
+			return err
+		}
+		dailyCosts = append(dailyCosts, models.DailyCost{
+			Date:      awssdk.ToString(result.TimePeriod.Start),
+			TotalCost: amount,
+			Currency:  currency,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	models.SortDailyCostsDesc(dailyCosts)
+	log.Printf("✅ Retrieved %d AWS daily cost records", len(dailyCosts))
+	return dailyCosts, nil
+}
+
+// MTDCosts returns the cost of the current and previous six months, newest month first
+func (p *Provider) MTDCosts(ctx context.Context) ([]models.MTDCost, error) {
+	log.Println("📊 Fetching AWS MTD cost data...")
+
+	today := p.today()
+	firstOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := firstOfMonth.AddDate(0, -6, 0)
+	end := today.AddDate(0, 0, 1)
+
+	var mtdCosts []models.MTDCost
+	err := p.query(ctx, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &types.DateInterval{Start: awssdk.String(start.Format("2006-01-02")), End: awssdk.String(end.Format("2006-01-02"))},
+		Granularity: types.GranularityMonthly,
+		Metrics:     []string{costMetric},
+	}, func(result types.ResultByTime) error {
+		amount, currency, err := metricAmount(result.Total, costMetric)
+		if err != nil {
+			return err
+		}
+		periodStart, err := time.Parse("2006-01-02", awssdk.ToString(result.TimePeriod.Start))
+		if err != nil {
+			return fmt.Errorf("unexpected Cost Explorer period %q: %v", awssdk.ToString(result.TimePeriod.Start), err)
+		}
+		periodEnd, err := time.Parse("2006-01-02", awssdk.ToString(result.TimePeriod.End))
+		if err != nil {
+			return fmt.Errorf("unexpected Cost Explorer period %q: %v", awssdk.ToString(result.TimePeriod.End), err)
+		}
+		mtdCosts = append(mtdCosts, models.MTDCost{
+			Month:    periodStart.Format("2006-01"),
+			Cost:     amount,
+			Days:     int(periodEnd.Sub(periodStart).Hours() / 24),
+			Currency: currency,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(mtdCosts, func(i, j int) bool {
+		return mtdCosts[i].Month > mtdCosts[j].Month
+	})
+	log.Printf("✅ Retrieved %d AWS MTD cost records", len(mtdCosts))
+	return mtdCosts, nil
+}
+
+// DimensionalCosts returns cost rows per day, account, service and usage type over
+// the last days days. Cost Explorer groups by at most two dimensions per request,
+// so accounts are listed first and each is queried by service and usage type.
+func (p *Provider) DimensionalCosts(ctx context.Context, days int) ([]models.CostData, error) {
+	log.Println("📊 Fetching AWS dimensional cost data...")
+
+	start, end := p.dailyPeriod(days)
+	period := &types.DateInterval{Start: awssdk.String(start), End: awssdk.String(end)}
+
+	accountNames := make(map[string]string)
+	err := p.queryPages(ctx, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  period,
+		Granularity: types.GranularityMonthly,
+		Metrics:     []string{costMetric},
+		GroupBy:     []types.GroupDefinition{{Type: types.GroupDefinitionTypeDimension, Key: awssdk.String("LINKED_ACCOUNT")}},
+	}, func(output *costexplorer.GetCostAndUsageOutput) error {
+		for _, attributes := range output.DimensionValueAttributes {
+			accountNames[awssdk.ToString(attributes.Value)] = attributes.Attributes["description"]
+		}
+		for _, result := range output.ResultsByTime {
+			for _, group := range result.Groups {
+				if len(group.Keys) > 0 {
+					if _, exists := accountNames[group.Keys[0]]; !exists {
+						accountNames[group.Keys[0]] = ""
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]string, 0, len(accountNames))
+	for account := range accountNames {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	var costs []models.CostData
+	for _, account := range accounts {
+		err := p.query(ctx, &costexplorer.GetCostAndUsageInput{
+			TimePeriod:  period,
+			Granularity: types.GranularityDaily,
+			Metrics:     []string{costMetric, usageMetric},
+			Filter: &types.Expression{Dimensions: &types.DimensionValues{
+				Key:    types.DimensionLinkedAccount,
+				Values: []string{account},
+			}},
+			GroupBy: []types.GroupDefinition{
+				{Type: types.GroupDefinitionTypeDimension, Key: awssdk.String("SERVICE")},
+				{Type: types.GroupDefinitionTypeDimension, Key: awssdk.String("USAGE_TYPE")},
+			},
+		}, func(result types.ResultByTime) error {
+			date := awssdk.ToString(result.TimePeriod.Start)
+			for _, group := range result.Groups {
+				if len(group.Keys) < 2 {
+					continue
+				}
+				cost, currency, err := metricAmount(group.Metrics, costMetric)
+				if err != nil {
+					return err
+				}
+				usage, unit, err := metricAmount(group.Metrics, usageMetric)
+				if err != nil {
+					return err
+				}
+				costs = append(costs, models.CostData{
+					Date:        date,
+					Service:     group.Keys[0],
+					SKU:         group.Keys[1],
+					ProjectID:   account,
+					ProjectName: accountNames[account],
+					Region:      UsageTypeRegion(group.Keys[1]),
+					Cost:        cost,
+					UsageAmount: usage,
+					UsageUnit:   unit,
+					Currency:    currency,
+					CostType:    "usage",
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("✅ Retrieved %d AWS dimensional cost records across %d accounts", len(costs), len(accounts))
+	return costs, nil
+}
+
+// query calls fn for each time period of every page of the request
+func (p *Provider) query(ctx context.Context, input *costexplorer.GetCostAndUsageInput, fn func(types.ResultByTime) error) error {
+	return p.queryPages(ctx, input, func(output *costexplorer.GetCostAndUsageOutput) error {
+		for _, result := range output.ResultsByTime {
+			if err := fn(result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// queryPages calls fn for every page of the request, following NextPageToken
+func (p *Provider) queryPages(ctx context.Context, input *costexplorer.GetCostAndUsageInput, fn func(*costexplorer.GetCostAndUsageOutput) error) error {
+	request := *input
+	for {
+		output, err := p.client.GetCostAndUsage(ctx, &request)
+		if err != nil {
+			return fmt.Errorf("failed to query Cost Explorer: %v", err)
+		}
+		if err := fn(output); err != nil {
+			return err
+		}
+		if awssdk.ToString(output.NextPageToken) == "" {
+			return nil
+		}
+		request.NextPageToken = output.NextPageToken
+	}
+}
+
+// dailyPeriod returns the Cost Explorer period (end exclusive) covering the last
+// days days up to today, clamped to the history Cost Explorer serves at daily granularity
+func (p *Provider) dailyPeriod(days int) (string, string) {
+	today := p.today()
+	start := today.AddDate(0, 0, -days)
+	if earliest := today.AddDate(0, -dailyHistoryMonths, 0); start.Before(earliest) {
+		log.Printf("Warning: Cost Explorer serves %d months of daily data; fetching from %s", dailyHistoryMonths, earliest.Format("2006-01-02"))
+		start = earliest
+	}
+	return start.Format("2006-01-02"), today.AddDate(0, 0, 1).Format("2006-01-02")
+}
+
+// today returns the current UTC date, the timezone Cost Explorer reports in
+func (p *Provider) today() time.Time {
+	now := p.now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// metricAmount parses a Cost Explorer metric value, returning its amount and unit
+func metricAmount(metrics map[string]types.MetricValue, name string) (float64, string, error) {
+	value, exists := metrics[name]
+	if !exists || value.Amount == nil {
+		return 0, "", nil
+	}
+	amount, err := strconv.ParseFloat(awssdk.ToString(value.Amount), 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid %s amount %q: %v", name, awssdk.ToString(value.Amount), err)
+	}
+	return amount, awssdk.ToString(value.Unit), nil
+}
+
+// usageTypeRegions maps the region prefix of AWS usage types to region codes
+var usageTypeRegions = map[string]string{
+	"USE1": "us-east-1",
+	"USE2": "us-east-2",
+	"USW1": "us-west-1",
+	"USW2": "us-west-2",
+	"CAN1": "ca-central-1",
+	"EUC1": "eu-central-1",
+	"EUW1": "eu-west-1",
+	"EUW2": "eu-west-2",
+	"EUW3": "eu-west-3",
+	"EUN1": "eu-north-1",
+	"EUS1": "eu-south-1",
+	"APN1": "ap-northeast-1",
+	"APN2": "ap-northeast-2",
+	"APN3": "ap-northeast-3",
+	"APS1": "ap-southeast-1",
+	"APS2": "ap-southeast-2",
+	"APS3": "ap-south-1",
+	"SAE1": "sa-east-1",
+	"MES1": "me-south-1",
+	"AFS1": "af-south-1",
+}
+
+// UsageTypeRegion returns the region encoded in an AWS usage type's prefix
+// (USE1-BoxUsage:t3.micro is us-east-1). Usage types without a known prefix are
+// us-east-1 or global; they are reported as "global".
+func UsageTypeRegion(usageType string) string {
+	prefix, _, found := strings.Cut(usageType, "-")
+	if !found {
+		return "global"
+	}
+	if region, known := usageTypeRegions[prefix]; known {
+		return region
+	}
+	return "global"
+}
@@ -0,0 +1,227 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// fakeCostExplorer serves canned pages, recording every request it receives
+type fakeCostExplorer struct {
+	respond  func(input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error)
+	requests []costexplorer.GetCostAndUsageInput
+}
+
+func (f *fakeCostExplorer) GetCostAndUsage(ctx context.Context, params *costexplorer.GetCostAndUsageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error) {
+	f.requests = append(f.requests, *params)
+	return f.respond(params)
+}
+
+// pages serves the outputs in turn, chaining them with page tokens
+func pages(outputs ...*costexplorer.GetCostAndUsageOutput) func(*costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+	return func(input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+		page, _ := strconv.Atoi(strings.TrimPrefix(awssdk.ToString(input.NextPageToken), "page-"))
+		output := *outputs[page]
+		if page+1 < len(outputs) {
+			output.NextPageToken = awssdk.String("page-" + strconv.Itoa(page+1))
+		}
+		return &output, nil
+	}
+}
+
+// period returns a result for the period starting on start and ending on end
+func period(start, end string, metrics map[string]types.MetricValue, groups ...types.Group) types.ResultByTime {
+	return types.ResultByTime{
+		TimePeriod: &types.DateInterval{Start: awssdk.String(start), End: awssdk.String(end)},
+		Total:      metrics,
+		Groups:     groups,
+	}
+}
+
+// cost returns Cost Explorer metrics holding an unblended cost and optionally a usage quantity
+func cost(amount, usage string) map[string]types.MetricValue {
+	metrics := map[string]types.MetricValue{costMetric: {Amount: awssdk.String(amount), Unit: awssdk.String("USD")}}
+	if usage != "" {
+		metrics[usageMetric] = types.MetricValue{Amount: awssdk.String(usage), Unit: awssdk.String("Hrs")}
+	}
+	return metrics
+}
+
+// newTestProvider returns a provider reading from client on 2024-05-15
+func newTestProvider(client CostExplorerAPI) *Provider {
+	provider := NewProviderWithClient(client)
+	provider.now = func() time.Time { return time.Date(2024, 5, 15, 18, 0, 0, 0, time.UTC) }
+	return provider
+}
+
+func TestDailyCostsFollowsPageTokens(t *testing.T) {
+	client := &fakeCostExplorer{respond: pages(
+		&costexplorer.GetCostAndUsageOutput{ResultsByTime: []types.ResultByTime{
+			period("2024-05-13", "2024-05-14", cost("10.5", "")),
+			period("2024-05-14", "2024-05-15", cost("20", "")),
+		}},
+		&costexplorer.GetCostAndUsageOutput{ResultsByTime: []types.ResultByTime{
+			period("2024-05-15", "2024-05-16", cost("30.25", "")),
+		}},
+	)}
+
+	daily, err := newTestProvider(client).DailyCosts(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("DailyCosts: %v", err)
+	}
+	if len(client.requests) != 2 {
+		t.Fatalf("made %d requests, want 2", len(client.requests))
+	}
+	if token := awssdk.ToString(client.requests[1].NextPageToken); token != "page-1" {
+		t.Errorf("second request token = %q, want page-1", token)
+	}
+	request := client.requests[0]
+	if request.Granularity != types.GranularityDaily || awssdk.ToString(request.TimePeriod.Start) != "2024-05-12" || awssdk.ToString(request.TimePeriod.End) != "2024-05-16" {
+		t.Errorf("request = %s from %s to %s, want DAILY from 2024-05-12 to 2024-05-16", request.Granularity, awssdk.ToString(request.TimePeriod.Start), awssdk.ToString(request.TimePeriod.End))
+	}
+
+	want := []struct {
+		date string
+		cost float64
+	}{{"2024-05-15", 30.25}, {"2024-05-14", 20}, {"2024-05-13", 10.5}}
+	if len(daily) != len(want) {
+		t.Fatalf("got %d days, want %d", len(daily), len(want))
+	}
+	for i, w := range want {
+		if daily[i].Date != w.date || daily[i].TotalCost != w.cost || daily[i].Currency != "USD" {
+			t.Errorf("day %d = %+v, want %s costing %v USD", i, daily[i], w.date, w.cost)
+		}
+	}
+}
+
+func TestDailyPeriodIsClampedToDailyHistory(t *testing.T) {
+	start, end := newTestProvider(nil).dailyPeriod(1000)
+	if start != "2023-04-15" || end != "2024-05-16" {
+		t.Errorf("period = %s to %s, want 2023-04-15 to 2024-05-16", start, end)
+	}
+}
+
+func TestMTDCostsCountsDaysInEachPeriod(t *testing.T) {
+	client := &fakeCostExplorer{respond: pages(&costexplorer.GetCostAndUsageOutput{ResultsByTime: []types.ResultByTime{
+		period("2024-04-01", "2024-05-01", cost("3000", "")),
+		period("2024-05-01", "2024-05-16", cost("1500", "")),
+	}})}
+
+	mtd, err := newTestProvider(client).MTDCosts(context.Background())
+	if err != nil {
+		t.Fatalf("MTDCosts: %v", err)
+	}
+	if request := client.requests[0]; request.Granularity != types.GranularityMonthly || awssdk.ToString(request.TimePeriod.Start) != "2023-11-01" {
+		t.Errorf("request = %s from %s, want MONTHLY from 2023-11-01", request.Granularity, awssdk.ToString(request.TimePeriod.Start))
+	}
+	if len(mtd) != 2 {
+		t.Fatalf("got %d months, want 2", len(mtd))
+	}
+	if mtd[0].Month != "2024-05" || mtd[0].Days != 15 || mtd[0].Cost != 1500 {
+		t.Errorf("current month = %+v, want 2024-05 with 15 days costing 1500", mtd[0])
+	}
+	if mtd[1].Month != "2024-04" || mtd[1].Days != 30 || mtd[1].Cost != 3000 {
+		t.Errorf("previous month = %+v, want 2024-04 with 30 days costing 3000", mtd[1])
+	}
+}
+
+func TestDimensionalCostsMapsAWSDimensions(t *testing.T) {
+	accounts := pages(
+		&costexplorer.GetCostAndUsageOutput{
+			DimensionValueAttributes: []types.DimensionValuesWithAttributes{{Value: awssdk.String("111"), Attributes: map[string]string{"description": "Production"}}},
+			ResultsByTime:            []types.ResultByTime{period("2024-05-14", "2024-05-16", nil, types.Group{Keys: []string{"111"}})},
+		},
+		&costexplorer.GetCostAndUsageOutput{
+			ResultsByTime: []types.ResultByTime{period("2024-05-14", "2024-05-16", nil, types.Group{Keys: []string{"222"}})},
+		},
+	)
+	client := &fakeCostExplorer{}
+	client.respond = func(input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+		if awssdk.ToString(input.GroupBy[0].Key) == "LINKED_ACCOUNT" {
+			return accounts(input)
+		}
+		account := input.Filter.Dimensions.Values[0]
+		return &costexplorer.GetCostAndUsageOutput{ResultsByTime: []types.ResultByTime{
+			period("2024-05-15", "2024-05-16", nil,
+				types.Group{Keys: []string{"Amazon EC2", "USE1-BoxUsage:t3.micro"}, Metrics: cost("1.5", "24")},
+				types.Group{Keys: []string{"AWS Lambda", "Request-" + account}, Metrics: cost("0.2", "")},
+			),
+		}}, nil
+	}
+
+	costs, err := newTestProvider(client).DimensionalCosts(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("DimensionalCosts: %v", err)
+	}
+	if len(client.requests) != 4 {
+		t.Errorf("made %d requests, want 2 account pages and one query per account", len(client.requests))
+	}
+	if len(costs) != 4 {
+		t.Fatalf("got %d rows, want 2 per account", len(costs))
+	}
+
+	ec2 := costs[0]
+	if ec2.Date != "2024-05-15" || ec2.Service != "Amazon EC2" || ec2.SKU != "USE1-BoxUsage:t3.micro" || ec2.ProjectID != "111" || ec2.ProjectName != "Production" || ec2.Region != "us-east-1" {
+		t.Errorf("EC2 row = %+v, want service, usage type, account and region mapped", ec2)
+	}
+	if ec2.Cost != 1.5 || ec2.UsageAmount != 24 || ec2.UsageUnit != "Hrs" || ec2.Currency != "USD" {
+		t.Errorf("EC2 row = %+v, want 1.5 USD for 24 Hrs", ec2)
+	}
+	if lambda := costs[3]; lambda.ProjectID != "222" || lambda.ProjectName != "" || lambda.Region != "global" {
+		t.Errorf("second account's Lambda row = %+v, want account 222 in global", lambda)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(*costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error)
+		err     string
+	}{
+		{"request fails", func(*costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+			return nil, errors.New("ThrottlingException")
+		}, "ThrottlingException"},
+		{"malformed amount", pages(&costexplorer.GetCostAndUsageOutput{ResultsByTime: []types.ResultByTime{
+			period("2024-05-15", "2024-05-16", cost("ten", "")),
+		}}), "invalid UnblendedCost amount"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestProvider(&fakeCostExplorer{respond: tt.respond}).DailyCosts(context.Background(), 1)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUsageTypeRegion(t *testing.T) {
+	tests := map[string]string{
+		"USE1-BoxUsage:t3.micro":      "us-east-1",
+		"EUW2-DataTransfer-Out-Bytes": "eu-west-2",
+		"APS3-TimedStorage-ByteHrs":   "ap-south-1",
+		"BoxUsage:t3.micro":           "global",
+		"XYZ9-Requests":               "global",
+	}
+	for usageType, want := range tests {
+		if got := UsageTypeRegion(usageType); got != want {
+			t.Errorf("UsageTypeRegion(%q) = %q, want %q", usageType, got, want)
+		}
+	}
+}
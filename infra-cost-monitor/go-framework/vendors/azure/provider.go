@@ -0,0 +1,434 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// queryAPIVersion is the Cost Management Query API version used
+const queryAPIVersion = "2023-03-01"
+
+// managementScope is the OAuth scope of the Azure Resource Manager API
+const managementScope = "https://management.azure.com/.default"
+
+// maxQueryDays is the longest period a single Cost Management query accepts
+const maxQueryDays = 365
+
+// maxThrottleRetries is how many times a throttled query is retried
+const maxThrottleRetries = 5
+
+// throttleHeaders are the Cost Management headers carrying the seconds to wait
+// after throttling, most specific first; Retry-After is the generic fallback
+var throttleHeaders = []string{
+	"x-ms-ratelimit-microsoft.costmanagement-qpu-retry-after",
+	"x-ms-ratelimit-microsoft.costmanagement-entity-retry-after",
+	"x-ms-ratelimit-microsoft.costmanagement-tenant-retry-after",
+	"Retry-After",
+}
+
+// HTTPClient is the subset of *http.Client used to call the Query API
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TokenFunc returns a bearer token for the Azure Resource Manager API
+type TokenFunc func(ctx context.Context) (string, error)
+
+// Provider is the provider.CostProvider for Azure, reading the Cost Management
+// Query API for a scope such as /subscriptions/<id>. MeterCategory maps to
+// Service, MeterSubCategory to SKU and ResourceGroupName to ProjectID.
+type Provider struct {
+	scope  string
+	client HTTPClient
+	token  TokenFunc
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewProvider creates a new Azure provider for the scope using the default credential chain
+func NewProvider(scope string) (*Provider, error) {
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Azure credentials: %v", err)
+	}
+
+	token := func(ctx context.Context) (string, error) {
+		accessToken, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{managementScope}})
+		if err != nil {
+			return "", err
+		}
+		return accessToken.Token, nil
+	}
+	return NewProviderWithClient(scope, &http.Client{Timeout: 60 * time.Second}, token), nil
+}
+
+// NewProviderWithClient creates a new Azure provider using the given HTTP client and token source
+func NewProviderWithClient(scope string, client HTTPClient, token TokenFunc) *Provider {
+	return &Provider{
+		scope:  strings.TrimSuffix(scope, "/"),
+		client: client,
+		token:  token,
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+// Name returns the vendor name
+func (p *Provider) Name() string {
+	return "azure"
+}
+
+// queryRequest is the body of a Cost Management query
+type queryRequest struct {
+	Type       string       `json:"type"`
+	Timeframe  string       `json:"timeframe"`
+	TimePeriod queryPeriod  `json:"timePeriod"`
+	Dataset    queryDataset `json:"dataset"`
+}
+
+type queryPeriod struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type queryDataset struct {
+	Granularity string                      `json:"granularity"`
+	Aggregation map[string]queryAggregation `json:"aggregation"`
+	Grouping    []queryGrouping             `json:"grouping,omitempty"`
+	Filter      *queryFilter                `json:"filter,omitempty"`
+}
+
+type queryAggregation struct {
+	Name     string `json:"name"`
+	Function string `json:"function"`
+}
+
+type queryGrouping struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type queryFilter struct {
+	Dimensions queryDimension `json:"dimensions"`
+}
+
+type queryDimension struct {
+	Name     string   `json:"name"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+// queryResponse is a page of Cost Management query results
+type queryResponse struct {
+	Properties struct {
+		NextLink string `json:"nextLink"`
+		Columns  []struct {
+			Name string `json:"name"`
+		} `json:"columns"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"properties"`
+}
+
+// queryRow is a result row keyed by column name
+type queryRow map[string]interface{}
+
+// DailyCosts returns the total cost per day over the last days days, newest first
+func (p *Provider) DailyCosts(ctx context.Context, days int) ([]models.DailyCost, error) {
+	log.Println("📊 Fetching Azure daily cost data...")
+
+	from, to := p.period(days)
+	var dailyCosts []models.DailyCost
+	err := p.query(ctx, p.request("Daily", from, to, nil, nil), func(row queryRow) error {
+		date, err := usageDate(row["UsageDate"])
+		if err != nil {
+			return err
+		}
+		dailyCosts = append(dailyCosts, models.DailyCost{
+			Date:      date,
+			TotalCost: number(row["Cost"]),
+			Currency:  text(row["Currency"]),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	models.SortDailyCostsDesc(dailyCosts)
+	log.Printf("✅ Retrieved %d Azure daily cost records", len(dailyCosts))
+	return dailyCosts, nil
+}
+
+// MTDCosts returns the cost of the current and previous six months, newest month first
+func (p *Provider) MTDCosts(ctx context.Context) ([]models.MTDCost, error) {
+	log.Println("📊 Fetching Azure MTD cost data...")
+
+	today := p.today()
+	from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -6, 0)
+
+	monthly := make(map[string]*models.MTDCost)
+	err := p.query(ctx, p.request("Daily", from, today, nil, nil), func(row queryRow) error {
+		date, err := usageDate(row["UsageDate"])
+		if err != nil {
+			return err
+		}
+		month := date[:7]
+		mtd, exists := monthly[month]
+		if !exists {
+			mtd = &models.MTDCost{Month: month, Currency: text(row["Currency"])}
+			monthly[month] = mtd
+		}
+		mtd.Cost += number(row["Cost"])
+		mtd.Days++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mtdCosts := make([]models.MTDCost, 0, len(monthly))
+	for _, mtd := range monthly {
+		mtdCosts = append(mtdCosts, *mtd)
+	}
+	sort.Slice(mtdCosts, func(i, j int) bool {
+		return mtdCosts[i].Month > mtdCosts[j].Month
+	})
+	log.Printf("✅ Retrieved %d Azure MTD cost records", len(mtdCosts))
+	return mtdCosts, nil
+}
+
+// DimensionalCosts returns cost rows per day, resource group, meter category and
+// meter subcategory over the last days days. Queries group by at most two
+// dimensions, so resource groups are listed first and each is queried by meter.
+func (p *Provider) DimensionalCosts(ctx context.Context, days int) ([]models.CostData, error) {
+	log.Println("📊 Fetching Azure dimensional cost data...")
+
+	from, to := p.period(days)
+	var resourceGroups []string
+	byResourceGroup := []queryGrouping{{Type: "Dimension", Name: "ResourceGroupName"}}
+	err := p.query(ctx, p.request("None", from, to, byResourceGroup, nil), func(row queryRow) error {
+		resourceGroups = append(resourceGroups, text(row["ResourceGroupName"]))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(resourceGroups)
+
+	byMeter := []queryGrouping{
+		{Type: "Dimension", Name: "MeterCategory"},
+		{Type: "Dimension", Name: "MeterSubCategory"},
+	}
+	var costs []models.CostData
+	for _, resourceGroup := range resourceGroups {
+		filter := &queryFilter{Dimensions: queryDimension{Name: "ResourceGroupName", Operator: "In", Values: []string{resourceGroup}}}
+		err := p.query(ctx, p.request("Daily", from, to, byMeter, filter), func(row queryRow) error {
+			date, err := usageDate(row["UsageDate"])
+			if err != nil {
+				return err
+			}
+			costs = append(costs, models.CostData{
+				Date:        date,
+				Service:     text(row["MeterCategory"]),
+				SKU:         text(row["MeterSubCategory"]),
+				ProjectID:   resourceGroup,
+				ProjectName: resourceGroup,
+				Cost:        number(row["Cost"]),
+				Currency:    text(row["Currency"]),
+				CostType:    "usage",
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("✅ Retrieved %d Azure dimensional cost records across %d resource groups", len(costs), len(resourceGroups))
+	return costs, nil
+}
+
+// request builds an actual-cost query summing Cost over the custom period
+func (p *Provider) request(granularity string, from, to time.Time, grouping []queryGrouping, filter *queryFilter) queryRequest {
+	return queryRequest{
+		Type:      "ActualCost",
+		Timeframe: "Custom",
+		TimePeriod: queryPeriod{
+			From: from.Format("2006-01-02") + "T00:00:00Z",
+			To:   to.Format("2006-01-02") + "T23:59:59Z",
+		},
+		Dataset: queryDataset{
+			Granularity: granularity,
+			Aggregation: map[string]queryAggregation{
+				"totalCost": {Name: "Cost", Function: "Sum"},
+			},
+			Grouping: grouping,
+			Filter:   filter,
+		},
+	}
+}
+
+// query runs the query and calls fn for every row of every page, following nextLink
+func (p *Provider) query(ctx context.Context, request queryRequest, fn func(queryRow) error) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://management.azure.com%s/providers/Microsoft.CostManagement/query?api-version=%s", p.scope, queryAPIVersion)
+	for url != "" {
+		page, err := p.post(ctx, url, body)
+		if err != nil {
+			return err
+		}
+
+		columns := make([]string, len(page.Properties.Columns))
+		for i, column := range page.Properties.Columns {
+			columns[i] = column.Name
+		}
+		for _, values := range page.Properties.Rows {
+			row := make(queryRow, len(columns))
+			for i, value := range values {
+				if i < len(columns) {
+					row[columns[i]] = value
+				}
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		url = page.Properties.NextLink
+	}
+	return nil
+}
+
+// post sends one query page, waiting out throttling as the response headers direct
+func (p *Provider) post(ctx context.Context, url string, body []byte) (*queryResponse, error) {
+	for attempt := 0; ; attempt++ {
+		token, err := p.token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Azure token: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query Cost Management: %v", err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Cost Management response: %v", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			if attempt >= maxThrottleRetries {
+				return nil, fmt.Errorf("Cost Management query still throttled after %d retries", attempt)
+			}
+			wait := throttleWait(resp.Header, attempt)
+			log.Printf("⏳ Cost Management throttled the query; retrying in %s", wait)
+			if err := p.sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Cost Management query returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+		}
+
+		var page queryResponse
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode Cost Management response: %v", err)
+		}
+		return &page, nil
+	}
+}
+
+// throttleWait returns how long to wait before retrying a throttled query: the
+// first throttling header present, or an exponential backoff without one
+func throttleWait(header http.Header, attempt int) time.Duration {
+	for _, name := range throttleHeaders {
+		if seconds, err := strconv.Atoi(header.Get(name)); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return time.Duration(5<<attempt) * time.Second
+}
+
+// sleepContext waits for d or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// period returns the query period covering the last days days up to today,
+// clamped to the longest period a single query accepts
+func (p *Provider) period(days int) (time.Time, time.Time) {
+	if days > maxQueryDays {
+		log.Printf("Warning: Cost Management queries cover at most %d days; fetching the last %d", maxQueryDays, maxQueryDays)
+		days = maxQueryDays
+	}
+	today := p.today()
+	return today.AddDate(0, 0, -days), today
+}
+
+// today returns the current UTC date
+func (p *Provider) today() time.Time {
+	now := p.now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// usageDate converts a UsageDate column (a number such as 20240115) to YYYY-MM-DD
+func usageDate(value interface{}) (string, error) {
+	digits := strconv.FormatFloat(number(value), 'f', 0, 64)
+	date, err := time.Parse("20060102", digits)
+	if err != nil {
+		return "", fmt.Errorf("unexpected UsageDate %v", value)
+	}
+	return date.Format("2006-01-02"), nil
+}
+
+// number reads a numeric column
+func number(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		parsed, _ := strconv.ParseFloat(v, 64)
+		return parsed
+	}
+	return 0
+}
+
+// text reads a string column
+func text(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
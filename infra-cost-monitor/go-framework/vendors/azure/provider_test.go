@@ -0,0 +1,271 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// cannedResponse is a canned Query API response
+type cannedResponse struct {
+	status int
+	header http.Header
+	body   string
+}
+
+// fakeHTTPClient answers requests from handle, recording each request and its body
+type fakeHTTPClient struct {
+	handle   func(req *http.Request, body queryRequest) cannedResponse
+	requests []*http.Request
+	bodies   []queryRequest
+}
+
+func (f *fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	var body queryRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	f.requests = append(f.requests, req)
+	f.bodies = append(f.bodies, body)
+
+	response := f.handle(req, body)
+	header := response.header
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		StatusCode: response.status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(response.body)),
+	}, nil
+}
+
+// sequence answers the nth request with the nth response
+func sequence(responses ...cannedResponse) func(*http.Request, queryRequest) cannedResponse {
+	calls := 0
+	return func(*http.Request, queryRequest) cannedResponse {
+		calls++
+		return responses[calls-1]
+	}
+}
+
+// page returns a 200 response holding a page of query results
+func page(nextLink string, columns []string, rows ...[]interface{}) cannedResponse {
+	var response queryResponse
+	response.Properties.NextLink = nextLink
+	for _, name := range columns {
+		response.Properties.Columns = append(response.Properties.Columns, struct {
+			Name string `json:"name"`
+		}{name})
+	}
+	response.Properties.Rows = rows
+	body, _ := json.Marshal(response)
+	return cannedResponse{status: http.StatusOK, body: string(body)}
+}
+
+// newTestProvider returns a provider for a subscription reading from client on
+// 2024-05-15, recording the waits instead of sleeping
+func newTestProvider(client HTTPClient) (*Provider, *[]time.Duration) {
+	provider := NewProviderWithClient("/subscriptions/sub-1/", client, func(context.Context) (string, error) {
+		return "token-1", nil
+	})
+	provider.now = func() time.Time { return time.Date(2024, 5, 15, 18, 0, 0, 0, time.UTC) }
+	var waits []time.Duration
+	provider.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return provider, &waits
+}
+
+var dailyColumns = []string{"Cost", "UsageDate", "Currency"}
+
+func TestDailyCostsFollowsNextLink(t *testing.T) {
+	client := &fakeHTTPClient{handle: sequence(
+		page("https://management.azure.com/next?page=2", dailyColumns,
+			[]interface{}{10.5, 20240513.0, "EUR"},
+			[]interface{}{20.0, 20240514.0, "EUR"},
+		),
+		page("", dailyColumns, []interface{}{"30.25", 20240515.0, "EUR"}),
+	)}
+	provider, _ := newTestProvider(client)
+
+	daily, err := provider.DailyCosts(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("DailyCosts: %v", err)
+	}
+	if len(client.requests) != 2 {
+		t.Fatalf("made %d requests, want 2", len(client.requests))
+	}
+	first := client.requests[0]
+	if first.URL.Path != "/subscriptions/sub-1/providers/Microsoft.CostManagement/query" || first.URL.Query().Get("api-version") != queryAPIVersion {
+		t.Errorf("first request URL = %s, want the subscription's query endpoint", first.URL)
+	}
+	if auth := first.Header.Get("Authorization"); auth != "Bearer token-1" {
+		t.Errorf("Authorization = %q, want Bearer token-1", auth)
+	}
+	if got := client.requests[1].URL.String(); got != "https://management.azure.com/next?page=2" {
+		t.Errorf("second request URL = %s, want the nextLink", got)
+	}
+	body := client.bodies[0]
+	if body.Type != "ActualCost" || body.Dataset.Granularity != "Daily" || body.TimePeriod.From != "2024-05-12T00:00:00Z" || body.TimePeriod.To != "2024-05-15T23:59:59Z" {
+		t.Errorf("query = %+v, want a daily actual-cost query from 2024-05-12 through 2024-05-15", body)
+	}
+
+	want := []struct {
+		date string
+		cost float64
+	}{{"2024-05-15", 30.25}, {"2024-05-14", 20}, {"2024-05-13", 10.5}}
+	if len(daily) != len(want) {
+		t.Fatalf("got %d days, want %d", len(daily), len(want))
+	}
+	for i, w := range want {
+		if daily[i].Date != w.date || daily[i].TotalCost != w.cost || daily[i].Currency != "EUR" {
+			t.Errorf("day %d = %+v, want %s costing %v EUR", i, daily[i], w.date, w.cost)
+		}
+	}
+}
+
+func TestMTDCostsSumsDaysPerMonth(t *testing.T) {
+	client := &fakeHTTPClient{handle: sequence(page("", dailyColumns,
+		[]interface{}{100.0, 20240429.0, "EUR"},
+		[]interface{}{50.0, 20240430.0, "EUR"},
+		[]interface{}{10.0, 20240501.0, "EUR"},
+	))}
+	provider, _ := newTestProvider(client)
+
+	mtd, err := provider.MTDCosts(context.Background())
+	if err != nil {
+		t.Fatalf("MTDCosts: %v", err)
+	}
+	if from := client.bodies[0].TimePeriod.From; from != "2023-11-01T00:00:00Z" {
+		t.Errorf("query from %s, want 2023-11-01T00:00:00Z", from)
+	}
+	if len(mtd) != 2 || mtd[0].Month != "2024-05" || mtd[0].Cost != 10 || mtd[0].Days != 1 || mtd[1].Month != "2024-04" || mtd[1].Cost != 150 || mtd[1].Days != 2 {
+		t.Errorf("MTD costs = %+v, want 2024-05 costing 10 over 1 day, then 2024-04 costing 150 over 2", mtd)
+	}
+}
+
+func TestDimensionalCostsMapsMetersAndResourceGroups(t *testing.T) {
+	client := &fakeHTTPClient{}
+	client.handle = func(req *http.Request, body queryRequest) cannedResponse {
+		if body.Dataset.Filter == nil {
+			return page("", []string{"Cost", "ResourceGroupName", "Currency"},
+				[]interface{}{90.0, "rg-web", "EUR"},
+				[]interface{}{10.0, "rg-data", "EUR"},
+			)
+		}
+		resourceGroup := body.Dataset.Filter.Dimensions.Values[0]
+		return page("", []string{"Cost", "UsageDate", "MeterCategory", "MeterSubCategory", "Currency"},
+			[]interface{}{1.5, 20240515.0, "Virtual Machines", "Dv3/DSv3 Series", "EUR"},
+			[]interface{}{0.5, 20240515.0, "Storage", resourceGroup + " disks", "EUR"},
+		)
+	}
+	provider, _ := newTestProvider(client)
+
+	costs, err := provider.DimensionalCosts(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("DimensionalCosts: %v", err)
+	}
+	if len(client.bodies) != 3 {
+		t.Errorf("made %d queries, want one listing resource groups and one per group", len(client.bodies))
+	}
+	if grouping := client.bodies[1].Dataset.Grouping; len(grouping) != 2 || grouping[0].Name != "MeterCategory" || grouping[1].Name != "MeterSubCategory" {
+		t.Errorf("per-group query groups by %+v, want MeterCategory and MeterSubCategory", grouping)
+	}
+	if len(costs) != 4 {
+		t.Fatalf("got %d rows, want 2 per resource group", len(costs))
+	}
+
+	// Resource groups are queried in sorted order
+	vm := costs[0]
+	if vm.Date != "2024-05-15" || vm.Service != "Virtual Machines" || vm.SKU != "Dv3/DSv3 Series" || vm.ProjectID != "rg-data" || vm.Cost != 1.5 || vm.Currency != "EUR" {
+		t.Errorf("first row = %+v, want Virtual Machines/Dv3/DSv3 Series in rg-data costing 1.5 EUR", vm)
+	}
+	if storage := costs[3]; storage.ProjectID != "rg-web" || storage.SKU != "rg-web disks" {
+		t.Errorf("last row = %+v, want rg-web's storage", storage)
+	}
+}
+
+func TestQueryWaitsOutThrottling(t *testing.T) {
+	throttled := func(name, seconds string) cannedResponse {
+		header := make(http.Header)
+		if name != "" {
+			header.Set(name, seconds)
+		}
+		return cannedResponse{status: http.StatusTooManyRequests, header: header, body: "throttled"}
+	}
+
+	tests := []struct {
+		name      string
+		responses []cannedResponse
+		waits     []time.Duration
+	}{
+		{"Cost Management header", []cannedResponse{throttled("x-ms-ratelimit-microsoft.costmanagement-qpu-retry-after", "7"), page("", dailyColumns)}, []time.Duration{7 * time.Second}},
+		{"Retry-After fallback", []cannedResponse{throttled("Retry-After", "3"), page("", dailyColumns)}, []time.Duration{3 * time.Second}},
+		{"exponential backoff without a header", []cannedResponse{throttled("", ""), {status: http.StatusServiceUnavailable}, page("", dailyColumns)}, []time.Duration{5 * time.Second, 10 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeHTTPClient{handle: sequence(tt.responses...)}
+			provider, waits := newTestProvider(client)
+			if _, err := provider.DailyCosts(context.Background(), 1); err != nil {
+				t.Fatalf("DailyCosts: %v", err)
+			}
+			if len(*waits) != len(tt.waits) {
+				t.Fatalf("waited %v, want %v", *waits, tt.waits)
+			}
+			for i := range tt.waits {
+				if (*waits)[i] != tt.waits[i] {
+					t.Errorf("wait %d = %s, want %s", i, (*waits)[i], tt.waits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	alwaysThrottled := func(*http.Request, queryRequest) cannedResponse {
+		return cannedResponse{status: http.StatusTooManyRequests}
+	}
+	tests := []struct {
+		name   string
+		handle func(*http.Request, queryRequest) cannedResponse
+		err    string
+	}{
+		{"throttled past the retry limit", alwaysThrottled, "still throttled after 5 retries"},
+		{"error status", sequence(cannedResponse{status: http.StatusForbidden, body: "AuthorizationFailed\n"}), "status 403: AuthorizationFailed"},
+		{"malformed body", sequence(cannedResponse{status: http.StatusOK, body: "{"}), "failed to decode"},
+		{"malformed usage date", sequence(page("", dailyColumns, []interface{}{1.0, "May 15", "EUR"})), "unexpected UsageDate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, _ := newTestProvider(&fakeHTTPClient{handle: tt.handle})
+			_, err := provider.DailyCosts(context.Background(), 1)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPeriodIsClampedToMaxQueryDays(t *testing.T) {
+	provider, _ := newTestProvider(nil)
+	from, to := provider.period(1000)
+	if days := int(to.Sub(from).Hours() / 24); days != maxQueryDays {
+		t.Errorf("period covers %d days, want %d", days, maxQueryDays)
+	}
+}
@@ -0,0 +1,217 @@
+package allocation
+
+import (
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"os"
+	"sort"
+)
+
+// Allocation methods supported by a Rule
+const (
+	MethodEven         = "even"
+	MethodProportional = "proportional"
+	MethodWeighted     = "weighted"
+)
+
+// Rule describes how a shared cost is redistributed to consuming projects
+type Rule struct {
+	Name           string             `json:"name"`
+	Services       []string           `json:"services"`
+	SourceProjects []string           `json:"source_projects"`
+	Method         string             `json:"method"`
+	Targets        []string           `json:"targets"`
+	Weights        map[string]float64 `json:"weights,omitempty"`
+}
+
+// Result holds the allocated view of the cost data: shared costs replaced by their
+// shares in the target projects, and the direct, allocated and fully loaded cost
+// of each project
+type Result struct {
+	Allocated []models.CostData          `json:"allocated"`
+	Projects  []models.ProjectAllocation `json:"projects"`
+}
+
+// Engine applies allocation rules to cost data
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine creates a new allocation engine after validating the rules
+func NewEngine(rules []Rule) (*Engine, error) {
+	for i, rule := range rules {
+		if len(rule.Services) == 0 && len(rule.SourceProjects) == 0 {
+			return nil, fmt.Errorf("allocation rule %d (%s) must match at least one service or source project", i, rule.Name)
+		}
+		if len(rule.Targets) == 0 {
+			return nil, fmt.Errorf("allocation rule %d (%s) has no targets", i, rule.Name)
+		}
+		switch rule.Method {
+		case MethodEven, MethodProportional:
+		case MethodWeighted:
+			for _, target := range rule.Targets {
+				if rule.Weights[target] < 0 {
+					return nil, fmt.Errorf("allocation rule %d (%s) has a negative weight for %s", i, rule.Name, target)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("allocation rule %d (%s) has unknown method %q", i, rule.Name, rule.Method)
+		}
+	}
+
+	return &Engine{rules: rules}, nil
+}
+
+// LoadRules loads allocation rules from a JSON file
+func LoadRules(filename string) ([]Rule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	err = json.Unmarshal(data, &rules)
+	return rules, err
+}
+
+// Allocate redistributes shared costs across target projects
+func (e *Engine) Allocate(costs []models.CostData) Result {
+	log.Println("🔄 Allocating shared costs...")
+
+	// Direct spend per date and project is the basis for proportional splits
+	directSpend := make(map[string]map[string]float64)
+	projectNames := make(map[string]string)
+	for _, cost := range costs {
+		if e.matchRule(cost) != nil {
+			continue
+		}
+		if directSpend[cost.Date] == nil {
+			directSpend[cost.Date] = make(map[string]float64)
+		}
+		directSpend[cost.Date][cost.ProjectID] += cost.Cost
+		projectNames[cost.ProjectID] = cost.ProjectName
+	}
+
+	var allocated []models.CostData
+	sharedCount := 0
+	for _, cost := range costs {
+		rule := e.matchRule(cost)
+		if rule == nil {
+			allocated = append(allocated, cost)
+			continue
+		}
+		sharedCount++
+
+		shares := rule.shares(directSpend[cost.Date])
+		for _, target := range rule.Targets {
+			share := shares[target]
+			if share == 0 {
+				continue
+			}
+
+			record := cost
+			record.ProjectID = target
+			record.ProjectName = projectNames[target]
+			record.Cost = cost.Cost * share
+			record.UsageAmount = cost.UsageAmount * share
+			record.AllocatedFrom = cost.ProjectID
+			record.AllocationRule = rule.Name
+			allocated = append(allocated, record)
+		}
+	}
+
+	log.Printf("✅ Allocated %d shared cost records", sharedCount)
+	return Result{
+		Allocated: allocated,
+		Projects:  projectAllocations(allocated),
+	}
+}
+
+// matchRule returns the first rule matching the record, or nil if the cost is direct
+func (e *Engine) matchRule(cost models.CostData) *Rule {
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if len(rule.Services) > 0 && !contains(rule.Services, cost.Service) {
+			continue
+		}
+		if len(rule.SourceProjects) > 0 && !contains(rule.SourceProjects, cost.ProjectID) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// shares returns the fraction of a shared cost assigned to each target
+func (r *Rule) shares(directSpend map[string]float64) map[string]float64 {
+	weights := make(map[string]float64)
+	switch r.Method {
+	case MethodProportional:
+		for _, target := range r.Targets {
+			if directSpend[target] > 0 {
+				weights[target] = directSpend[target]
+			}
+		}
+	case MethodWeighted:
+		for _, target := range r.Targets {
+			weights[target] = r.Weights[target]
+		}
+	}
+
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+
+	// Fall back to an even split when there is nothing to weight by
+	shares := make(map[string]float64)
+	if total == 0 {
+		for _, target := range r.Targets {
+			shares[target] = 1.0 / float64(len(r.Targets))
+		}
+		return shares
+	}
+
+	for target, weight := range weights {
+		shares[target] = weight / total
+	}
+	return shares
+}
+
+// projectAllocations summarizes direct, allocated and fully loaded cost per project
+func projectAllocations(allocated []models.CostData) []models.ProjectAllocation {
+	byProject := make(map[string]*models.ProjectAllocation)
+	for _, cost := range allocated {
+		project, exists := byProject[cost.ProjectID]
+		if !exists {
+			project = &models.ProjectAllocation{ProjectID: cost.ProjectID}
+			byProject[cost.ProjectID] = project
+		}
+		if cost.AllocatedFrom != "" {
+			project.AllocatedCost += cost.Cost
+		} else {
+			project.DirectCost += cost.Cost
+		}
+		project.FullyLoadedCost += cost.Cost
+	}
+
+	projects := make([]models.ProjectAllocation, 0, len(byProject))
+	for _, project := range byProject {
+		projects = append(projects, *project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].FullyLoadedCost > projects[j].FullyLoadedCost
+	})
+	return projects
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package allocation
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// sharedDay returns one day with 300 of direct spend in app-a, 100 in app-b and
+// a shared networking cost of 100 in the net project
+func sharedDay() []models.CostData {
+	return []models.CostData{
+		{Date: "2024-05-01", Service: "Compute", ProjectID: "app-a", ProjectName: "App A", Cost: 300},
+		{Date: "2024-05-01", Service: "Compute", ProjectID: "app-b", ProjectName: "App B", Cost: 100},
+		{Date: "2024-05-01", Service: "Networking", ProjectID: "net", Cost: 100, UsageAmount: 40},
+	}
+}
+
+func TestAllocateSplitsSharedCosts(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want map[string]float64
+	}{
+		{
+			"even",
+			Rule{Name: "network", Services: []string{"Networking"}, Method: MethodEven, Targets: []string{"app-a", "app-b"}},
+			map[string]float64{"app-a": 50, "app-b": 50},
+		},
+		{
+			"proportional to direct spend",
+			Rule{Name: "network", Services: []string{"Networking"}, Method: MethodProportional, Targets: []string{"app-a", "app-b"}},
+			map[string]float64{"app-a": 75, "app-b": 25},
+		},
+		{
+			"weighted",
+			Rule{Name: "network", Services: []string{"Networking"}, Method: MethodWeighted, Targets: []string{"app-a", "app-b"}, Weights: map[string]float64{"app-a": 1, "app-b": 3}},
+			map[string]float64{"app-a": 25, "app-b": 75},
+		},
+		{
+			"proportional with no direct spend falls back to even",
+			Rule{Name: "network", Services: []string{"Networking"}, Method: MethodProportional, Targets: []string{"app-c", "app-d"}},
+			map[string]float64{"app-c": 50, "app-d": 50},
+		},
+		{
+			"weighted with zero weights falls back to even",
+			Rule{Name: "network", Services: []string{"Networking"}, Method: MethodWeighted, Targets: []string{"app-a", "app-b"}, Weights: map[string]float64{}},
+			map[string]float64{"app-a": 50, "app-b": 50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEngine([]Rule{tt.rule})
+			if err != nil {
+				t.Fatalf("NewEngine: %v", err)
+			}
+			result := engine.Allocate(sharedDay())
+
+			got := make(map[string]float64)
+			total := 0.0
+			for _, cost := range result.Allocated {
+				if cost.ProjectID == "net" {
+					t.Errorf("shared cost left in its source project: %+v", cost)
+				}
+				total += cost.Cost
+				if cost.AllocatedFrom == "" {
+					continue
+				}
+				if cost.AllocatedFrom != "net" || cost.AllocationRule != "network" {
+					t.Errorf("allocated record %+v does not name its source project and rule", cost)
+				}
+				if math.Abs(cost.UsageAmount-40*cost.Cost/100) > 1e-9 {
+					t.Errorf("allocated usage %v is not split with the cost %v", cost.UsageAmount, cost.Cost)
+				}
+				got[cost.ProjectID] += cost.Cost
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("allocated to %v, want %v", got, tt.want)
+			}
+			for project, want := range tt.want {
+				if math.Abs(got[project]-want) > 1e-9 {
+					t.Errorf("%s allocated %v, want %v", project, got[project], want)
+				}
+			}
+			if math.Abs(total-500) > 1e-9 {
+				t.Errorf("allocated view totals %v, want the raw 500", total)
+			}
+		})
+	}
+}
+
+func TestAllocateSummarizesProjects(t *testing.T) {
+	engine, err := NewEngine([]Rule{{Name: "network", SourceProjects: []string{"net"}, Method: MethodProportional, Targets: []string{"app-a", "app-b"}}})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	result := engine.Allocate(sharedDay())
+
+	want := []models.ProjectAllocation{
+		{ProjectID: "app-a", DirectCost: 300, AllocatedCost: 75, FullyLoadedCost: 375},
+		{ProjectID: "app-b", DirectCost: 100, AllocatedCost: 25, FullyLoadedCost: 125},
+	}
+	if len(result.Projects) != len(want) {
+		t.Fatalf("got %d projects, want %d: %+v", len(result.Projects), len(want), result.Projects)
+	}
+	for i, project := range result.Projects {
+		if project != want[i] {
+			t.Errorf("project %d = %+v, want %+v", i, project, want[i])
+		}
+	}
+}
+
+func TestNewEngineRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want string
+	}{
+		{"matches nothing", Rule{Name: "r", Method: MethodEven, Targets: []string{"a"}}, "must match"},
+		{"no targets", Rule{Name: "r", Services: []string{"Networking"}, Method: MethodEven}, "no targets"},
+		{"unknown method", Rule{Name: "r", Services: []string{"Networking"}, Method: "random", Targets: []string{"a"}}, "unknown method"},
+		{"negative weight", Rule{Name: "r", Services: []string{"Networking"}, Method: MethodWeighted, Targets: []string{"a"}, Weights: map[string]float64{"a": -1}}, "negative weight"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEngine([]Rule{tt.rule})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewEngine() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
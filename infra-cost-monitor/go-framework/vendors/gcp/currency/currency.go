@@ -0,0 +1,110 @@
+package currency
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Converter converts costs into a base currency using rates from a RateSource,
+// by default a static table of units of the base currency per unit of the source currency
+type Converter struct {
+	base       string
+	source     RateSource
+	configured bool
+}
+
+// NewConverter creates a new currency converter backed by a static table of rates
+func NewConverter(base string, rates map[string]float64) *Converter {
+	return &Converter{
+		base:       strings.ToUpper(base),
+		source:     NewCachedRates(NewStaticRates(base, rates)),
+		configured: len(rates) > 0,
+	}
+}
+
+// SetRateSource replaces the static rates with another source; results are cached per (from, to, day)
+func (c *Converter) SetRateSource(source RateSource) {
+	c.source = NewCachedRates(source)
+	c.configured = true
+}
+
+// Base returns the base currency
+func (c *Converter) Base() string {
+	return c.base
+}
+
+// Convert converts an amount billed on date (YYYY-MM-DD) from the given currency
+// into the base currency, using today's rate when the date cannot be parsed
+func (c *Converter) Convert(amount float64, from, date string) (float64, error) {
+	from = strings.ToUpper(from)
+	if from == "" || from == c.base {
+		return amount, nil
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		day = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	rate, err := c.source.Rate(from, c.base, day)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// GroupByCurrency groups cost records by their billing currency
+func GroupByCurrency(costs []models.CostData) map[string][]models.CostData {
+	groups := make(map[string][]models.CostData)
+	for _, cost := range costs {
+		code := strings.ToUpper(cost.Currency)
+		groups[code] = append(groups[code], cost)
+	}
+	return groups
+}
+
+// Currencies returns the distinct billing currencies present in the cost records
+func Currencies(costs []models.CostData) []string {
+	var codes []string
+	for code := range GroupByCurrency(costs) {
+		if code != "" {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Normalize converts all cost records into the base currency. Data in a single
+// currency is returned unchanged when no base currency is configured.
+func (c *Converter) Normalize(costs []models.CostData) ([]models.CostData, error) {
+	codes := Currencies(costs)
+	if len(codes) == 0 || (len(codes) == 1 && (c.base == "" || codes[0] == c.base)) {
+		return costs, nil
+	}
+
+	if c.base == "" || !c.configured {
+		log.Println("⚠️  ==========================================================")
+		log.Printf("⚠️  Billing data contains currencies %s but no conversion is configured", strings.Join(codes, ", "))
+		log.Println("⚠️  Costs cannot be summed across currencies until a base currency and rates are set")
+		log.Println("⚠️  ==========================================================")
+		return costs, fmt.Errorf("currency conversion required for %s but no base currency or rates configured", strings.Join(codes, ", "))
+	}
+
+	log.Printf("💱 Converting costs in %s to %s", strings.Join(codes, ", "), c.base)
+
+	converted := make([]models.CostData, len(costs))
+	for i, cost := range costs {
+		amount, err := c.Convert(cost.Cost, cost.Currency, cost.Date)
+		if err != nil {
+			return costs, err
+		}
+		cost.Cost = amount
+		cost.Currency = c.base
+		converted[i] = cost
+	}
+	return converted, nil
+}
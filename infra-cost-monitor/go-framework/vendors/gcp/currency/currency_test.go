@@ -0,0 +1,121 @@
+package currency
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// mixedRows returns one INR and one USD record on the same day
+func mixedRows() []models.CostData {
+	return []models.CostData{
+		{Date: "2024-05-01", Service: "Compute", Cost: 830, Currency: "INR"},
+		{Date: "2024-05-01", Service: "Compute", Cost: 10, Currency: "usd"},
+	}
+}
+
+func TestNormalizeConvertsMixedCurrencies(t *testing.T) {
+	converted, err := NewConverter("INR", map[string]float64{"USD": 83}).Normalize(mixedRows())
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	for i, want := range []float64{830, 830} {
+		if converted[i].Cost != want || converted[i].Currency != "INR" {
+			t.Errorf("row %d = %v %s, want %v INR", i, converted[i].Cost, converted[i].Currency, want)
+		}
+	}
+}
+
+func TestNormalizeRejectsUnconvertedMixedCurrencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		converter *Converter
+	}{
+		{"no base currency", NewConverter("", nil)},
+		{"no rates", NewConverter("INR", nil)},
+		{"no rate for USD", NewConverter("INR", map[string]float64{"EUR": 90})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.converter.Normalize(mixedRows()); err == nil {
+				t.Error("Normalize summed INR and USD without converting them")
+			}
+		})
+	}
+}
+
+func TestNormalizeLeavesSingleCurrencyUnchanged(t *testing.T) {
+	rows := []models.CostData{{Date: "2024-05-01", Cost: 10, Currency: "USD"}}
+	converted, err := NewConverter("", nil).Normalize(rows)
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if converted[0].Cost != 10 || converted[0].Currency != "USD" {
+		t.Errorf("row = %v %s, want 10 USD", converted[0].Cost, converted[0].Currency)
+	}
+}
+
+func TestStaticRatesCrossThroughBase(t *testing.T) {
+	rates := NewStaticRates("INR", map[string]float64{"USD": 80, "EUR": 90})
+	rate, err := rates.Rate("EUR", "USD", time.Time{})
+	if err != nil {
+		t.Fatalf("Rate: %v", err)
+	}
+	if rate != 90.0/80.0 {
+		t.Errorf("EUR to USD = %v, want %v", rate, 90.0/80.0)
+	}
+}
+
+func TestCurrenciesGroupsCaseInsensitively(t *testing.T) {
+	rows := []models.CostData{
+		{Cost: 1, Currency: "usd"},
+		{Cost: 2, Currency: "USD"},
+		{Cost: 3, Currency: "INR"},
+		{Cost: 4},
+	}
+	if got := strings.Join(Currencies(rows), ","); got != "INR,USD" {
+		t.Errorf("Currencies() = %s, want INR,USD", got)
+	}
+	if groups := GroupByCurrency(rows); len(groups["USD"]) != 2 || len(groups["INR"]) != 1 || len(groups[""]) != 1 {
+		t.Errorf("GroupByCurrency() = %v, want 2 USD, 1 INR and 1 untagged row", groups)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	converter := NewConverter("inr", map[string]float64{"USD": 83})
+	tests := []struct {
+		name    string
+		amount  float64
+		from    string
+		want    float64
+		wantErr bool
+	}{
+		{"foreign currency", 10, "USD", 830, false},
+		{"lowercase code", 10, "usd", 830, false},
+		{"base currency", 10, "INR", 10, false},
+		{"untagged row", 10, "", 10, false},
+		{"no rate", 10, "EUR", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := converter.Convert(tt.amount, tt.from, "2024-05-01")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
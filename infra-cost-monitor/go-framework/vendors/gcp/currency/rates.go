@@ -0,0 +1,146 @@
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateSource provides the exchange rate from one currency to another on a given
+// day, as units of the target currency per unit of the source currency
+type RateSource interface {
+	Rate(from, to string, day time.Time) (float64, error)
+}
+
+// StaticRates is a RateSource backed by a fixed table of rates into a base currency,
+// such as the rates in the monitor configuration or negotiated internal rates
+type StaticRates struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewStaticRates creates a static rate source; rates are units of base per unit of each currency
+func NewStaticRates(base string, rates map[string]float64) *StaticRates {
+	normalized := make(map[string]float64)
+	for code, rate := range rates {
+		normalized[strings.ToUpper(code)] = rate
+	}
+
+	return &StaticRates{
+		base:  strings.ToUpper(base),
+		rates: normalized,
+	}
+}
+
+// Rate returns the configured rate, crossing through the base currency when neither side is the base
+func (s *StaticRates) Rate(from, to string, day time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	fromRate, err := s.toBase(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := s.toBase(to)
+	if err != nil {
+		return 0, err
+	}
+	return fromRate / toRate, nil
+}
+
+// toBase returns units of the base currency per unit of code
+func (s *StaticRates) toBase(code string) (float64, error) {
+	if code == s.base {
+		return 1, nil
+	}
+	rate, exists := s.rates[code]
+	if !exists || rate <= 0 {
+		return 0, fmt.Errorf("no exchange rate configured from %s to %s", code, s.base)
+	}
+	return rate, nil
+}
+
+// HTTPRates is a RateSource that fetches rates from an HTTP API. The URL may use
+// the {from}, {to} and {date} placeholders and must return JSON with a "rate" field.
+type HTTPRates struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPRates creates a new HTTP rate source
+func NewHTTPRates(url string) *HTTPRates {
+	return &HTTPRates{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Rate fetches the rate for the day from the API
+func (h *HTTPRates) Rate(from, to string, day time.Time) (float64, error) {
+	url := strings.NewReplacer(
+		"{from}", from,
+		"{to}", to,
+		"{date}", day.Format("2006-01-02"),
+	).Replace(h.url)
+
+	resp, err := h.client.Get(url)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch exchange rate %s->%s: %v", from, to, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("exchange rate API returned status %d for %s->%s", resp.StatusCode, from, to)
+	}
+
+	var body struct {
+		Rate float64 `json:"rate"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode exchange rate %s->%s: %v", from, to, err)
+	}
+	if body.Rate <= 0 {
+		return 0, fmt.Errorf("exchange rate API returned no rate for %s->%s", from, to)
+	}
+	return body.Rate, nil
+}
+
+// CachedRates caches another RateSource's results per (from, to, day)
+type CachedRates struct {
+	source RateSource
+	mu     sync.Mutex
+	cache  map[string]float64
+}
+
+// NewCachedRates wraps a rate source with a per-day cache
+func NewCachedRates(source RateSource) *CachedRates {
+	return &CachedRates{
+		source: source,
+		cache:  make(map[string]float64),
+	}
+}
+
+// Rate returns the cached rate, fetching it from the underlying source on a miss
+func (c *CachedRates) Rate(from, to string, day time.Time) (float64, error) {
+	key := from + "|" + to + "|" + day.Format("2006-01-02")
+
+	c.mu.Lock()
+	rate, exists := c.cache[key]
+	c.mu.Unlock()
+	if exists {
+		return rate, nil
+	}
+
+	rate, err := c.source.Rate(from, to, day)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.cache[key] = rate
+	c.mu.Unlock()
+	return rate, nil
+}
@@ -0,0 +1,99 @@
+package currency
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// countingRates returns a fixed rate and records how often it was asked
+type countingRates struct {
+	rate  float64
+	calls int
+	days  []string
+}
+
+func (c *countingRates) Rate(from, to string, day time.Time) (float64, error) {
+	c.calls++
+	c.days = append(c.days, day.Format("2006-01-02"))
+	return c.rate, nil
+}
+
+func TestHTTPRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    float64
+		wantErr bool
+	}{
+		{"rate", http.StatusOK, `{"rate": 83.5}`, 83.5, false},
+		{"error status", http.StatusBadGateway, `{"rate": 83.5}`, 0, true},
+		{"no rate", http.StatusOK, `{}`, 0, true},
+		{"invalid JSON", http.StatusOK, `rate`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			rates := NewHTTPRates(server.URL + "/{date}/{from}/{to}")
+			got, err := rates.Rate("USD", "INR", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rate() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Rate() = %v, want %v", got, tt.want)
+			}
+			if gotPath != "/2024-05-01/USD/INR" {
+				t.Errorf("requested %s, want the placeholders filled in", gotPath)
+			}
+		})
+	}
+}
+
+func TestCachedRatesCachePerPairAndDay(t *testing.T) {
+	source := &countingRates{rate: 83}
+	cached := NewCachedRates(source)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if rate, err := cached.Rate("USD", "INR", day); err != nil || rate != 83 {
+			t.Fatalf("Rate() = %v, %v, want 83", rate, err)
+		}
+	}
+	cached.Rate("EUR", "INR", day)
+	cached.Rate("USD", "INR", day.AddDate(0, 0, 1))
+
+	if source.calls != 3 {
+		t.Errorf("source asked %d times, want once per pair and day (3)", source.calls)
+	}
+}
+
+func TestConverterUsesRateSourceForBillingDay(t *testing.T) {
+	source := &countingRates{rate: 90}
+	converter := NewConverter("INR", nil)
+	converter.SetRateSource(source)
+
+	got, err := converter.Convert(2, "EUR", "2024-05-03")
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if got != 180 {
+		t.Errorf("Convert() = %v, want 180", got)
+	}
+	if len(source.days) != 1 || source.days[0] != "2024-05-03" {
+		t.Errorf("rates requested for %v, want the billing day 2024-05-03", source.days)
+	}
+	if _, err := converter.Normalize(mixedRows()); err != nil {
+		t.Errorf("Normalize() with a rate source error = %v, want none", err)
+	}
+}
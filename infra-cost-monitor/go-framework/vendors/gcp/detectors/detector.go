@@ -0,0 +1,115 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+)
+
+// Series holds the cost data detectors run against
+type Series struct {
+	Daily     []models.DailyCost
+	MTD       []models.MTDCost
+	Composite []models.CostData
+}
+
+// Params holds numeric parameters for a detector
+type Params map[string]float64
+
+// Get returns the named parameter, or def if it is not set
+func (p Params) Get(name string, def float64) float64 {
+	if value, exists := p[name]; exists {
+		return value
+	}
+	return def
+}
+
+// Detector is an anomaly detection algorithm
+type Detector interface {
+	Detect(series Series, params Params) []models.Anomaly
+}
+
+// Settings controls whether a registered detector runs and with which parameters
+type Settings struct {
+	Enabled bool   `json:"enabled"`
+	Params  Params `json:"params,omitempty"`
+}
+
+// Registry holds detectors by name and runs them through a common loop
+type Registry struct {
+	detectors map[string]Detector
+	names     []string
+}
+
+// NewRegistry creates an empty detector registry
+func NewRegistry() *Registry {
+	return &Registry{
+		detectors: make(map[string]Detector),
+	}
+}
+
+// NewDefaultRegistry creates a registry with the built-in detectors registered
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register("daily_spike", &DailySpikeDetector{})
+	registry.Register("monthly_spike", &MonthlySpikeDetector{})
+	registry.Register("daily_drop", &DailyDropDetector{})
+	registry.Register("monthly_drop", &MonthlyDropDetector{})
+	registry.Register("daily_percentile", &PercentileDetector{})
+	registry.Register("n_days_ago", &LagDetector{})
+	registry.Register("region_shift", &RegionShiftDetector{})
+	registry.Register("seasonal_naive", &SeasonalNaiveDetector{})
+	registry.Register("ewma", &EWMADetector{})
+	registry.Register("weekday_baseline", &WeekdayBaselineDetector{})
+	registry.Register("service_spike", &ServiceSpikeDetector{Thresholds: DefaultServiceThresholds()})
+	registry.Register("usage_spike", &UsageSpikeDetector{})
+	registry.Register("unit_price_drift", &UnitPriceDriftDetector{})
+	return registry
+}
+
+// Register adds a detector under the given name, replacing any existing one
+func (r *Registry) Register(name string, detector Detector) {
+	if _, exists := r.detectors[name]; !exists {
+		r.names = append(r.names, name)
+	}
+	r.detectors[name] = detector
+}
+
+// Names returns the registered detector names in registration order
+func (r *Registry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Plan returns the registered detectors that will run and those disabled by settings.
+// Detectors without settings are enabled.
+func (r *Registry) Plan(settings map[string]Settings) ([]string, []string) {
+	var enabled, disabled []string
+	for _, name := range r.names {
+		if setting, configured := settings[name]; configured && !setting.Enabled {
+			disabled = append(disabled, name)
+		} else {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled, disabled
+}
+
+// Run runs each enabled detector and returns the combined anomalies.
+// Detectors without settings run with their default parameters. They see the
+// daily costs newest first; the caller's slice is left in its order.
+func (r *Registry) Run(series Series, settings map[string]Settings) []models.Anomaly {
+	series.Daily = append([]models.DailyCost(nil), series.Daily...)
+	models.SortDailyCostsDesc(series.Daily)
+
+	enabled, disabled := r.Plan(settings)
+	for _, name := range disabled {
+		log.Printf("⏭️  Detector %s disabled", name)
+	}
+
+	var anomalies []models.Anomaly
+	for _, name := range enabled {
+		found := models.AssignIDs(r.detectors[name].Detect(series, settings[name].Params))
+		log.Printf("🔍 Detector %s found %d anomalies", name, len(found))
+		anomalies = append(anomalies, found...)
+	}
+	return anomalies
+}
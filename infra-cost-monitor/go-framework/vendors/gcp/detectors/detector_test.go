@@ -0,0 +1,86 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"strings"
+	"testing"
+)
+
+// recordingDetector reports one anomaly per call and remembers the params it ran with
+type recordingDetector struct {
+	testName string
+	params   []Params
+}
+
+func (rd *recordingDetector) Detect(series Series, params Params) []models.Anomaly {
+	rd.params = append(rd.params, params)
+	return []models.Anomaly{{TestName: rd.testName, Type: "spike", Date: "2024-05-03", Service: "Compute"}}
+}
+
+func TestRegistryPlansAndRunsEnabledDetectors(t *testing.T) {
+	first := &recordingDetector{testName: "first"}
+	second := &recordingDetector{testName: "second"}
+	disabled := &recordingDetector{testName: "disabled"}
+	replaced := &recordingDetector{testName: "replaced"}
+
+	registry := NewRegistry()
+	registry.Register("first", replaced)
+	registry.Register("second", second)
+	registry.Register("disabled", disabled)
+	registry.Register("first", first)
+
+	if got := strings.Join(registry.Names(), ","); got != "first,second,disabled" {
+		t.Errorf("Names() = %s, want registration order with the replacement kept in place", got)
+	}
+
+	settings := map[string]Settings{
+		"second":   {Enabled: true, Params: Params{"percentage": 30}},
+		"disabled": {Enabled: false},
+	}
+	enabled, skipped := registry.Plan(settings)
+	if strings.Join(enabled, ",") != "first,second" || strings.Join(skipped, ",") != "disabled" {
+		t.Errorf("Plan() = %v enabled, %v disabled, want [first second] and [disabled]", enabled, skipped)
+	}
+
+	anomalies := registry.Run(Series{}, settings)
+	if len(anomalies) != 2 {
+		t.Fatalf("got %d anomalies, want one from each enabled detector", len(anomalies))
+	}
+	for _, anomaly := range anomalies {
+		if anomaly.ID == "" {
+			t.Errorf("anomaly from %s has no ID assigned", anomaly.TestName)
+		}
+	}
+	if len(replaced.params) != 0 || len(disabled.params) != 0 {
+		t.Error("Run called a replaced or disabled detector")
+	}
+	if len(first.params) != 1 || first.params[0] != nil {
+		t.Errorf("detector without settings ran with %v, want default (nil) params", first.params)
+	}
+	if len(second.params) != 1 || second.params[0].Get("percentage", 0) != 30 {
+		t.Errorf("configured detector ran with %v, want its configured params", second.params)
+	}
+}
+
+func TestRegistryRunSortsDailyNewestFirst(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("daily_spike", &DailySpikeDetector{})
+	settings := map[string]Settings{"daily_spike": {Enabled: true, Params: Params{"percentage": 20, "absolute": 100000}}}
+
+	// Oldest first, as a loader might return them; the latest day tripled
+	daily := []models.DailyCost{
+		{Date: "2024-05-01", TotalCost: 100},
+		{Date: "2024-05-02", TotalCost: 100},
+		{Date: "2024-05-03", TotalCost: 300},
+	}
+	anomalies := registry.Run(Series{Daily: daily}, settings)
+	if len(anomalies) != 1 {
+		t.Fatalf("got %d anomalies, want the spike on the latest day", len(anomalies))
+	}
+	if anomalies[0].Date != "2024-05-03" || anomalies[0].CurrentValue != 300 || anomalies[0].PreviousValue != 100 {
+		t.Errorf("anomaly compares %s %v against %v, want 2024-05-03 300 against 100", anomalies[0].Date, anomalies[0].CurrentValue, anomalies[0].PreviousValue)
+	}
+	if daily[0].Date != "2024-05-01" {
+		t.Error("Run reordered the caller's daily costs")
+	}
+}
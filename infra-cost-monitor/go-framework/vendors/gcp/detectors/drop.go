@@ -0,0 +1,86 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"time"
+)
+
+// DailyDropDetector flags a day-over-day fall in total cost, which often means a
+// pipeline died or the billing export broke.
+// Params: percentage (default 50), the fall below the previous day that is flagged.
+type DailyDropDetector struct{}
+
+// Detect compares the two most recent daily totals
+func (d *DailyDropDetector) Detect(series Series, params Params) []models.Anomaly {
+	dailyCosts := series.Daily
+	if len(dailyCosts) < 2 {
+		return nil
+	}
+
+	current := dailyCosts[0].TotalCost
+	previous := dailyCosts[1].TotalCost
+	if previous <= 0 {
+		return nil
+	}
+
+	percentageThreshold := params.Get("percentage", 50)
+	threshold := previous * (1 - percentageThreshold/100)
+	if current >= threshold {
+		return nil
+	}
+
+	return []models.Anomaly{models.Anomaly{
+		Date:        dailyCosts[0].Date,
+		TestName:    "Daily Drop Detector",
+		Type:        "daily_drop",
+		Category:    models.CategoryDrop,
+		Service:     "daily_total",
+		CostImpact:  current - previous,
+		Description: fmt.Sprintf("Daily cost dropped %.1f%% (%.2f -> %.2f); check for stopped pipelines or a broken billing export", (previous-current)/previous*100, previous, current),
+		Severity:    models.SeverityHigh,
+		DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+	}.WithValues(current, previous, threshold)}
+}
+
+// MonthlyDropDetector flags a fall in the current month's daily run rate versus
+// the previous month, so a partial month is not mistaken for a drop.
+// Params: percentage (default 50) and min_days_elapsed (default 0).
+type MonthlyDropDetector struct{}
+
+// Detect compares the average daily cost of the two most recent months
+func (d *MonthlyDropDetector) Detect(series Series, params Params) []models.Anomaly {
+	mtdCosts := series.MTD
+	if len(mtdCosts) < 2 || mtdCosts[0].Days == 0 || mtdCosts[1].Days == 0 {
+		return nil
+	}
+	if minDays := int(params.Get("min_days_elapsed", 0)); mtdCosts[0].Days < minDays {
+		log.Printf("Skipping monthly drop check: %d of %d days elapsed in %s", mtdCosts[0].Days, minDays, mtdCosts[0].Month)
+		return nil
+	}
+
+	current := mtdCosts[0].Cost / float64(mtdCosts[0].Days)
+	previous := mtdCosts[1].Cost / float64(mtdCosts[1].Days)
+	if previous <= 0 {
+		return nil
+	}
+
+	percentageThreshold := params.Get("percentage", 50)
+	threshold := previous * (1 - percentageThreshold/100)
+	if current >= threshold {
+		return nil
+	}
+
+	return []models.Anomaly{models.Anomaly{
+		Date:        mtdCosts[0].Month,
+		TestName:    "Monthly Drop Detector",
+		Type:        "monthly_drop",
+		Category:    models.CategoryDrop,
+		Service:     "monthly_total",
+		CostImpact:  (current - previous) * float64(mtdCosts[0].Days),
+		Description: fmt.Sprintf("Average daily cost dropped %.1f%% versus last month (%.2f -> %.2f per day)", (previous-current)/previous*100, previous, current),
+		Severity:    models.SeverityHigh,
+		DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+	}.WithValues(current, previous, threshold)}
+}
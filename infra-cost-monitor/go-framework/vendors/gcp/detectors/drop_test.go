@@ -0,0 +1,67 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+func TestDailyDropDetector(t *testing.T) {
+	tests := []struct {
+		name    string
+		current float64
+		params  Params
+		drop    bool
+	}{
+		{"70% drop", 300, nil, true},
+		{"30% drop", 700, nil, false},
+		{"spike", 2000, nil, false},
+		{"70% drop below a stricter threshold", 300, Params{"percentage": 80}, false},
+		{"30% drop above a looser threshold", 700, Params{"percentage": 20}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			daily := []models.DailyCost{{Date: "2024-03-02", TotalCost: tt.current}, {Date: "2024-03-01", TotalCost: 1000}}
+			anomalies := (&DailyDropDetector{}).Detect(Series{Daily: daily}, tt.params)
+			if drop := len(anomalies) > 0; drop != tt.drop {
+				t.Fatalf("drop = %v, want %v", drop, tt.drop)
+			}
+			if !tt.drop {
+				return
+			}
+			anomaly := anomalies[0]
+			if anomaly.Category != models.CategoryDrop {
+				t.Errorf("category = %q, want %q", anomaly.Category, models.CategoryDrop)
+			}
+			if anomaly.CostImpact != tt.current-1000 {
+				t.Errorf("cost impact = %v, want %v", anomaly.CostImpact, tt.current-1000)
+			}
+		})
+	}
+}
+
+func TestMonthlyDropDetectorComparesRunRates(t *testing.T) {
+	previous := models.MTDCost{Month: "2024-04", Cost: 30000, Days: 30}
+	tests := []struct {
+		name    string
+		current models.MTDCost
+		drop    bool
+	}{
+		// 10 days at 300/day against 1000/day
+		{"70% lower run rate", models.MTDCost{Month: "2024-05", Cost: 3000, Days: 10}, true},
+		// A third of the previous total, but the same run rate
+		{"partial month at the same run rate", models.MTDCost{Month: "2024-05", Cost: 10000, Days: 10}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := (&MonthlyDropDetector{}).Detect(Series{MTD: []models.MTDCost{tt.current, previous}}, nil)
+			if drop := len(anomalies) > 0; drop != tt.drop {
+				t.Fatalf("drop = %v, want %v", drop, tt.drop)
+			}
+			if tt.drop && anomalies[0].Category != models.CategoryDrop {
+				t.Errorf("category = %q, want %q", anomalies[0].Category, models.CategoryDrop)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// EWMADetector runs an EWMA control chart over the daily totals. The mean and
+// standard deviation of the days before the latest form the baseline, and the
+// latest day is flagged when the exponentially weighted moving average drifts
+// outside the control limits. Unlike the day-over-day spike check this catches
+// sustained ramps while damping single noisy days.
+// Params: alpha smoothing factor (default 0.3), sigmas control-limit width
+// (default 3), min_history (default 14).
+type EWMADetector struct{}
+
+// Detect computes the EWMA of the daily totals, oldest first, and checks the latest value against the limits
+func (d *EWMADetector) Detect(series Series, params Params) []models.Anomaly {
+	alpha := params.Get("alpha", 0.3)
+	sigmas := params.Get("sigmas", 3)
+	if alpha <= 0 || alpha > 1 || len(series.Daily) < int(params.Get("min_history", 14)) || len(series.Daily) < 3 {
+		return nil
+	}
+
+	dailyCosts := append([]models.DailyCost(nil), series.Daily...)
+	sort.Slice(dailyCosts, func(i, j int) bool {
+		return dailyCosts[i].Date < dailyCosts[j].Date
+	})
+	baseline := dailyCosts[:len(dailyCosts)-1]
+	latest := dailyCosts[len(dailyCosts)-1]
+
+	var mean float64
+	for _, day := range baseline {
+		mean += day.TotalCost
+	}
+	mean /= float64(len(baseline))
+
+	var variance float64
+	for _, day := range baseline {
+		variance += (day.TotalCost - mean) * (day.TotalCost - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(baseline)-1))
+	if stdDev == 0 {
+		return nil
+	}
+
+	ewma := mean
+	for _, day := range dailyCosts {
+		ewma = alpha*day.TotalCost + (1-alpha)*ewma
+	}
+
+	// Steady-state control limit of the EWMA statistic
+	limit := sigmas * stdDev * math.Sqrt(alpha/(2-alpha))
+	deviation := ewma - mean
+	if math.Abs(deviation) <= limit {
+		return nil
+	}
+
+	bound := mean + limit
+	direction := "above"
+	if deviation < 0 {
+		bound = mean - limit
+		direction = "below"
+	}
+	severity := models.SeverityMedium
+	if math.Abs(deviation) > 2*limit {
+		severity = models.SeverityHigh
+	}
+
+	return []models.Anomaly{models.Anomaly{
+		Date:        latest.Date,
+		TestName:    "EWMA Control Chart",
+		Type:        "ewma",
+		Service:     "daily_total",
+		CostImpact:  latest.TotalCost - mean,
+		Description: fmt.Sprintf("Daily cost EWMA (%.2f, alpha %g) is %s the %g-sigma control limit %.2f around the mean of %.2f", ewma, alpha, direction, sigmas, bound, mean),
+		Severity:    severity,
+		DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+	}.WithValues(latest.TotalCost, mean, bound)}
+}
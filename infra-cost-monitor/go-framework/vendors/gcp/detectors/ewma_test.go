@@ -0,0 +1,76 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+// dailySeries builds a newest-first daily series from oldest-first costs ending on 2024-03-30
+func dailySeries(costs []float64) []models.DailyCost {
+	daily := make([]models.DailyCost, len(costs))
+	for i, cost := range costs {
+		daily[len(costs)-1-i] = models.DailyCost{Date: fmt.Sprintf("2024-03-%02d", 30-len(costs)+1+i), TotalCost: cost}
+	}
+	return daily
+}
+
+// flatCosts returns n costs alternating between 99 and 101
+func flatCosts(n int) []float64 {
+	costs := make([]float64, n)
+	for i := range costs {
+		costs[i] = 99 + float64(i%2)*2
+	}
+	return costs
+}
+
+func TestEWMADetector(t *testing.T) {
+	rising := make([]float64, 30)
+	for i := range rising {
+		rising[i] = 100 + float64(i)*5
+	}
+	latestBlip := flatCosts(30)
+	latestBlip[29] = 200
+	oldBlip := flatCosts(30)
+	oldBlip[15] = 200
+
+	tests := []struct {
+		name   string
+		costs  []float64
+		params Params
+		flag   bool
+	}{
+		{"steadily rising series", rising, nil, true},
+		{"flat series", flatCosts(30), nil, false},
+		{"flat series with a blip on the latest day", latestBlip, nil, true},
+		{"flat series with an old blip", oldBlip, nil, false},
+		{"too little history", rising[:10], nil, false},
+		{"invalid alpha", rising, Params{"alpha": 1.5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := (&EWMADetector{}).Detect(Series{Daily: dailySeries(tt.costs)}, tt.params)
+			if flagged := len(anomalies) > 0; flagged != tt.flag {
+				t.Fatalf("flagged = %v, want %v", flagged, tt.flag)
+			}
+			if tt.flag && anomalies[0].Date != "2024-03-30" {
+				t.Errorf("flagged %s, want the latest day", anomalies[0].Date)
+			}
+		})
+	}
+}
+
+func TestEWMADetectorAlphaControlsSmoothing(t *testing.T) {
+	// A small blip on the latest day moves a heavily smoothed EWMA too little to flag
+	costs := flatCosts(30)
+	costs[29] = 105
+	series := Series{Daily: dailySeries(costs)}
+
+	if anomalies := (&EWMADetector{}).Detect(series, Params{"alpha": 0.05}); len(anomalies) != 0 {
+		t.Errorf("alpha 0.05 flagged %d anomalies, want 0", len(anomalies))
+	}
+	if anomalies := (&EWMADetector{}).Detect(series, Params{"alpha": 0.9}); len(anomalies) != 1 {
+		t.Errorf("alpha 0.9 flagged %d anomalies, want 1", len(anomalies))
+	}
+}
@@ -0,0 +1,89 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"time"
+)
+
+// LagDetector compares the latest day directly to the same day N days ago.
+// When that day is missing it falls back to the nearest available day.
+// Params: days (default 7), percentage (default 30).
+type LagDetector struct{}
+
+// Detect compares the latest daily total to the total N days earlier
+func (d *LagDetector) Detect(series Series, params Params) []models.Anomaly {
+	lagDays := int(params.Get("days", 7))
+	threshold := params.Get("percentage", 30)
+
+	latest, latestDate, ok := latestDay(series.Daily)
+	if !ok || lagDays <= 0 {
+		return nil
+	}
+	target := latestDate.AddDate(0, 0, -lagDays)
+
+	// Find the exact lagged day, or the nearest earlier-than-latest day available
+	var baseline models.DailyCost
+	var baselineDate time.Time
+	bestDistance := math.MaxFloat64
+	for _, day := range series.Daily {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil || !date.Before(latestDate) {
+			continue
+		}
+		distance := math.Abs(date.Sub(target).Hours())
+		if distance < bestDistance || (distance == bestDistance && date.Before(baselineDate)) {
+			bestDistance = distance
+			baseline = day
+			baselineDate = date
+		}
+	}
+	if baseline.Date == "" || baseline.TotalCost <= 0 {
+		return nil
+	}
+
+	change := latest.TotalCost - baseline.TotalCost
+	percentage := (change / baseline.TotalCost) * 100
+	if math.Abs(percentage) <= threshold {
+		return nil
+	}
+
+	note := ""
+	if !baselineDate.Equal(target) {
+		note = fmt.Sprintf(" (%s missing, used nearest day %s)", target.Format("2006-01-02"), baseline.Date)
+	}
+
+	severity := models.SeverityMedium
+	if math.Abs(percentage) > 2*threshold {
+		severity = models.SeverityHigh
+	}
+
+	return []models.Anomaly{models.Anomaly{
+		Date:        latest.Date,
+		TestName:    fmt.Sprintf("%d-Day-Ago Comparison", lagDays),
+		Type:        "n_days_ago",
+		Service:     "daily_total",
+		CostImpact:  change,
+		Description: fmt.Sprintf("Daily cost (%.2f) changed %.1f%% versus %d days ago (%.2f)%s", latest.TotalCost, percentage, lagDays, baseline.TotalCost, note),
+		Severity:    severity,
+		DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+	}.WithValues(latest.TotalCost, baseline.TotalCost, baseline.TotalCost*(1+math.Copysign(threshold, change)/100))}
+}
+
+// latestDay returns the most recent day in the series
+func latestDay(dailyCosts []models.DailyCost) (models.DailyCost, time.Time, bool) {
+	var latest models.DailyCost
+	var latestDate time.Time
+	found := false
+	for _, day := range dailyCosts {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		if !found || date.After(latestDate) {
+			latest, latestDate, found = day, date, true
+		}
+	}
+	return latest, latestDate, found
+}
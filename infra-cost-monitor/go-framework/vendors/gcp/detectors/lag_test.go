@@ -0,0 +1,78 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"strings"
+	"testing"
+)
+
+func TestLagDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  map[string]float64
+		params   Params
+		baseline float64
+		note     string
+	}{
+		{
+			"exact day seven days ago",
+			map[string]float64{"2024-03-14": 200, "2024-03-08": 100, "2024-03-07": 200},
+			nil, 100, "",
+		},
+		{
+			"missing day falls back to the nearest",
+			map[string]float64{"2024-03-14": 200, "2024-03-09": 100, "2024-03-06": 200},
+			nil, 100, "(2024-03-08 missing, used nearest day 2024-03-09)",
+		},
+		{
+			"equally near days prefer the earlier",
+			map[string]float64{"2024-03-09": 200, "2024-03-07": 100},
+			nil, 100, "(2024-03-08 missing, used nearest day 2024-03-07)",
+		},
+		{
+			"configured lag",
+			map[string]float64{"2024-03-08": 200, "2024-03-01": 100},
+			Params{"days": 14}, 100, "",
+		},
+		{
+			"change within the threshold",
+			map[string]float64{"2024-03-08": 180},
+			nil, 0, "",
+		},
+		{
+			"no earlier day",
+			nil,
+			nil, 0, "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			daily := []models.DailyCost{{Date: "2024-03-15", TotalCost: 200}}
+			for date, cost := range tt.history {
+				daily = append(daily, models.DailyCost{Date: date, TotalCost: cost})
+			}
+
+			anomalies := (&LagDetector{}).Detect(Series{Daily: daily}, tt.params)
+			if flagged := len(anomalies) > 0; flagged != (tt.baseline > 0) {
+				t.Fatalf("flagged = %v, want %v", flagged, tt.baseline > 0)
+			}
+			if tt.baseline == 0 {
+				return
+			}
+			anomaly := anomalies[0]
+			if anomaly.Date != "2024-03-15" || anomaly.CostImpact != 200-tt.baseline {
+				t.Errorf("anomaly = %+v, want one on 2024-03-15 with impact %v", anomaly, 200-tt.baseline)
+			}
+			if anomaly.Severity != models.SeverityHigh {
+				t.Errorf("severity = %q for a 100%% change, want %q", anomaly.Severity, models.SeverityHigh)
+			}
+			if tt.note == "" && strings.Contains(anomaly.Description, "missing") {
+				t.Errorf("description %q notes a fallback, want none", anomaly.Description)
+			}
+			if !strings.Contains(anomaly.Description, tt.note) {
+				t.Errorf("description %q, want it to contain %q", anomaly.Description, tt.note)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"sort"
+	"time"
+)
+
+// PercentileDetector flags the latest daily total when it exceeds a historical percentile.
+// Params: percentile (default 0.99), min_history (default 90), min_history_floor
+// (default min_history) down to which it still runs with low confidence, and opt-in
+// sampling via sample_every (default 1, exact), sample_seed and min_sample (default 30).
+type PercentileDetector struct{}
+
+// Detect compares the latest daily total to the percentile of the series
+func (d *PercentileDetector) Detect(series Series, params Params) []models.Anomaly {
+	dailyCosts := series.Daily
+	minHistory := int(params.Get("min_history", 90))
+	floor := int(params.Get("min_history_floor", float64(minHistory)))
+	if len(dailyCosts) == 0 || len(dailyCosts) < floor {
+		return nil
+	}
+	confidence := ""
+	if len(dailyCosts) < minHistory {
+		confidence = models.ConfidenceLow
+	}
+
+	costs := make([]float64, len(dailyCosts))
+	for i, record := range dailyCosts {
+		costs[i] = record.TotalCost
+	}
+	costs = SampleHistory(costs, int(params.Get("sample_every", 1)), int64(params.Get("sample_seed", 0)), int(params.Get("min_sample", 30)))
+
+	p := params.Get("percentile", 0.99)
+	threshold := Percentiles(costs, []float64{p})[p]
+	currentCost := dailyCosts[0].TotalCost
+	if currentCost <= threshold || threshold <= 0 {
+		return nil
+	}
+
+	percentageDiff := ((currentCost - threshold) / threshold) * 100
+
+	return []models.Anomaly{models.Anomaly{
+		Date:        dailyCosts[0].Date,
+		TestName:    fmt.Sprintf("Daily Percentile Detector - p%g", p*100),
+		Type:        "daily_percentile",
+		Service:     "daily_total",
+		CostImpact:  currentCost - threshold,
+		Description: fmt.Sprintf("Current date cost (%.2f) is above the p%g threshold (%.2f)", currentCost, p*100, threshold),
+		Severity:    models.SeverityForPercentage(percentageDiff),
+		DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+		Confidence:  confidence,
+	}.WithValues(currentCost, threshold, threshold)}
+}
+
+// Percentiles returns the requested percentiles (0-1) of values, sorting a copy
+// once. Each percentile is the value at index floor(n*p), capped at the maximum.
+func Percentiles(values []float64, ps []float64) map[float64]float64 {
+	result := make(map[float64]float64, len(ps))
+	if len(values) == 0 {
+		return result
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	for _, p := range ps {
+		index := int(float64(len(sorted)) * p)
+		if index >= len(sorted) {
+			index = len(sorted) - 1
+		}
+		if index < 0 {
+			index = 0
+		}
+		result[p] = sorted[index]
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package detectors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPercentiles(t *testing.T) {
+	// 1..100 shuffled; the value at index floor(n*p) of the sorted values is n*p+1
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64((i*37)%100 + 1)
+	}
+	original := append([]float64(nil), values...)
+
+	got := Percentiles(values, []float64{0, 0.5, 0.9, 0.95, 0.99, 1})
+	want := map[float64]float64{0: 1, 0.5: 51, 0.9: 91, 0.95: 96, 0.99: 100, 1: 100}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Percentiles() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(values, original) {
+		t.Error("Percentiles() reordered its input")
+	}
+}
+
+func TestPercentilesEdgeCases(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		ps     []float64
+		want   map[float64]float64
+	}{
+		{"no values", nil, []float64{0.5}, map[float64]float64{}},
+		{"single value", []float64{42}, []float64{0.01, 0.99}, map[float64]float64{0.01: 42, 0.99: 42}},
+		{"out of range percentiles are clamped", []float64{3, 1, 2}, []float64{-0.5, 1.5}, map[float64]float64{-0.5: 1, 1.5: 3}},
+		{"no percentiles", []float64{1, 2}, nil, map[float64]float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Percentiles(tt.values, tt.ps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Percentiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
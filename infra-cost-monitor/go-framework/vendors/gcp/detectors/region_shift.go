@@ -0,0 +1,144 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"sort"
+	"time"
+)
+
+// RegionShiftDetector flags cost moving between regions by comparing each region's
+// share of the latest day's total to its average share over the preceding window.
+// Params: window (default 7 days), threshold (default 10 percentage points).
+type RegionShiftDetector struct{}
+
+// Detect pairs the region whose share fell the most with the region whose share rose the most
+func (d *RegionShiftDetector) Detect(series Series, params Params) []models.Anomaly {
+	window := int(params.Get("window", 7))
+	threshold := params.Get("threshold", 10)
+
+	// Region breakdown per date, the same shape as DimensionalMonitor.GetRegionBreakdown
+	breakdowns := make(map[string]map[string]float64)
+	for _, cost := range series.Composite {
+		if breakdowns[cost.Date] == nil {
+			breakdowns[cost.Date] = make(map[string]float64)
+		}
+		breakdowns[cost.Date][cost.Region] += cost.Cost
+	}
+
+	dates := make([]string, 0, len(breakdowns))
+	for date := range breakdowns {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	if len(dates) < 2 || window <= 0 {
+		return nil
+	}
+
+	latestDate := dates[len(dates)-1]
+	history := dates[:len(dates)-1]
+	if len(history) > window {
+		history = history[len(history)-window:]
+	}
+
+	latestShares, latestTotal := regionShares(breakdowns[latestDate])
+	if latestTotal <= 0 {
+		return nil
+	}
+	baselineShares := make(map[string]float64)
+	for _, date := range history {
+		shares, _ := regionShares(breakdowns[date])
+		for region, share := range shares {
+			baselineShares[region] += share / float64(len(history))
+		}
+	}
+
+	// Find the largest share loss and gain, in percentage points
+	var regions []string
+	for region := range baselineShares {
+		regions = append(regions, region)
+	}
+	for region := range latestShares {
+		if _, exists := baselineShares[region]; !exists {
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+
+	var fromRegion, toRegion string
+	var maxLoss, maxGain float64
+	for _, region := range regions {
+		shift := (latestShares[region] - baselineShares[region]) * 100
+		if shift < maxLoss {
+			fromRegion, maxLoss = region, shift
+		}
+		if shift > maxGain {
+			toRegion, maxGain = region, shift
+		}
+	}
+	if -maxLoss <= threshold || maxGain <= threshold {
+		return nil
+	}
+
+	// The share that moved, applied to the latest day's total
+	moved := minFloat(-maxLoss, maxGain) / 100 * latestTotal
+
+	severity := models.SeverityMedium
+	if minFloat(-maxLoss, maxGain) > 2*threshold {
+		severity = models.SeverityHigh
+	}
+
+	return []models.Anomaly{models.Anomaly{
+		Date:         latestDate,
+		TestName:     "Region Share Shift",
+		Type:         "region_shift",
+		Service:      "region_share",
+		CompositeKey: fromRegion + "->" + toRegion,
+		CostImpact:   moved,
+		Description: fmt.Sprintf("Cost share moved from %s (%.1f%% -> %.1f%%) to %s (%.1f%% -> %.1f%%), about %.2f over the %d-day baseline",
+			regionLabel(fromRegion), baselineShares[fromRegion]*100, latestShares[fromRegion]*100,
+			regionLabel(toRegion), baselineShares[toRegion]*100, latestShares[toRegion]*100,
+			moved, len(history)),
+		Severity:   severity,
+		DetectedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}.WithValues(latestShares[toRegion]*100, baselineShares[toRegion]*100, baselineShares[toRegion]*100+threshold)}
+}
+
+// regionShares converts a region breakdown into each region's share of the total.
+// Regions are summed in name order so the total doesn't vary with map order.
+func regionShares(breakdown map[string]float64) (map[string]float64, float64) {
+	regions := make([]string, 0, len(breakdown))
+	for region := range breakdown {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	total := 0.0
+	for _, region := range regions {
+		total += breakdown[region]
+	}
+
+	shares := make(map[string]float64)
+	if total <= 0 {
+		return shares, total
+	}
+	for region, cost := range breakdown {
+		shares[region] = cost / total
+	}
+	return shares, total
+}
+
+// regionLabel names rows without a location, such as global services
+func regionLabel(region string) string {
+	if region == "" {
+		return "global"
+	}
+	return region
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,77 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"strings"
+	"testing"
+)
+
+// regionHistory returns eight days of 100 in total cost split 80/20 between
+// us-east1 and europe-west1, then a latest day split as given
+func regionHistory(latest map[string]float64) Series {
+	var series Series
+	for day := 1; day <= 8; day++ {
+		date := fmt.Sprintf("2024-03-%02d", day)
+		series.Composite = append(series.Composite,
+			models.CostData{Date: date, Region: "us-east1", Cost: 80},
+			models.CostData{Date: date, Region: "europe-west1", Cost: 20},
+		)
+	}
+	for region, cost := range latest {
+		series.Composite = append(series.Composite, models.CostData{Date: "2024-03-09", Region: region, Cost: cost})
+	}
+	return series
+}
+
+func TestRegionShiftDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		latest   map[string]float64
+		params   Params
+		key      string
+		moved    float64
+		severity string
+	}{
+		{"same split", map[string]float64{"us-east1": 80, "europe-west1": 20}, nil, "", 0, ""},
+		{"shift within the threshold", map[string]float64{"us-east1": 72, "europe-west1": 28}, nil, "", 0, ""},
+		{"workload migrated", map[string]float64{"us-east1": 65, "europe-west1": 35}, nil, "us-east1->europe-west1", 15, models.SeverityMedium},
+		{"most of the spend moved", map[string]float64{"us-east1": 20, "europe-west1": 80}, nil, "us-east1->europe-west1", 60, models.SeverityHigh},
+		{"moved to a new region", map[string]float64{"us-east1": 50, "europe-west1": 20, "asia-east1": 30}, nil, "us-east1->asia-east1", 30, models.SeverityHigh},
+		{"moved to global services", map[string]float64{"us-east1": 50, "europe-west1": 20, "": 30}, nil, "us-east1->", 30, models.SeverityHigh},
+		{"stricter threshold", map[string]float64{"us-east1": 72, "europe-west1": 28}, Params{"threshold": 5}, "us-east1->europe-west1", 8, models.SeverityMedium},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := (&RegionShiftDetector{}).Detect(regionHistory(tt.latest), tt.params)
+			if flagged := len(anomalies) > 0; flagged != (tt.key != "") {
+				t.Fatalf("flagged = %v, want %v: %+v", flagged, tt.key != "", anomalies)
+			}
+			if tt.key == "" {
+				return
+			}
+			anomaly := anomalies[0]
+			if anomaly.CompositeKey != tt.key || anomaly.Date != "2024-03-09" {
+				t.Errorf("anomaly on %s for %q, want 2024-03-09 for %q", anomaly.Date, anomaly.CompositeKey, tt.key)
+			}
+			if math.Abs(anomaly.CostImpact-tt.moved) > 1e-9 {
+				t.Errorf("cost moved = %v, want %v", anomaly.CostImpact, tt.moved)
+			}
+			if anomaly.Severity != tt.severity {
+				t.Errorf("severity = %q, want %q", anomaly.Severity, tt.severity)
+			}
+			if strings.HasSuffix(tt.key, "->") && !strings.Contains(anomaly.Description, "to global") {
+				t.Errorf("description %q does not name the regionless rows as global", anomaly.Description)
+			}
+		})
+	}
+}
+
+func TestRegionShiftDetectorNeedsHistory(t *testing.T) {
+	series := Series{Composite: []models.CostData{{Date: "2024-03-09", Region: "us-east1", Cost: 100}}}
+	if anomalies := (&RegionShiftDetector{}).Detect(series, nil); len(anomalies) != 0 {
+		t.Errorf("flagged %+v from a single day", anomalies)
+	}
+}
@@ -0,0 +1,102 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sequence returns the values 0 to n-1
+func sequence(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = float64(i)
+	}
+	return values
+}
+
+func TestSampleHistory(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []float64
+		every     int
+		minSample int
+		size      int
+	}{
+		{"every day is exact", sequence(90), 1, 30, 90},
+		{"sampling off is exact", sequence(90), 0, 30, 90},
+		{"every other day", sequence(90), 2, 30, 45},
+		{"every third day", sequence(90), 3, 30, 30},
+		{"below the minimum sample falls back to exact", sequence(90), 4, 30, 90},
+		{"empty history", nil, 2, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample := SampleHistory(tt.values, tt.every, 7, tt.minSample)
+			if len(sample) != tt.size {
+				t.Fatalf("sampled %d values, want %d", len(sample), tt.size)
+			}
+			if tt.size == len(tt.values) {
+				if !reflect.DeepEqual(sample, tt.values) {
+					t.Errorf("sample = %v, want the full history", sample)
+				}
+				return
+			}
+			for i := 1; i < len(sample); i++ {
+				if sample[i]-sample[i-1] != float64(tt.every) {
+					t.Fatalf("sample = %v, want every %d-th value", sample, tt.every)
+				}
+			}
+			if sample[0] >= float64(tt.every) {
+				t.Errorf("sample starts at %v, want an offset below %d", sample[0], tt.every)
+			}
+		})
+	}
+}
+
+func TestSampleHistoryIsDeterministicPerSeed(t *testing.T) {
+	values := sequence(90)
+	first := SampleHistory(values, 5, 42, 1)
+	if again := SampleHistory(values, 5, 42, 1); !reflect.DeepEqual(first, again) {
+		t.Errorf("same seed sampled %v then %v", first, again)
+	}
+
+	offsets := make(map[float64]bool)
+	for seed := int64(0); seed < 50; seed++ {
+		offsets[SampleHistory(values, 5, seed, 1)[0]] = true
+	}
+	if len(offsets) < 2 {
+		t.Errorf("50 seeds all started the sample at %v, want the offset to vary with the seed", offsets)
+	}
+}
+
+func TestPercentileDetectorSamplingIsOptIn(t *testing.T) {
+	// 90 days to 2024-06-30 of 100, with the latest at 140 and one day of 150 at
+	// an even offset: the exact p99 is 150, and a sample of the odd days misses it
+	latest := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	var series Series
+	for i := 0; i < 90; i++ {
+		cost := 100.0
+		switch i {
+		case 0:
+			cost = 140
+		case 10:
+			cost = 150
+		}
+		series.Daily = append(series.Daily, models.DailyCost{Date: latest.AddDate(0, 0, -i).Format("2006-01-02"), TotalCost: cost})
+	}
+
+	if anomalies := (&PercentileDetector{}).Detect(series, Params{"min_history": 30}); len(anomalies) != 0 {
+		t.Errorf("exact baseline flagged %v against a p99 of 150", anomalies)
+	}
+	flagged := false
+	for seed := int64(0); seed < 10 && !flagged; seed++ {
+		params := Params{"min_history": 30, "sample_every": 2, "sample_seed": float64(seed), "min_sample": 30}
+		flagged = len((&PercentileDetector{}).Detect(series, params)) > 0
+	}
+	if !flagged {
+		t.Error("no seed's sampled baseline skipped the outlier, want sampling to change the baseline")
+	}
+}
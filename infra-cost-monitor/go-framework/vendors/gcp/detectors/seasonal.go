@@ -0,0 +1,125 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"time"
+)
+
+// SeasonalNaiveDetector forecasts each day as the same weekday one season ago,
+// scaled by the recent trend (the last season's total over the season before),
+// and flags the latest day when its residual exceeds k standard deviations of
+// the historical residuals.
+// Params: season (default 7), k (default 3), min_residuals (default 14).
+type SeasonalNaiveDetector struct{}
+
+// Detect compares the latest daily total to its seasonal-naive forecast
+func (d *SeasonalNaiveDetector) Detect(series Series, params Params) []models.Anomaly {
+	season := int(params.Get("season", 7))
+	k := params.Get("k", 3)
+	minResiduals := int(params.Get("min_residuals", 14))
+
+	latest, latestDate, ok := latestDay(series.Daily)
+	if !ok || season <= 0 {
+		return nil
+	}
+
+	costs := make(map[string]float64)
+	earliest := latestDate
+	for _, day := range series.Daily {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		costs[day.Date] = day.TotalCost
+		if date.Before(earliest) {
+			earliest = date
+		}
+	}
+
+	// Residuals for every historical day that has a forecast
+	var residuals []float64
+	for date := earliest; date.Before(latestDate); date = date.AddDate(0, 0, 1) {
+		actual, exists := costs[date.Format("2006-01-02")]
+		if !exists {
+			continue
+		}
+		if expected, ok := seasonalForecast(costs, date, season); ok {
+			residuals = append(residuals, actual-expected)
+		}
+	}
+	if len(residuals) < minResiduals {
+		return nil
+	}
+
+	expected, ok := seasonalForecast(costs, latestDate, season)
+	if !ok {
+		return nil
+	}
+
+	mean := 0.0
+	for _, residual := range residuals {
+		mean += residual
+	}
+	mean /= float64(len(residuals))
+	variance := 0.0
+	for _, residual := range residuals {
+		variance += (residual - mean) * (residual - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(residuals)))
+	if stdDev == 0 {
+		return nil
+	}
+
+	residual := latest.TotalCost - expected
+	score := (residual - mean) / stdDev
+	if math.Abs(score) <= k {
+		return nil
+	}
+
+	severity := models.SeverityMedium
+	if math.Abs(score) > 2*k {
+		severity = models.SeverityHigh
+	}
+
+	return []models.Anomaly{models.Anomaly{
+		Date:        latest.Date,
+		TestName:    "Seasonal-Naive Forecast",
+		Type:        "seasonal_naive",
+		Service:     "daily_total",
+		CostImpact:  residual,
+		Description: fmt.Sprintf("Daily cost (%.2f) differs from the seasonal-naive expectation (%.2f) by %.2f, %.1f standard deviations of historical residuals", latest.TotalCost, expected, residual, score),
+		Severity:    severity,
+		DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+	}.WithValues(latest.TotalCost, expected, expected+mean+math.Copysign(k, score)*stdDev)}
+}
+
+// seasonalForecast predicts the cost on date from the same day one season ago,
+// scaled by the ratio of the last season's total to the season before it
+func seasonalForecast(costs map[string]float64, date time.Time, season int) (float64, bool) {
+	previous, exists := costs[date.AddDate(0, 0, -season).Format("2006-01-02")]
+	if !exists {
+		return 0, false
+	}
+
+	recent, recentOK := seasonTotal(costs, date.AddDate(0, 0, -season), season)
+	prior, priorOK := seasonTotal(costs, date.AddDate(0, 0, -2*season), season)
+	if !recentOK || !priorOK || prior <= 0 {
+		return previous, true
+	}
+	return previous * recent / prior, true
+}
+
+// seasonTotal sums the season days starting at from, reporting false if any is missing
+func seasonTotal(costs map[string]float64, from time.Time, season int) (float64, bool) {
+	total := 0.0
+	for i := 0; i < season; i++ {
+		cost, exists := costs[from.AddDate(0, 0, i).Format("2006-01-02")]
+		if !exists {
+			return 0, false
+		}
+		total += cost
+	}
+	return total, true
+}
@@ -0,0 +1,52 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeasonalNaiveDetector(t *testing.T) {
+	monday := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+	saturday := time.Date(2024, 5, 18, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		daily    []models.DailyCost
+		params   Params
+		severity string
+	}{
+		// Against the weekend before it a Monday looks like a spike; against last Monday it is ordinary
+		{"ordinary Monday", weeklySeries(monday, 10, 1000), nil, ""},
+		{"ordinary Saturday", weeklySeries(saturday, 10, 300), nil, ""},
+		{"Monday spike", weeklySeries(monday, 10, 3000), nil, models.SeverityHigh},
+		{"Saturday at a weekday level", weeklySeries(saturday, 10, 1000), nil, models.SeverityHigh},
+		{"Monday drop", weeklySeries(monday, 10, 300), nil, models.SeverityHigh},
+		{"too few residuals", weeklySeries(monday, 3, 3000), nil, ""},
+		{"fewer residuals required", weeklySeries(monday, 3, 3000), Params{"min_residuals": 7}, models.SeverityHigh},
+		{"moderate spike", weeklySeries(monday, 10, 1075), nil, models.SeverityMedium},
+		{"moderate spike under a looser k", weeklySeries(monday, 10, 1075), Params{"k": 5}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := (&SeasonalNaiveDetector{}).Detect(Series{Daily: tt.daily}, tt.params)
+			if flagged := len(anomalies) > 0; flagged != (tt.severity != "") {
+				t.Fatalf("flagged = %v, want %v: %+v", flagged, tt.severity != "", anomalies)
+			}
+			if tt.severity == "" {
+				return
+			}
+			anomaly := anomalies[0]
+			if anomaly.Severity != tt.severity {
+				t.Errorf("severity = %q, want %q", anomaly.Severity, tt.severity)
+			}
+			if anomaly.Date != tt.daily[0].Date {
+				t.Errorf("anomaly date = %s, want the latest day %s", anomaly.Date, tt.daily[0].Date)
+			}
+			if !strings.Contains(anomaly.Description, "seasonal-naive expectation") {
+				t.Errorf("description %q does not report the expected cost", anomaly.Description)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Threshold is how far a service's daily cost must rise over the previous day to
+// be flagged: by more than Percentage percent or by more than Absolute
+type Threshold struct {
+	Percentage float64 `json:"percentage"`
+	Absolute   float64 `json:"absolute"`
+}
+
+// ServiceThresholds maps service names to thresholds, falling back to Default.
+// Keys may be glob patterns where * matches any run of characters (including /)
+// and ? any single character.
+type ServiceThresholds struct {
+	Default  Threshold            `json:"default"`
+	Services map[string]Threshold `json:"services"`
+}
+
+// DefaultServiceThresholds returns the daily spike defaults for every service
+func DefaultServiceThresholds() ServiceThresholds {
+	return ServiceThresholds{
+		Default: Threshold{Percentage: 50, Absolute: 1000},
+	}
+}
+
+// Validate checks that thresholds are non-negative and patterns compile
+func (st ServiceThresholds) Validate() error {
+	if st.Default.Percentage < 0 || st.Default.Absolute < 0 {
+		return fmt.Errorf("default service threshold must not be negative")
+	}
+	for pattern, threshold := range st.Services {
+		if threshold.Percentage < 0 || threshold.Absolute < 0 {
+			return fmt.Errorf("service threshold for %q must not be negative", pattern)
+		}
+		if _, err := globRegexp(pattern); err != nil {
+			return fmt.Errorf("invalid service pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// For returns the threshold of a service: an exact entry first, then the most
+// specific matching pattern (the most literal characters), then the default
+func (st ServiceThresholds) For(service string) Threshold {
+	if threshold, exists := st.Services[service]; exists {
+		return threshold
+	}
+
+	patterns := make([]string, 0, len(st.Services))
+	for pattern := range st.Services {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		li, lj := literalLength(patterns[i]), literalLength(patterns[j])
+		if li != lj {
+			return li > lj
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if re, err := globRegexp(pattern); err == nil && re.MatchString(service) {
+			return st.Services[pattern]
+		}
+	}
+	return st.Default
+}
+
+// globRegexp compiles a glob pattern into an anchored regular expression
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	return regexp.Compile("^" + expr + "$")
+}
+
+// literalLength counts the characters of a pattern that are not wildcards
+func literalLength(pattern string) int {
+	return len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+}
+
+// ServiceSpikeDetector flags a day-over-day increase in each service's cost,
+// judged against that service's threshold so volatile and flat services can be
+// held to different standards. It takes no params; thresholds come from Thresholds.
+type ServiceSpikeDetector struct {
+	Thresholds ServiceThresholds
+}
+
+// Detect compares each service's cost on the latest date to the day before
+func (d *ServiceSpikeDetector) Detect(series Series, params Params) []models.Anomaly {
+	latest := ""
+	for _, cost := range series.Composite {
+		if cost.Date > latest {
+			latest = cost.Date
+		}
+	}
+	latestDate, err := time.Parse("2006-01-02", latest)
+	if err != nil {
+		return nil
+	}
+	previousDate := latestDate.AddDate(0, 0, -1).Format("2006-01-02")
+
+	currentCosts := make(map[string]float64)
+	previousCosts := make(map[string]float64)
+	for _, cost := range series.Composite {
+		switch cost.Date {
+		case latest:
+			currentCosts[cost.Service] += cost.Cost
+		case previousDate:
+			previousCosts[cost.Service] += cost.Cost
+		}
+	}
+
+	services := make([]string, 0, len(currentCosts))
+	for service := range currentCosts {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	var anomalies []models.Anomaly
+	for _, service := range services {
+		current, previous := currentCosts[service], previousCosts[service]
+		if previous <= 0 {
+			continue
+		}
+
+		increase := current - previous
+		percentage := (increase / previous) * 100
+		threshold := d.Thresholds.For(service)
+		if percentage <= threshold.Percentage && increase <= threshold.Absolute {
+			continue
+		}
+
+		anomalies = append(anomalies, models.Anomaly{
+			Date:         latest,
+			TestName:     "Service Spike Detector",
+			Type:         "service_spike",
+			Service:      service,
+			CompositeKey: service,
+			CostImpact:   increase,
+			Description:  fmt.Sprintf("%s cost rose %.1f%% (%.2f -> %.2f) over the previous day", service, percentage, previous, current),
+			Severity:     models.SeverityForPercentage(percentage),
+			DetectedAt:   time.Now().Format("2006-01-02 15:04:05"),
+		}.WithValues(current, previous, previous+math.Min(previous*threshold.Percentage/100, threshold.Absolute)))
+	}
+	return anomalies
+}
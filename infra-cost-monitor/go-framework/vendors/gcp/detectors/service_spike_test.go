@@ -0,0 +1,111 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+// serviceRise returns composite rows where every service rises from 100 to 160
+func serviceRise(services ...string) Series {
+	var series Series
+	for _, service := range services {
+		series.Composite = append(series.Composite,
+			models.CostData{Date: "2024-05-09", Service: service, Cost: 100},
+			models.CostData{Date: "2024-05-10", Service: service, Cost: 160},
+		)
+	}
+	return series
+}
+
+func TestServiceSpikeDetectorAppliesEachServicesThreshold(t *testing.T) {
+	detector := &ServiceSpikeDetector{Thresholds: ServiceThresholds{
+		Default: Threshold{Percentage: 50, Absolute: 1000},
+		Services: map[string]Threshold{
+			"BigQuery":    {Percentage: 100, Absolute: 1000},
+			"Cloud *":     {Percentage: 75, Absolute: 1000},
+			"Static IP??": {Percentage: 5, Absolute: 1000},
+		},
+	}}
+
+	// The same 60% rise is noise for BigQuery and the Cloud services but a spike
+	// for a static IP and for services on the default threshold
+	anomalies := detector.Detect(serviceRise("BigQuery", "Cloud Storage", "Compute Engine", "Static IPv4"), nil)
+	flagged := make(map[string]bool)
+	for _, anomaly := range anomalies {
+		flagged[anomaly.Service] = true
+	}
+	want := map[string]bool{"Compute Engine": true, "Static IPv4": true}
+	if len(flagged) != len(want) {
+		t.Errorf("flagged %v, want %v", flagged, want)
+	}
+	for service := range want {
+		if !flagged[service] {
+			t.Errorf("%s was not flagged for a 60%% rise", service)
+		}
+	}
+}
+
+func TestServiceSpikeDetectorAbsoluteThreshold(t *testing.T) {
+	thresholds := ServiceThresholds{
+		Default:  Threshold{Percentage: 50, Absolute: 1000},
+		Services: map[string]Threshold{"BigQuery": {Percentage: 100, Absolute: 50}},
+	}
+	anomalies := (&ServiceSpikeDetector{Thresholds: thresholds}).Detect(serviceRise("BigQuery"), nil)
+	if len(anomalies) != 1 {
+		t.Fatalf("got %d anomalies, want BigQuery flagged for rising more than 50", len(anomalies))
+	}
+	if anomalies[0].Threshold != 150 {
+		t.Errorf("threshold = %v, want the lower of the percentage and absolute limits, 150", anomalies[0].Threshold)
+	}
+}
+
+func TestServiceThresholdsFor(t *testing.T) {
+	thresholds := ServiceThresholds{
+		Default: Threshold{Percentage: 50},
+		Services: map[string]Threshold{
+			"Cloud Storage":  {Percentage: 1},
+			"Cloud *":        {Percentage: 2},
+			"Cloud SQL*":     {Percentage: 3},
+			"Compute/?2-*":   {Percentage: 4},
+			"*":              {Percentage: 5},
+			"Networking API": {Percentage: 6},
+		},
+	}
+	tests := map[string]float64{
+		"Cloud Storage":        1,
+		"Cloud Run":            2,
+		"Cloud SQL for MySQL":  3,
+		"Compute/N2-standard":  4,
+		"Compute/N22-standard": 5,
+		"Networking API":       6,
+	}
+	for service, want := range tests {
+		if got := thresholds.For(service).Percentage; got != want {
+			t.Errorf("For(%q) = %v%%, want %v%%", service, got, want)
+		}
+	}
+
+	if got := (ServiceThresholds{Default: Threshold{Percentage: 50}}).For("BigQuery").Percentage; got != 50 {
+		t.Errorf("For without entries = %v%%, want the default 50%%", got)
+	}
+}
+
+func TestServiceThresholdsValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds ServiceThresholds
+		valid      bool
+	}{
+		{"defaults", DefaultServiceThresholds(), true},
+		{"patterns", ServiceThresholds{Services: map[string]Threshold{"Cloud *": {Percentage: 10}}}, true},
+		{"negative default", ServiceThresholds{Default: Threshold{Percentage: -1}}, false},
+		{"negative service", ServiceThresholds{Services: map[string]Threshold{"BigQuery": {Absolute: -1}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.thresholds.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid = %v", err, tt.valid)
+			}
+		})
+	}
+}
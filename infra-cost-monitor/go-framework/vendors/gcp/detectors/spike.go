@@ -0,0 +1,106 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"math"
+	"time"
+)
+
+// DailySpikeDetector flags a day-over-day increase in total cost, or with
+// weekday set to 1 an increase over the same weekday a week earlier.
+// Params: percentage (default 50), absolute (default 1000), weekday (default 0).
+type DailySpikeDetector struct{}
+
+// Detect compares the most recent daily total to the day before, or to the same weekday last week
+func (d *DailySpikeDetector) Detect(series Series, params Params) []models.Anomaly {
+	dailyCosts := series.Daily
+	if len(dailyCosts) < 2 {
+		return nil
+	}
+
+	current := dailyCosts[0].TotalCost
+	previous := dailyCosts[1].TotalCost
+	if params.Get("weekday", 0) == 1 {
+		var found bool
+		previous, found = sameWeekdayLastWeek(dailyCosts)
+		if !found {
+			return nil
+		}
+	}
+	if previous <= 0 {
+		return nil
+	}
+
+	increase := current - previous
+	percentage := (increase / previous) * 100
+	percentageThreshold := params.Get("percentage", 50)
+	absoluteThreshold := params.Get("absolute", 1000)
+	if percentage <= percentageThreshold && increase <= absoluteThreshold {
+		return nil
+	}
+
+	return []models.Anomaly{models.Anomaly{
+		Date:        dailyCosts[0].Date,
+		TestName:    "Daily Spike Detector",
+		Type:        "daily_spike",
+		Service:     "daily_total",
+		CostImpact:  increase,
+		Description: "Daily cost spike detected",
+		Severity:    models.SeverityForPercentage(percentage),
+		DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+	}.WithValues(current, previous, previous+math.Min(previous*percentageThreshold/100, absoluteThreshold))}
+}
+
+// sameWeekdayLastWeek returns the total of the day a week before the latest
+func sameWeekdayLastWeek(dailyCosts []models.DailyCost) (float64, bool) {
+	latest, err := time.Parse("2006-01-02", dailyCosts[0].Date)
+	if err != nil {
+		return 0, false
+	}
+	weekAgo := latest.AddDate(0, 0, -7).Format("2006-01-02")
+	for _, day := range dailyCosts {
+		if day.Date == weekAgo {
+			return day.TotalCost, true
+		}
+	}
+	return 0, false
+}
+
+// MonthlySpikeDetector flags a month-over-month increase in cost.
+// Params: percentage (default 30), absolute (default 5000), and min_days_elapsed
+// (default 0) below which the current month is too young to compare.
+type MonthlySpikeDetector struct{}
+
+// Detect compares the two most recent months, prorating the previous one
+func (d *MonthlySpikeDetector) Detect(series Series, params Params) []models.Anomaly {
+	mtdCosts := series.MTD
+	if len(mtdCosts) < 2 {
+		return nil
+	}
+	if minDays := int(params.Get("min_days_elapsed", 0)); mtdCosts[0].Days < minDays {
+		log.Printf("Skipping monthly spike check: %d of %d days elapsed in %s", mtdCosts[0].Days, minDays, mtdCosts[0].Month)
+		return nil
+	}
+
+	// The previous month is prorated to the days elapsed, so early in the month a
+	// partial month is not compared against a full one
+	percentageThreshold := params.Get("percentage", 30)
+	absoluteThreshold := params.Get("absolute", 5000)
+	comparison := models.CompareMonths(mtdCosts[0], mtdCosts[1], absoluteThreshold, percentageThreshold)
+	if !comparison.Spike {
+		return nil
+	}
+
+	previous := comparison.ProratedPrevious
+	return []models.Anomaly{models.Anomaly{
+		Date:        mtdCosts[0].Month,
+		TestName:    "Monthly Spike Detector",
+		Type:        "monthly_spike",
+		Service:     "monthly_total",
+		CostImpact:  comparison.NormalizedIncrease,
+		Description: "Monthly cost spike detected",
+		Severity:    models.SeverityForPercentage(comparison.NormalizedPercentage),
+		DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+	}.WithValues(comparison.Current, previous, previous+math.Min(previous*percentageThreshold/100, absoluteThreshold))}
+}
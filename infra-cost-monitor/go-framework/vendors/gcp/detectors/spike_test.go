@@ -0,0 +1,95 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+func TestMonthlySpikeDetectorProratesPreviousMonth(t *testing.T) {
+	previous := models.MTDCost{Month: "2024-04", Cost: 3000, Days: 30}
+	tests := []struct {
+		name    string
+		current models.MTDCost
+		spike   bool
+	}{
+		// Day 3 at 400 against 3000 over 30 days is 100/day against 133/day; the raw
+		// comparison would see a drop
+		{"day 3 above the prorated run rate", models.MTDCost{Month: "2024-05", Cost: 400, Days: 3}, true},
+		{"day 3 at the prorated run rate", models.MTDCost{Month: "2024-05", Cost: 300, Days: 3}, false},
+		{"full month above the previous one", models.MTDCost{Month: "2024-05", Cost: 4500, Days: 31}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := (&MonthlySpikeDetector{}).Detect(Series{MTD: []models.MTDCost{tt.current, previous}}, Params{})
+			if spike := len(anomalies) > 0; spike != tt.spike {
+				t.Fatalf("spike = %v, want %v", spike, tt.spike)
+			}
+			if tt.spike && anomalies[0].PreviousValue != previous.Cost*float64(tt.current.Days)/30 {
+				t.Errorf("previous value = %v, want the previous month prorated to %d days", anomalies[0].PreviousValue, tt.current.Days)
+			}
+		})
+	}
+}
+
+func TestMonthlySpikeDetectorWaitsForMinDaysElapsed(t *testing.T) {
+	previous := models.MTDCost{Month: "2024-04", Cost: 3000, Days: 30}
+	tests := []struct {
+		name  string
+		days  int
+		spike bool
+	}{
+		{"before the minimum", 4, false},
+		{"at the minimum", 5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := models.MTDCost{Month: "2024-05", Cost: 1000 * float64(tt.days), Days: tt.days}
+			anomalies := (&MonthlySpikeDetector{}).Detect(Series{MTD: []models.MTDCost{current, previous}}, Params{"min_days_elapsed": 5})
+			if spike := len(anomalies) > 0; spike != tt.spike {
+				t.Errorf("spike = %v, want %v", spike, tt.spike)
+			}
+		})
+	}
+}
+
+func TestTwoDetectorsReportingTheSameSpikeMerge(t *testing.T) {
+	// The latest day rose 25% on the day before but 150% on the same weekday last
+	// week, so the day-over-day and weekday runs report one spike at two severities
+	var daily []models.DailyCost
+	for day := 10; day >= 1; day-- {
+		cost := 2000.0
+		switch day {
+		case 10:
+			cost = 2500
+		case 3:
+			cost = 1000
+		}
+		daily = append(daily, models.DailyCost{Date: fmt.Sprintf("2024-05-%02d", day), TotalCost: cost})
+	}
+	series := Series{Daily: daily}
+	params := Params{"percentage": 20, "absolute": 100000}
+
+	dayOverDay := (&DailySpikeDetector{}).Detect(series, params)
+	params["weekday"] = 1
+	weekday := (&DailySpikeDetector{}).Detect(series, params)
+	if len(dayOverDay) != 1 || len(weekday) != 1 {
+		t.Fatalf("got %d day-over-day and %d weekday anomalies, want one each", len(dayOverDay), len(weekday))
+	}
+	if dayOverDay[0].Severity == weekday[0].Severity {
+		t.Fatalf("both runs rated the spike %s, want different severities", weekday[0].Severity)
+	}
+
+	collection := models.NewAnomalyCollection()
+	collection.AddAnomalies(dayOverDay)
+	collection.AddAnomalies(weekday)
+	merged := collection.All()
+	if len(merged) != 1 {
+		t.Fatalf("got %d anomalies, want the two reports merged into one", len(merged))
+	}
+	if merged[0].Severity != weekday[0].Severity {
+		t.Errorf("merged severity = %s, want the higher %s", merged[0].Severity, weekday[0].Severity)
+	}
+}
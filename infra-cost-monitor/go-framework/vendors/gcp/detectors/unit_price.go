@@ -0,0 +1,89 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"time"
+)
+
+// UnitPriceDriftDetector derives each SKU's daily unit price (cost / usage) and
+// flags the latest day when it moved beyond the threshold from the average unit
+// price of the preceding window, catching price changes and tier shifts. Days
+// without usage have no unit price and are skipped.
+// Params: window (default 7 days), percentage (default 25), min_days (default 3).
+type UnitPriceDriftDetector struct{}
+
+// Detect compares each SKU's latest unit price to its trailing average
+func (d *UnitPriceDriftDetector) Detect(series Series, params Params) []models.Anomaly {
+	window := int(params.Get("window", 7))
+	threshold := params.Get("percentage", 25)
+	minDays := int(params.Get("min_days", 3))
+
+	var anomalies []models.Anomaly
+	for _, entry := range buildSKUSeries(series.Composite) {
+		// Only days with usage have a unit price
+		var dates []string
+		for _, date := range entry.dates() {
+			if entry.days[date].usage > 0 {
+				dates = append(dates, date)
+			}
+		}
+		if len(dates) < 2 {
+			continue
+		}
+
+		latestDate := dates[len(dates)-1]
+		history := dates[:len(dates)-1]
+		if len(history) > window {
+			history = history[len(history)-window:]
+		}
+		if len(history) < minDays {
+			continue
+		}
+
+		var oldPrice float64
+		for _, date := range history {
+			oldPrice += entry.days[date].cost / entry.days[date].usage / float64(len(history))
+		}
+		if oldPrice <= 0 {
+			continue
+		}
+
+		latest := entry.days[latestDate]
+		newPrice := latest.cost / latest.usage
+		percentage := (newPrice - oldPrice) / oldPrice * 100
+		if math.Abs(percentage) <= threshold {
+			continue
+		}
+
+		direction, category := "rose", ""
+		if percentage < 0 {
+			direction, category = "fell", models.CategoryDrop
+		}
+		anomalies = append(anomalies, models.Anomaly{
+			Date:         latestDate,
+			TestName:     "Unit Price Drift Detector",
+			Type:         "unit_price_drift",
+			Category:     category,
+			Service:      entry.service,
+			SKU:          entry.sku,
+			CompositeKey: entry.service + "|" + entry.sku + "|" + entry.unit,
+			// What the latest day's usage cost over what it would have at the old price
+			CostImpact: (newPrice - oldPrice) * latest.usage,
+			Description: fmt.Sprintf("Unit price of %s (%s) %s %.1f%% from %.6f to %.6f per %s versus the %d-day average",
+				entry.sku, entry.service, direction, math.Abs(percentage), oldPrice, newPrice, unitLabel(entry.unit), len(history)),
+			Severity:   models.SeverityForPercentage(math.Abs(percentage)),
+			DetectedAt: time.Now().Format("2006-01-02 15:04:05"),
+		}.WithValues(newPrice, oldPrice, oldPrice*(1+threshold/100)))
+	}
+	return anomalies
+}
+
+// unitLabel names series without a usage unit
+func unitLabel(unit string) string {
+	if unit == "" {
+		return "unit"
+	}
+	return unit
+}
@@ -0,0 +1,76 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"strings"
+	"testing"
+)
+
+func TestUnitPriceDriftDetectorFlagsADoubledPrice(t *testing.T) {
+	// Usage is constant at 100 hours a day while the price goes from 0.5 to 1 per hour
+	series := Series{Composite: skuDays("hour",
+		[]float64{50, 50, 50, 50, 100},
+		[]float64{100, 100, 100, 100, 100},
+	)}
+
+	if anomalies := (&UsageSpikeDetector{}).Detect(series, nil); len(anomalies) != 0 {
+		t.Errorf("usage spike detector flagged %d anomalies on constant usage, want none", len(anomalies))
+	}
+
+	anomalies := (&UnitPriceDriftDetector{}).Detect(series, nil)
+	if len(anomalies) != 1 {
+		t.Fatalf("got %d anomalies, want 1", len(anomalies))
+	}
+	anomaly := anomalies[0]
+	if anomaly.Date != "2024-05-05" || anomaly.Type != "unit_price_drift" || anomaly.Category != "" {
+		t.Errorf("anomaly = %+v, want a unit price rise on 2024-05-05", anomaly)
+	}
+	if anomaly.CurrentValue != 1 || anomaly.PreviousValue != 0.5 || anomaly.PercentageDiff != 100 {
+		t.Errorf("unit price %v -> %v (%v%%), want 0.5 -> 1 (100%%)", anomaly.PreviousValue, anomaly.CurrentValue, anomaly.PercentageDiff)
+	}
+	if anomaly.CostImpact != 50 {
+		t.Errorf("cost impact = %v, want 50 more than the day's usage cost at the old price", anomaly.CostImpact)
+	}
+	if anomaly.Severity != models.SeverityCritical {
+		t.Errorf("severity = %s, want %s", anomaly.Severity, models.SeverityCritical)
+	}
+	if !strings.Contains(anomaly.Description, "from 0.500000 to 1.000000 per hour") {
+		t.Errorf("description %q does not give the old and new unit price", anomaly.Description)
+	}
+}
+
+func TestUnitPriceDriftDetectorFlagsAPriceDrop(t *testing.T) {
+	series := Series{Composite: skuDays("hour", []float64{100, 100, 100, 40}, []float64{100, 100, 100, 100})}
+	anomalies := (&UnitPriceDriftDetector{}).Detect(series, nil)
+	if len(anomalies) != 1 || anomalies[0].Category != models.CategoryDrop || !strings.Contains(anomalies[0].Description, "fell 60.0%") {
+		t.Errorf("anomalies = %+v, want one 60%% unit price drop", anomalies)
+	}
+}
+
+func TestUnitPriceDriftDetectorSkipsDaysWithoutUsage(t *testing.T) {
+	tests := []struct {
+		name  string
+		costs []float64
+		usage []float64
+		drift bool
+	}{
+		// A zero-usage day has no unit price and must not divide by zero
+		{"zero usage on the latest day", []float64{50, 50, 50, 30}, []float64{100, 100, 100, 0}, false},
+		{"zero usage in the history", []float64{50, 9, 50, 50, 100}, []float64{100, 0, 100, 100, 100}, true},
+		{"too few days with usage", []float64{50, 50, 50, 100}, []float64{100, 0, 100, 100}, false},
+		{"free SKU", []float64{0, 0, 0, 0}, []float64{100, 100, 100, 100}, false},
+		{"price within the threshold", []float64{50, 50, 50, 60}, []float64{100, 100, 100, 100}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := (&UnitPriceDriftDetector{}).Detect(Series{Composite: skuDays("hour", tt.costs, tt.usage)}, nil)
+			if drift := len(anomalies) > 0; drift != tt.drift {
+				t.Fatalf("drift = %v, want %v", drift, tt.drift)
+			}
+			if tt.drift && anomalies[0].PreviousValue != 0.5 {
+				t.Errorf("old unit price = %v, want 0.5 from the days with usage", anomalies[0].PreviousValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"sort"
+	"time"
+)
+
+// skuTotals is a SKU's cost and usage on one day
+type skuTotals struct {
+	cost  float64
+	usage float64
+}
+
+// skuSeries is the daily cost and usage of one (service, SKU, unit) series
+type skuSeries struct {
+	service string
+	sku     string
+	unit    string
+	days    map[string]*skuTotals
+}
+
+// dates returns the series' dates in ascending order
+func (s *skuSeries) dates() []string {
+	dates := make([]string, 0, len(s.days))
+	for date := range s.days {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// buildSKUSeries groups composite rows into daily (service, SKU, unit) series, in key order
+func buildSKUSeries(composite []models.CostData) []*skuSeries {
+	index := make(map[string]*skuSeries)
+	var keys []string
+	for _, cost := range composite {
+		key := cost.Service + "|" + cost.SKU + "|" + cost.UsageUnit
+		entry, exists := index[key]
+		if !exists {
+			entry = &skuSeries{service: cost.Service, sku: cost.SKU, unit: cost.UsageUnit, days: make(map[string]*skuTotals)}
+			index[key] = entry
+			keys = append(keys, key)
+		}
+		if entry.days[cost.Date] == nil {
+			entry.days[cost.Date] = &skuTotals{}
+		}
+		entry.days[cost.Date].cost += cost.Cost
+		entry.days[cost.Date].usage += cost.UsageAmount
+	}
+
+	sort.Strings(keys)
+	series := make([]*skuSeries, len(keys))
+	for i, key := range keys {
+		series[i] = index[key]
+	}
+	return series
+}
+
+// UsageSpikeDetector flags a (service, SKU, unit) series whose usage quantity on
+// the latest day is well above its average over the preceding window, whatever
+// its cost did, so "we used more" can be told apart from "the price went up".
+// Params: window (default 7 days), percentage (default 50), min_days (default 3).
+type UsageSpikeDetector struct{}
+
+// Detect compares each series' latest usage to its trailing average
+func (d *UsageSpikeDetector) Detect(series Series, params Params) []models.Anomaly {
+	window := int(params.Get("window", 7))
+	threshold := params.Get("percentage", 50)
+	minDays := int(params.Get("min_days", 3))
+
+	var anomalies []models.Anomaly
+	for _, entry := range buildSKUSeries(series.Composite) {
+		if entry.unit == "" {
+			continue
+		}
+		dates := entry.dates()
+		if len(dates) < 2 {
+			continue
+		}
+
+		latestDate := dates[len(dates)-1]
+		history := dates[:len(dates)-1]
+		if len(history) > window {
+			history = history[len(history)-window:]
+		}
+		if len(history) < minDays {
+			continue
+		}
+
+		var baselineUsage, baselineCost float64
+		for _, date := range history {
+			baselineUsage += entry.days[date].usage / float64(len(history))
+			baselineCost += entry.days[date].cost / float64(len(history))
+		}
+		if baselineUsage <= 0 {
+			continue
+		}
+
+		latest := entry.days[latestDate]
+		usagePercentage := (latest.usage - baselineUsage) / baselineUsage * 100
+		if usagePercentage <= threshold {
+			continue
+		}
+		var costPercentage float64
+		if baselineCost > 0 {
+			costPercentage = (latest.cost - baselineCost) / baselineCost * 100
+		}
+
+		anomalies = append(anomalies, models.Anomaly{
+			Date:         latestDate,
+			TestName:     "Usage Spike Detector",
+			Type:         "usage_spike",
+			Service:      entry.service,
+			SKU:          entry.sku,
+			CompositeKey: entry.service + "|" + entry.sku + "|" + entry.unit,
+			CostImpact:   latest.cost - baselineCost,
+			Description: fmt.Sprintf("Usage of %s (%s) rose %.1f%% to %.2f %s versus the %d-day average (%.2f); cost changed %.1f%%",
+				entry.sku, entry.service, usagePercentage, latest.usage, entry.unit, len(history), baselineUsage, costPercentage),
+			Severity:   models.SeverityForPercentage(usagePercentage),
+			DetectedAt: time.Now().Format("2006-01-02 15:04:05"),
+			Usage: &models.UsageChange{
+				Unit:            entry.unit,
+				CurrentUsage:    latest.usage,
+				BaselineUsage:   baselineUsage,
+				UsageDelta:      latest.usage - baselineUsage,
+				UsagePercentage: usagePercentage,
+				CurrentCost:     latest.cost,
+				BaselineCost:    baselineCost,
+				CostDelta:       latest.cost - baselineCost,
+				CostPercentage:  costPercentage,
+			},
+		}.WithValues(latest.usage, baselineUsage, baselineUsage*(1+threshold/100)))
+	}
+	return anomalies
+}
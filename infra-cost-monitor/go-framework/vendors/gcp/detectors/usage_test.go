@@ -0,0 +1,92 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"strings"
+	"testing"
+)
+
+// skuDays returns one row per day from 2024-05-01 of a Compute VM SKU billed in
+// unit, costing costs[i] for usage[i]
+func skuDays(unit string, costs, usage []float64) []models.CostData {
+	rows := make([]models.CostData, len(costs))
+	for i := range costs {
+		rows[i] = models.CostData{Date: fmt.Sprintf("2024-05-%02d", i+1), Service: "Compute", SKU: "VM", UsageUnit: unit, Cost: costs[i], UsageAmount: usage[i]}
+	}
+	return rows
+}
+
+func TestUsageSpikeDetectorFlagsUsageHiddenByADiscount(t *testing.T) {
+	// Usage doubles on the last day but a committed-use discount halves the price,
+	// so cost stays flat and cost-based detectors see nothing
+	series := Series{Composite: skuDays("hour",
+		[]float64{100, 100, 100, 100, 100},
+		[]float64{50, 50, 50, 50, 100},
+	)}
+
+	if anomalies := (&ServiceSpikeDetector{Thresholds: DefaultServiceThresholds()}).Detect(series, nil); len(anomalies) != 0 {
+		t.Errorf("service spike detector flagged %d anomalies on flat cost, want none", len(anomalies))
+	}
+
+	anomalies := (&UsageSpikeDetector{}).Detect(series, nil)
+	if len(anomalies) != 1 {
+		t.Fatalf("got %d anomalies, want 1", len(anomalies))
+	}
+	anomaly := anomalies[0]
+	if anomaly.Date != "2024-05-05" || anomaly.CompositeKey != "Compute|VM|hour" {
+		t.Errorf("flagged %s on %s, want Compute|VM|hour on 2024-05-05", anomaly.CompositeKey, anomaly.Date)
+	}
+	want := models.UsageChange{
+		Unit:            "hour",
+		CurrentUsage:    100,
+		BaselineUsage:   50,
+		UsageDelta:      50,
+		UsagePercentage: 100,
+		CurrentCost:     100,
+		BaselineCost:    100,
+	}
+	if anomaly.Usage == nil || *anomaly.Usage != want {
+		t.Errorf("usage change = %+v, want %+v", anomaly.Usage, want)
+	}
+	if anomaly.CostImpact != 0 {
+		t.Errorf("cost impact = %v, want 0 for flat cost", anomaly.CostImpact)
+	}
+	if !strings.Contains(anomaly.Description, "rose 100.0%") || !strings.Contains(anomaly.Description, "cost changed 0.0%") {
+		t.Errorf("description %q does not report both the usage and the cost change", anomaly.Description)
+	}
+}
+
+func TestUsageSpikeDetectorSkipsSeries(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []models.CostData
+		params Params
+	}{
+		{"rise within the threshold", skuDays("hour", []float64{1, 1, 1, 1}, []float64{50, 50, 50, 70}), nil},
+		{"too little history", skuDays("hour", []float64{1, 1, 1}, []float64{50, 50, 100}), nil},
+		{"no usage unit", skuDays("", []float64{1, 1, 1, 1}, []float64{50, 50, 50, 100}), nil},
+		{"no baseline usage", skuDays("hour", []float64{1, 1, 1, 1}, []float64{0, 0, 0, 100}), nil},
+		{"raised threshold", skuDays("hour", []float64{1, 1, 1, 1}, []float64{50, 50, 50, 100}), Params{"percentage": 150}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if anomalies := (&UsageSpikeDetector{}).Detect(Series{Composite: tt.rows}, tt.params); len(anomalies) != 0 {
+				t.Errorf("got %d anomalies, want none", len(anomalies))
+			}
+		})
+	}
+}
+
+func TestUsageSpikeDetectorKeepsUnitsApart(t *testing.T) {
+	// The same SKU billed in two units forms two series; only the gibibyte one spikes
+	rows := append(
+		skuDays("hour", []float64{1, 1, 1, 1}, []float64{50, 50, 50, 50}),
+		skuDays("gibibyte", []float64{1, 1, 1, 1}, []float64{10, 10, 10, 40})...,
+	)
+	anomalies := (&UsageSpikeDetector{}).Detect(Series{Composite: rows}, nil)
+	if len(anomalies) != 1 || anomalies[0].CompositeKey != "Compute|VM|gibibyte" {
+		t.Errorf("anomalies = %+v, want only Compute|VM|gibibyte flagged", anomalies)
+	}
+}
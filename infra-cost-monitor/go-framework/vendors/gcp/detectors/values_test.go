@@ -0,0 +1,91 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"log"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// spikeSeries is a series every spike-side detector flags: 200 days of slightly
+// noisy daily totals ending in a jump, a month running well above the last, and a
+// SKU whose cost, usage and unit price all rise while its region takes the whole spend
+func spikeSeries() Series {
+	latest := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	var series Series
+	for i := 0; i < 200; i++ {
+		cost := 1000 + float64(i%3)*10
+		if i == 0 {
+			cost = 5000
+		}
+		series.Daily = append(series.Daily, models.DailyCost{Date: latest.AddDate(0, 0, -i).Format("2006-01-02"), TotalCost: cost})
+	}
+	series.MTD = []models.MTDCost{
+		{Month: "2024-05", Cost: 100000, Days: 10},
+		{Month: "2024-04", Cost: 30000, Days: 30},
+	}
+	for i := 4; i >= 1; i-- {
+		date := latest.AddDate(0, 0, -i).Format("2006-01-02")
+		series.Composite = append(series.Composite,
+			models.CostData{Date: date, Service: "Compute", SKU: "VM", Region: "us-east1", Cost: 10, UsageAmount: 10, UsageUnit: "hour"},
+			models.CostData{Date: date, Service: "Storage", SKU: "Disk", Region: "europe-west1", Cost: 10, UsageAmount: 10, UsageUnit: "gibibyte month"},
+		)
+	}
+	series.Composite = append(series.Composite,
+		models.CostData{Date: latest.Format("2006-01-02"), Service: "Compute", SKU: "VM", Region: "us-east1", Cost: 100, UsageAmount: 50, UsageUnit: "hour"})
+	return series
+}
+
+// dropSeries is a series the drop detectors flag
+func dropSeries() Series {
+	return Series{
+		Daily: []models.DailyCost{{Date: "2024-05-10", TotalCost: 100}, {Date: "2024-05-09", TotalCost: 1000}},
+		MTD: []models.MTDCost{
+			{Month: "2024-05", Cost: 1000, Days: 10},
+			{Month: "2024-04", Cost: 30000, Days: 30},
+		},
+	}
+}
+
+func TestDetectorsPopulateValues(t *testing.T) {
+	registry := NewDefaultRegistry()
+	registry.Register("zscore", &ZScoreDetector{})
+
+	for _, name := range registry.Names() {
+		t.Run(name, func(t *testing.T) {
+			series := spikeSeries()
+			if name == "daily_drop" || name == "monthly_drop" {
+				series = dropSeries()
+			}
+			anomalies := registry.detectors[name].Detect(series, nil)
+			if len(anomalies) == 0 {
+				t.Fatal("detector flagged nothing on its fixture")
+			}
+			for _, anomaly := range anomalies {
+				if anomaly.CurrentValue == 0 && anomaly.PreviousValue == 0 {
+					t.Errorf("%s: current and previous values are unset", anomaly.Type)
+				}
+				if anomaly.Threshold == 0 {
+					t.Errorf("%s: threshold is unset", anomaly.Type)
+				}
+				if delta := anomaly.CurrentValue - anomaly.PreviousValue; anomaly.Delta != delta {
+					t.Errorf("%s: delta = %v, want %v", anomaly.Type, anomaly.Delta, delta)
+				}
+				if anomaly.PreviousValue != 0 {
+					want := anomaly.Delta / math.Abs(anomaly.PreviousValue) * 100
+					if math.Abs(anomaly.PercentageDiff-want) > 1e-9 {
+						t.Errorf("%s: percentage diff = %v, want %v", anomaly.Type, anomaly.PercentageDiff, want)
+					}
+				}
+			}
+		})
+	}
+}
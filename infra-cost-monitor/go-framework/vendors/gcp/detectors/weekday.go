@@ -0,0 +1,79 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"time"
+)
+
+// WeekdayBaselineDetector builds a baseline (mean and standard deviation) from
+// the same weekday over the previous weeks and flags the latest day when it is
+// more than k standard deviations from its own weekday's baseline, so naturally
+// lower weekend spend does not make Mondays look like spikes.
+// Params: weeks (default 8), min_weeks (default 4), k (default 3).
+type WeekdayBaselineDetector struct{}
+
+// Detect compares the latest daily total to the baseline of its weekday
+func (d *WeekdayBaselineDetector) Detect(series Series, params Params) []models.Anomaly {
+	weeks := int(params.Get("weeks", 8))
+	minWeeks := int(params.Get("min_weeks", 4))
+	k := params.Get("k", 3)
+
+	latest, latestDate, ok := latestDay(series.Daily)
+	if !ok {
+		return nil
+	}
+
+	costs := make(map[string]float64, len(series.Daily))
+	for _, day := range series.Daily {
+		costs[day.Date] = day.TotalCost
+	}
+
+	// The same weekday in each of the previous weeks
+	var baseline []float64
+	for week := 1; week <= weeks; week++ {
+		if cost, exists := costs[latestDate.AddDate(0, 0, -7*week).Format("2006-01-02")]; exists {
+			baseline = append(baseline, cost)
+		}
+	}
+	if len(baseline) < minWeeks || len(baseline) < 2 {
+		return nil
+	}
+
+	var mean float64
+	for _, cost := range baseline {
+		mean += cost
+	}
+	mean /= float64(len(baseline))
+	var variance float64
+	for _, cost := range baseline {
+		variance += (cost - mean) * (cost - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(baseline)-1))
+	if stdDev == 0 {
+		return nil
+	}
+
+	z := (latest.TotalCost - mean) / stdDev
+	if math.Abs(z) <= k {
+		return nil
+	}
+
+	severity := models.SeverityMedium
+	if math.Abs(z) > 2*k {
+		severity = models.SeverityHigh
+	}
+	weekday := latestDate.Weekday()
+
+	return []models.Anomaly{models.Anomaly{
+		Date:        latest.Date,
+		TestName:    "Weekday Baseline Detector",
+		Type:        "weekday_baseline",
+		Service:     "daily_total",
+		CostImpact:  latest.TotalCost - mean,
+		Description: fmt.Sprintf("%s cost (%.2f) is %.1f standard deviations from the %s baseline of %.2f over %d weeks", weekday, latest.TotalCost, z, weekday, mean, len(baseline)),
+		Severity:    severity,
+		DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+	}.WithValues(latest.TotalCost, mean, mean+math.Copysign(k, z)*stdDev)}
+}
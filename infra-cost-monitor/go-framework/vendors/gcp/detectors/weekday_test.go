@@ -0,0 +1,84 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+	"time"
+)
+
+// weeklySeries returns weeks of daily totals ending on end, newest first, with
+// weekdays near 1000 and weekends near 300, and latest as the cost of end itself
+func weeklySeries(end time.Time, weeks int, latest float64) []models.DailyCost {
+	days := weeks * 7
+	daily := make([]models.DailyCost, days)
+	for i := 0; i < days; i++ {
+		date := end.AddDate(0, 0, -i)
+		cost := 1000.0
+		if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			cost = 300
+		}
+		cost += float64((i/7)%3) * 10
+		if i == 0 {
+			cost = latest
+		}
+		daily[i] = models.DailyCost{Date: date.Format("2006-01-02"), TotalCost: cost}
+	}
+	return daily
+}
+
+func TestWeekdayBaselineOnAWeeklyCycle(t *testing.T) {
+	monday := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+	wednesday := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		daily    []models.DailyCost
+		raw      bool
+		adjusted bool
+		baseline bool
+	}{
+		// Monday against Sunday looks like a spike, but it is an ordinary Monday
+		{"ordinary Monday", weeklySeries(monday, 10, 1010), true, false, false},
+		{"ordinary Wednesday", weeklySeries(wednesday, 10, 1010), false, false, false},
+		{"Wednesday anomaly", weeklySeries(wednesday, 10, 2000), true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := Series{Daily: tt.daily}
+			raw := (&DailySpikeDetector{}).Detect(series, nil)
+			adjusted := (&DailySpikeDetector{}).Detect(series, Params{"weekday": 1})
+			baseline := (&WeekdayBaselineDetector{}).Detect(series, nil)
+
+			if got := len(raw) > 0; got != tt.raw {
+				t.Errorf("raw spike flagged = %v, want %v", got, tt.raw)
+			}
+			if got := len(adjusted) > 0; got != tt.adjusted {
+				t.Errorf("weekday-adjusted spike flagged = %v, want %v", got, tt.adjusted)
+			}
+			if got := len(baseline) > 0; got != tt.baseline {
+				t.Fatalf("weekday baseline flagged = %v, want %v", got, tt.baseline)
+			}
+			if tt.baseline {
+				anomaly := baseline[0]
+				if anomaly.Date != "2024-05-15" || anomaly.Severity != models.SeverityHigh {
+					t.Errorf("anomaly on %s with severity %s, want 2024-05-15 HIGH", anomaly.Date, anomaly.Severity)
+				}
+				// The baseline is the eight previous Wednesdays
+				if anomaly.PreviousValue < 1000 || anomaly.PreviousValue > 1020 {
+					t.Errorf("baseline = %v, want the Wednesday mean between 1000 and 1020", anomaly.PreviousValue)
+				}
+			}
+		})
+	}
+}
+
+func TestWeekdayBaselineNeedsMinWeeks(t *testing.T) {
+	wednesday := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	series := Series{Daily: weeklySeries(wednesday, 4, 2000)}
+	if anomalies := (&WeekdayBaselineDetector{}).Detect(series, nil); len(anomalies) != 0 {
+		t.Errorf("flagged with 3 previous Wednesdays, want none below min_weeks 4")
+	}
+	if anomalies := (&WeekdayBaselineDetector{}).Detect(series, Params{"min_weeks": 3}); len(anomalies) != 1 {
+		t.Errorf("got %d anomalies with min_weeks 3, want 1", len(anomalies))
+	}
+}
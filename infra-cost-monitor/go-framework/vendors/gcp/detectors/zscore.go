@@ -0,0 +1,73 @@
+package detectors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"time"
+)
+
+// ZScoreDetector flags every day whose total is more than threshold standard
+// deviations from the mean of the series, in either direction.
+// Params: threshold (default 3).
+type ZScoreDetector struct{}
+
+// Detect computes the mean and standard deviation of the daily totals and flags outliers
+func (d *ZScoreDetector) Detect(series Series, params Params) []models.Anomaly {
+	threshold := params.Get("threshold", 3)
+	dailyCosts := series.Daily
+	if len(dailyCosts) < 2 {
+		return nil
+	}
+
+	var mean float64
+	for _, day := range dailyCosts {
+		mean += day.TotalCost
+	}
+	mean /= float64(len(dailyCosts))
+
+	var variance float64
+	for _, day := range dailyCosts {
+		variance += (day.TotalCost - mean) * (day.TotalCost - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(dailyCosts)))
+	if stdDev == 0 {
+		return nil
+	}
+
+	var anomalies []models.Anomaly
+	for _, day := range dailyCosts {
+		z := (day.TotalCost - mean) / stdDev
+		if math.Abs(z) <= threshold {
+			continue
+		}
+
+		bound := mean + threshold*stdDev
+		if z < 0 {
+			bound = mean - threshold*stdDev
+		}
+		anomalies = append(anomalies, models.Anomaly{
+			Date:        day.Date,
+			TestName:    "Z-Score Detector",
+			Type:        "zscore",
+			Service:     "daily_total",
+			CostImpact:  day.TotalCost - mean,
+			Description: fmt.Sprintf("Daily cost (%.2f) has a z-score of %.2f against the mean of %.2f (std dev %.2f)", day.TotalCost, z, mean, stdDev),
+			Severity:    zScoreSeverity(math.Abs(z), threshold),
+			DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+		}.WithValues(day.TotalCost, mean, bound))
+	}
+	return anomalies
+}
+
+// zScoreSeverity bands the absolute z-score: up to threshold+1 is MEDIUM, up to
+// threshold+2 is HIGH and beyond that CRITICAL
+func zScoreSeverity(z, threshold float64) string {
+	switch {
+	case z > threshold+2:
+		return models.SeverityCritical
+	case z > threshold+1:
+		return models.SeverityHigh
+	}
+	return models.SeverityMedium
+}
@@ -0,0 +1,25 @@
+package detectors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+func TestZScoreSeverity(t *testing.T) {
+	tests := []struct {
+		z    float64
+		want string
+	}{
+		{3.5, models.SeverityMedium},
+		{4, models.SeverityMedium},
+		{4.5, models.SeverityHigh},
+		{5, models.SeverityHigh},
+		{5.5, models.SeverityCritical},
+	}
+
+	for _, tt := range tests {
+		if got := zScoreSeverity(tt.z, 3); got != tt.want {
+			t.Errorf("zScoreSeverity(%v, 3) = %s, want %s", tt.z, got, tt.want)
+		}
+	}
+}
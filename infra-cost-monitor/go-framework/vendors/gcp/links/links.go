@@ -0,0 +1,109 @@
+package links
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Template is a named dashboard URL template. Placeholders are {service},
+// {sku}, {project}, {date}, {from} and {to}, where from/to span the
+// WindowDays days up to the anomaly date.
+type Template struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	WindowDays int    `json:"window_days"`
+}
+
+// placeholders lists the supported template placeholders
+var placeholders = map[string]bool{
+	"service": true,
+	"sku":     true,
+	"project": true,
+	"date":    true,
+	"from":    true,
+	"to":      true,
+}
+
+// Validate checks that a template only uses known placeholders and renders to an absolute URL
+func (t Template) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("dashboard link needs a name")
+	}
+	if t.WindowDays < 0 {
+		return fmt.Errorf("dashboard link %s: window_days must not be negative", t.Name)
+	}
+
+	rest := t.URL
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			break
+		}
+		if rest[open] == '}' {
+			return fmt.Errorf("dashboard link %s: unmatched '}' in template", t.Name)
+		}
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] != '}' {
+			return fmt.Errorf("dashboard link %s: unterminated placeholder in template", t.Name)
+		}
+		name := rest[open+1 : open+1+end]
+		if !placeholders[name] {
+			return fmt.Errorf("dashboard link %s: unknown placeholder {%s}", t.Name, name)
+		}
+		rest = rest[open+1+end+1:]
+	}
+
+	rendered, err := url.Parse(t.Render(models.Anomaly{Date: "2006-01-02"}))
+	if err != nil {
+		return fmt.Errorf("dashboard link %s: invalid URL: %v", t.Name, err)
+	}
+	if rendered.Scheme == "" || rendered.Host == "" {
+		return fmt.Errorf("dashboard link %s: URL must be absolute", t.Name)
+	}
+	return nil
+}
+
+// Render fills the template's placeholders from the anomaly, escaping each value
+func (t Template) Render(anomaly models.Anomaly) string {
+	from, to := anomaly.Date, anomaly.Date
+	if date, err := time.Parse("2006-01-02", anomaly.Date); err == nil {
+		from = date.AddDate(0, 0, -t.WindowDays).Format("2006-01-02")
+	}
+
+	replacer := strings.NewReplacer(
+		"{service}", escape(anomaly.Service),
+		"{sku}", escape(anomaly.SKU),
+		"{project}", escape(anomaly.ProjectID),
+		"{date}", escape(anomaly.Date),
+		"{from}", escape(from),
+		"{to}", escape(to),
+	)
+	return replacer.Replace(t.URL)
+}
+
+// escape encodes a value so it is safe in both a URL path and its query
+func escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// Annotate attaches the rendered link for each template to every anomaly
+func Annotate(anomalies []models.Anomaly, templates []Template) []models.Anomaly {
+	if len(templates) == 0 {
+		return anomalies
+	}
+
+	log.Printf("🔗 Adding %d dashboard links to anomalies", len(templates))
+	for i := range anomalies {
+		for _, template := range templates {
+			anomalies[i].Links = append(anomalies[i].Links, models.Link{
+				Name: template.Name,
+				URL:  template.Render(anomalies[i]),
+			})
+		}
+	}
+	return anomalies
+}
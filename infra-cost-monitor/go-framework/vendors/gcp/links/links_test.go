@@ -0,0 +1,114 @@
+package links
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestTemplateRender(t *testing.T) {
+	anomaly := models.Anomaly{Date: "2024-03-10", Service: "Compute Engine", SKU: "N2 Core", ProjectID: "prod&eu"}
+	tests := []struct {
+		name     string
+		template Template
+		want     string
+	}{
+		{
+			"all placeholders",
+			Template{URL: "https://grafana.example.com/d/cost?service={service}&sku={sku}&project={project}&from={from}&to={to}", WindowDays: 7},
+			"https://grafana.example.com/d/cost?service=Compute%20Engine&sku=N2%20Core&project=prod%26eu&from=2024-03-03&to=2024-03-10",
+		},
+		{
+			"path placeholders",
+			Template{URL: "https://console.example.com/billing/{project}/{date}"},
+			"https://console.example.com/billing/prod%26eu/2024-03-10",
+		},
+		{
+			"zero window",
+			Template{URL: "https://example.com/?from={from}&to={to}"},
+			"https://example.com/?from=2024-03-10&to=2024-03-10",
+		},
+		{
+			"no placeholders",
+			Template{URL: "https://example.com/costs"},
+			"https://example.com/costs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.template.Render(anomaly); got != tt.want {
+				t.Errorf("Render() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template Template
+		want     string
+	}{
+		{"valid", Template{Name: "grafana", URL: "https://grafana.example.com/d/cost?service={service}&from={from}"}, ""},
+		{"no name", Template{URL: "https://example.com"}, "needs a name"},
+		{"negative window", Template{Name: "g", URL: "https://example.com", WindowDays: -1}, "window_days"},
+		{"unknown placeholder", Template{Name: "g", URL: "https://example.com/{region}"}, "unknown placeholder {region}"},
+		{"unterminated placeholder", Template{Name: "g", URL: "https://example.com/{service"}, "unterminated"},
+		{"nested brace", Template{Name: "g", URL: "https://example.com/{ser{vice}"}, "unterminated"},
+		{"unmatched close", Template{Name: "g", URL: "https://example.com/service}"}, "unmatched"},
+		{"relative URL", Template{Name: "g", URL: "/d/cost?service={service}"}, "absolute"},
+		{"unparseable URL", Template{Name: "g", URL: "https://exa mple.com/{service}"}, "invalid URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.template.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	anomalies := []models.Anomaly{{Date: "2024-03-10", Service: "Compute"}, {Date: "2024-03-11", Service: "Storage"}}
+	templates := []Template{
+		{Name: "grafana", URL: "https://grafana.example.com/?service={service}"},
+		{Name: "console", URL: "https://console.example.com/{date}"},
+	}
+
+	annotated := Annotate(anomalies, templates)
+	want := [][]models.Link{
+		{{Name: "grafana", URL: "https://grafana.example.com/?service=Compute"}, {Name: "console", URL: "https://console.example.com/2024-03-10"}},
+		{{Name: "grafana", URL: "https://grafana.example.com/?service=Storage"}, {Name: "console", URL: "https://console.example.com/2024-03-11"}},
+	}
+	for i, anomaly := range annotated {
+		if len(anomaly.Links) != len(want[i]) {
+			t.Fatalf("anomaly %d links = %+v, want %+v", i, anomaly.Links, want[i])
+		}
+		for j, link := range anomaly.Links {
+			if link != want[i][j] {
+				t.Errorf("anomaly %d link %d = %+v, want %+v", i, j, link, want[i][j])
+			}
+		}
+	}
+
+	if unchanged := Annotate([]models.Anomaly{{Service: "Compute"}}, nil); len(unchanged[0].Links) != 0 {
+		t.Errorf("links = %+v without templates, want none", unchanged[0].Links)
+	}
+}
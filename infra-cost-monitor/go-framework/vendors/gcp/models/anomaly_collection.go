@@ -0,0 +1,78 @@
+package models
+
+import (
+	"strings"
+	"sync"
+)
+
+// AnomalyCollection gathers the anomalies of a run from several detectors. The
+// same spike reported twice (same test, date and composite key) is kept once, at
+// the highest severity either report gave it. It is safe for concurrent use, so
+// monitors running in goroutines can share one collection.
+type AnomalyCollection struct {
+	mu        sync.Mutex
+	anomalies []Anomaly
+	index     map[string]int
+}
+
+// NewAnomalyCollection creates a new, empty anomaly collection
+func NewAnomalyCollection() *AnomalyCollection {
+	return &AnomalyCollection{
+		index: make(map[string]int),
+	}
+}
+
+// DedupKey identifies the spike an anomaly reports: its test name, date and
+// composite key, or its service and SKU when it has no composite key. Charge type
+// and vendor splits of the same series stay distinct.
+func (a Anomaly) DedupKey() string {
+	key := a.CompositeKey
+	if key == "" {
+		key = a.Service + "|" + a.SKU
+	}
+	return strings.Join([]string{a.TestName, a.Date, key, a.CostType, a.Vendor}, "|")
+}
+
+// AddAnomaly adds an anomaly, merging it into an earlier report of the same spike
+func (c *AnomalyCollection) AddAnomaly(anomaly Anomaly) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.index == nil {
+		c.index = make(map[string]int)
+	}
+
+	key := anomaly.DedupKey()
+	i, exists := c.index[key]
+	if !exists {
+		c.index[key] = len(c.anomalies)
+		c.anomalies = append(c.anomalies, anomaly)
+		return
+	}
+	if SeverityRank(anomaly.Severity) > SeverityRank(c.anomalies[i].Severity) {
+		c.anomalies[i].Severity = anomaly.Severity
+	}
+}
+
+// AddAnomalies adds each anomaly in turn
+func (c *AnomalyCollection) AddAnomalies(anomalies []Anomaly) {
+	for _, anomaly := range anomalies {
+		c.AddAnomaly(anomaly)
+	}
+}
+
+// All returns a copy of the merged anomalies in the order they were first reported
+func (c *AnomalyCollection) All() []Anomaly {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Anomaly(nil), c.anomalies...)
+}
+
+// Len returns the number of distinct anomalies
+func (c *AnomalyCollection) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.anomalies)
+}
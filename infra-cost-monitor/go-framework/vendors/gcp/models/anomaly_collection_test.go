@@ -0,0 +1,146 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestAnomalyCollectionMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		anomalies []Anomaly
+		want      []string // severities of the merged anomalies, in order
+	}{
+		{
+			name: "same spike keeps highest severity",
+			anomalies: []Anomaly{
+				{TestName: "spike", Date: "2024-03-01", CompositeKey: "Compute|VM", Severity: "MEDIUM"},
+				{TestName: "spike", Date: "2024-03-01", CompositeKey: "Compute|VM", Severity: "CRITICAL"},
+				{TestName: "spike", Date: "2024-03-01", CompositeKey: "Compute|VM", Severity: "LOW"},
+			},
+			want: []string{"CRITICAL"},
+		},
+		{
+			name: "different composite keys stay distinct",
+			anomalies: []Anomaly{
+				{TestName: "spike", Date: "2024-03-01", CompositeKey: "Compute|VM", Severity: "HIGH"},
+				{TestName: "spike", Date: "2024-03-01", CompositeKey: "Storage|Disk", Severity: "LOW"},
+			},
+			want: []string{"HIGH", "LOW"},
+		},
+		{
+			name: "different dates and tests stay distinct",
+			anomalies: []Anomaly{
+				{TestName: "spike", Date: "2024-03-01", CompositeKey: "Compute|VM", Severity: "HIGH"},
+				{TestName: "spike", Date: "2024-03-02", CompositeKey: "Compute|VM", Severity: "HIGH"},
+				{TestName: "drop", Date: "2024-03-01", CompositeKey: "Compute|VM", Severity: "HIGH"},
+			},
+			want: []string{"HIGH", "HIGH", "HIGH"},
+		},
+		{
+			name: "charge type and vendor splits stay distinct",
+			anomalies: []Anomaly{
+				{TestName: "spike", Date: "2024-03-01", CompositeKey: "Compute", CostType: "usage", Severity: "HIGH"},
+				{TestName: "spike", Date: "2024-03-01", CompositeKey: "Compute", CostType: "tax", Severity: "HIGH"},
+				{TestName: "spike", Date: "2024-03-01", CompositeKey: "Compute", Vendor: "aws", Severity: "HIGH"},
+			},
+			want: []string{"HIGH", "HIGH", "HIGH"},
+		},
+		{
+			name: "without composite keys different services stay distinct",
+			anomalies: []Anomaly{
+				{TestName: "new_sku", Date: "2024-03-01", Service: "Compute", SKU: "VM", Severity: "MEDIUM"},
+				{TestName: "new_sku", Date: "2024-03-01", Service: "Storage", SKU: "Disk", Severity: "MEDIUM"},
+			},
+			want: []string{"MEDIUM", "MEDIUM"},
+		},
+		{
+			name: "without composite keys different SKUs stay distinct",
+			anomalies: []Anomaly{
+				{TestName: "new_sku", Date: "2024-03-01", Service: "Compute", SKU: "VM", Severity: "MEDIUM"},
+				{TestName: "new_sku", Date: "2024-03-01", Service: "Compute", SKU: "GPU", Severity: "HIGH"},
+			},
+			want: []string{"MEDIUM", "HIGH"},
+		},
+		{
+			name: "without composite keys the same SKU merges",
+			anomalies: []Anomaly{
+				{TestName: "new_sku", Date: "2024-03-01", Service: "Compute", SKU: "VM", Severity: "MEDIUM"},
+				{TestName: "new_sku", Date: "2024-03-01", Service: "Compute", SKU: "VM", Severity: "HIGH"},
+			},
+			want: []string{"HIGH"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collection := NewAnomalyCollection()
+			collection.AddAnomalies(tt.anomalies)
+
+			got := collection.All()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d anomalies, want %d", len(got), len(tt.want))
+			}
+			for i, anomaly := range got {
+				if anomaly.Severity != tt.want[i] {
+					t.Errorf("anomaly %d has severity %q, want %q", i, anomaly.Severity, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAnomalyCollectionConcurrentAdds(t *testing.T) {
+	const workers, keys = 16, 100
+	severities := []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+	// Every worker reports every key, so each key is reported by all of them at
+	// the severity of the worker's turn; only the highest must survive
+	collection := NewAnomalyCollection()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for k := 0; k < keys; k++ {
+				collection.AddAnomaly(Anomaly{
+					TestName:     "spike",
+					Date:         "2024-03-01",
+					CompositeKey: fmt.Sprintf("key-%d", k),
+					Severity:     severities[w%len(severities)],
+				})
+				collection.Len()
+				collection.All()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if collection.Len() != keys {
+		t.Fatalf("Len() = %d, want %d", collection.Len(), keys)
+	}
+	for _, anomaly := range collection.All() {
+		if anomaly.Severity != SeverityCritical {
+			t.Errorf("%s has severity %s, want %s", anomaly.CompositeKey, anomaly.Severity, SeverityCritical)
+		}
+	}
+}
+
+func TestAnomalyCollectionZeroValue(t *testing.T) {
+	var collection AnomalyCollection
+	collection.AddAnomaly(Anomaly{TestName: "spike", Date: "2024-03-01", Severity: SeverityLow})
+	collection.AddAnomaly(Anomaly{TestName: "spike", Date: "2024-03-01", Severity: SeverityHigh})
+	if all := collection.All(); len(all) != 1 || all[0].Severity != SeverityHigh {
+		t.Errorf("All() = %+v, want one HIGH anomaly", all)
+	}
+}
+
+func TestAnomalyCollectionAllReturnsACopy(t *testing.T) {
+	collection := NewAnomalyCollection()
+	collection.AddAnomaly(Anomaly{TestName: "spike", Date: "2024-03-01", Severity: SeverityLow})
+	collection.All()[0].Severity = SeverityCritical
+	if got := collection.All()[0].Severity; got != SeverityLow {
+		t.Errorf("severity = %s after modifying All()'s result, want %s", got, SeverityLow)
+	}
+}
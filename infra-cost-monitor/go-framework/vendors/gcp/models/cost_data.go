@@ -1,7 +1,11 @@
 package models
 
 import (
-	"time"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sort"
+	"strings"
 )
 
 // CostData represents a single cost record
@@ -13,14 +17,73 @@ type CostData struct {
 	ProjectName string  `json:"project_name"`
 	Region      string  `json:"region"`
 	Cost        float64 `json:"cost"`
+	Credits     float64 `json:"credits,omitempty"`
 	UsageAmount float64 `json:"usage_amount"`
 	UsageUnit   string  `json:"usage_unit"`
+	Currency    string  `json:"currency,omitempty"`
+	CostType    string  `json:"cost_type,omitempty"`
+	Vendor      string  `json:"vendor,omitempty"`
+
+	// Set on records produced by shared-cost allocation
+	AllocatedFrom  string `json:"allocated_from,omitempty"`
+	AllocationRule string `json:"allocation_rule,omitempty"`
+}
+
+// compositeKeyEscaper escapes the key delimiter, and the escape character itself,
+// inside key fields
+var compositeKeyEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`)
+
+// CompositeKey returns the service|sku|project_id|region key identifying the
+// record's cost line. Backslashes and pipes within fields are escaped with a
+// backslash, so a field containing the delimiter can't collide with another key.
+func (c CostData) CompositeKey() string {
+	return strings.Join([]string{
+		compositeKeyEscaper.Replace(c.Service),
+		compositeKeyEscaper.Replace(c.SKU),
+		compositeKeyEscaper.Replace(c.ProjectID),
+		compositeKeyEscaper.Replace(c.Region),
+	}, "|")
+}
+
+// SplitCompositeKey splits a key built by CompositeKey into its service, SKU,
+// project ID and region, unescaping each field. ok is false for keys of any other
+// shape, such as the service|sku|unit keys of the usage detectors.
+func SplitCompositeKey(key string) (service, sku, projectID, region string, ok bool) {
+	var fields []string
+	var field strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\' && i+1 < len(key):
+			i++
+			field.WriteByte(key[i])
+		case key[i] == '|':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(key[i])
+		}
+	}
+	fields = append(fields, field.String())
+
+	if len(fields) != 4 {
+		return "", "", "", "", false
+	}
+	return fields[0], fields[1], fields[2], fields[3], true
 }
 
 // DailyCost represents daily aggregated cost
 type DailyCost struct {
 	Date      string  `json:"date"`
 	TotalCost float64 `json:"total_cost"`
+	Currency  string  `json:"currency,omitempty"`
+}
+
+// SortDailyCostsDesc sorts daily costs newest first, in place. Day-over-day
+// comparisons read index 0 as the latest day and index 1 as the day before.
+func SortDailyCostsDesc(dailyCosts []DailyCost) {
+	sort.SliceStable(dailyCosts, func(i, j int) bool {
+		return dailyCosts[i].Date > dailyCosts[j].Date
+	})
 }
 
 // MTDCost represents month-to-date cost
@@ -28,6 +91,95 @@ type MTDCost struct {
 	Month     string  `json:"month"`
 	Cost      float64 `json:"cost"`
 	Days      int     `json:"days"`
+	Currency  string  `json:"currency,omitempty"`
+}
+
+// MonthlyComparison compares the current month to date with the previous month,
+// both raw and with the previous month prorated to the same number of days
+type MonthlyComparison struct {
+	Current              float64 `json:"current"`
+	Previous             float64 `json:"previous"`
+	RawIncrease          float64 `json:"raw_increase"`
+	RawPercentage        float64 `json:"raw_percentage"`
+	ProratedPrevious     float64 `json:"prorated_previous"`
+	NormalizedIncrease   float64 `json:"normalized_increase"`
+	NormalizedPercentage float64 `json:"normalized_percentage"`
+	Spike                bool    `json:"spike"`
+}
+
+// CompareMonths compares the current month to date with the previous month. The
+// previous month is prorated to the days elapsed in the current month, and it is
+// a spike when the normalized increase exceeds absoluteThreshold or
+// percentageThreshold percent, so partial months compare fairly.
+func CompareMonths(current, previous MTDCost, absoluteThreshold, percentageThreshold float64) MonthlyComparison {
+	comparison := MonthlyComparison{
+		Current:  current.Cost,
+		Previous: previous.Cost,
+	}
+	if previous.Cost <= 0 {
+		return comparison
+	}
+
+	comparison.RawIncrease = current.Cost - previous.Cost
+	comparison.RawPercentage = (comparison.RawIncrease / previous.Cost) * 100
+
+	comparison.ProratedPrevious = previous.Cost
+	if previous.Days > 0 && current.Days > 0 {
+		comparison.ProratedPrevious = previous.Cost * float64(current.Days) / float64(previous.Days)
+	}
+	if comparison.ProratedPrevious == 0 {
+		return comparison
+	}
+	comparison.NormalizedIncrease = current.Cost - comparison.ProratedPrevious
+	comparison.NormalizedPercentage = (comparison.NormalizedIncrease / comparison.ProratedPrevious) * 100
+	comparison.Spike = comparison.NormalizedIncrease > absoluteThreshold || comparison.NormalizedPercentage > percentageThreshold
+	return comparison
+}
+
+// MonthEndForecast projects the current month's full cost from its month-to-date
+// spend. Lower and Upper bound a ~95% interval from the day-to-day variation of
+// the month's daily costs; without a daily series they equal Projected.
+type MonthEndForecast struct {
+	Month          string  `json:"month"`
+	AsOf           string  `json:"as_of"`
+	DaysElapsed    int     `json:"days_elapsed"`
+	DaysInMonth    int     `json:"days_in_month"`
+	MTDCost        float64 `json:"mtd_cost"`
+	RunRate        float64 `json:"run_rate"`
+	Projected      float64 `json:"projected"`
+	TrendProjected float64 `json:"trend_projected,omitempty"`
+	Lower          float64 `json:"lower"`
+	Upper          float64 `json:"upper"`
+}
+
+// ForecastPoint represents a user-provided expected cost for a day
+type ForecastPoint struct {
+	Date         string  `json:"date"`
+	ExpectedCost float64 `json:"expected_cost"`
+}
+
+// ForecastVariance represents actual versus forecast cost for a day
+type ForecastVariance struct {
+	Date               string  `json:"date"`
+	ActualCost         float64 `json:"actual_cost"`
+	ExpectedCost       float64 `json:"expected_cost"`
+	Variance           float64 `json:"variance"`
+	VariancePercentage float64 `json:"variance_percentage"`
+	CumulativeVariance float64 `json:"cumulative_variance"`
+	OutsideTolerance   bool    `json:"outside_tolerance"`
+}
+
+// UnitCostTrend represents the trend of cost per usage unit for a SKU
+type UnitCostTrend struct {
+	Service          string  `json:"service"`
+	SKU              string  `json:"sku"`
+	UsageUnit        string  `json:"usage_unit"`
+	Days             int     `json:"days"`
+	FirstUnitCost    float64 `json:"first_unit_cost"`
+	LastUnitCost     float64 `json:"last_unit_cost"`
+	SlopePerDay      float64 `json:"slope_per_day"`
+	ChangePercentage float64 `json:"change_percentage"`
+	Trend            string  `json:"trend"`
 }
 
 // ServiceCost represents cost by service
@@ -37,25 +189,315 @@ type ServiceCost struct {
 	Days       int     `json:"days"`
 }
 
+// ProjectAllocation represents a project's direct, allocated and fully loaded cost
+type ProjectAllocation struct {
+	ProjectID       string  `json:"project_id"`
+	DirectCost      float64 `json:"direct_cost"`
+	AllocatedCost   float64 `json:"allocated_cost"`
+	FullyLoadedCost float64 `json:"fully_loaded_cost"`
+}
+
 // Anomaly represents a detected cost anomaly
 type Anomaly struct {
-	Date        string  `json:"date"`
-	Service     string  `json:"service"`
-	CostImpact  float64 `json:"cost_impact"`
-	Description string  `json:"description"`
-	Severity    string  `json:"severity"`
-	DetectedAt  string  `json:"detected_at"`
+	ID           string  `json:"id,omitempty"`
+	Date         string  `json:"date"`
+	TestName     string  `json:"test_name,omitempty"`
+	Type         string  `json:"type,omitempty"`
+	Category     string  `json:"category,omitempty"`
+	Service      string  `json:"service"`
+	SKU          string  `json:"sku,omitempty"`
+	ProjectID    string  `json:"project_id,omitempty"`
+	CompositeKey string  `json:"composite_key,omitempty"`
+	CostType     string  `json:"cost_type,omitempty"`
+	Vendor       string  `json:"vendor,omitempty"`
+	CostImpact   float64 `json:"cost_impact"`
+	Description  string  `json:"description"`
+	Severity     string  `json:"severity"`
+	DetectedAt   string  `json:"detected_at"`
+
+	// The observed value, the baseline it was compared to and the threshold it crossed
+	CurrentValue   float64 `json:"current_value"`
+	PreviousValue  float64 `json:"previous_value"`
+	Threshold      float64 `json:"threshold"`
+	Delta          float64 `json:"delta"`
+	PercentageDiff float64 `json:"percentage_diff"`
+
+	// Confidence is LOW when the test ran on less history than it normally requires
+	Confidence string `json:"confidence,omitempty"`
+
+	InGraceWindow bool          `json:"in_grace_window,omitempty"`
+	Links         []Link        `json:"links,omitempty"`
+	Attribution   []Contributor `json:"attribution,omitempty"`
+	RootCause     *RootCause    `json:"root_cause,omitempty"`
+	Usage         *UsageChange  `json:"usage,omitempty"`
+}
+
+// CategoryDrop marks anomalies where cost fell rather than rose
+const CategoryDrop = "DROP"
+
+// ConfidenceLow marks anomalies detected on shorter-than-required history
+const ConfidenceLow = "LOW"
+
+// Contributor is one node of a root-cause attribution tree: the change in cost
+// for a dimension value between two periods, drilled into the next dimension
+type Contributor struct {
+	Dimension    string        `json:"dimension"`
+	Value        string        `json:"value"`
+	CurrentCost  float64       `json:"current_cost"`
+	PreviousCost float64       `json:"previous_cost"`
+	Delta        float64       `json:"delta"`
+	Children     []Contributor `json:"children,omitempty"`
+}
+
+// UsageChange is the usage and cost movement behind a usage-based anomaly, so a
+// rise in usage can be told apart from a rise in price
+type UsageChange struct {
+	Unit            string  `json:"unit"`
+	CurrentUsage    float64 `json:"current_usage"`
+	BaselineUsage   float64 `json:"baseline_usage"`
+	UsageDelta      float64 `json:"usage_delta"`
+	UsagePercentage float64 `json:"usage_percentage"`
+	CurrentCost     float64 `json:"current_cost"`
+	BaselineCost    float64 `json:"baseline_cost"`
+	CostDelta       float64 `json:"cost_delta"`
+	CostPercentage  float64 `json:"cost_percentage"`
+}
+
+// Root cause comparison periods
+const (
+	RootCauseDaily = "daily"
+	RootCauseMTD   = "month_to_date"
+)
+
+// RootCause ranks the (service, SKU, project, region) combinations that drove an
+// anomaly by their cost increase over the prior period
+type RootCause struct {
+	Period         string                 `json:"period"`
+	CurrentPeriod  string                 `json:"current_period"`
+	PreviousPeriod string                 `json:"previous_period"`
+	Contributors   []RootCauseContributor `json:"contributors"`
+}
+
+// RootCauseContributor is one combination's cost in the anomaly's period and the one before
+type RootCauseContributor struct {
+	Service      string  `json:"service"`
+	SKU          string  `json:"sku"`
+	ProjectID    string  `json:"project_id"`
+	Region       string  `json:"region"`
+	CurrentCost  float64 `json:"current_cost"`
+	PreviousCost float64 `json:"previous_cost"`
+	Increase     float64 `json:"increase"`
+}
+
+// Link is a rendered dashboard link attached to an anomaly for triage
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// WithValues returns the anomaly with its current, previous and threshold values
+// set, and the delta and percentage difference computed from them
+func (a Anomaly) WithValues(current, previous, threshold float64) Anomaly {
+	a.CurrentValue = current
+	a.PreviousValue = previous
+	a.Threshold = threshold
+	a.Delta = current - previous
+	a.PercentageDiff = 0
+	if previous != 0 {
+		a.PercentageDiff = a.Delta / math.Abs(previous) * 100
+	}
+	return a
+}
+
+// Key returns the dimension an anomaly is about: its composite key, or the service
+func (a Anomaly) Key() string {
+	if a.CompositeKey != "" {
+		return a.CompositeKey
+	}
+	return a.Service
+}
+
+// Project returns the project an anomaly is about: its ProjectID, or the project
+// of its service|sku|project_id|region composite key
+func (a Anomaly) Project() string {
+	if a.ProjectID != "" {
+		return a.ProjectID
+	}
+	if _, _, projectID, _, ok := SplitCompositeKey(a.CompositeKey); ok {
+		return projectID
+	}
+	return ""
+}
+
+// Detector returns the test that raised the anomaly: its Type, which unlike
+// TestName does not embed parameters, or the TestName when no type is set
+func (a Anomaly) Detector() string {
+	if a.Type != "" {
+		return a.Type
+	}
+	return a.TestName
+}
+
+// StableID returns a deterministic identifier derived from the test, the anomaly's
+// key, SKU and date, so the same logical anomaly has the same ID across runs
+func (a Anomaly) StableID() string {
+	test := a.Detector()
+
+	// The key is the composite key when present, else the service; anomalies on
+	// different SKUs of a service, or charge type and vendor splits of the same
+	// series, are distinct
+	key := a.Key()
+	if a.SKU != "" {
+		key += "|" + a.SKU
+	}
+	if a.CostType != "" {
+		key += "|" + a.CostType
+	}
+	if a.Vendor != "" {
+		key += "|" + a.Vendor
+	}
+
+	hash := sha256.Sum256([]byte(test + "|" + key + "|" + a.Date))
+	return hex.EncodeToString(hash[:6])
+}
+
+// AssignIDs sets each anomaly's ID to its stable ID
+func AssignIDs(anomalies []Anomaly) []Anomaly {
+	for i := range anomalies {
+		anomalies[i].ID = anomalies[i].StableID()
+	}
+	return anomalies
 }
 
 // Alert represents a triggered alert
 type Alert struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
-	Time    string `json:"time"`
+	Type       string  `json:"type"`
+	Severity   string  `json:"severity"`
+	Message    string  `json:"message"`
+	CostImpact float64 `json:"cost_impact"`
+	Time       string  `json:"time"`
+}
+
+// Budget periods
+const (
+	BudgetPeriodMonthly = "monthly"
+)
+
+// Budget is a team's spend budget for a project over a period
+type Budget struct {
+	ProjectID string  `json:"project_id"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+	Period    string  `json:"period"`
+}
+
+// DataQualityCheck represents the outcome of a pre-flight data quality check
+type DataQualityCheck struct {
+	Name      string  `json:"name"`
+	Passed    bool    `json:"passed"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Message   string  `json:"message"`
+}
+
+// RunReport records what happened during a monitoring run
+type RunReport struct {
+	RunID         string             `json:"run_id"`
+	StartedAt     string             `json:"started_at"`
+	FinishedAt    string             `json:"finished_at"`
+	DataQuality   []DataQualityCheck `json:"data_quality"`
+	Aborted       bool               `json:"aborted"`
+	TestsRun      []string           `json:"tests_run"`
+	TestsDisabled []string           `json:"tests_disabled"`
+
+	// DeferredDays were left out of alerting because their data was not yet complete
+	DeferredDays []string `json:"deferred_days,omitempty"`
+}
+
+// QueryUsage records the bytes a single monitor query processed and was billed for
+type QueryUsage struct {
+	Name           string  `json:"name"`
+	JobID          string  `json:"job_id"`
+	BytesProcessed int64   `json:"bytes_processed"`
+	BytesBilled    int64   `json:"bytes_billed"`
+	CacheHit       bool    `json:"cache_hit"`
+	EstimatedCost  float64 `json:"estimated_cost"`
+}
+
+// SelfCostReport estimates the on-demand BigQuery cost of the monitor's own queries
+type SelfCostReport struct {
+	RunID               string       `json:"run_id,omitempty"`
+	PricePerTiB         float64      `json:"price_per_tib"`
+	TotalBytesProcessed int64        `json:"total_bytes_processed"`
+	TotalBytesBilled    int64        `json:"total_bytes_billed"`
+	EstimatedCost       float64      `json:"estimated_cost"`
+	HeaviestQuery       string       `json:"heaviest_query,omitempty"`
+	Queries             []QueryUsage `json:"queries"`
+}
+
+// TrendPoint is one dated value of a trended metric
+type TrendPoint struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+}
+
+// TrendReport is a metric's series across saved runs with its growth statistics
+type TrendReport struct {
+	Metric      string       `json:"metric"`
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	Mean        float64      `json:"mean"`
+	SlopePerDay float64      `json:"slope_per_day"`
+	CAGR        float64      `json:"cagr_percentage"`
+	Volatility  float64      `json:"volatility_percentage"`
+	Points      []TrendPoint `json:"points"`
+}
+
+// Cost diff statuses of a composite key between two runs
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// CostDiffEntry is how one composite key's total cost differs between two runs.
+// Percentage is zero for added keys, which have no previous cost.
+type CostDiffEntry struct {
+	CompositeKey string  `json:"composite_key"`
+	Status       string  `json:"status"`
+	Service      string  `json:"service"`
+	SKU          string  `json:"sku"`
+	ProjectID    string  `json:"project_id"`
+	Region       string  `json:"region"`
+	PreviousCost float64 `json:"previous_cost"`
+	CurrentCost  float64 `json:"current_cost"`
+	Delta        float64 `json:"delta"`
+	Percentage   float64 `json:"percentage"`
+}
+
+// CostDiff compares the composite data of two runs
+type CostDiff struct {
+	PreviousTotal float64         `json:"previous_total"`
+	CurrentTotal  float64         `json:"current_total"`
+	Delta         float64         `json:"delta"`
+	Added         int             `json:"added"`
+	Removed       int             `json:"removed"`
+	Changed       int             `json:"changed"`
+	Unchanged     int             `json:"unchanged"`
+	Entries       []CostDiffEntry `json:"entries"`
+}
+
+// DetectorPrecision is a detector's share of user-labeled anomalies that were true positives
+type DetectorPrecision struct {
+	Detector       string  `json:"detector"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	Precision      float64 `json:"precision"`
 }
 
 // Summary represents system summary statistics
 type Summary struct {
+	RunID              string  `json:"run_id,omitempty"`
 	TotalAnomalies     int     `json:"total_anomalies"`
 	TotalCostImpact    float64 `json:"total_cost_impact"`
 	CurrentMonthCost   float64 `json:"current_month_cost"`
@@ -67,4 +509,37 @@ type Summary struct {
 	MTDRecords         int     `json:"mtd_records"`
 	DailyRecords       int     `json:"daily_records"`
 	CompositeRecords   int     `json:"composite_records"`
-} 
\ No newline at end of file
+
+	CostTypeBreakdown map[string]float64 `json:"cost_type_breakdown,omitempty"`
+
+	// DailyCostPercentiles is the daily total cost profile, keyed p50, p90, ...
+	DailyCostPercentiles map[string]float64 `json:"daily_cost_percentiles,omitempty"`
+
+	// Cost of no-alert projects, included in the totals above but not in detection
+	NoAlertProjects       []string `json:"no_alert_projects,omitempty"`
+	NoAlertCost           float64  `json:"no_alert_cost,omitempty"`
+	NoAlertCostPercentage float64  `json:"no_alert_cost_percentage,omitempty"`
+
+	// NegativeCostMode is how refunds and credit rows were treated (net, exclude, separate)
+	NegativeCostMode  string  `json:"negative_cost_mode,omitempty"`
+	RefundsAndCredits float64 `json:"refunds_and_credits"`
+
+	// DetectorPrecision is computed from user feedback over the configured window
+	DetectorPrecision []DetectorPrecision `json:"detector_precision,omitempty"`
+
+	MonthEndForecast *MonthEndForecast `json:"month_end_forecast,omitempty"`
+}
+
+// MultiVendorSummary merges the summaries of several cloud vendors into one view
+type MultiVendorSummary struct {
+	RunID           string             `json:"run_id,omitempty"`
+	TotalCost       float64            `json:"total_cost"`
+	TotalAnomalies  int                `json:"total_anomalies"`
+	TotalCostImpact float64            `json:"total_cost_impact"`
+	VendorCosts     map[string]float64 `json:"vendor_costs"`
+	VendorShares    map[string]float64 `json:"vendor_shares"`
+
+	// ServiceBreakdown is keyed by "vendor/service"
+	ServiceBreakdown map[string]float64 `json:"service_breakdown"`
+	Vendors          map[string]Summary `json:"vendors"`
+}
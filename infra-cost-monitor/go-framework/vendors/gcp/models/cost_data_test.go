@@ -0,0 +1,138 @@
+package models
+
+import "testing"
+
+func TestStableID(t *testing.T) {
+	base := Anomaly{Type: "new_sku", TestName: "new_sku", Date: "2024-03-01", Service: "Compute", SKU: "VM"}
+
+	tests := []struct {
+		name     string
+		change   func(a *Anomaly)
+		distinct bool
+	}{
+		{"same anomaly", func(a *Anomaly) {}, false},
+		{"severity and description do not matter", func(a *Anomaly) { a.Severity, a.Description = "HIGH", "other" }, false},
+		{"different SKU", func(a *Anomaly) { a.SKU = "GPU" }, true},
+		{"different service", func(a *Anomaly) { a.Service = "Storage" }, true},
+		{"different date", func(a *Anomaly) { a.Date = "2024-03-02" }, true},
+		{"different detector", func(a *Anomaly) { a.Type = "spike" }, true},
+		{"composite key", func(a *Anomaly) { a.CompositeKey = "Compute|VM|proj-1|us-east1" }, true},
+		{"charge type split", func(a *Anomaly) { a.CostType = "tax" }, true},
+		{"vendor split", func(a *Anomaly) { a.Vendor = "aws" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base
+			tt.change(&other)
+			if distinct := base.StableID() != other.StableID(); distinct != tt.distinct {
+				t.Errorf("distinct IDs = %v, want %v", distinct, tt.distinct)
+			}
+		})
+	}
+
+	withKey := base
+	withKey.CompositeKey = "Compute|VM|proj-1|us-east1"
+	otherProject := withKey
+	otherProject.CompositeKey = "Compute|VM|proj-2|us-east1"
+	if withKey.StableID() == otherProject.StableID() {
+		t.Error("anomalies with different composite keys have the same ID")
+	}
+}
+
+func TestSplitCompositeKey(t *testing.T) {
+	cost := CostData{Service: `Compute|Engine`, SKU: `VM\Core`, ProjectID: "proj-1", Region: "us-east1"}
+	service, sku, projectID, region, ok := SplitCompositeKey(cost.CompositeKey())
+	if !ok || service != cost.Service || sku != cost.SKU || projectID != cost.ProjectID || region != cost.Region {
+		t.Errorf("got %q, %q, %q, %q, %v; want the fields of %+v", service, sku, projectID, region, ok, cost)
+	}
+
+	for _, key := range []string{"Compute", "Compute|VM|hour", "us-east1->europe-west1"} {
+		if _, _, _, _, ok := SplitCompositeKey(key); ok {
+			t.Errorf("SplitCompositeKey(%q) succeeded, want it to fail", key)
+		}
+	}
+
+	anomaly := Anomaly{CompositeKey: cost.CompositeKey()}
+	if got := anomaly.Project(); got != "proj-1" {
+		t.Errorf("Project() = %q, want proj-1", got)
+	}
+	anomaly.ProjectID = "explicit"
+	if got := anomaly.Project(); got != "explicit" {
+		t.Errorf("Project() = %q, want explicit", got)
+	}
+}
+
+func TestCompositeKeyEscapesDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b CostData
+	}{
+		{
+			name: "pipe in the service name",
+			a:    CostData{Service: "Compute|Engine", SKU: "VM", ProjectID: "proj-1", Region: "us-east1"},
+			b:    CostData{Service: "Compute", SKU: "Engine|VM", ProjectID: "proj-1", Region: "us-east1"},
+		},
+		{
+			name: "pipe at the end of a field",
+			a:    CostData{Service: "Compute|", SKU: "VM", ProjectID: "proj-1", Region: "us-east1"},
+			b:    CostData{Service: "Compute", SKU: "|VM", ProjectID: "proj-1", Region: "us-east1"},
+		},
+		{
+			name: "escaped backslash before the delimiter",
+			a:    CostData{Service: `Compute\`, SKU: "VM", ProjectID: "proj-1", Region: "us-east1"},
+			b:    CostData{Service: `Compute\|VM`, SKU: "", ProjectID: "proj-1", Region: "us-east1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.a.CompositeKey() == tt.b.CompositeKey() {
+				t.Errorf("%+v and %+v share the key %q", tt.a, tt.b, tt.a.CompositeKey())
+			}
+		})
+	}
+
+	plain := CostData{Service: "Compute", SKU: "VM", ProjectID: "proj-1", Region: "us-east1"}
+	if got := plain.CompositeKey(); got != "Compute|VM|proj-1|us-east1" {
+		t.Errorf("CompositeKey() = %q, want Compute|VM|proj-1|us-east1", got)
+	}
+}
+
+func TestCompareMonthsProratesThePreviousMonth(t *testing.T) {
+	previous := MTDCost{Month: "2024-04", Cost: 3000, Days: 30}
+	tests := []struct {
+		name    string
+		current MTDCost
+		want    MonthlyComparison
+	}{
+		{
+			// The raw comparison sees a 50% drop, but 150/day against 100/day is a 50% rise
+			name:    "10 days above the run rate",
+			current: MTDCost{Month: "2024-05", Cost: 1500, Days: 10},
+			want:    MonthlyComparison{Current: 1500, Previous: 3000, RawIncrease: -1500, RawPercentage: -50, ProratedPrevious: 1000, NormalizedIncrease: 500, NormalizedPercentage: 50, Spike: true},
+		},
+		{
+			name:    "10 days within the threshold of the run rate",
+			current: MTDCost{Month: "2024-05", Cost: 1050, Days: 10},
+			want:    MonthlyComparison{Current: 1050, Previous: 3000, RawIncrease: -1950, RawPercentage: -65, ProratedPrevious: 1000, NormalizedIncrease: 50, NormalizedPercentage: 5},
+		},
+		{
+			name:    "unknown elapsed days fall back to the raw comparison",
+			current: MTDCost{Month: "2024-05", Cost: 3600},
+			want:    MonthlyComparison{Current: 3600, Previous: 3000, RawIncrease: 600, RawPercentage: 20, ProratedPrevious: 3000, NormalizedIncrease: 600, NormalizedPercentage: 20, Spike: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareMonths(tt.current, previous, 5000, 10); got != tt.want {
+				t.Errorf("CompareMonths = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := CompareMonths(MTDCost{Cost: 100, Days: 10}, MTDCost{}, 0, 0); got.Spike || got.ProratedPrevious != 0 {
+		t.Errorf("CompareMonths without a previous month = %+v, want no comparison", got)
+	}
+}
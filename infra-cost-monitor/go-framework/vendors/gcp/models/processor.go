@@ -0,0 +1,56 @@
+package models
+
+// CostDataProcessor holds the daily total and composite series the monitors test
+type CostDataProcessor struct {
+	DailyTotalData []DailyCost
+	CompositeData  []CostData
+}
+
+// NewCostDataProcessor creates a new processor over the daily totals and composite records
+func NewCostDataProcessor(daily []DailyCost, composite []CostData) *CostDataProcessor {
+	return &CostDataProcessor{
+		DailyTotalData: daily,
+		CompositeData:  composite,
+	}
+}
+
+// GetCurrentDate returns the most recent date in the daily totals, or "" with no data
+func (p *CostDataProcessor) GetCurrentDate() string {
+	latest := ""
+	for _, day := range p.DailyTotalData {
+		if day.Date > latest {
+			latest = day.Date
+		}
+	}
+	return latest
+}
+
+// GetCurrentDateCost returns the total of the most recent day, or 0 with no data
+func (p *CostDataProcessor) GetCurrentDateCost() float64 {
+	var latest DailyCost
+	for _, day := range p.DailyTotalData {
+		if day.Date > latest.Date {
+			latest = day
+		}
+	}
+	return latest.TotalCost
+}
+
+// GetCurrentDateCompositeCosts returns the cost per composite key on the most
+// recent date in the composite data
+func (p *CostDataProcessor) GetCurrentDateCompositeCosts() map[string]float64 {
+	latest := ""
+	for _, record := range p.CompositeData {
+		if record.Date > latest {
+			latest = record.Date
+		}
+	}
+
+	costs := make(map[string]float64)
+	for _, record := range p.CompositeData {
+		if record.Date == latest {
+			costs[record.CompositeKey()] += record.Cost
+		}
+	}
+	return costs
+}
@@ -0,0 +1,52 @@
+package models
+
+import "testing"
+
+func TestCostDataProcessorEmpty(t *testing.T) {
+	p := NewCostDataProcessor(nil, nil)
+	if got := p.GetCurrentDate(); got != "" {
+		t.Errorf("GetCurrentDate() = %q, want empty", got)
+	}
+	if got := p.GetCurrentDateCost(); got != 0 {
+		t.Errorf("GetCurrentDateCost() = %v, want 0", got)
+	}
+	if got := p.GetCurrentDateCompositeCosts(); len(got) != 0 {
+		t.Errorf("GetCurrentDateCompositeCosts() = %v, want empty", got)
+	}
+}
+
+func TestCostDataProcessorSingleDay(t *testing.T) {
+	vm := CostData{Date: "2024-03-01", Service: "Compute", SKU: "VM", ProjectID: "proj-1", Region: "us-east1", Cost: 10}
+	disk := CostData{Date: "2024-03-01", Service: "Storage", SKU: "Disk", ProjectID: "proj-1", Region: "us-east1", Cost: 2.5}
+	p := NewCostDataProcessor(
+		[]DailyCost{{Date: "2024-03-01", TotalCost: 12.5}},
+		[]CostData{vm, disk, vm},
+	)
+
+	if got := p.GetCurrentDate(); got != "2024-03-01" {
+		t.Errorf("GetCurrentDate() = %q, want 2024-03-01", got)
+	}
+	if got := p.GetCurrentDateCost(); got != 12.5 {
+		t.Errorf("GetCurrentDateCost() = %v, want 12.5", got)
+	}
+	costs := p.GetCurrentDateCompositeCosts()
+	if len(costs) != 2 || costs[vm.CompositeKey()] != 20 || costs[disk.CompositeKey()] != 2.5 {
+		t.Errorf("GetCurrentDateCompositeCosts() = %v, want %s: 20 and %s: 2.5", costs, vm.CompositeKey(), disk.CompositeKey())
+	}
+}
+
+func TestCostDataProcessorLatestDay(t *testing.T) {
+	old := CostData{Date: "2024-03-01", Service: "Compute", SKU: "VM", Cost: 10}
+	latest := CostData{Date: "2024-03-02", Service: "Compute", SKU: "VM", Cost: 7}
+	p := NewCostDataProcessor(
+		[]DailyCost{{Date: "2024-03-02", TotalCost: 7}, {Date: "2024-03-01", TotalCost: 10}},
+		[]CostData{latest, old},
+	)
+
+	if got := p.GetCurrentDateCost(); got != 7 {
+		t.Errorf("GetCurrentDateCost() = %v, want 7", got)
+	}
+	if costs := p.GetCurrentDateCompositeCosts(); len(costs) != 1 || costs[latest.CompositeKey()] != 7 {
+		t.Errorf("GetCurrentDateCompositeCosts() = %v, want only the latest day", costs)
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity levels. The canonical order used for comparison is
+// LOW < MEDIUM < HIGH < CRITICAL.
+const (
+	SeverityLow      = "LOW"
+	SeverityMedium   = "MEDIUM"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+var severityOrder = []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// SeverityRank returns the position of a severity in the canonical order, or -1 if unknown
+func SeverityRank(severity string) int {
+	severity = strings.ToUpper(severity)
+	for i, s := range severityOrder {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// SeverityBands are the upper bounds, in percent over the expected cost, of the
+// LOW, MEDIUM and HIGH severities; anything at or above High is CRITICAL
+type SeverityBands struct {
+	Low    float64 `json:"low"`
+	Medium float64 `json:"medium"`
+	High   float64 `json:"high"`
+}
+
+// DefaultSeverityBands returns LOW < 10%, MEDIUM < 25%, HIGH < 50%, CRITICAL otherwise
+func DefaultSeverityBands() SeverityBands {
+	return SeverityBands{Low: 10, Medium: 25, High: 50}
+}
+
+// Validate checks that the bands are positive and ascending
+func (b SeverityBands) Validate() error {
+	if b.Low <= 0 || b.Medium <= b.Low || b.High <= b.Medium {
+		return fmt.Errorf("severity bands must be positive and ascending, got low %g, medium %g, high %g", b.Low, b.Medium, b.High)
+	}
+	return nil
+}
+
+// Severity returns the severity band of a percentage over the expected cost
+func (b SeverityBands) Severity(percentageDiff float64) string {
+	switch {
+	case percentageDiff < b.Low:
+		return SeverityLow
+	case percentageDiff < b.Medium:
+		return SeverityMedium
+	case percentageDiff < b.High:
+		return SeverityHigh
+	}
+	return SeverityCritical
+}
+
+// severityBands are the bands used by SeverityForPercentage
+var severityBands = DefaultSeverityBands()
+
+// SetSeverityBands overrides the bands used by SeverityForPercentage
+func SetSeverityBands(bands SeverityBands) {
+	severityBands = bands
+}
+
+// SeverityForPercentage returns the severity of a percentage over the expected
+// cost using the configured bands, so percentage-based tests agree on severity
+func SeverityForPercentage(percentageDiff float64) string {
+	return severityBands.Severity(percentageDiff)
+}
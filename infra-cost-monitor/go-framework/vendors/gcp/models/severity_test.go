@@ -0,0 +1,73 @@
+package models
+
+import "testing"
+
+func TestSeverityBandBoundaries(t *testing.T) {
+	tests := []struct {
+		percentage float64
+		want       string
+	}{
+		{-40, SeverityLow},
+		{0, SeverityLow},
+		{9.99, SeverityLow},
+		{10, SeverityMedium},
+		{24.99, SeverityMedium},
+		{25, SeverityHigh},
+		{49.99, SeverityHigh},
+		{50, SeverityCritical},
+		{500, SeverityCritical},
+	}
+
+	bands := DefaultSeverityBands()
+	for _, tt := range tests {
+		if got := bands.Severity(tt.percentage); got != tt.want {
+			t.Errorf("Severity(%v) = %s, want %s", tt.percentage, got, tt.want)
+		}
+		if got := SeverityForPercentage(tt.percentage); got != tt.want {
+			t.Errorf("SeverityForPercentage(%v) = %s, want %s", tt.percentage, got, tt.want)
+		}
+	}
+}
+
+func TestSetSeverityBands(t *testing.T) {
+	defer SetSeverityBands(DefaultSeverityBands())
+	SetSeverityBands(SeverityBands{Low: 20, Medium: 100, High: 200})
+
+	tests := []struct {
+		percentage float64
+		want       string
+	}{
+		{19.99, SeverityLow},
+		{20, SeverityMedium},
+		{99.99, SeverityMedium},
+		{100, SeverityHigh},
+		{199.99, SeverityHigh},
+		{200, SeverityCritical},
+	}
+	for _, tt := range tests {
+		if got := SeverityForPercentage(tt.percentage); got != tt.want {
+			t.Errorf("SeverityForPercentage(%v) = %s, want %s", tt.percentage, got, tt.want)
+		}
+	}
+}
+
+func TestSeverityBandsValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		bands SeverityBands
+		valid bool
+	}{
+		{"defaults", DefaultSeverityBands(), true},
+		{"zero low band", SeverityBands{Low: 0, Medium: 25, High: 50}, false},
+		{"medium not above low", SeverityBands{Low: 25, Medium: 25, High: 50}, false},
+		{"high below medium", SeverityBands{Low: 10, Medium: 50, High: 25}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.bands.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import "math"
+
+// Summation modes for cost aggregation
+const (
+	// SummationFloat adds float64 values directly (the historical behaviour)
+	SummationFloat = "float"
+	// SummationKahan uses compensated summation to cancel rounding drift
+	SummationKahan = "kahan"
+	// SummationMinorUnits accumulates whole cents/paise as integers
+	SummationMinorUnits = "minor_units"
+)
+
+// Accumulator sums costs using the configured summation mode. Kahan and
+// minor-unit totals are rounded to 2 decimals.
+type Accumulator struct {
+	mode         string
+	sum          float64
+	compensation float64
+	minorUnits   int64
+}
+
+// NewAccumulator creates an accumulator for the given summation mode
+func NewAccumulator(mode string) *Accumulator {
+	return &Accumulator{mode: mode}
+}
+
+// Add adds a value to the running total
+func (a *Accumulator) Add(value float64) {
+	switch a.mode {
+	case SummationKahan:
+		y := value - a.compensation
+		t := a.sum + y
+		a.compensation = (t - a.sum) - y
+		a.sum = t
+	case SummationMinorUnits:
+		a.minorUnits += int64(math.Round(value * 100))
+	default:
+		a.sum += value
+	}
+}
+
+// Total returns the running total
+func (a *Accumulator) Total() float64 {
+	switch a.mode {
+	case SummationKahan:
+		return RoundMoney(a.sum)
+	case SummationMinorUnits:
+		return float64(a.minorUnits) / 100
+	default:
+		return a.sum
+	}
+}
+
+// RoundMoney rounds an amount to 2 decimals
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// Breakdown sums costs per key using the configured summation mode
+type Breakdown struct {
+	mode   string
+	totals map[string]*Accumulator
+}
+
+// NewBreakdown creates a breakdown for the given summation mode
+func NewBreakdown(mode string) *Breakdown {
+	return &Breakdown{
+		mode:   mode,
+		totals: make(map[string]*Accumulator),
+	}
+}
+
+// Add adds a value to the total for key
+func (b *Breakdown) Add(key string, value float64) {
+	total, exists := b.totals[key]
+	if !exists {
+		total = NewAccumulator(b.mode)
+		b.totals[key] = total
+	}
+	total.Add(value)
+}
+
+// Totals returns the total per key
+func (b *Breakdown) Totals() map[string]float64 {
+	totals := make(map[string]float64, len(b.totals))
+	for key, total := range b.totals {
+		totals[key] = total.Total()
+	}
+	return totals
+}
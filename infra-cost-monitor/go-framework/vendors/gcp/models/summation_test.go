@@ -0,0 +1,70 @@
+package models
+
+import "testing"
+
+// smallCosts returns n costs of 0.01, whose exact total is n/100
+func smallCosts(n int) []float64 {
+	costs := make([]float64, n)
+	for i := range costs {
+		costs[i] = 0.01
+	}
+	return costs
+}
+
+func TestAccumulatorEliminatesDrift(t *testing.T) {
+	costs := smallCosts(1000000)
+
+	plain := NewAccumulator(SummationFloat)
+	for _, cost := range costs {
+		plain.Add(cost)
+	}
+	if plain.Total() == 10000 {
+		t.Fatal("float summation of the fixture did not drift; the test no longer shows anything")
+	}
+
+	for _, mode := range []string{SummationKahan, SummationMinorUnits} {
+		t.Run(mode, func(t *testing.T) {
+			acc := NewAccumulator(mode)
+			for _, cost := range costs {
+				acc.Add(cost)
+			}
+			if got := acc.Total(); got != 10000 {
+				t.Errorf("Total() = %v, want 10000 (float summation gave %v)", got, plain.Total())
+			}
+		})
+	}
+}
+
+func TestAccumulatorCreditsAndRounding(t *testing.T) {
+	costs := []float64{12.34, -2.34, 0.1, 0.2}
+	for _, mode := range []string{SummationKahan, SummationMinorUnits} {
+		t.Run(mode, func(t *testing.T) {
+			acc := NewAccumulator(mode)
+			for _, cost := range costs {
+				acc.Add(cost)
+			}
+			if got := acc.Total(); got != 10.3 {
+				t.Errorf("Total() = %v, want 10.3", got)
+			}
+		})
+	}
+}
+
+func TestBreakdownSumsPerKey(t *testing.T) {
+	breakdown := NewBreakdown(SummationMinorUnits)
+	for _, cost := range smallCosts(100000) {
+		breakdown.Add("Compute", cost)
+		breakdown.Add("Storage", cost*2)
+	}
+
+	totals := breakdown.Totals()
+	if totals["Compute"] != 1000 {
+		t.Errorf("Compute total = %v, want 1000", totals["Compute"])
+	}
+	if totals["Storage"] != 2000 {
+		t.Errorf("Storage total = %v, want 2000", totals["Storage"])
+	}
+	if len(totals) != 2 {
+		t.Errorf("got %d keys, want 2", len(totals))
+	}
+}
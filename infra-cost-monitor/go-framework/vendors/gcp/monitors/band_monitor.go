@@ -0,0 +1,89 @@
+package monitors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"sort"
+	"time"
+)
+
+// BandMonitor flags services whose daily cost falls outside a configured expected range
+type BandMonitor struct {
+	cfg config.ServiceBandsConfig
+}
+
+// NewBandMonitor creates a new expected band monitor
+func NewBandMonitor(cfg config.ServiceBandsConfig) *BandMonitor {
+	return &BandMonitor{
+		cfg: cfg,
+	}
+}
+
+// Check returns an anomaly for each service day in the lookback window that is outside its band
+func (bm *BandMonitor) Check(costs []models.CostData) []models.Anomaly {
+	log.Println("📏 Checking services against expected daily bands...")
+
+	daily := make(map[string]map[string]float64)
+	dateSet := make(map[string]bool)
+	for _, cost := range costs {
+		if _, banded := bm.cfg.Services[cost.Service]; !banded {
+			continue
+		}
+		if daily[cost.Service] == nil {
+			daily[cost.Service] = make(map[string]float64)
+		}
+		daily[cost.Service][cost.Date] += cost.Cost
+		dateSet[cost.Date] = true
+	}
+
+	// Only the most recent days are checked so historical breaches are not re-reported
+	dates := make([]string, 0, len(dateSet))
+	for date := range dateSet {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	if len(dates) > bm.cfg.LookbackDays {
+		dates = dates[:bm.cfg.LookbackDays]
+	}
+
+	services := make([]string, 0, len(bm.cfg.Services))
+	for service := range bm.cfg.Services {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	var anomalies []models.Anomaly
+	for _, service := range services {
+		band := bm.cfg.Services[service]
+		for _, date := range dates {
+			cost := daily[service][date]
+
+			var impact, bound float64
+			var direction string
+			switch {
+			case cost < band.Min:
+				impact, bound, direction = cost-band.Min, band.Min, "below"
+			case band.Max > 0 && cost > band.Max:
+				impact, bound, direction = cost-band.Max, band.Max, "above"
+			default:
+				continue
+			}
+
+			anomalies = append(anomalies, models.Anomaly{
+				Date:        date,
+				TestName:    "Expected Band Monitor",
+				Type:        "expected_band",
+				Service:     service,
+				CostImpact:  impact,
+				Description: fmt.Sprintf("%s daily cost (%.2f) is %s its expected band [%.2f, %.2f]", service, cost, direction, band.Min, band.Max),
+				Severity:    models.SeverityMedium,
+				DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+			}.WithValues(cost, bound, bound))
+		}
+	}
+
+	log.Printf("✅ Detected %d expected band breaches", len(anomalies))
+	return models.AssignIDs(anomalies)
+}
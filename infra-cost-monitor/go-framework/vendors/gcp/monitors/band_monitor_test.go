@@ -0,0 +1,110 @@
+package monitors
+
+import (
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"strings"
+	"testing"
+)
+
+func TestBandMonitorFlagsServicesOutsideTheirBand(t *testing.T) {
+	bands := map[string]config.Band{
+		"Compute":  {Min: 100, Max: 200},
+		"Storage":  {Min: 10, Max: 20},
+		"BigQuery": {Min: 5},
+	}
+	tests := []struct {
+		name     string
+		lookback int
+		costs    []models.CostData
+		want     map[string]float64
+	}{
+		{
+			"inside every band",
+			1,
+			[]models.CostData{
+				{Date: "2024-03-02", Service: "Compute", Cost: 120},
+				{Date: "2024-03-02", Service: "Compute", Cost: 80},
+				{Date: "2024-03-02", Service: "Storage", Cost: 10},
+				{Date: "2024-03-02", Service: "BigQuery", Cost: 500},
+				{Date: "2024-03-02", Service: "Networking", Cost: 9999},
+			},
+			map[string]float64{},
+		},
+		{
+			"above and below",
+			1,
+			[]models.CostData{
+				{Date: "2024-03-02", Service: "Compute", Cost: 250},
+				{Date: "2024-03-02", Service: "Storage", Cost: 4},
+				{Date: "2024-03-02", Service: "BigQuery", Cost: 5},
+			},
+			map[string]float64{"Compute": 50, "Storage": -6},
+		},
+		{
+			"a banded service missing from the day is below its band",
+			1,
+			[]models.CostData{
+				{Date: "2024-03-02", Service: "Compute", Cost: 150},
+				{Date: "2024-03-02", Service: "Storage", Cost: 15},
+			},
+			map[string]float64{"BigQuery": -5},
+		},
+		{
+			"breaches before the lookback window are not reported",
+			1,
+			[]models.CostData{
+				{Date: "2024-03-01", Service: "Compute", Cost: 500},
+				{Date: "2024-03-02", Service: "Compute", Cost: 150},
+				{Date: "2024-03-02", Service: "Storage", Cost: 15},
+				{Date: "2024-03-02", Service: "BigQuery", Cost: 50},
+			},
+			map[string]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := NewBandMonitor(config.ServiceBandsConfig{LookbackDays: tt.lookback, Services: bands})
+			anomalies := monitor.Check(tt.costs)
+
+			got := make(map[string]float64)
+			for _, anomaly := range anomalies {
+				got[anomaly.Service] = anomaly.CostImpact
+				if anomaly.ID == "" || anomaly.Type != "expected_band" {
+					t.Errorf("anomaly = %+v, want an identified expected_band anomaly", anomaly)
+				}
+				if !strings.Contains(anomaly.Description, "expected band [") {
+					t.Errorf("description %q does not include the band", anomaly.Description)
+				}
+			}
+			if len(got) != len(tt.want) || len(anomalies) != len(tt.want) {
+				t.Fatalf("flagged %v, want %v", got, tt.want)
+			}
+			for service, impact := range tt.want {
+				if got[service] != impact {
+					t.Errorf("%s impact = %v, want %v", service, got[service], impact)
+				}
+			}
+		})
+	}
+}
+
+func TestBandMonitorChecksEachDayInTheLookback(t *testing.T) {
+	monitor := NewBandMonitor(config.ServiceBandsConfig{LookbackDays: 2, Services: map[string]config.Band{"Compute": {Min: 100, Max: 200}}})
+	anomalies := monitor.Check([]models.CostData{
+		{Date: "2024-03-01", Service: "Compute", Cost: 500},
+		{Date: "2024-03-02", Service: "Compute", Cost: 300},
+		{Date: "2024-03-03", Service: "Compute", Cost: 50},
+	})
+
+	if len(anomalies) != 2 {
+		t.Fatalf("got %d anomalies, want one per day in the lookback: %+v", len(anomalies), anomalies)
+	}
+	if anomalies[0].Date != "2024-03-03" || !strings.Contains(anomalies[0].Description, "is below") {
+		t.Errorf("first anomaly = %s %q, want 2024-03-03 below the band", anomalies[0].Date, anomalies[0].Description)
+	}
+	if anomalies[1].Date != "2024-03-02" || !strings.Contains(anomalies[1].Description, "is above") {
+		t.Errorf("second anomaly = %s %q, want 2024-03-02 above the band", anomalies[1].Date, anomalies[1].Description)
+	}
+}
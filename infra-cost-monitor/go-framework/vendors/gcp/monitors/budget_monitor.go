@@ -0,0 +1,142 @@
+package monitors
+
+import (
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"os"
+	"sort"
+	"time"
+)
+
+// BudgetMonitor alerts when a project's month-to-date spend crosses a percentage
+// band of its budget. Each band alerts once per project and month.
+type BudgetMonitor struct {
+	store       *state.Store
+	budgets     []models.Budget
+	percentages []float64
+}
+
+// NewBudgetMonitor creates a new budget monitor backed by the state store
+func NewBudgetMonitor(store *state.Store, budgets []models.Budget, percentages []float64) *BudgetMonitor {
+	sorted := append([]float64(nil), percentages...)
+	sort.Float64s(sorted)
+
+	return &BudgetMonitor{
+		store:       store,
+		budgets:     budgets,
+		percentages: sorted,
+	}
+}
+
+// LoadBudgets loads budgets from a JSON file containing an array of budgets
+func LoadBudgets(path string) ([]models.Budget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var budgets []models.Budget
+	if err := json.Unmarshal(data, &budgets); err != nil {
+		return nil, fmt.Errorf("failed to parse budgets %s: %v", path, err)
+	}
+
+	for i, budget := range budgets {
+		if budget.ProjectID == "" {
+			return nil, fmt.Errorf("budget %d in %s has no project_id", i, path)
+		}
+		if budget.Amount <= 0 {
+			return nil, fmt.Errorf("budget for %s must have a positive amount", budget.ProjectID)
+		}
+		if budget.Period == "" {
+			budgets[i].Period = models.BudgetPeriodMonthly
+		} else if budget.Period != models.BudgetPeriodMonthly {
+			return nil, fmt.Errorf("budget for %s has unsupported period %q", budget.ProjectID, budget.Period)
+		}
+	}
+	return budgets, nil
+}
+
+// Check sums each project's spend for the latest month in costs and returns an
+// alert for every band crossed that has not been alerted before
+func (bm *BudgetMonitor) Check(costs []models.CostData) []models.Alert {
+	log.Println("💰 Checking month-to-date spend against budgets...")
+
+	var latest string
+	for _, cost := range costs {
+		if cost.Date > latest {
+			latest = cost.Date
+		}
+	}
+	if len(latest) < 7 {
+		log.Println("✅ No cost data to check budgets against")
+		return nil
+	}
+	month := latest[:7]
+
+	type projectSpend struct {
+		cost       float64
+		currencies map[string]bool
+	}
+	spend := make(map[string]*projectSpend)
+	for _, cost := range costs {
+		if len(cost.Date) < 7 || cost.Date[:7] != month {
+			continue
+		}
+		entry, exists := spend[cost.ProjectID]
+		if !exists {
+			entry = &projectSpend{currencies: make(map[string]bool)}
+			spend[cost.ProjectID] = entry
+		}
+		entry.cost += cost.Cost
+		entry.currencies[cost.Currency] = true
+	}
+
+	var alerts []models.Alert
+	for _, budget := range bm.budgets {
+		entry, exists := spend[budget.ProjectID]
+		if !exists {
+			continue
+		}
+		if budget.Currency != "" && (len(entry.currencies) != 1 || !entry.currencies[budget.Currency]) {
+			log.Printf("Warning: Skipping budget for %s: spend is not in the budget currency %s", budget.ProjectID, budget.Currency)
+			continue
+		}
+
+		used := entry.cost / budget.Amount * 100
+		for _, percentage := range bm.percentages {
+			if used < percentage {
+				break
+			}
+			key := fmt.Sprintf("%s|%s|%g", budget.ProjectID, month, percentage)
+			if bm.store.BudgetAlerted(key) {
+				continue
+			}
+			bm.store.MarkBudgetAlerted(key, latest)
+
+			alerts = append(alerts, models.Alert{
+				Type:       "budget_threshold",
+				Severity:   budgetSeverity(percentage),
+				Message:    fmt.Sprintf("%s has spent %.2f of its %.2f %s budget for %s (%.0f%%, crossed %g%%)", budget.ProjectID, entry.cost, budget.Amount, budget.Currency, month, used, percentage),
+				CostImpact: entry.cost - budget.Amount,
+				Time:       time.Now().Format("2006-01-02 15:04:05"),
+			})
+		}
+	}
+
+	log.Printf("✅ Raised %d budget alerts", len(alerts))
+	return alerts
+}
+
+// budgetSeverity maps a crossed budget band to an alert severity
+func budgetSeverity(percentage float64) string {
+	switch {
+	case percentage >= 100:
+		return models.SeverityHigh
+	case percentage >= 80:
+		return models.SeverityMedium
+	}
+	return models.SeverityLow
+}
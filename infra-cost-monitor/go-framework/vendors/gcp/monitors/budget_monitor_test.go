@@ -0,0 +1,122 @@
+package monitors
+
+import (
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBudgetMonitor returns a monitor with a 1000 INR budget for prod and bands
+// at 50, 80 and 100%, backed by a fresh state store
+func newBudgetMonitor(t *testing.T) *BudgetMonitor {
+	t.Helper()
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	budgets := []models.Budget{{ProjectID: "prod", Amount: 1000, Currency: "INR", Period: models.BudgetPeriodMonthly}}
+	return NewBudgetMonitor(store, budgets, []float64{100, 50, 80})
+}
+
+func TestBudgetMonitorCrossesSeveralBandsInOneRun(t *testing.T) {
+	monitor := newBudgetMonitor(t)
+	costs := []models.CostData{
+		{Date: "2024-04-30", ProjectID: "prod", Cost: 5000, Currency: "INR"},
+		{Date: "2024-05-01", ProjectID: "prod", Cost: 400, Currency: "INR"},
+		{Date: "2024-05-02", ProjectID: "prod", Cost: 650, Currency: "INR"},
+		{Date: "2024-05-02", ProjectID: "dev", Cost: 9000, Currency: "INR"},
+	}
+
+	alerts := monitor.Check(costs)
+	var severities []string
+	for _, alert := range alerts {
+		severities = append(severities, alert.Severity)
+		if alert.Type != "budget_threshold" {
+			t.Errorf("alert type = %s, want budget_threshold", alert.Type)
+		}
+		if alert.CostImpact != 50 {
+			t.Errorf("cost impact = %v, want 50 over budget", alert.CostImpact)
+		}
+	}
+	want := []string{models.SeverityLow, models.SeverityMedium, models.SeverityHigh}
+	if strings.Join(severities, ",") != strings.Join(want, ",") {
+		t.Fatalf("alert severities = %v, want %v for the 50, 80 and 100%% bands", severities, want)
+	}
+	if !strings.Contains(alerts[2].Message, "crossed 100%") {
+		t.Errorf("message %q does not name the 100%% band", alerts[2].Message)
+	}
+
+	// Each band alerts once per month
+	costs = append(costs, models.CostData{Date: "2024-05-03", ProjectID: "prod", Cost: 100, Currency: "INR"})
+	if again := monitor.Check(costs); len(again) != 0 {
+		t.Errorf("second run raised %d alerts, want none", len(again))
+	}
+}
+
+func TestBudgetMonitorAlertsOnlyCrossedBands(t *testing.T) {
+	tests := []struct {
+		name   string
+		spend  float64
+		alerts int
+	}{
+		{"under every band", 499, 0},
+		{"exactly 50%", 500, 1},
+		{"between 80 and 100%", 999, 2},
+		{"exactly 100%", 1000, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			costs := []models.CostData{{Date: "2024-05-02", ProjectID: "prod", Cost: tt.spend, Currency: "INR"}}
+			if alerts := newBudgetMonitor(t).Check(costs); len(alerts) != tt.alerts {
+				t.Errorf("got %d alerts, want %d", len(alerts), tt.alerts)
+			}
+		})
+	}
+}
+
+func TestBudgetMonitorSkipsSpendInAnotherCurrency(t *testing.T) {
+	costs := []models.CostData{{Date: "2024-05-02", ProjectID: "prod", Cost: 2000, Currency: "USD"}}
+	if alerts := newBudgetMonitor(t).Check(costs); len(alerts) != 0 {
+		t.Errorf("got %d alerts for USD spend against an INR budget, want none", len(alerts))
+	}
+}
+
+func TestLoadBudgets(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		err  string
+	}{
+		{"defaults the period", `[{"project_id": "prod", "amount": 1000, "currency": "INR"}]`, ""},
+		{"missing project", `[{"amount": 1000}]`, "no project_id"},
+		{"zero amount", `[{"project_id": "prod", "amount": 0}]`, "positive amount"},
+		{"unsupported period", `[{"project_id": "prod", "amount": 1000, "period": "weekly"}]`, "unsupported period"},
+		{"malformed", `{`, "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "budgets.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			budgets, err := LoadBudgets(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadBudgets: %v", err)
+			}
+			if len(budgets) != 1 || budgets[0].Period != models.BudgetPeriodMonthly {
+				t.Errorf("budgets = %+v, want one monthly budget", budgets)
+			}
+		})
+	}
+}
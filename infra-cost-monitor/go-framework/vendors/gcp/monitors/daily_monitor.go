@@ -2,78 +2,171 @@ package monitors
 
 import (
 	"fmt"
-	"sort"
 	"time"
-	"infra-cost-monitor/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
 )
 
 // DailyMonitor handles daily cost monitoring
 type DailyMonitor struct {
-	processor *models.CostDataProcessor
+	processor     *models.CostDataProcessor
+	disabledTests map[string]bool
+
+	sampleEvery int
+	sampleSeed  int64
+	minSample   int
+
+	// minHistory is the days of history the total and each composite key need to be
+	// tested normally; down to historyFloor they are tested with low confidence
+	minHistory   int
+	historyFloor int
+
+	// percentile is the threshold both daily tests compare against
+	percentile float64
+
+	// currency is the billing currency amounts are formatted in
+	currency string
 }
 
 // NewDailyMonitor creates a new daily monitor
 func NewDailyMonitor(processor *models.CostDataProcessor) *DailyMonitor {
 	return &DailyMonitor{
-		processor: processor,
+		processor:     processor,
+		disabledTests: make(map[string]bool),
+		minHistory:    90,
+		historyFloor:  90,
+		percentile:    0.99,
+		currency:      "INR",
 	}
 }
 
+// SetPercentile sets the percentile (0 < p < 1) the daily tests compare against;
+// noisier spend may need 0.95 or 0.90 for meaningful spikes to fire
+func (d *DailyMonitor) SetPercentile(p float64) error {
+	if p <= 0 || p >= 1 {
+		return fmt.Errorf("percentile must be between 0 and 1 exclusive, got %g", p)
+	}
+	d.percentile = p
+	return nil
+}
+
+// SetMinHistory sets the days of history the daily tests need to run normally and
+// the floor below which they are skipped; in between, anomalies are flagged with
+// low confidence. Composite keys are checked individually so mature services are
+// not gated by newer ones.
+func (d *DailyMonitor) SetMinHistory(days, floor int) {
+	if floor > days {
+		floor = days
+	}
+	d.minHistory = days
+	d.historyFloor = floor
+}
+
+// confidence returns the confidence of a test run on the given days of history,
+// and false when there is too little history to run it at all
+func (d *DailyMonitor) confidence(days int) (string, bool) {
+	if days < d.historyFloor {
+		return "", false
+	}
+	if days < d.minHistory {
+		return models.ConfidenceLow, true
+	}
+	return "", true
+}
+
+// SetCurrency sets the billing currency (ISO 4217 code) used to format amounts in descriptions
+func (d *DailyMonitor) SetCurrency(currency string) {
+	d.currency = currency
+}
+
+// DisableTests disables daily tests by name (daily_total, daily_composite)
+func (d *DailyMonitor) DisableTests(names ...string) {
+	for _, name := range names {
+		d.disabledTests[name] = true
+	}
+}
+
+// SetHistorySampling makes the composite test compute per-key baselines from a
+// deterministic sample of every n-th historical day; see detectors.SampleHistory
+func (d *DailyMonitor) SetHistorySampling(every int, seed int64, minSample int) {
+	d.sampleEvery = every
+	d.sampleSeed = seed
+	d.minSample = minSample
+}
+
+// TestStatuses returns the daily tests that will run and those that are disabled
+func (d *DailyMonitor) TestStatuses() ([]string, []string) {
+	var enabled, disabled []string
+	for _, name := range []string{"daily_total", "daily_composite"} {
+		if d.disabledTests[name] {
+			disabled = append(disabled, name)
+		} else {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled, disabled
+}
+
 // RunDailyTests runs all daily tests and returns anomalies
 func (d *DailyMonitor) RunDailyTests(anomalies *models.AnomalyCollection) {
 	fmt.Println("Running Daily Cost Tests...")
 	
-	// Test 1: Daily total cost test (99th percentile)
-	d.testDailyTotalCost(anomalies)
+	// Test 1: Daily total cost test (configured percentile, 99th by default)
+	if d.disabledTests["daily_total"] {
+		fmt.Println("Skipping disabled test: daily_total")
+	} else {
+		d.testDailyTotalCost(anomalies)
+	}
 	
-	// Test 2: Daily composite cost test (99th percentile)
-	d.testDailyCompositeCost(anomalies)
+	// Test 2: Daily composite cost test (configured percentile, 99th by default)
+	if d.disabledTests["daily_composite"] {
+		fmt.Println("Skipping disabled test: daily_composite")
+	} else {
+		d.testDailyCompositeCost(anomalies)
+	}
 }
 
-// testDailyTotalCost tests if current date cost is above 99th percentile
+// testDailyTotalCost tests if current date cost is above the configured percentile
 func (d *DailyMonitor) testDailyTotalCost(anomalies *models.AnomalyCollection) {
-	if len(d.processor.DailyTotalData) < 90 {
-		fmt.Println("Warning: Less than 90 days of data available for daily total cost test")
+	confidence, ok := d.confidence(len(d.processor.DailyTotalData))
+	if !ok {
+		fmt.Printf("Warning: Less than %d days of data available for daily total cost test\n", d.historyFloor)
 		return
 	}
+	if confidence == models.ConfidenceLow {
+		fmt.Printf("Warning: Only %d of %d days of data available; daily total cost test runs with low confidence\n", len(d.processor.DailyTotalData), d.minHistory)
+	}
 	
-	// Calculate 99th percentile
+	// Calculate the configured percentile
 	costs := make([]float64, len(d.processor.DailyTotalData))
 	for i, record := range d.processor.DailyTotalData {
-		costs[i] = record.Cost
+		costs[i] = record.TotalCost
 	}
 	
-	sort.Float64s(costs)
-	percentileIndex := int(float64(len(costs)) * 0.99)
-	if percentileIndex >= len(costs) {
-		percentileIndex = len(costs) - 1
-	}
-	
-	percentile99 := costs[percentileIndex]
+	threshold := detectors.Percentiles(costs, []float64{d.percentile})[d.percentile]
 	currentCost := d.processor.GetCurrentDateCost()
 	
-	if currentCost > percentile99 {
+	if currentCost > threshold {
 		// Calculate difference margin
-		differenceMargin := currentCost - percentile99
-		percentageDiff := (differenceMargin / percentile99) * 100
+		differenceMargin := currentCost - threshold
+		percentageDiff := (differenceMargin / threshold) * 100
 		
 		anomaly := models.Anomaly{
-			TestName:       "Daily Total Cost Monitor - 99th Percentile",
-			Description:    fmt.Sprintf("Current date cost (₹%.2f) is above 99th percentile (₹%.2f)", currentCost, percentile99),
-			CostImpact:     currentCost,
-			PercentageDiff: percentageDiff,
-			CurrentValue:   currentCost,
-			PreviousValue:  percentile99,
-			Threshold:      percentile99,
-			Timestamp:      time.Now().Format(time.RFC3339),
-			Severity:       getSeverity(percentageDiff),
-		}
+			Date:        d.processor.GetCurrentDate(),
+			TestName:    fmt.Sprintf("Daily Total Cost Monitor - p%g", d.percentile*100),
+			Service:     "daily_total",
+			Description: fmt.Sprintf("Current date cost (%s) is above the p%g threshold (%s)", formatMoney(currentCost, d.currency), d.percentile*100, formatMoney(threshold, d.currency)),
+			CostImpact:  currentCost,
+			DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+			Severity:    getSeverity(percentageDiff),
+			Confidence:  confidence,
+		}.WithValues(currentCost, threshold, threshold)
 		
 		anomalies.AddAnomaly(anomaly)
 	}
 }
 
-// testDailyCompositeCost tests if current date composite costs are above 99th percentile
+// testDailyCompositeCost tests if current date composite costs are above the configured percentile
 func (d *DailyMonitor) testDailyCompositeCost(anomalies *models.AnomalyCollection) {
 	if len(d.processor.CompositeData) == 0 {
 		fmt.Println("Warning: No composite data available for daily composite cost test")
@@ -90,42 +183,58 @@ func (d *DailyMonitor) testDailyCompositeCost(anomalies *models.AnomalyCollectio
 	
 	// Get current date composite costs
 	currentDateCosts := d.processor.GetCurrentDateCompositeCosts()
+	currentDate := ""
+	for _, record := range d.processor.CompositeData {
+		if record.Date > currentDate {
+			currentDate = record.Date
+		}
+	}
 	
-	// Test each composite key
+	// Test each composite key that has enough history of its own
+	tested, skipped := 0, 0
 	for compositeKey, currentCost := range currentDateCosts {
 		historicalCosts, exists := compositeCosts[compositeKey]
-		if !exists || len(historicalCosts) < 90 {
-			continue // Skip if not enough historical data
-		}
-		
-		// Calculate 99th percentile for this composite key
-		sort.Float64s(historicalCosts)
-		percentileIndex := int(float64(len(historicalCosts)) * 0.99)
-		if percentileIndex >= len(historicalCosts) {
-			percentileIndex = len(historicalCosts) - 1
+		confidence, ok := d.confidence(len(historicalCosts))
+		if !exists || !ok {
+			skipped++
+			continue
 		}
+		tested++
 		
-		percentile99 := historicalCosts[percentileIndex]
+		// Calculate the configured percentile for this composite key
+		historicalCosts = detectors.SampleHistory(historicalCosts, d.sampleEvery, d.sampleSeed, d.minSample)
+		threshold := detectors.Percentiles(historicalCosts, []float64{d.percentile})[d.percentile]
 		
-		if currentCost > percentile99 {
+		if currentCost > threshold {
 			// Calculate difference margin
-			differenceMargin := currentCost - percentile99
-			percentageDiff := (differenceMargin / percentile99) * 100
+			differenceMargin := currentCost - threshold
+			percentageDiff := (differenceMargin / threshold) * 100
 			
+			service, sku, projectID, _, _ := models.SplitCompositeKey(compositeKey)
 			anomaly := models.Anomaly{
-				TestName:       "Daily Composite Cost Monitor - 99th Percentile",
-				Description:    fmt.Sprintf("Composite cost for %s (₹%.2f) is above 99th percentile (₹%.2f)", compositeKey, currentCost, percentile99),
-				CostImpact:     currentCost,
-				PercentageDiff: percentageDiff,
-				CurrentValue:   currentCost,
-				PreviousValue:  percentile99,
-				Threshold:      percentile99,
-				CompositeKey:   compositeKey,
-				Timestamp:      time.Now().Format(time.RFC3339),
-				Severity:       getSeverity(percentageDiff),
-			}
+				Date:         currentDate,
+				TestName:     fmt.Sprintf("Daily Composite Cost Monitor - p%g", d.percentile*100),
+				Description:  fmt.Sprintf("Composite cost for %s (%s) is above the p%g threshold (%s)", compositeKey, formatMoney(currentCost, d.currency), d.percentile*100, formatMoney(threshold, d.currency)),
+				CostImpact:   currentCost,
+				Service:      service,
+				SKU:          sku,
+				ProjectID:    projectID,
+				CompositeKey: compositeKey,
+				DetectedAt:   time.Now().Format("2006-01-02 15:04:05"),
+				Severity:     getSeverity(percentageDiff),
+				Confidence:   confidence,
+			}.WithValues(currentCost, threshold, threshold)
 			
 			anomalies.AddAnomaly(anomaly)
 		}
 	}
-} 
\ No newline at end of file
+
+	if skipped > 0 {
+		fmt.Printf("Tested %d composite keys, skipped %d with less than %d days of history\n", tested, skipped, d.historyFloor)
+	}
+}
+
+// getSeverity bands the percentage over the threshold using the shared severity bands
+func getSeverity(percentageDiff float64) string {
+	return models.SeverityForPercentage(percentageDiff)
+}
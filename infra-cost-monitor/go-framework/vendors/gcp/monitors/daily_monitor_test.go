@@ -0,0 +1,230 @@
+package monitors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// historyWithSpike returns days of history ending 2024-06-30, newest first, flat
+// at 100 except for a spike of 500 on the latest day, as daily totals and as the
+// composite rows of a single key
+func historyWithSpike(days int) ([]models.DailyCost, []models.CostData) {
+	latest := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	var daily []models.DailyCost
+	var composite []models.CostData
+	for i := 0; i < days; i++ {
+		date := latest.AddDate(0, 0, -i).Format("2006-01-02")
+		cost := 100.0
+		if i == 0 {
+			cost = 500
+		}
+		daily = append(daily, models.DailyCost{Date: date, TotalCost: cost})
+		composite = append(composite, models.CostData{Date: date, Service: "Compute", SKU: "VM", ProjectID: "proj-1", Region: "us-east1", Cost: cost})
+	}
+	return daily, composite
+}
+
+func TestDailyMonitorMinHistoryBoundaries(t *testing.T) {
+	tests := []struct {
+		days       int
+		run        bool
+		confidence string
+	}{
+		{29, false, ""},
+		{30, true, models.ConfidenceLow},
+		{89, true, models.ConfidenceLow},
+		{90, true, ""},
+	}
+
+	for _, tt := range tests {
+		for _, test := range []string{"daily_total", "daily_composite"} {
+			t.Run(fmt.Sprintf("%s with %d days", test, tt.days), func(t *testing.T) {
+				daily, composite := historyWithSpike(tt.days)
+				monitor := NewDailyMonitor(models.NewCostDataProcessor(daily, composite))
+				monitor.SetMinHistory(90, 30)
+				if err := monitor.SetPercentile(0.9); err != nil {
+					t.Fatalf("SetPercentile: %v", err)
+				}
+				for _, other := range []string{"daily_total", "daily_composite"} {
+					if other != test {
+						monitor.DisableTests(other)
+					}
+				}
+
+				collection := models.NewAnomalyCollection()
+				monitor.RunDailyTests(collection)
+				anomalies := collection.All()
+				if run := len(anomalies) > 0; run != tt.run {
+					t.Fatalf("flagged = %v, want %v", run, tt.run)
+				}
+				if tt.run && anomalies[0].Confidence != tt.confidence {
+					t.Errorf("confidence = %q, want %q", anomalies[0].Confidence, tt.confidence)
+				}
+			})
+		}
+	}
+}
+
+func TestSetPercentileRejectsValuesOutsideTheOpenUnitInterval(t *testing.T) {
+	monitor := NewDailyMonitor(models.NewCostDataProcessor(nil, nil))
+	for _, p := range []float64{0, 1, -0.5, 99} {
+		if err := monitor.SetPercentile(p); err == nil {
+			t.Errorf("SetPercentile(%g) returned no error", p)
+		}
+	}
+	if err := monitor.SetPercentile(0.95); err != nil {
+		t.Errorf("SetPercentile(0.95) = %v, want no error", err)
+	}
+}
+
+func TestDailyTestsNameTheirPercentile(t *testing.T) {
+	tests := []struct {
+		percentile float64
+		label      string
+	}{
+		{0.9, "p90"},
+		{0.95, "p95"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			daily, composite := historyWithSpike(90)
+			monitor := NewDailyMonitor(models.NewCostDataProcessor(daily, composite))
+			if err := monitor.SetPercentile(tt.percentile); err != nil {
+				t.Fatalf("SetPercentile: %v", err)
+			}
+
+			collection := models.NewAnomalyCollection()
+			monitor.RunDailyTests(collection)
+			if collection.Len() != 2 {
+				t.Fatalf("got %d anomalies, want the total and composite spikes", collection.Len())
+			}
+			for _, anomaly := range collection.All() {
+				if !strings.HasSuffix(anomaly.TestName, " - "+tt.label) {
+					t.Errorf("test name = %q, want it to end with %s", anomaly.TestName, tt.label)
+				}
+				if !strings.Contains(anomaly.Description, "above the "+tt.label+" threshold") {
+					t.Errorf("description = %q, want the %s threshold named", anomaly.Description, tt.label)
+				}
+			}
+		})
+	}
+}
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	writer.Close()
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	return string(output)
+}
+
+func TestDailyCompositeTestGatesEachKeyOnItsOwnHistory(t *testing.T) {
+	daily, mature := historyWithSpike(90)
+	_, young := historyWithSpike(20)
+	composite := mature
+	for _, row := range young {
+		row.Service = "BigQuery"
+		composite = append(composite, row)
+	}
+	monitor := NewDailyMonitor(models.NewCostDataProcessor(daily, composite))
+	monitor.SetMinHistory(90, 30)
+	if err := monitor.SetPercentile(0.9); err != nil {
+		t.Fatalf("SetPercentile: %v", err)
+	}
+	monitor.DisableTests("daily_total")
+
+	collection := models.NewAnomalyCollection()
+	output := captureStdout(t, func() { monitor.RunDailyTests(collection) })
+	anomalies := collection.All()
+	if len(anomalies) != 1 || anomalies[0].Service != "Compute" {
+		t.Errorf("anomalies = %+v, want only the mature Compute key flagged", anomalies)
+	}
+	if !strings.Contains(output, "Tested 1 composite keys, skipped 1") {
+		t.Errorf("output = %q, want the skipped key counted", output)
+	}
+}
+
+func TestGetSeverityUsesTheSharedBands(t *testing.T) {
+	defer models.SetSeverityBands(models.DefaultSeverityBands())
+	for _, bands := range []models.SeverityBands{models.DefaultSeverityBands(), {Low: 20, Medium: 100, High: 200}} {
+		models.SetSeverityBands(bands)
+		for _, percentage := range []float64{0, bands.Low, bands.Medium, bands.High, bands.High * 2} {
+			if got, want := getSeverity(percentage), bands.Severity(percentage); got != want {
+				t.Errorf("getSeverity(%v) = %s, want %s with bands %+v", percentage, got, want, bands)
+			}
+		}
+	}
+
+	// 500 against a p90 of 100 is 400% over: CRITICAL under the default bands
+	models.SetSeverityBands(models.DefaultSeverityBands())
+	daily, composite := historyWithSpike(90)
+	monitor := NewDailyMonitor(models.NewCostDataProcessor(daily, composite))
+	if err := monitor.SetPercentile(0.9); err != nil {
+		t.Fatalf("SetPercentile: %v", err)
+	}
+	monitor.DisableTests("daily_composite")
+	collection := models.NewAnomalyCollection()
+	monitor.RunDailyTests(collection)
+	if anomalies := collection.All(); len(anomalies) != 1 || anomalies[0].Severity != models.SeverityCritical {
+		t.Errorf("anomalies = %+v, want one CRITICAL daily total anomaly", anomalies)
+	}
+}
+
+func TestDisableTestsSkipsOnlyTheNamedTest(t *testing.T) {
+	tests := []struct {
+		disable  string
+		enabled  string
+		testName string
+	}{
+		{"daily_total", "daily_composite", "Daily Composite Cost Monitor"},
+		{"daily_composite", "daily_total", "Daily Total Cost Monitor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.disable, func(t *testing.T) {
+			daily, composite := historyWithSpike(90)
+			monitor := NewDailyMonitor(models.NewCostDataProcessor(daily, composite))
+			if err := monitor.SetPercentile(0.9); err != nil {
+				t.Fatalf("SetPercentile: %v", err)
+			}
+			monitor.DisableTests(tt.disable)
+
+			enabled, disabled := monitor.TestStatuses()
+			if len(enabled) != 1 || enabled[0] != tt.enabled {
+				t.Errorf("enabled = %v, want [%s]", enabled, tt.enabled)
+			}
+			if len(disabled) != 1 || disabled[0] != tt.disable {
+				t.Errorf("disabled = %v, want [%s]", disabled, tt.disable)
+			}
+
+			collection := models.NewAnomalyCollection()
+			monitor.RunDailyTests(collection)
+			if collection.Len() == 0 {
+				t.Fatalf("the enabled %s test flagged nothing", tt.enabled)
+			}
+			for _, anomaly := range collection.All() {
+				if !strings.HasPrefix(anomaly.TestName, tt.testName) {
+					t.Errorf("anomaly from %q, want only %s", anomaly.TestName, tt.testName)
+				}
+			}
+		})
+	}
+}
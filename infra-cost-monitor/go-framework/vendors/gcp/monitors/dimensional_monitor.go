@@ -1,117 +1,235 @@
 package monitors
 
 import (
-	"cloud.google.com/go/bigquery"
+	"context"
+	"fmt"
+	"cloud.google.com/go/civil"
 	"infra-cost-monitor/go-framework/adapters/bigquery"
 	"infra-cost-monitor/go-framework/vendors/gcp/models"
 	"log"
+	"time"
+
+	"google.golang.org/api/iterator"
 )
 
 // DimensionalMonitor monitors cost data across multiple dimensions
 type DimensionalMonitor struct {
-	client *bigquery.Client
+	client    *bigquery.Client
+	ctx       context.Context
+	since     time.Time
+	days      int
+	summation string
+	cache     *bigquery.QueryCache
 }
 
 // NewDimensionalMonitor creates a new dimensional monitor
 func NewDimensionalMonitor(client *bigquery.Client) *DimensionalMonitor {
 	return &DimensionalMonitor{
 		client: client,
+		ctx:    context.Background(),
+		days:   90,
 	}
 }
 
+// SetContext sets the context BigQuery fetches run under, so they can be cancelled
+func (dm *DimensionalMonitor) SetContext(ctx context.Context) {
+	dm.ctx = ctx
+}
+
+// SetDays sets how many days of billing data are fetched when no watermark is set
+func (dm *DimensionalMonitor) SetDays(days int) {
+	dm.days = days
+}
+
+// SetSince limits fetches to usage that started after since instead of the last 90 days
+func (dm *DimensionalMonitor) SetSince(since time.Time) {
+	dm.since = since
+}
+
+// SetCache serves billing rows from a query cache shared with the other monitors
+func (dm *DimensionalMonitor) SetCache(queryCache *bigquery.QueryCache) {
+	dm.cache = queryCache
+}
+
+// SetSummation sets how breakdowns accumulate costs (see models.SummationKahan)
+func (dm *DimensionalMonitor) SetSummation(mode string) {
+	dm.summation = mode
+}
+
 // GetDimensionalCosts retrieves cost data grouped by multiple dimensions
 func (dm *DimensionalMonitor) GetDimensionalCosts() ([]models.CostData, error) {
+	var dimensionalCosts []models.CostData
+	err := dm.ForEachCostRow(func(cost models.CostData) error {
+		dimensionalCosts = append(dimensionalCosts, cost)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Retrieved %d dimensional cost records", len(dimensionalCosts))
+	return dimensionalCosts, nil
+}
+
+// ForEachCostRow calls fn for each dimensional cost row as it is read, without
+// holding them all in memory, so aggregation can happen incrementally. An error
+// from fn stops the iteration and is returned. With a query cache set, rows of
+// the last dm.days days are served from (and materialized into) the cache.
+func (dm *DimensionalMonitor) ForEachCostRow(fn func(models.CostData) error) error {
 	log.Println("📊 Fetching dimensional cost data...")
 
-	// Create BigQuery client
-	bqClient, err := bigquery.NewClient()
+	// Get billing data since the watermark, or for the last dm.days days
+	if dm.since.IsZero() {
+		return forEachBillingRow(dm.ctx, dm.client, dm.cache, dm.days, fn)
+	}
+
+	log.Printf("📊 Fetching usage since %s", dm.since.Format("2006-01-02"))
+	it, err := dm.client.GetBillingDataSince(dm.ctx, dm.since)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer bqClient.Close()
+	return readCostRows(it, fn)
+}
 
-	// Get billing data for last 90 days
-	it, err := bqClient.GetBillingData(90)
+// forEachBillingRow calls fn for each billing export row of the last days days,
+// through the query cache when one is set
+func forEachBillingRow(ctx context.Context, client *bigquery.Client, queryCache *bigquery.QueryCache, days int, fn func(models.CostData) error) error {
+	if queryCache == nil {
+		it, err := client.GetBillingData(ctx, days)
+		if err != nil {
+			return err
+		}
+		return readCostRows(it, fn)
+	}
+
+	rows, err := queryCache.Fetch(client.BillingTableID(), bigquery.QueryBillingData, days, func(days int) ([]models.CostData, error) {
+		it, err := client.GetBillingData(ctx, days)
+		if err != nil {
+			return nil, err
+		}
+		var rows []models.CostData
+		err = readCostRows(it, func(cost models.CostData) error {
+			rows = append(rows, cost)
+			return nil
+		})
+		return rows, err
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	var dimensionalCosts []models.CostData
+// rowIterator is the part of a BigQuery row iterator the billing readers use
+type rowIterator interface {
+	Next(dst interface{}) error
+}
+
+// readCostRows calls fn for each billing export row read from it
+func readCostRows(it rowIterator, fn func(models.CostData) error) error {
 	for {
 		var row struct {
-			Date        string  `bigquery:"date"`
+			Date        civil.Date `bigquery:"date"`
 			Service     string  `bigquery:"service"`
 			SKU         string  `bigquery:"sku"`
 			ProjectID   string  `bigquery:"project_id"`
 			ProjectName string  `bigquery:"project_name"`
 			Region      string  `bigquery:"region"`
 			Cost        float64 `bigquery:"cost"`
+			Credits     float64 `bigquery:"credits"`
 			UsageAmount float64 `bigquery:"usage_amount"`
 			UsageUnit   string  `bigquery:"usage_unit"`
+			Currency    string  `bigquery:"currency"`
+			CostType    string  `bigquery:"cost_type"`
 		}
 
+		// Only iterator.Done ends the read; anything else would be a partial result
 		err := it.Next(&row)
-		if err != nil {
+		if err == iterator.Done {
 			break
 		}
+		if err != nil {
+			return fmt.Errorf("failed to read billing rows: %w", err)
+		}
 
-		dimensionalCosts = append(dimensionalCosts, models.CostData{
-			Date:        row.Date,
+		err = fn(models.CostData{
+			Date:        row.Date.String(),
 			Service:     row.Service,
 			SKU:         row.SKU,
 			ProjectID:   row.ProjectID,
 			ProjectName: row.ProjectName,
 			Region:      row.Region,
 			Cost:        row.Cost,
+			Credits:     row.Credits,
 			UsageAmount: row.UsageAmount,
 			UsageUnit:   row.UsageUnit,
+			Currency:    row.Currency,
+			CostType:    row.CostType,
 		})
+		if err != nil {
+			return err
+		}
 	}
 
-	log.Printf("✅ Retrieved %d dimensional cost records", len(dimensionalCosts))
-	return dimensionalCosts, nil
+	return nil
 }
 
 // GetServiceBreakdown returns cost breakdown by service
 func (dm *DimensionalMonitor) GetServiceBreakdown(costs []models.CostData) map[string]float64 {
-	serviceCosts := make(map[string]float64)
+	serviceCosts := models.NewBreakdown(dm.summation)
 	
 	for _, cost := range costs {
-		serviceCosts[cost.Service] += cost.Cost
+		serviceCosts.Add(cost.Service, cost.Cost)
 	}
 	
-	return serviceCosts
+	return serviceCosts.Totals()
 }
 
 // GetProjectBreakdown returns cost breakdown by project
 func (dm *DimensionalMonitor) GetProjectBreakdown(costs []models.CostData) map[string]float64 {
-	projectCosts := make(map[string]float64)
+	projectCosts := models.NewBreakdown(dm.summation)
 	
 	for _, cost := range costs {
-		projectCosts[cost.ProjectID] += cost.Cost
+		projectCosts.Add(cost.ProjectID, cost.Cost)
 	}
 	
-	return projectCosts
+	return projectCosts.Totals()
 }
 
 // GetRegionBreakdown returns cost breakdown by region
 func (dm *DimensionalMonitor) GetRegionBreakdown(costs []models.CostData) map[string]float64 {
-	regionCosts := make(map[string]float64)
+	regionCosts := models.NewBreakdown(dm.summation)
+	
+	for _, cost := range costs {
+		regionCosts.Add(cost.Region, cost.Cost)
+	}
+	
+	return regionCosts.Totals()
+}
+
+// GetCostTypeBreakdown returns cost breakdown by charge type (usage, tax, credit, adjustment)
+func (dm *DimensionalMonitor) GetCostTypeBreakdown(costs []models.CostData) map[string]float64 {
+	costTypeCosts := models.NewBreakdown(dm.summation)
 	
 	for _, cost := range costs {
-		regionCosts[cost.Region] += cost.Cost
+		costTypeCosts.Add(cost.CostType, cost.Cost)
 	}
 	
-	return regionCosts
+	return costTypeCosts.Totals()
 }
 
 // GetSKUBreakdown returns cost breakdown by SKU
 func (dm *DimensionalMonitor) GetSKUBreakdown(costs []models.CostData) map[string]float64 {
-	skuCosts := make(map[string]float64)
+	skuCosts := models.NewBreakdown(dm.summation)
 	
 	for _, cost := range costs {
-		skuCosts[cost.SKU] += cost.Cost
+		skuCosts.Add(cost.SKU, cost.Cost)
 	}
 	
-	return skuCosts
+	return skuCosts.Totals()
 } 
\ No newline at end of file
@@ -0,0 +1,238 @@
+package monitors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"infra-cost-monitor/go-framework/adapters/bigquery"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"google.golang.org/api/iterator"
+)
+
+// fakeRowIterator yields its count of zero-value rows, then returns err
+type fakeRowIterator struct {
+	rows int
+	err  error
+}
+
+func (it *fakeRowIterator) Next(dst interface{}) error {
+	if it.rows == 0 {
+		return it.err
+	}
+	it.rows--
+	return nil
+}
+
+func TestReadCostRows(t *testing.T) {
+	reset := errors.New("connection reset by peer")
+	tests := []struct {
+		name string
+		it   *fakeRowIterator
+		rows int
+		err  error
+	}{
+		{"complete read", &fakeRowIterator{rows: 3, err: iterator.Done}, 3, nil},
+		{"empty result", &fakeRowIterator{err: iterator.Done}, 0, nil},
+		{"error partway through", &fakeRowIterator{rows: 2, err: reset}, 2, reset},
+		{"error on the first row", &fakeRowIterator{err: reset}, 0, reset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := 0
+			err := readCostRows(tt.it, func(models.CostData) error {
+				rows++
+				return nil
+			})
+			if tt.err == nil && err != nil {
+				t.Fatalf("got error %v, want a complete read", err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if rows != tt.rows {
+				t.Errorf("read %d rows, want %d", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestReadCostRowsStopsOnCallbackError(t *testing.T) {
+	full := errors.New("aggregator full")
+	it := &fakeRowIterator{rows: 5, err: iterator.Done}
+	rows := 0
+	err := readCostRows(it, func(models.CostData) error {
+		rows++
+		return full
+	})
+	if !errors.Is(err, full) {
+		t.Errorf("got error %v, want %v", err, full)
+	}
+	if rows != 1 {
+		t.Errorf("read %d rows, want 1", rows)
+	}
+}
+
+// contextRowIterator yields zero-value rows until its context is done, like a
+// BigQuery iterator whose next page fetch sees a cancelled context
+type contextRowIterator struct {
+	ctx context.Context
+}
+
+func (it *contextRowIterator) Next(dst interface{}) error {
+	return it.ctx.Err()
+}
+
+func TestReadCostRowsStopsWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rows := 0
+	err := readCostRows(&contextRowIterator{ctx: ctx}, func(models.CostData) error {
+		rows++
+		if rows == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	if rows != 2 {
+		t.Errorf("read %d rows, want 2", rows)
+	}
+}
+
+// syntheticRowIterator yields rows with distinct SKUs and costs until rows are
+// exhausted, without holding any of them
+type syntheticRowIterator struct {
+	rows int
+	next int
+}
+
+func (it *syntheticRowIterator) Next(dst interface{}) error {
+	if it.next == it.rows {
+		return iterator.Done
+	}
+	row := reflect.ValueOf(dst).Elem()
+	row.FieldByName("Date").Set(reflect.ValueOf(civil.Date{Year: 2024, Month: time.March, Day: 1 + it.next%28}))
+	row.FieldByName("Service").SetString(fmt.Sprintf("service-%d", it.next%1000))
+	row.FieldByName("SKU").SetString(fmt.Sprintf("sku-%d", it.next))
+	row.FieldByName("Cost").SetFloat(float64(it.next%100) + 0.25)
+	it.next++
+	return nil
+}
+
+func TestReadCostRowsStreamsWithBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("streams a quarter of a million rows")
+	}
+	const rows = 250000
+	path := filepath.Join(t.TempDir(), "composite_data.json")
+	writer, err := utils.NewJSONOutput().StreamArray(path)
+	if err != nil {
+		t.Fatalf("StreamArray: %v", err)
+	}
+
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	baseline, peak := stats.HeapAlloc, stats.HeapAlloc
+
+	read := 0
+	err = readCostRows(&syntheticRowIterator{rows: rows}, func(cost models.CostData) error {
+		read++
+		if read%10000 == 0 {
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > peak {
+				peak = stats.HeapAlloc
+			}
+		}
+		return writer.Write(cost)
+	})
+	if err != nil {
+		t.Fatalf("readCostRows: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if read != rows {
+		t.Fatalf("read %d rows, want %d", read, rows)
+	}
+
+	// Buffered as a slice, the records would take over 50MB
+	if growth := (peak - baseline) >> 20; growth > 16 {
+		t.Errorf("heap grew %dMB while streaming, want at most 16MB", growth)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+	decoder := json.NewDecoder(file)
+	if _, err := decoder.Token(); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	written := 0
+	for decoder.More() {
+		var cost models.CostData
+		if err := decoder.Decode(&cost); err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		written++
+	}
+	if written != rows {
+		t.Errorf("wrote %d array elements, want %d", written, rows)
+	}
+}
+
+func TestMonitorsUseTheInjectedClient(t *testing.T) {
+	// With no configuration in the environment bigquery.NewClient fails, so the
+	// monitors can only succeed through the client they were given
+	for _, name := range []string{"GOOGLE_CLOUD_PROJECT", "BIGQUERY_DATASET", "BIGQUERY_TABLE", "BIGQUERY_BILLING_EXPORT_TABLE"} {
+		t.Setenv(name, "")
+	}
+	if _, err := bigquery.NewClient(); err == nil {
+		t.Fatal("NewClient succeeded without configuration")
+	}
+
+	rows := []models.CostData{
+		{Date: "2024-05-01", Service: "Compute", Cost: 10},
+		{Date: "2024-05-02", Service: "Storage", Cost: 5},
+	}
+	client := &bigquery.Client{}
+	queryCache := bigquery.NewQueryCache(time.Hour)
+	queryCache.Put(client.BillingTableID(), bigquery.QueryBillingData, 90, rows)
+	queryCache.Put(client.BillingTableID(), bigquery.QueryBillingData, mtdWindowDays, rows)
+
+	dimensional := NewDimensionalMonitor(client)
+	dimensional.SetCache(queryCache)
+	costs, err := dimensional.GetDimensionalCosts()
+	if err != nil {
+		t.Fatalf("GetDimensionalCosts: %v", err)
+	}
+	if len(costs) != len(rows) {
+		t.Errorf("GetDimensionalCosts returned %d rows, want %d", len(costs), len(rows))
+	}
+
+	mtd := NewMTDMonitor(client)
+	mtd.SetCache(queryCache)
+	mtdCosts, err := mtd.GetMTDCosts()
+	if err != nil {
+		t.Fatalf("GetMTDCosts: %v", err)
+	}
+	if len(mtdCosts) != 1 || mtdCosts[0].Cost != 15 {
+		t.Errorf("GetMTDCosts = %+v, want one month costing 15", mtdCosts)
+	}
+}
@@ -0,0 +1,128 @@
+package monitors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// ForecastMonitor compares actual daily costs to a user-provided forecast series
+type ForecastMonitor struct {
+	cfg config.ForecastConfig
+}
+
+// NewForecastMonitor creates a new forecast monitor
+func NewForecastMonitor(cfg config.ForecastConfig) *ForecastMonitor {
+	return &ForecastMonitor{
+		cfg: cfg,
+	}
+}
+
+// Compare returns the per-day variance against the forecast and an anomaly for
+// each of the most recent LookbackDays days whose actual cost falls outside the
+// tolerance band
+func (fm *ForecastMonitor) Compare(dailyCosts []models.DailyCost, forecast []models.ForecastPoint) ([]models.ForecastVariance, []models.Anomaly) {
+	log.Println("📈 Comparing actuals to forecast...")
+
+	expected := make(map[string]float64)
+	for _, point := range forecast {
+		expected[point.Date] = point.ExpectedCost
+	}
+
+	// Cumulative variance must accumulate oldest-first
+	var days []models.DailyCost
+	for _, day := range dailyCosts {
+		if _, exists := expected[day.Date]; exists {
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+
+	// Only the most recent days are checked so historical variances are not re-reported
+	checkFrom := len(days) - fm.cfg.LookbackDays
+	if fm.cfg.LookbackDays < 1 {
+		checkFrom = len(days) - 1
+	}
+
+	var variances []models.ForecastVariance
+	var anomalies []models.Anomaly
+	cumulative := 0.0
+	for i, day := range days {
+		expectedCost := expected[day.Date]
+		variance := day.TotalCost - expectedCost
+		percentage := 0.0
+		if expectedCost != 0 {
+			percentage = (variance / expectedCost) * 100
+		}
+		cumulative += variance
+
+		outside := fm.outsideTolerance(expectedCost, variance, percentage)
+		variances = append(variances, models.ForecastVariance{
+			Date:               day.Date,
+			ActualCost:         day.TotalCost,
+			ExpectedCost:       expectedCost,
+			Variance:           variance,
+			VariancePercentage: percentage,
+			CumulativeVariance: cumulative,
+			OutsideTolerance:   outside,
+		})
+
+		if outside && i >= checkFrom {
+			direction := "over"
+			if variance < 0 {
+				direction = "under"
+			}
+
+			severity := models.SeverityMedium
+			if math.Abs(percentage) > 50 || expectedCost == 0 {
+				severity = models.SeverityHigh
+			}
+			description := fmt.Sprintf("Actual cost (%.2f) is %.1f%% %s forecast (%.2f); cumulative variance to date %.2f", day.TotalCost, math.Abs(percentage), direction, expectedCost, cumulative)
+			if expectedCost == 0 {
+				description = fmt.Sprintf("Actual cost (%.2f) against a zero forecast; cumulative variance to date %.2f", day.TotalCost, cumulative)
+			}
+
+			anomalies = append(anomalies, models.Anomaly{
+				Date:        day.Date,
+				TestName:    "Forecast Variance Monitor",
+				Type:        "forecast_variance",
+				Service:     "daily_total",
+				CostImpact:  variance,
+				Description: description,
+				Severity:    severity,
+				DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+			}.WithValues(day.TotalCost, expectedCost, fm.toleranceBound(expectedCost, variance)))
+		}
+	}
+
+	log.Printf("✅ Forecast compared over %d days - cumulative variance %.2f", len(variances), cumulative)
+	return variances, models.AssignIDs(anomalies)
+}
+
+// toleranceBound returns the edge of the tolerance band on the side of the variance
+func (fm *ForecastMonitor) toleranceBound(expectedCost, variance float64) float64 {
+	tolerance := fm.cfg.Tolerance
+	if fm.cfg.ToleranceMode != config.ToleranceAbsolute {
+		tolerance = math.Abs(expectedCost) * fm.cfg.Tolerance / 100
+	}
+	return expectedCost + math.Copysign(tolerance, variance)
+}
+
+// outsideTolerance reports whether a variance exceeds the configured tolerance band.
+// A percentage of a zero forecast is undefined, so in percentage mode any spend
+// against a zero forecast is outside the band.
+func (fm *ForecastMonitor) outsideTolerance(expectedCost, variance, percentage float64) bool {
+	if fm.cfg.ToleranceMode == config.ToleranceAbsolute {
+		return math.Abs(variance) > fm.cfg.Tolerance
+	}
+	if expectedCost == 0 {
+		return variance != 0
+	}
+	return math.Abs(percentage) > fm.cfg.Tolerance
+}
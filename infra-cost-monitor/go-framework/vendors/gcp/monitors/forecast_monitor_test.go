@@ -0,0 +1,64 @@
+package monitors
+
+import (
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+func TestForecastMonitorChecksOnlyLookbackDays(t *testing.T) {
+	monitor := NewForecastMonitor(config.ForecastConfig{ToleranceMode: config.TolerancePercentage, Tolerance: 15, LookbackDays: 2})
+	daily := []models.DailyCost{
+		{Date: "2024-03-04", TotalCost: 200},
+		{Date: "2024-03-03", TotalCost: 100},
+		{Date: "2024-03-02", TotalCost: 200},
+		{Date: "2024-03-01", TotalCost: 200},
+	}
+	forecast := []models.ForecastPoint{
+		{Date: "2024-03-01", ExpectedCost: 100},
+		{Date: "2024-03-02", ExpectedCost: 100},
+		{Date: "2024-03-03", ExpectedCost: 100},
+		{Date: "2024-03-04", ExpectedCost: 100},
+	}
+
+	variances, anomalies := monitor.Compare(daily, forecast)
+	if len(variances) != 4 {
+		t.Fatalf("got %d variances, want one per forecast day", len(variances))
+	}
+	if variances[3].CumulativeVariance != 300 {
+		t.Errorf("cumulative variance = %v, want 300", variances[3].CumulativeVariance)
+	}
+	if len(anomalies) != 1 || anomalies[0].Date != "2024-03-04" {
+		t.Errorf("got %+v, want a single anomaly on 2024-03-04", anomalies)
+	}
+}
+
+func TestForecastMonitorZeroForecast(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		actual  float64
+		anomaly bool
+	}{
+		{"percentage mode with spend", config.TolerancePercentage, 5, true},
+		{"percentage mode without spend", config.TolerancePercentage, 0, false},
+		{"absolute mode within tolerance", config.ToleranceAbsolute, 5, false},
+		{"absolute mode over tolerance", config.ToleranceAbsolute, 50, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := NewForecastMonitor(config.ForecastConfig{ToleranceMode: tt.mode, Tolerance: 15, LookbackDays: 1})
+			_, anomalies := monitor.Compare(
+				[]models.DailyCost{{Date: "2024-03-01", TotalCost: tt.actual}},
+				[]models.ForecastPoint{{Date: "2024-03-01", ExpectedCost: 0}},
+			)
+			if got := len(anomalies) > 0; got != tt.anomaly {
+				t.Fatalf("anomaly = %v, want %v", got, tt.anomaly)
+			}
+			if tt.anomaly && tt.mode == config.TolerancePercentage && anomalies[0].Severity != models.SeverityHigh {
+				t.Errorf("severity = %s, want %s", anomalies[0].Severity, models.SeverityHigh)
+			}
+		})
+	}
+}
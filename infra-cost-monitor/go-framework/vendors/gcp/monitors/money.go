@@ -0,0 +1,28 @@
+package monitors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// currencySymbols maps ISO 4217 codes to the symbol shown in descriptions
+var currencySymbols = map[string]string{
+	"INR": "₹",
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+}
+
+// formatMoney formats an amount in the given currency, falling back to the
+// currency code when it has no known symbol and to a bare number without one
+func formatMoney(amount float64, currency string) string {
+	code := strings.ToUpper(currency)
+	if symbol, known := currencySymbols[code]; known {
+		return fmt.Sprintf("%s%.2f", symbol, amount)
+	}
+	if code != "" {
+		return fmt.Sprintf("%s %.2f", code, amount)
+	}
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,66 @@
+package monitors
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"strings"
+	"testing"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{1234.5, "INR", "₹1234.50"},
+		{1234.5, "USD", "$1234.50"},
+		{0.1, "usd", "$0.10"},
+		{-12, "INR", "₹-12.00"},
+		{99, "CHF", "CHF 99.00"},
+		{99, "", "99.00"},
+	}
+
+	for _, tt := range tests {
+		if got := formatMoney(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("formatMoney(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestDailyMonitorDescribesAmountsInItsCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		symbol   string
+		other    string
+	}{
+		{"default", "", "₹", "$"},
+		{"INR", "INR", "₹", "$"},
+		{"USD", "USD", "$", "₹"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			daily, composite := historyWithSpike(90)
+			monitor := NewDailyMonitor(models.NewCostDataProcessor(daily, composite))
+			if err := monitor.SetPercentile(0.9); err != nil {
+				t.Fatalf("SetPercentile: %v", err)
+			}
+			if tt.currency != "" {
+				monitor.SetCurrency(tt.currency)
+			}
+
+			collection := models.NewAnomalyCollection()
+			monitor.RunDailyTests(collection)
+			anomalies := collection.All()
+			if len(anomalies) != 2 {
+				t.Fatalf("got %d anomalies, want the total and composite spikes", len(anomalies))
+			}
+			for _, anomaly := range anomalies {
+				if !strings.Contains(anomaly.Description, tt.symbol+"500.00") || strings.Contains(anomaly.Description, tt.other) {
+					t.Errorf("description %q does not format 500 as %s500.00", anomaly.Description, tt.symbol)
+				}
+			}
+		})
+	}
+}
@@ -1,80 +1,146 @@
 package monitors
 
 import (
-	"cloud.google.com/go/bigquery"
+	"context"
+	"fmt"
+	"cloud.google.com/go/civil"
 	"infra-cost-monitor/go-framework/adapters/bigquery"
+	"infra-cost-monitor/go-framework/vendors/gcp/currency"
 	"infra-cost-monitor/go-framework/vendors/gcp/models"
 	"log"
+	"math"
+	"sort"
 	"time"
 )
 
+// mtdWindowDays is how many days of billing rows the MTD comparison reads: the
+// current month and the six before it, at 30 days each
+const mtdWindowDays = 210
+
 // MTDMonitor monitors month-to-date cost data
 type MTDMonitor struct {
-	client *bigquery.Client
+	client   *bigquery.Client
+	ctx      context.Context
+	location *time.Location
+
+	// converter converts each row into the base currency before months are summed
+	converter *currency.Converter
+
+	// dailyCosts optionally adds a linear trend and a confidence interval to month-end forecasts
+	dailyCosts []models.DailyCost
+
+	// cache serves billing rows already fetched in this or a recent run
+	cache *bigquery.QueryCache
 }
 
 // NewMTDMonitor creates a new MTD monitor
 func NewMTDMonitor(client *bigquery.Client) *MTDMonitor {
 	return &MTDMonitor{
-		client: client,
+		client:   client,
+		ctx:      context.Background(),
+		location: time.UTC,
 	}
 }
 
+// SetContext sets the context BigQuery fetches run under, so they can be cancelled
+func (dm *MTDMonitor) SetContext(ctx context.Context) {
+	dm.ctx = ctx
+}
+
+// SetLocation sets the timezone used to derive the month of each billing date
+func (dm *MTDMonitor) SetLocation(location *time.Location) {
+	dm.location = location
+}
+
+// SetConverter converts billing rows into the converter's base currency before
+// they are summed; without one, rows in more than one currency are an error
+func (dm *MTDMonitor) SetConverter(converter *currency.Converter) {
+	dm.converter = converter
+}
+
+// SetCache serves billing rows from a query cache shared with the other monitors.
+// The cache is widened to the MTD window so a single scan serves every monitor.
+func (dm *MTDMonitor) SetCache(queryCache *bigquery.QueryCache) {
+	queryCache.Widen(bigquery.QueryBillingData, mtdWindowDays)
+	dm.cache = queryCache
+}
+
+// SetDailyCosts sets the daily series month-end forecasts fit their trend and interval to
+func (dm *MTDMonitor) SetDailyCosts(dailyCosts []models.DailyCost) {
+	dm.dailyCosts = dailyCosts
+}
+
 // GetMTDCosts retrieves month-to-date cost data from BigQuery
 func (dm *MTDMonitor) GetMTDCosts() ([]models.MTDCost, error) {
 	log.Println("📊 Fetching MTD cost data...")
 
-	// Create BigQuery client
-	bqClient, err := bigquery.NewClient()
-	if err != nil {
-		return nil, err
-	}
-	defer bqClient.Close()
-
-	// Get billing data for last 7 months
-	it, err := bqClient.GetBillingData(210) // 7 months * 30 days
-	if err != nil {
-		return nil, err
-	}
-
 	// Group by month
 	monthlyCosts := make(map[string]float64)
-	monthlyDays := make(map[string]int)
+	// Rows are grouped by many dimensions, so a date repeats; the set dedupes it
+	monthlyDays := make(map[string]map[string]struct{})
+	currencies := make(map[string]bool)
 
-	for {
-		var row struct {
-			Date string  `bigquery:"date"`
-			Cost float64 `bigquery:"cost"`
+	// Get billing data for last 7 months
+	err := forEachBillingRow(dm.ctx, dm.client, dm.cache, mtdWindowDays, func(row models.CostData) error {
+		// Derive the month (YYYY-MM) from the parsed date rather than its string form
+		date, err := civil.ParseDate(row.Date)
+		if err != nil || !date.IsValid() {
+			log.Printf("Warning: Skipping MTD row with invalid date %v", row.Date)
+			return nil
 		}
 
-		err := it.Next(&row)
-		if err != nil {
-			break
+		cost := row.Cost
+		if dm.converter != nil && row.Currency != "" {
+			cost, err = dm.converter.Convert(row.Cost, row.Currency, row.Date)
+			if err != nil {
+				return fmt.Errorf("failed to convert MTD cost: %v", err)
+			}
+			row.Currency = dm.converter.Base()
+		}
+		if row.Currency != "" {
+			currencies[row.Currency] = true
 		}
 
-		// Extract month from date (YYYY-MM format)
-		if len(row.Date) >= 7 {
-			month := row.Date[:7] // YYYY-MM
-			monthlyCosts[month] += row.Cost
-			
-			// Count unique days in this month
-			if monthlyDays[month] == 0 {
-				monthlyDays[month] = 1
-			}
+		month := date.In(dm.location).Format("2006-01")
+		monthlyCosts[month] += cost
+		
+		// Count unique days in this month
+		if monthlyDays[month] == nil {
+			monthlyDays[month] = make(map[string]struct{})
 		}
+		monthlyDays[month][row.Date] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Months can only be summed in one currency
+	var currencyCode string
+	for code := range currencies {
+		currencyCode = code
+	}
+	if len(currencies) > 1 {
+		return nil, fmt.Errorf("MTD costs span %d currencies; configure a base currency to convert them", len(currencies))
 	}
 
 	// Convert to slice
 	var mtdCosts []models.MTDCost
 	for month, cost := range monthlyCosts {
-		days := monthlyDays[month]
+		days := len(monthlyDays[month])
 		mtdCosts = append(mtdCosts, models.MTDCost{
-			Month: month,
-			Cost:  cost,
-			Days:  days,
+			Month:    month,
+			Cost:     cost,
+			Days:     days,
+			Currency: currencyCode,
 		})
 	}
 
+	// Newest month first: callers read index 0 as the current month and 1 as the previous
+	sort.Slice(mtdCosts, func(i, j int) bool {
+		return mtdCosts[i].Month > mtdCosts[j].Month
+	})
+
 	log.Printf("✅ Retrieved %d MTD cost records", len(mtdCosts))
 	return mtdCosts, nil
 }
@@ -109,4 +175,103 @@ func (dm *MTDMonitor) CalculateMonthlySpike(current, previous float64, threshold
 	percentage := (increase / previous) * 100
 
 	return increase > threshold || percentage > 10.0, increase, percentage
-} 
\ No newline at end of file
+}
+
+// CompareMonths compares the current month to date with the previous month,
+// prorated to the days elapsed, flagging a spike over threshold or 10%
+func (dm *MTDMonitor) CompareMonths(current, previous models.MTDCost, threshold float64) models.MonthlyComparison {
+	return models.CompareMonths(current, previous, threshold, 10.0)
+}
+
+// ForecastMonthEnd projects the full cost of the month containing asOf from its
+// month-to-date cost at the elapsed-days run rate. When a daily series is set, a
+// least-squares trend over the month's days is also extrapolated, and the interval
+// is widened by the daily variation over the remaining days.
+func (dm *MTDMonitor) ForecastMonthEnd(mtdCosts []models.MTDCost, asOf time.Time) models.MonthEndForecast {
+	asOf = asOf.In(dm.location)
+	month := asOf.Format("2006-01")
+	daysInMonth := time.Date(asOf.Year(), asOf.Month()+1, 0, 0, 0, 0, 0, dm.location).Day()
+
+	forecast := models.MonthEndForecast{
+		Month:       month,
+		AsOf:        asOf.Format("2006-01-02"),
+		DaysInMonth: daysInMonth,
+	}
+	for _, mtd := range mtdCosts {
+		if mtd.Month == month {
+			forecast.MTDCost = mtd.Cost
+			forecast.DaysElapsed = mtd.Days
+			break
+		}
+	}
+	if forecast.DaysElapsed == 0 {
+		return forecast
+	}
+
+	forecast.RunRate = forecast.MTDCost / float64(forecast.DaysElapsed)
+	forecast.Projected = forecast.RunRate * float64(daysInMonth)
+	forecast.Lower, forecast.Upper = forecast.Projected, forecast.Projected
+
+	// Daily costs of this month, keyed by day of month
+	var xs, ys []float64
+	lastDay := 0
+	for _, day := range dm.dailyCosts {
+		if len(day.Date) < 10 || day.Date[:7] != month {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		xs = append(xs, float64(date.Day()))
+		ys = append(ys, day.TotalCost)
+		if date.Day() > lastDay {
+			lastDay = date.Day()
+		}
+	}
+	if len(ys) < 2 {
+		return forecast
+	}
+	remaining := daysInMonth - lastDay
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	// Spread of daily costs around the run rate, or around the trend when it can be fitted
+	n := float64(len(ys))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range ys {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+	mean := sumY / n
+	var squares float64
+	for _, y := range ys {
+		squares += (y - mean) * (y - mean)
+	}
+	spread := math.Sqrt(squares / (n - 1))
+
+	if denominator := n*sumXX - sumX*sumX; len(ys) >= 3 && denominator != 0 {
+		slope := (n*sumXY - sumX*sumY) / denominator
+		intercept := (sumY - slope*sumX) / n
+
+		forecast.TrendProjected = forecast.MTDCost
+		for day := lastDay + 1; day <= daysInMonth; day++ {
+			forecast.TrendProjected += math.Max(0, intercept+slope*float64(day))
+		}
+
+		var residuals float64
+		for i := range ys {
+			residual := ys[i] - (intercept + slope*xs[i])
+			residuals += residual * residual
+		}
+		spread = math.Sqrt(residuals / (n - 2))
+	}
+
+	margin := 1.96 * spread * math.Sqrt(float64(remaining))
+	forecast.Lower = math.Max(forecast.MTDCost, forecast.Projected-margin)
+	forecast.Upper = forecast.Projected + margin
+	return forecast
+}
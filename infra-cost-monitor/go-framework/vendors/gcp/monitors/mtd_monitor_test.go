@@ -0,0 +1,213 @@
+package monitors
+
+import (
+	"infra-cost-monitor/go-framework/adapters/bigquery"
+	"infra-cost-monitor/go-framework/vendors/gcp/currency"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// newCachedMTDMonitor returns an MTD monitor whose billing rows are served from a
+// query cache holding rows, so no BigQuery client is needed
+func newCachedMTDMonitor(rows []models.CostData) *MTDMonitor {
+	client := &bigquery.Client{}
+	queryCache := bigquery.NewQueryCache(time.Hour)
+	queryCache.Put(client.BillingTableID(), bigquery.QueryBillingData, mtdWindowDays, rows)
+
+	monitor := NewMTDMonitor(client)
+	monitor.SetCache(queryCache)
+	return monitor
+}
+
+func TestGetMTDCostsCountsDistinctDays(t *testing.T) {
+	var rows []models.CostData
+	for _, date := range []string{"2024-05-01", "2024-05-02", "2024-05-03"} {
+		for _, service := range []string{"Compute", "Storage", "BigQuery"} {
+			rows = append(rows, models.CostData{Date: date, Service: service, Cost: 10})
+		}
+	}
+	rows = append(rows,
+		models.CostData{Date: "2024-04-29", Service: "Compute", Cost: 5},
+		models.CostData{Date: "2024-04-30", Service: "Compute", Cost: 5},
+		models.CostData{Date: "2024-04-30", Service: "Storage", Cost: 5},
+	)
+
+	mtdCosts, err := newCachedMTDMonitor(rows).GetMTDCosts()
+	if err != nil {
+		t.Fatalf("GetMTDCosts: %v", err)
+	}
+	if len(mtdCosts) != 2 {
+		t.Fatalf("got %d months, want 2", len(mtdCosts))
+	}
+	if mtdCosts[0].Month != "2024-05" || mtdCosts[0].Days != 3 || mtdCosts[0].Cost != 90 {
+		t.Errorf("current month = %+v, want 2024-05 with 3 days costing 90", mtdCosts[0])
+	}
+	if mtdCosts[1].Month != "2024-04" || mtdCosts[1].Days != 2 || mtdCosts[1].Cost != 15 {
+		t.Errorf("previous month = %+v, want 2024-04 with 2 days costing 15", mtdCosts[1])
+	}
+}
+
+func TestGetMTDCostsSortsNewestMonthFirst(t *testing.T) {
+	rows := []models.CostData{
+		{Date: "2024-03-15", Service: "Compute", Cost: 1},
+		{Date: "2024-05-01", Service: "Compute", Cost: 1},
+		{Date: "2023-12-31", Service: "Compute", Cost: 1},
+		{Date: "2024-01-10", Service: "Compute", Cost: 1},
+		{Date: "2024-04-20", Service: "Compute", Cost: 1},
+		{Date: "2024-02-29", Service: "Compute", Cost: 1},
+	}
+	want := []string{"2024-05", "2024-04", "2024-03", "2024-02", "2024-01", "2023-12"}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		rng.Shuffle(len(rows), func(a, b int) { rows[a], rows[b] = rows[b], rows[a] })
+
+		mtdCosts, err := newCachedMTDMonitor(rows).GetMTDCosts()
+		if err != nil {
+			t.Fatalf("GetMTDCosts: %v", err)
+		}
+		if len(mtdCosts) != len(want) {
+			t.Fatalf("got %d months, want %d", len(mtdCosts), len(want))
+		}
+		for j, mtd := range mtdCosts {
+			if mtd.Month != want[j] {
+				t.Fatalf("month %d = %s, want %s", j, mtd.Month, want[j])
+			}
+		}
+	}
+}
+
+func TestGetMTDCostsDerivesMonthsFromParsedDates(t *testing.T) {
+	rows := []models.CostData{
+		{Date: "2024-04-30", Service: "Compute", Cost: 1},
+		{Date: "2024-05-01", Service: "Compute", Cost: 2},
+		{Date: "2024-05-31", Service: "Compute", Cost: 4},
+		{Date: "2024-5-15", Service: "Compute", Cost: 100},
+		{Date: "", Service: "Compute", Cost: 100},
+	}
+
+	for _, zone := range []string{"UTC", "Pacific/Kiritimati", "Pacific/Pago_Pago"} {
+		t.Run(zone, func(t *testing.T) {
+			location, err := time.LoadLocation(zone)
+			if err != nil {
+				t.Skipf("LoadLocation: %v", err)
+			}
+			monitor := newCachedMTDMonitor(rows)
+			monitor.SetLocation(location)
+
+			mtdCosts, err := monitor.GetMTDCosts()
+			if err != nil {
+				t.Fatalf("GetMTDCosts: %v", err)
+			}
+			if len(mtdCosts) != 2 {
+				t.Fatalf("got %d months, want 2: %+v", len(mtdCosts), mtdCosts)
+			}
+			if mtdCosts[0].Month != "2024-05" || mtdCosts[0].Days != 2 || mtdCosts[0].Cost != 6 {
+				t.Errorf("current month = %+v, want 2024-05 with the first and last day costing 6", mtdCosts[0])
+			}
+			if mtdCosts[1].Month != "2024-04" || mtdCosts[1].Cost != 1 {
+				t.Errorf("previous month = %+v, want 2024-04 costing 1", mtdCosts[1])
+			}
+		})
+	}
+}
+
+// rampDailyCosts returns June 2024 daily costs of 10 x day of month for the first days days
+func rampDailyCosts(days int) ([]models.DailyCost, float64) {
+	var daily []models.DailyCost
+	total := 0.0
+	for day := 1; day <= days; day++ {
+		cost := 10 * float64(day)
+		daily = append(daily, models.DailyCost{Date: time.Date(2024, time.June, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), TotalCost: cost})
+		total += cost
+	}
+	return daily, total
+}
+
+func TestForecastMonthEndOnAKnownRamp(t *testing.T) {
+	daily, mtd := rampDailyCosts(10)
+	asOf := time.Date(2024, time.June, 10, 12, 0, 0, 0, time.UTC)
+	mtdCosts := []models.MTDCost{{Month: "2024-06", Cost: mtd, Days: 10}, {Month: "2024-05", Cost: 9000, Days: 31}}
+
+	monitor := NewMTDMonitor(nil)
+	monitor.SetDailyCosts(daily)
+	forecast := monitor.ForecastMonthEnd(mtdCosts, asOf)
+
+	// 550 over 10 days runs at 55 a day, 1650 over June's 30 days; the ramp itself
+	// continues to 10 x 30, summing to 10 x (1 + ... + 30) = 4650
+	almostEqual := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if forecast.Month != "2024-06" || forecast.DaysInMonth != 30 || forecast.DaysElapsed != 10 {
+		t.Fatalf("forecast = %+v, want June 2024 with 10 of 30 days elapsed", forecast)
+	}
+	if !almostEqual(forecast.RunRate, 55) || !almostEqual(forecast.Projected, 1650) {
+		t.Errorf("run rate %v projecting %v, want 55 projecting 1650", forecast.RunRate, forecast.Projected)
+	}
+	if !almostEqual(forecast.TrendProjected, 4650) {
+		t.Errorf("trend projection = %v, want 4650", forecast.TrendProjected)
+	}
+
+	// The ramp fits its trend exactly, leaving no spread to widen the interval
+	if !almostEqual(forecast.Lower, 1650) || !almostEqual(forecast.Upper, 1650) {
+		t.Errorf("interval = [%v, %v], want [1650, 1650]", forecast.Lower, forecast.Upper)
+	}
+}
+
+func TestForecastMonthEndInterval(t *testing.T) {
+	asOf := time.Date(2024, time.June, 10, 0, 0, 0, 0, time.UTC)
+	mtdCosts := []models.MTDCost{{Month: "2024-06", Cost: 550, Days: 10}}
+
+	// Without a daily series the interval collapses to the run-rate projection
+	forecast := NewMTDMonitor(nil).ForecastMonthEnd(mtdCosts, asOf)
+	if forecast.Lower != forecast.Projected || forecast.Upper != forecast.Projected || forecast.TrendProjected != 0 {
+		t.Errorf("forecast without daily costs = %+v, want the run-rate projection alone", forecast)
+	}
+
+	// Noise around the ramp widens it, but never below the spend so far
+	daily, _ := rampDailyCosts(10)
+	for i := range daily {
+		if i%2 == 0 {
+			daily[i].TotalCost += 20
+		} else {
+			daily[i].TotalCost -= 20
+		}
+	}
+	monitor := NewMTDMonitor(nil)
+	monitor.SetDailyCosts(daily)
+	forecast = monitor.ForecastMonthEnd(mtdCosts, asOf)
+	if !(forecast.Lower < forecast.Projected && forecast.Projected < forecast.Upper) {
+		t.Errorf("interval [%v, %v] does not surround %v", forecast.Lower, forecast.Upper, forecast.Projected)
+	}
+	if forecast.Lower < forecast.MTDCost {
+		t.Errorf("lower bound %v is below the month-to-date cost %v", forecast.Lower, forecast.MTDCost)
+	}
+
+	// A month with no spend yet has nothing to project
+	forecast = NewMTDMonitor(nil).ForecastMonthEnd(mtdCosts, time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC))
+	if forecast.Projected != 0 || forecast.DaysInMonth != 31 {
+		t.Errorf("July forecast = %+v, want no projection over 31 days", forecast)
+	}
+}
+
+func TestGetMTDCostsWithMixedCurrencies(t *testing.T) {
+	rows := []models.CostData{
+		{Date: "2024-05-01", Service: "Compute", Cost: 830, Currency: "INR"},
+		{Date: "2024-05-02", Service: "Compute", Cost: 10, Currency: "USD"},
+	}
+
+	if _, err := newCachedMTDMonitor(rows).GetMTDCosts(); err == nil {
+		t.Error("GetMTDCosts summed INR and USD without a converter")
+	}
+
+	monitor := newCachedMTDMonitor(rows)
+	monitor.SetConverter(currency.NewConverter("INR", map[string]float64{"USD": 83}))
+	mtdCosts, err := monitor.GetMTDCosts()
+	if err != nil {
+		t.Fatalf("GetMTDCosts: %v", err)
+	}
+	if len(mtdCosts) != 1 || mtdCosts[0].Cost != 1660 || mtdCosts[0].Currency != "INR" {
+		t.Errorf("MTD costs = %+v, want one month costing 1660 INR", mtdCosts)
+	}
+}
@@ -0,0 +1,107 @@
+package monitors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"sort"
+	"time"
+)
+
+// NewSKUMonitor flags SKUs that appear for the first time with non-trivial cost
+type NewSKUMonitor struct {
+	store     *state.Store
+	allowlist config.Allowlist
+	minCost   float64
+}
+
+// NewNewSKUMonitor creates a new SKU monitor backed by the state store
+func NewNewSKUMonitor(store *state.Store, allowlist config.Allowlist, minCost float64) *NewSKUMonitor {
+	return &NewSKUMonitor{
+		store:     store,
+		allowlist: allowlist,
+		minCost:   minCost,
+	}
+}
+
+// Detect returns a new_sku anomaly for each SKU not previously seen
+func (nm *NewSKUMonitor) Detect(costs []models.CostData) []models.Anomaly {
+	log.Println("🔍 Checking for new SKUs...")
+
+	type skuCost struct {
+		service   string
+		sku       string
+		firstDate string
+		cost      float64
+	}
+
+	skus := make(map[string]*skuCost)
+	for _, cost := range costs {
+		key := cost.Service + "|" + cost.SKU
+		entry, exists := skus[key]
+		if !exists {
+			entry = &skuCost{service: cost.Service, sku: cost.SKU, firstDate: cost.Date}
+			skus[key] = entry
+		}
+		if cost.Date < entry.firstDate {
+			entry.firstDate = cost.Date
+		}
+		entry.cost += cost.Cost
+	}
+
+	// The first run with data only seeds the store so existing SKUs are not
+	// reported as new; a run without rows leaves it unseeded
+	if len(skus) == 0 {
+		return nil
+	}
+	if !nm.store.SKUsSeeded() {
+		for key, entry := range skus {
+			nm.store.MarkSKUSeen(key, entry.firstDate)
+		}
+		nm.store.SetSKUsSeeded()
+		log.Printf("✅ Seeded %d known SKUs", len(skus))
+		return nil
+	}
+
+	keys := make([]string, 0, len(skus))
+	for key := range skus {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var anomalies []models.Anomaly
+	for _, key := range keys {
+		entry := skus[key]
+		if nm.store.SeenSKU(key) {
+			continue
+		}
+
+		// A SKU below the cost floor is left unseen, so it is reported once it
+		// crosses the floor
+		if entry.cost < nm.minCost {
+			continue
+		}
+		nm.store.MarkSKUSeen(key, entry.firstDate)
+		if nm.allowlist.HasSKU(entry.sku) {
+			continue
+		}
+
+		anomalies = append(anomalies, models.Anomaly{
+			Date:         entry.firstDate,
+			TestName:     "New SKU Monitor",
+			Type:         "new_sku",
+			Service:      entry.service,
+			SKU:          entry.sku,
+			CompositeKey: key,
+			CostImpact:   entry.cost,
+			Description:  fmt.Sprintf("New SKU %s in %s first seen on %s with cost %.2f", entry.sku, entry.service, entry.firstDate, entry.cost),
+			Severity:     "MEDIUM",
+			DetectedAt:   time.Now().Format("2006-01-02 15:04:05"),
+		}.WithValues(entry.cost, 0, nm.minCost))
+	}
+
+	log.Printf("✅ Detected %d new SKUs", len(anomalies))
+	return models.AssignIDs(anomalies)
+}
@@ -0,0 +1,67 @@
+package monitors
+
+import (
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/state"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"path/filepath"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *state.Store {
+	t.Helper()
+	store, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return store
+}
+
+func TestNewSKUMonitor(t *testing.T) {
+	store := newTestStore(t)
+	monitor := NewNewSKUMonitor(store, config.Allowlist{SKUs: []string{"Allowed"}}, 10)
+
+	// An empty first run does not seed the store
+	if anomalies := monitor.Detect(nil); len(anomalies) != 0 {
+		t.Fatalf("empty run reported %d anomalies", len(anomalies))
+	}
+	if store.SKUsSeeded() {
+		t.Fatal("empty run seeded the store")
+	}
+
+	// The first run with data seeds it without reporting anything
+	seed := []models.CostData{{Date: "2024-03-01", Service: "Compute", SKU: "VM", Cost: 100}}
+	if anomalies := monitor.Detect(seed); len(anomalies) != 0 {
+		t.Fatalf("seeding run reported %d anomalies", len(anomalies))
+	}
+	if !store.SKUsSeeded() || !store.SeenSKU("Compute|VM") {
+		t.Fatal("seeding run did not record the existing SKU")
+	}
+
+	costs := []models.CostData{
+		{Date: "2024-03-02", Service: "Compute", SKU: "VM", Cost: 100},
+		{Date: "2024-03-02", Service: "Compute", SKU: "GPU", Cost: 50},
+		{Date: "2024-03-02", Service: "Storage", SKU: "Tiny", Cost: 1},
+		{Date: "2024-03-02", Service: "Storage", SKU: "Allowed", Cost: 50},
+	}
+	anomalies := monitor.Detect(costs)
+	if len(anomalies) != 1 {
+		t.Fatalf("got %d anomalies, want 1", len(anomalies))
+	}
+	anomaly := anomalies[0]
+	if anomaly.SKU != "GPU" || anomaly.TestName == "" || anomaly.CompositeKey != "Compute|GPU" {
+		t.Errorf("got anomaly %+v, want the GPU SKU with a test name and composite key", anomaly)
+	}
+	if store.SeenSKU("Storage|Tiny") {
+		t.Error("SKU below the cost floor was marked seen")
+	}
+	if !store.SeenSKU("Storage|Allowed") {
+		t.Error("allowlisted SKU was not marked seen")
+	}
+
+	// The below-floor SKU is reported once it crosses the floor
+	grown := []models.CostData{{Date: "2024-03-03", Service: "Storage", SKU: "Tiny", Cost: 20}}
+	if anomalies := monitor.Detect(grown); len(anomalies) != 1 || anomalies[0].SKU != "Tiny" {
+		t.Errorf("got %+v, want the Tiny SKU reported after crossing the floor", anomalies)
+	}
+}
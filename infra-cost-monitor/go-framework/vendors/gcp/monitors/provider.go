@@ -0,0 +1,114 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"infra-cost-monitor/go-framework/adapters/bigquery"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+
+	"cloud.google.com/go/civil"
+	"google.golang.org/api/iterator"
+)
+
+// GCPProvider is the provider.CostProvider for GCP, reading the BigQuery billing
+// export through the MTD and dimensional monitors
+type GCPProvider struct {
+	client      *bigquery.Client
+	mtd         *MTDMonitor
+	dimensional *DimensionalMonitor
+}
+
+// NewGCPProvider creates a new GCP cost provider from configured monitors sharing the client
+func NewGCPProvider(client *bigquery.Client, mtd *MTDMonitor, dimensional *DimensionalMonitor) *GCPProvider {
+	return &GCPProvider{
+		client:      client,
+		mtd:         mtd,
+		dimensional: dimensional,
+	}
+}
+
+// Name returns the vendor name
+func (gp *GCPProvider) Name() string {
+	return "gcp"
+}
+
+// DailyCosts returns the total cost per day over the last days days, newest first.
+// Rows in other currencies are converted with the MTD monitor's converter; without
+// one, more than one currency is an error.
+func (gp *GCPProvider) DailyCosts(ctx context.Context, days int) ([]models.DailyCost, error) {
+	log.Println("📊 Fetching daily cost data...")
+
+	it, err := gp.client.GetDailyCosts(ctx, days)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64)
+	currencies := make(map[string]bool)
+	for {
+		var row struct {
+			Date      civil.Date `bigquery:"date"`
+			Currency  string     `bigquery:"currency"`
+			TotalCost float64    `bigquery:"total_cost"`
+		}
+
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read daily cost rows: %w", err)
+		}
+
+		date := row.Date.String()
+		cost := row.TotalCost
+		if converter := gp.mtd.converter; converter != nil && row.Currency != "" {
+			cost, err = converter.Convert(row.TotalCost, row.Currency, date)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert daily cost: %v", err)
+			}
+			row.Currency = converter.Base()
+		}
+		if row.Currency != "" {
+			currencies[row.Currency] = true
+		}
+		totals[date] += cost
+	}
+
+	// Days can only be summed in one currency
+	var currencyCode string
+	for code := range currencies {
+		currencyCode = code
+	}
+	if len(currencies) > 1 {
+		return nil, fmt.Errorf("daily costs span %d currencies; configure a base currency to convert them", len(currencies))
+	}
+
+	var dailyCosts []models.DailyCost
+	for date, total := range totals {
+		dailyCosts = append(dailyCosts, models.DailyCost{
+			Date:      date,
+			TotalCost: total,
+			Currency:  currencyCode,
+		})
+	}
+
+	models.SortDailyCostsDesc(dailyCosts)
+	log.Printf("✅ Retrieved %d daily cost records", len(dailyCosts))
+	return dailyCosts, nil
+}
+
+// MTDCosts returns the cost per month, newest month first
+func (gp *GCPProvider) MTDCosts(ctx context.Context) ([]models.MTDCost, error) {
+	gp.mtd.SetContext(ctx)
+	return gp.mtd.GetMTDCosts()
+}
+
+// DimensionalCosts returns the dimensional cost rows over the last days days, or
+// since the dimensional monitor's watermark when one is set
+func (gp *GCPProvider) DimensionalCosts(ctx context.Context, days int) ([]models.CostData, error) {
+	gp.dimensional.SetContext(ctx)
+	gp.dimensional.SetDays(days)
+	return gp.dimensional.GetDimensionalCosts()
+}
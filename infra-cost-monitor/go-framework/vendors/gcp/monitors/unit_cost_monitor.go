@@ -0,0 +1,154 @@
+package monitors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"sort"
+	"time"
+)
+
+// UnitCostMonitor trends cost per unit of usage for each SKU
+type UnitCostMonitor struct {
+	cfg config.UnitCostConfig
+}
+
+// NewUnitCostMonitor creates a new unit cost monitor
+func NewUnitCostMonitor(cfg config.UnitCostConfig) *UnitCostMonitor {
+	return &UnitCostMonitor{
+		cfg: cfg,
+	}
+}
+
+// Analyze computes the daily unit cost trend per SKU and flags SKUs whose unit
+// cost is worsening beyond the threshold, even when absolute cost is flat
+func (um *UnitCostMonitor) Analyze(costs []models.CostData) ([]models.UnitCostTrend, []models.Anomaly) {
+	log.Println("📐 Analyzing unit cost trends...")
+
+	type dayTotals struct {
+		cost  float64
+		usage float64
+	}
+	type skuSeries struct {
+		service string
+		sku     string
+		unit    string
+		days    map[string]*dayTotals
+	}
+
+	series := make(map[string]*skuSeries)
+	for _, cost := range costs {
+		key := cost.Service + "|" + cost.SKU + "|" + cost.UsageUnit
+		entry, exists := series[key]
+		if !exists {
+			entry = &skuSeries{service: cost.Service, sku: cost.SKU, unit: cost.UsageUnit, days: make(map[string]*dayTotals)}
+			series[key] = entry
+		}
+		if entry.days[cost.Date] == nil {
+			entry.days[cost.Date] = &dayTotals{}
+		}
+		entry.days[cost.Date].cost += cost.Cost
+		entry.days[cost.Date].usage += cost.UsageAmount
+	}
+
+	var trends []models.UnitCostTrend
+	var anomalies []models.Anomaly
+	for _, entry := range series {
+		dates := make([]string, 0, len(entry.days))
+		for date, totals := range entry.days {
+			if totals.usage > 0 {
+				dates = append(dates, date)
+			}
+		}
+		if len(dates) < um.cfg.MinDays {
+			continue
+		}
+		sort.Strings(dates)
+
+		unitCosts := make([]float64, len(dates))
+		for i, date := range dates {
+			unitCosts[i] = entry.days[date].cost / entry.days[date].usage
+		}
+
+		slope, mean := linearTrend(unitCosts)
+		if mean <= 0 {
+			continue
+		}
+
+		// Project the slope across the window relative to the average unit cost
+		changePercentage := slope * float64(len(unitCosts)-1) / mean * 100
+		trend := "stable"
+		if changePercentage > um.cfg.WorseningThreshold {
+			trend = "worsening"
+		} else if changePercentage < -um.cfg.WorseningThreshold {
+			trend = "improving"
+		}
+
+		unitTrend := models.UnitCostTrend{
+			Service:          entry.service,
+			SKU:              entry.sku,
+			UsageUnit:        entry.unit,
+			Days:             len(dates),
+			FirstUnitCost:    unitCosts[0],
+			LastUnitCost:     unitCosts[len(unitCosts)-1],
+			SlopePerDay:      slope,
+			ChangePercentage: changePercentage,
+			Trend:            trend,
+		}
+		trends = append(trends, unitTrend)
+
+		if trend == "worsening" {
+			anomalies = append(anomalies, models.Anomaly{
+				Date:        dates[len(dates)-1],
+				TestName:    "Unit Cost Trend Monitor",
+				Type:        "unit_cost_trend",
+				Service:     entry.service,
+				SKU:         entry.sku,
+				CostImpact:  0,
+				Description: fmt.Sprintf("Unit cost for %s (%s) rose %.1f%% over %d days (%.6f to %.6f per %s)", entry.sku, entry.service, changePercentage, len(dates), unitTrend.FirstUnitCost, unitTrend.LastUnitCost, entry.unit),
+				Severity:    models.SeverityMedium,
+				DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
+			}.WithValues(unitTrend.LastUnitCost, unitTrend.FirstUnitCost, unitTrend.FirstUnitCost*(1+um.cfg.WorseningThreshold/100)))
+		}
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].UsageUnit != trends[j].UsageUnit {
+			return trends[i].UsageUnit < trends[j].UsageUnit
+		}
+		return trends[i].ChangePercentage > trends[j].ChangePercentage
+	})
+
+	log.Printf("✅ Analyzed %d SKU unit cost trends - %d worsening", len(trends), len(anomalies))
+	return trends, models.AssignIDs(anomalies)
+}
+
+// GroupTrendsByUnit groups unit cost trends by usage unit
+func (um *UnitCostMonitor) GroupTrendsByUnit(trends []models.UnitCostTrend) map[string][]models.UnitCostTrend {
+	grouped := make(map[string][]models.UnitCostTrend)
+	for _, trend := range trends {
+		grouped[trend.UsageUnit] = append(grouped[trend.UsageUnit], trend)
+	}
+	return grouped
+}
+
+// linearTrend returns the least-squares slope and the mean of a series
+func linearTrend(values []float64) (float64, float64) {
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	mean := sumY / n
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, mean
+	}
+	return (n*sumXY - sumX*sumY) / denominator, mean
+}
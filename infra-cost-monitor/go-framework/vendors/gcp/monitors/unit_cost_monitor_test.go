@@ -0,0 +1,100 @@
+package monitors
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+// flatCostSKU returns days of a constant 100 in cost for one SKU, with the usage
+// on day i given by usage(i)
+func flatCostSKU(sku, unit string, days int, usage func(i int) float64) []models.CostData {
+	var costs []models.CostData
+	for i := 0; i < days; i++ {
+		costs = append(costs, models.CostData{
+			Date:        fmt.Sprintf("2024-05-%02d", i+1),
+			Service:     "Compute",
+			SKU:         sku,
+			UsageUnit:   unit,
+			Cost:        100,
+			UsageAmount: usage(i),
+		})
+	}
+	return costs
+}
+
+func TestUnitCostMonitorTrends(t *testing.T) {
+	tests := []struct {
+		name    string
+		usage   func(i int) float64
+		trend   string
+		flagged bool
+	}{
+		{"falling usage at flat cost is worsening", func(i int) float64 { return 100 - 5*float64(i) }, "worsening", true},
+		{"rising usage at flat cost is improving", func(i int) float64 { return 100 + 10*float64(i) }, "improving", false},
+		{"steady usage is stable", func(i int) float64 { return 100 }, "stable", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := NewUnitCostMonitor(config.UnitCostConfig{Enabled: true, MinDays: 7, WorseningThreshold: 10})
+			trends, anomalies := monitor.Analyze(flatCostSKU("VM", "hour", 10, tt.usage))
+
+			if len(trends) != 1 {
+				t.Fatalf("got %d trends, want 1: %+v", len(trends), trends)
+			}
+			if trends[0].Trend != tt.trend {
+				t.Errorf("trend = %q (%.1f%%), want %q", trends[0].Trend, trends[0].ChangePercentage, tt.trend)
+			}
+			if trends[0].Days != 10 || trends[0].FirstUnitCost != 100/tt.usage(0) || trends[0].LastUnitCost != 100/tt.usage(9) {
+				t.Errorf("trend = %+v, want 10 days from %v to %v", trends[0], 100/tt.usage(0), 100/tt.usage(9))
+			}
+			if flagged := len(anomalies) > 0; flagged != tt.flagged {
+				t.Fatalf("flagged = %v, want %v", flagged, tt.flagged)
+			}
+			if tt.flagged && (anomalies[0].Type != "unit_cost_trend" || anomalies[0].SKU != "VM" || anomalies[0].Date != "2024-05-10") {
+				t.Errorf("anomaly = %+v, want a unit_cost_trend for VM on the last day", anomalies[0])
+			}
+		})
+	}
+}
+
+func TestUnitCostMonitorSkipsShortAndZeroUsageSeries(t *testing.T) {
+	monitor := NewUnitCostMonitor(config.UnitCostConfig{Enabled: true, MinDays: 7, WorseningThreshold: 10})
+
+	// Six days of usage plus days with none fall short of the seven day minimum
+	costs := flatCostSKU("VM", "hour", 10, func(i int) float64 {
+		if i%2 == 0 && i < 8 {
+			return 0
+		}
+		return 100
+	})
+	costs = append(costs, flatCostSKU("Disk", "gibibyte month", 5, func(i int) float64 { return 10 })...)
+
+	trends, anomalies := monitor.Analyze(costs)
+	if len(trends) != 0 || len(anomalies) != 0 {
+		t.Errorf("got %d trends and %d anomalies, want none: %+v", len(trends), len(anomalies), trends)
+	}
+}
+
+func TestUnitCostMonitorGroupsByUsageUnit(t *testing.T) {
+	monitor := NewUnitCostMonitor(config.UnitCostConfig{Enabled: true, MinDays: 3, WorseningThreshold: 10})
+	costs := flatCostSKU("VM", "hour", 5, func(i int) float64 { return 100 })
+	costs = append(costs, flatCostSKU("GPU", "hour", 5, func(i int) float64 { return 100 - 10*float64(i) })...)
+	costs = append(costs, flatCostSKU("Disk", "gibibyte month", 5, func(i int) float64 { return 10 })...)
+
+	trends, _ := monitor.Analyze(costs)
+	var order []string
+	for _, trend := range trends {
+		order = append(order, trend.SKU)
+	}
+	if fmt.Sprint(order) != "[Disk GPU VM]" {
+		t.Errorf("trend order = %v, want [Disk GPU VM] (by unit, then worst first)", order)
+	}
+
+	grouped := monitor.GroupTrendsByUnit(trends)
+	if len(grouped["hour"]) != 2 || len(grouped["gibibyte month"]) != 1 {
+		t.Errorf("grouped = %+v, want 2 hour trends and 1 gibibyte month trend", grouped)
+	}
+}
@@ -0,0 +1,100 @@
+package triggers
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// attribute splits the change between two periods by the first dimension,
+// keeps the top-K contributors by absolute delta and recurses into the rest
+func attribute(current, previous []models.CostData, dimensions []string, topK int) []models.Contributor {
+	if len(dimensions) == 0 {
+		return nil
+	}
+	dimension := dimensions[0]
+
+	currentByValue := groupByDimension(current, dimension)
+	previousByValue := groupByDimension(previous, dimension)
+
+	var contributors []models.Contributor
+	for value := range union(currentByValue, previousByValue) {
+		currentCost := sumCosts(currentByValue[value])
+		previousCost := sumCosts(previousByValue[value])
+		contributors = append(contributors, models.Contributor{
+			Dimension:    dimension,
+			Value:        value,
+			CurrentCost:  currentCost,
+			PreviousCost: previousCost,
+			Delta:        currentCost - previousCost,
+		})
+	}
+
+	sort.Slice(contributors, func(i, j int) bool {
+		if math.Abs(contributors[i].Delta) != math.Abs(contributors[j].Delta) {
+			return math.Abs(contributors[i].Delta) > math.Abs(contributors[j].Delta)
+		}
+		return contributors[i].Value < contributors[j].Value
+	})
+	if topK > 0 && len(contributors) > topK {
+		contributors = contributors[:topK]
+	}
+
+	for i := range contributors {
+		value := contributors[i].Value
+		contributors[i].Children = attribute(currentByValue[value], previousByValue[value], dimensions[1:], topK)
+	}
+	return contributors
+}
+
+// groupByDimension groups cost records by their value in a dimension
+func groupByDimension(costs []models.CostData, dimension string) map[string][]models.CostData {
+	groups := make(map[string][]models.CostData)
+	for _, cost := range costs {
+		value, err := utils.PivotValue(cost, dimension)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			return groups
+		}
+		groups[value] = append(groups[value], cost)
+	}
+	return groups
+}
+
+func union(a, b map[string][]models.CostData) map[string]bool {
+	values := make(map[string]bool)
+	for value := range a {
+		values[value] = true
+	}
+	for value := range b {
+		values[value] = true
+	}
+	return values
+}
+
+func sumCosts(costs []models.CostData) float64 {
+	total := 0.0
+	for _, cost := range costs {
+		total += cost.Cost
+	}
+	return total
+}
+
+// latestDate returns the most recent date in the cost records
+func latestDate(costs []models.CostData) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	for _, cost := range costs {
+		date, err := time.Parse("2006-01-02", cost.Date)
+		if err != nil {
+			continue
+		}
+		if !found || date.After(latest) {
+			latest, found = date, true
+		}
+	}
+	return latest, found
+}
@@ -0,0 +1,92 @@
+package triggers
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+// flatten returns each contributor as its dimension path and delta, depth first
+func flatten(contributors []models.Contributor, prefix string) map[string]float64 {
+	paths := make(map[string]float64)
+	for _, contributor := range contributors {
+		path := prefix + contributor.Dimension + "=" + contributor.Value
+		paths[path] = contributor.Delta
+		for child, delta := range flatten(contributor.Children, path+"/") {
+			paths[child] = delta
+		}
+	}
+	return paths
+}
+
+func TestTriggerDailyRootCauseDrillsThroughDimensions(t *testing.T) {
+	triggers := NewMTDTriggers()
+	triggers.SetAttribution([]string{"service", "project", "sku"}, 2)
+	contributors := triggers.TriggerDailyRootCause(models.Anomaly{Date: "2024-05-15"}, spikeCosts("2024-05-14", "2024-05-15"))
+
+	want := map[string]float64{
+		"service=Compute":                           850,
+		"service=Compute/project=prod":              850,
+		"service=Compute/project=prod/sku=N2 Core":  850,
+		"service=Pub/Sub":                           40,
+		"service=Pub/Sub/project=prod":              40,
+		"service=Pub/Sub/project=prod/sku=Messages": 40,
+	}
+	got := flatten(contributors, "")
+	if len(got) != len(want) {
+		t.Errorf("contributors = %v, want %v", got, want)
+	}
+	for path, delta := range want {
+		if got[path] != delta {
+			t.Errorf("%s delta = %v, want %v", path, got[path], delta)
+		}
+	}
+	if top := contributors[0]; top.CurrentCost != 950 || top.PreviousCost != 100 {
+		t.Errorf("top contributor = %+v, want Compute from 100 to 950", top)
+	}
+}
+
+func TestAttributionRanksByAbsoluteDelta(t *testing.T) {
+	triggers := NewMTDTriggers()
+	triggers.SetAttribution([]string{"service"}, 4)
+	contributors := triggers.TriggerDailyRootCause(models.Anomaly{Date: "2024-05-15"}, spikeCosts("2024-05-14", "2024-05-15"))
+
+	// Networking fell by 20, which outranks BigQuery's rise of 10
+	want := []string{"Compute", "Pub/Sub", "Storage", "Networking"}
+	if len(contributors) != len(want) {
+		t.Fatalf("got %d contributors, want the top %d", len(contributors), len(want))
+	}
+	for i, contributor := range contributors {
+		if contributor.Value != want[i] {
+			t.Errorf("contributor %d = %s, want %s", i, contributor.Value, want[i])
+		}
+		if len(contributor.Children) != 0 {
+			t.Errorf("%s has children %+v at a depth of one", contributor.Value, contributor.Children)
+		}
+	}
+}
+
+func TestTriggerMTDRootCauseComparesMonthToDate(t *testing.T) {
+	triggers := NewMTDTriggers()
+	triggers.SetAttribution([]string{"project", "service"}, 0)
+	costs := append(spikeCosts("2024-04-15", "2024-05-15"), models.CostData{Date: "2024-04-20", Service: "Compute", ProjectID: "prod", Cost: 5000})
+	contributors := triggers.TriggerMTDRootCause(models.Anomaly{Date: "2024-05-15", Service: "monthly_total"}, costs)
+
+	got := flatten(contributors, "")
+	want := map[string]float64{
+		"project=prod":                       900,
+		"project=prod/service=Compute":       850,
+		"project=prod/service=Pub/Sub":       40,
+		"project=prod/service=Storage":       30,
+		"project=prod/service=Networking":    -20,
+		"project=analytics":                  10,
+		"project=analytics/service=BigQuery": 10,
+	}
+	if len(got) != len(want) {
+		t.Errorf("contributors = %v, want %v", got, want)
+	}
+	for path, delta := range want {
+		if got[path] != delta {
+			t.Errorf("%s delta = %v, want %v", path, got[path], delta)
+		}
+	}
+}
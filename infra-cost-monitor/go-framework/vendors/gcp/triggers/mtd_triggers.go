@@ -3,21 +3,50 @@ package triggers
 import (
 	"infra-cost-monitor/go-framework/vendors/gcp/models"
 	"log"
+	"sort"
 	"time"
 )
 
 // MTDTriggers handles month-to-date alert triggers
-type MTDTriggers struct{}
+type MTDTriggers struct {
+	dimensions     []string
+	topK           int
+	minDaysElapsed int
+	dropPercentage float64
+}
 
 // NewMTDTriggers creates a new MTD triggers instance
 func NewMTDTriggers() *MTDTriggers {
-	return &MTDTriggers{}
+	return &MTDTriggers{
+		dimensions:     []string{"service"},
+		topK:           5,
+		dropPercentage: 50,
+	}
+}
+
+// SetAttribution sets the dimensions root-cause analysis drills through, in
+// order (e.g. service, project, sku), and the number of contributors kept per level
+func (mt *MTDTriggers) SetAttribution(dimensions []string, topK int) {
+	mt.dimensions = dimensions
+	mt.topK = topK
+}
+
+// SetMinDaysElapsed suppresses the monthly spike alert until the current month has this many days of data
+func (mt *MTDTriggers) SetMinDaysElapsed(days int) {
+	mt.minDaysElapsed = days
+}
+
+// SetDropPercentage sets how far, in percent, the latest daily cost or monthly run
+// rate must fall below the previous one to trigger a drop alert
+func (mt *MTDTriggers) SetDropPercentage(percentage float64) {
+	mt.dropPercentage = percentage
 }
 
 // CheckTriggers checks for alert conditions and returns triggered alerts
 func (mt *MTDTriggers) CheckTriggers(dailyCosts []models.DailyCost, mtdCosts []models.MTDCost) []models.Alert {
 	log.Println("🔔 Checking MTD triggers...")
 	
+	models.SortDailyCostsDesc(dailyCosts)
 	var alerts []models.Alert
 	
 	// Check for daily cost spikes
@@ -32,32 +61,57 @@ func (mt *MTDTriggers) CheckTriggers(dailyCosts []models.DailyCost, mtdCosts []m
 			// Trigger alert if increase > 50% or > $1000
 			if percentage > 50 || increase > 1000 {
 				alert := models.Alert{
-					Type:    "cost_spike",
-					Message: "Daily cost spike detected",
-					Time:    time.Now().Format("2006-01-02 15:04:05"),
+					Type:       "cost_spike",
+					Severity:   models.SeverityHigh,
+					Message:    "Daily cost spike detected",
+					CostImpact: increase,
+					Time:       time.Now().Format("2006-01-02 15:04:05"),
 				}
 				alerts = append(alerts, alert)
 			}
+			
+			// Trigger alert if cost fell by more than the drop percentage
+			if current < previous*(1-mt.dropPercentage/100) {
+				alerts = append(alerts, models.Alert{
+					Type:       "cost_drop",
+					Severity:   models.SeverityHigh,
+					Message:    "Daily cost drop detected",
+					CostImpact: increase,
+					Time:       time.Now().Format("2006-01-02 15:04:05"),
+				})
+			}
 		}
 	}
 	
-	// Check for monthly cost spikes
-	if len(mtdCosts) >= 2 {
+	// Check for monthly cost spikes once enough of the month has elapsed to compare
+	if len(mtdCosts) >= 2 && mtdCosts[0].Days >= mt.minDaysElapsed {
 		current := mtdCosts[0].Cost
 		previous := mtdCosts[1].Cost
 		
-		if previous > 0 {
-			increase := current - previous
-			percentage := (increase / previous) * 100
-			
-			// Trigger alert if increase > 30% or > $5000
-			if percentage > 30 || increase > 5000 {
-				alert := models.Alert{
-					Type:    "monthly_spike",
-					Message: "Monthly cost spike detected",
-					Time:    time.Now().Format("2006-01-02 15:04:05"),
-				}
-				alerts = append(alerts, alert)
+		// Trigger alert if the increase over the prorated previous month is > 30% or > $5000
+		if comparison := models.CompareMonths(mtdCosts[0], mtdCosts[1], 5000, 30); comparison.Spike {
+			alert := models.Alert{
+				Type:       "monthly_spike",
+				Severity:   models.SeverityHigh,
+				Message:    "Monthly cost spike detected",
+				CostImpact: comparison.NormalizedIncrease,
+				Time:       time.Now().Format("2006-01-02 15:04:05"),
+			}
+			alerts = append(alerts, alert)
+		}
+		
+		// Compare daily run rates so a partial month is not mistaken for a drop
+		if mtdCosts[0].Days > 0 && mtdCosts[1].Days > 0 {
+			currentRate := current / float64(mtdCosts[0].Days)
+			previousRate := previous / float64(mtdCosts[1].Days)
+			if previousRate > 0 && currentRate < previousRate*(1-mt.dropPercentage/100) {
+				alerts = append(alerts, models.Alert{
+					Type:       "monthly_drop",
+					Severity:   models.SeverityHigh,
+					Message:    "Monthly cost drop detected",
+					CostImpact: (currentRate - previousRate) * float64(mtdCosts[0].Days),
+					Time:       time.Now().Format("2006-01-02 15:04:05"),
+				})
 			}
 		}
 	}
@@ -66,8 +120,134 @@ func (mt *MTDTriggers) CheckTriggers(dailyCosts []models.DailyCost, mtdCosts []m
 	return alerts
 }
 
-// TriggerMTDRootCause triggers root cause analysis for MTD anomalies
-func (mt *MTDTriggers) TriggerMTDRootCause(anomaly models.Anomaly) {
+// TriggerMTDRootCause attributes an MTD anomaly by comparing month-to-date
+// costs with the same days of the previous month, returning a tree of contributors
+func (mt *MTDTriggers) TriggerMTDRootCause(anomaly models.Anomaly, costs []models.CostData) []models.Contributor {
+	log.Printf("🔍 Triggering root cause analysis for anomaly: %s", anomaly.Description)
+
+	current, previous, _, _, ok := mtdPeriods(anomaly, costs)
+	if !ok {
+		return nil
+	}
+	return attribute(current, previous, mt.dimensions, mt.topK)
+}
+
+// TriggerDailyRootCause attributes a daily anomaly by comparing the anomaly's
+// date with the previous day, returning a tree of contributors
+func (mt *MTDTriggers) TriggerDailyRootCause(anomaly models.Anomaly, costs []models.CostData) []models.Contributor {
 	log.Printf("🔍 Triggering root cause analysis for anomaly: %s", anomaly.Description)
-	// Implementation for root cause analysis would go here
-} 
\ No newline at end of file
+
+	current, previous, _, _, ok := dailyPeriods(anomaly, costs)
+	if !ok {
+		return nil
+	}
+	return attribute(current, previous, mt.dimensions, mt.topK)
+}
+
+// RootCause ranks the (service, SKU, project, region) combinations by their cost
+// increase from the prior period to the anomaly's, keeping the top contributors.
+// Monthly anomalies compare month to date with the same days of the previous
+// month; all others compare the anomaly's date with the day before.
+func (mt *MTDTriggers) RootCause(anomaly models.Anomaly, costs []models.CostData) *models.RootCause {
+	rootCause := &models.RootCause{Period: models.RootCauseDaily}
+	var current, previous []models.CostData
+	var ok bool
+	if anomaly.Service == "monthly_total" {
+		rootCause.Period = models.RootCauseMTD
+		current, previous, rootCause.CurrentPeriod, rootCause.PreviousPeriod, ok = mtdPeriods(anomaly, costs)
+	} else {
+		current, previous, rootCause.CurrentPeriod, rootCause.PreviousPeriod, ok = dailyPeriods(anomaly, costs)
+	}
+	if !ok {
+		return nil
+	}
+
+	type combination struct {
+		service, sku, projectID, region string
+	}
+	totals := make(map[combination]*models.RootCauseContributor)
+	add := func(cost models.CostData) *models.RootCauseContributor {
+		key := combination{cost.Service, cost.SKU, cost.ProjectID, cost.Region}
+		contributor, exists := totals[key]
+		if !exists {
+			contributor = &models.RootCauseContributor{
+				Service:   cost.Service,
+				SKU:       cost.SKU,
+				ProjectID: cost.ProjectID,
+				Region:    cost.Region,
+			}
+			totals[key] = contributor
+		}
+		return contributor
+	}
+	for _, cost := range current {
+		add(cost).CurrentCost += cost.Cost
+	}
+	for _, cost := range previous {
+		add(cost).PreviousCost += cost.Cost
+	}
+
+	for _, contributor := range totals {
+		contributor.Increase = contributor.CurrentCost - contributor.PreviousCost
+		rootCause.Contributors = append(rootCause.Contributors, *contributor)
+	}
+	sort.Slice(rootCause.Contributors, func(i, j int) bool {
+		a, b := rootCause.Contributors[i], rootCause.Contributors[j]
+		if a.Increase != b.Increase {
+			return a.Increase > b.Increase
+		}
+		return a.Service+a.SKU+a.ProjectID+a.Region < b.Service+b.SKU+b.ProjectID+b.Region
+	})
+	if mt.topK > 0 && len(rootCause.Contributors) > mt.topK {
+		rootCause.Contributors = rootCause.Contributors[:mt.topK]
+	}
+	return rootCause
+}
+
+// dailyPeriods splits out the costs of the anomaly's date and of the day before
+func dailyPeriods(anomaly models.Anomaly, costs []models.CostData) ([]models.CostData, []models.CostData, string, string, bool) {
+	date, err := time.Parse("2006-01-02", anomaly.Date)
+	if err != nil {
+		return nil, nil, "", "", false
+	}
+	previousDate := date.AddDate(0, 0, -1).Format("2006-01-02")
+
+	var current, previous []models.CostData
+	for _, cost := range costs {
+		switch cost.Date {
+		case anomaly.Date:
+			current = append(current, cost)
+		case previousDate:
+			previous = append(previous, cost)
+		}
+	}
+	return current, previous, anomaly.Date, previousDate, true
+}
+
+// mtdPeriods splits out the month-to-date costs of the anomaly's month, up to the
+// anomaly's day (or the latest day when it has no date), and the same days of the previous month
+func mtdPeriods(anomaly models.Anomaly, costs []models.CostData) ([]models.CostData, []models.CostData, string, string, bool) {
+	latest, err := time.Parse("2006-01-02", anomaly.Date)
+	if err != nil {
+		var ok bool
+		if latest, ok = latestDate(costs); !ok {
+			return nil, nil, "", "", false
+		}
+	}
+	monthStart := time.Date(latest.Year(), latest.Month(), 1, 0, 0, 0, 0, time.UTC)
+	previousStart := monthStart.AddDate(0, -1, 0)
+
+	var current, previous []models.CostData
+	for _, cost := range costs {
+		date, err := time.Parse("2006-01-02", cost.Date)
+		if err != nil || date.Day() > latest.Day() || date.After(latest) {
+			continue
+		}
+		if !date.Before(monthStart) {
+			current = append(current, cost)
+		} else if !date.Before(previousStart) {
+			previous = append(previous, cost)
+		}
+	}
+	return current, previous, monthStart.Format("2006-01"), previousStart.Format("2006-01"), true
+}
@@ -0,0 +1,193 @@
+package triggers
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+func TestCheckTriggersMonthlySpikeOnDayThree(t *testing.T) {
+	previous := models.MTDCost{Month: "2024-04", Cost: 3000, Days: 30}
+	tests := []struct {
+		name    string
+		current models.MTDCost
+		spike   bool
+	}{
+		{"above the prorated previous month", models.MTDCost{Month: "2024-05", Cost: 400, Days: 3}, true},
+		{"at the prorated previous month", models.MTDCost{Month: "2024-05", Cost: 300, Days: 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := NewMTDTriggers().CheckTriggers(nil, []models.MTDCost{tt.current, previous})
+			spike := false
+			for _, alert := range alerts {
+				if alert.Type == "monthly_spike" {
+					spike = true
+					if alert.CostImpact != tt.current.Cost-300 {
+						t.Errorf("cost impact = %v, want %v", alert.CostImpact, tt.current.Cost-300)
+					}
+				}
+			}
+			if spike != tt.spike {
+				t.Errorf("monthly spike = %v, want %v", spike, tt.spike)
+			}
+		})
+	}
+}
+
+func TestCheckTriggersSuppressesMonthlySpikeEarlyInTheMonth(t *testing.T) {
+	previous := models.MTDCost{Month: "2024-04", Cost: 3000, Days: 30}
+	daily := []models.DailyCost{{Date: "2024-05-02", TotalCost: 100}, {Date: "2024-05-03", TotalCost: 1000}}
+	tests := []struct {
+		name    string
+		minDays int
+		days    int
+		spike   bool
+	}{
+		{"before the minimum", 5, 4, false},
+		{"at the minimum", 5, 5, true},
+		{"no minimum", 0, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mt := NewMTDTriggers()
+			mt.SetMinDaysElapsed(tt.minDays)
+			current := models.MTDCost{Month: "2024-05", Cost: 1000 * float64(tt.days), Days: tt.days}
+
+			monthly, daySpike := false, false
+			for _, alert := range mt.CheckTriggers(daily, []models.MTDCost{current, previous}) {
+				switch alert.Type {
+				case "monthly_spike":
+					monthly = true
+				case "cost_spike":
+					daySpike = true
+				}
+			}
+			if monthly != tt.spike {
+				t.Errorf("monthly spike = %v, want %v", monthly, tt.spike)
+			}
+			if !daySpike {
+				t.Error("daily spike alert suppressed along with the monthly one")
+			}
+		})
+	}
+}
+
+func TestCheckTriggersDailyDrop(t *testing.T) {
+	tests := []struct {
+		name           string
+		dropPercentage float64
+		current        float64
+		drop           bool
+	}{
+		{"70% drop", 50, 300, true},
+		{"30% drop", 50, 700, false},
+		{"70% drop below a stricter drop threshold", 80, 300, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mt := NewMTDTriggers()
+			mt.SetDropPercentage(tt.dropPercentage)
+			daily := []models.DailyCost{{Date: "2024-03-01", TotalCost: 1000}, {Date: "2024-03-02", TotalCost: tt.current}}
+
+			drop, spike := false, false
+			for _, alert := range mt.CheckTriggers(daily, nil) {
+				switch alert.Type {
+				case "cost_drop":
+					drop = true
+					if alert.CostImpact != tt.current-1000 {
+						t.Errorf("cost impact = %v, want %v", alert.CostImpact, tt.current-1000)
+					}
+				case "cost_spike":
+					spike = true
+				}
+			}
+			if drop != tt.drop {
+				t.Errorf("daily drop = %v, want %v", drop, tt.drop)
+			}
+			if spike {
+				t.Error("a drop raised a spike alert")
+			}
+		})
+	}
+}
+
+// spikeCosts returns two days of costs in which Compute in prod drives most of
+// the second day's increase
+func spikeCosts(first, second string) []models.CostData {
+	row := func(date, service, sku, project, region string, cost float64) models.CostData {
+		return models.CostData{Date: date, Service: service, SKU: sku, ProjectID: project, Region: region, Cost: cost}
+	}
+	return []models.CostData{
+		row(first, "Compute", "N2 Core", "prod", "us-central1", 100),
+		row(first, "Storage", "Standard", "prod", "us-central1", 50),
+		row(first, "BigQuery", "Analysis", "analytics", "us", 200),
+		row(first, "Networking", "Egress", "prod", "us-central1", 30),
+		row(second, "Compute", "N2 Core", "prod", "us-central1", 500),
+		row(second, "Compute", "N2 Core", "prod", "us-central1", 450),
+		row(second, "Storage", "Standard", "prod", "us-central1", 80),
+		row(second, "BigQuery", "Analysis", "analytics", "us", 210),
+		row(second, "Networking", "Egress", "prod", "us-central1", 10),
+		row(second, "Pub/Sub", "Messages", "prod", "us-central1", 40),
+	}
+}
+
+func TestRootCauseRanksTheDominantServiceFirst(t *testing.T) {
+	anomaly := models.Anomaly{Date: "2024-05-15", Service: "daily_total"}
+	rootCause := NewMTDTriggers().RootCause(anomaly, spikeCosts("2024-05-14", "2024-05-15"))
+	if rootCause == nil {
+		t.Fatal("RootCause = nil, want a ranking")
+	}
+	if rootCause.Period != models.RootCauseDaily || rootCause.CurrentPeriod != "2024-05-15" || rootCause.PreviousPeriod != "2024-05-14" {
+		t.Errorf("periods = %s %s vs %s, want daily 2024-05-15 vs 2024-05-14", rootCause.Period, rootCause.CurrentPeriod, rootCause.PreviousPeriod)
+	}
+
+	want := []struct {
+		service  string
+		increase float64
+	}{{"Compute", 850}, {"Pub/Sub", 40}, {"Storage", 30}, {"BigQuery", 10}, {"Networking", -20}}
+	if len(rootCause.Contributors) != len(want) {
+		t.Fatalf("got %d contributors, want %d", len(rootCause.Contributors), len(want))
+	}
+	for i, w := range want {
+		contributor := rootCause.Contributors[i]
+		if contributor.Service != w.service || contributor.Increase != w.increase {
+			t.Errorf("contributor %d = %s up %v, want %s up %v", i, contributor.Service, contributor.Increase, w.service, w.increase)
+		}
+	}
+	if top := rootCause.Contributors[0]; top.SKU != "N2 Core" || top.ProjectID != "prod" || top.Region != "us-central1" || top.CurrentCost != 950 || top.PreviousCost != 100 {
+		t.Errorf("top contributor = %+v, want N2 Core in prod us-central1 from 100 to 950", top)
+	}
+}
+
+func TestRootCauseKeepsTopContributors(t *testing.T) {
+	triggers := NewMTDTriggers()
+	triggers.SetAttribution([]string{"service"}, 2)
+	rootCause := triggers.RootCause(models.Anomaly{Date: "2024-05-15"}, spikeCosts("2024-05-14", "2024-05-15"))
+	if len(rootCause.Contributors) != 2 || rootCause.Contributors[0].Service != "Compute" {
+		t.Errorf("contributors = %+v, want the top 2 led by Compute", rootCause.Contributors)
+	}
+}
+
+func TestRootCauseComparesMonthToDate(t *testing.T) {
+	// The same day of the previous month is compared; days after it are not
+	costs := append(spikeCosts("2024-04-15", "2024-05-15"), models.CostData{Date: "2024-04-20", Service: "Compute", SKU: "N2 Core", ProjectID: "prod", Region: "us-central1", Cost: 5000})
+	rootCause := NewMTDTriggers().RootCause(models.Anomaly{Date: "2024-05-15", Service: "monthly_total"}, costs)
+	if rootCause == nil {
+		t.Fatal("RootCause = nil, want a ranking")
+	}
+	if rootCause.Period != models.RootCauseMTD || rootCause.CurrentPeriod != "2024-05" || rootCause.PreviousPeriod != "2024-04" {
+		t.Errorf("periods = %s %s vs %s, want month_to_date 2024-05 vs 2024-04", rootCause.Period, rootCause.CurrentPeriod, rootCause.PreviousPeriod)
+	}
+	if top := rootCause.Contributors[0]; top.Service != "Compute" || top.Increase != 850 {
+		t.Errorf("top contributor = %s up %v, want Compute up 850", top.Service, top.Increase)
+	}
+}
+
+func TestRootCauseWithoutADate(t *testing.T) {
+	if rootCause := NewMTDTriggers().RootCause(models.Anomaly{Date: "latest"}, spikeCosts("2024-05-14", "2024-05-15")); rootCause != nil {
+		t.Errorf("RootCause = %+v for an unparseable date, want nil", rootCause)
+	}
+}
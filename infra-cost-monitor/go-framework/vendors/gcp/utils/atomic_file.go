@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile is written under a temporary name in its target's directory and
+// renamed into place on Commit, so readers only ever see a complete file.
+// Abort, or a crash before Commit, leaves any previous file untouched.
+type AtomicFile struct {
+	*os.File
+	target string
+	done   bool
+}
+
+// CreateAtomic starts an atomic write of filename, creating its directory if needed
+func CreateAtomic(filename string) (*AtomicFile, error) {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory %s: %v", dir, err)
+	}
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	return &AtomicFile{File: file, target: filename}, nil
+}
+
+// Commit flushes the temporary file to disk and renames it over the target
+func (af *AtomicFile) Commit() error {
+	if af.done {
+		return nil
+	}
+	af.done = true
+
+	if err := af.Sync(); err != nil {
+		af.discard()
+		return err
+	}
+	if err := af.Chmod(0644); err != nil {
+		af.discard()
+		return err
+	}
+	if err := af.Close(); err != nil {
+		os.Remove(af.Name())
+		return err
+	}
+	if err := os.Rename(af.Name(), af.target); err != nil {
+		os.Remove(af.Name())
+		return err
+	}
+	return nil
+}
+
+// Abort discards the temporary file; it does nothing after Commit, so it can be deferred
+func (af *AtomicFile) Abort() {
+	if af.done {
+		return
+	}
+	af.done = true
+	af.discard()
+}
+
+// discard closes and removes the temporary file
+func (af *AtomicFile) discard() {
+	af.Close()
+	os.Remove(af.Name())
+}
+
+// WriteFileAtomic writes data to filename through a temporary file and a rename
+func WriteFileAtomic(filename string, data []byte) error {
+	file, err := CreateAtomic(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	return file.Commit()
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestWriteFileAtomicReadersSeeOnlyCompleteFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "report.json")
+	payloads := [][]byte{
+		bytes.Repeat([]byte("a"), 1<<20),
+		bytes.Repeat([]byte("b"), 3<<20),
+	}
+	if err := WriteFileAtomic(path, payloads[0]); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			if err := WriteFileAtomic(path, payloads[i%2]); err != nil {
+				t.Errorf("WriteFileAtomic: %v", err)
+				return
+			}
+		}
+	}()
+
+	reads := 0
+	for {
+		select {
+		case <-done:
+			wg.Wait()
+			if reads == 0 {
+				t.Fatal("reader never ran")
+			}
+			entries, err := os.ReadDir(filepath.Dir(path))
+			if err != nil {
+				t.Fatalf("ReadDir: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("got %d files in the output directory, want only the target", len(entries))
+			}
+			return
+		default:
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if !bytes.Equal(data, payloads[0]) && !bytes.Equal(data, payloads[1]) {
+			t.Fatalf("reader saw a partial file of %d bytes", len(data))
+		}
+		reads++
+	}
+}
+
+func TestAtomicFileAbortKeepsPreviousFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	if err := WriteFileAtomic(path, []byte("previous")); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+
+	file, err := CreateAtomic(path)
+	if err != nil {
+		t.Fatalf("CreateAtomic: %v", err)
+	}
+	if _, err := file.Write([]byte("partial")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	file.Abort()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "previous" {
+		t.Errorf("got %q after Abort, want the previous contents", data)
+	}
+	if _, err := os.Stat(file.Name()); !os.IsNotExist(err) {
+		t.Errorf("temporary file %s was left behind", file.Name())
+	}
+}
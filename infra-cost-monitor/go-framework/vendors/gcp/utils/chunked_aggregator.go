@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// spilledAggregate is one partial per-key, per-day aggregate written to a spill file
+type spilledAggregate struct {
+	Key  string  `json:"key"`
+	Date string  `json:"date"`
+	Cost float64 `json:"cost"`
+}
+
+// ChunkedAggregator aggregates cost rows as they stream in so the full
+// []models.CostData never has to be held in memory. Per-key daily aggregates
+// are buffered up to batchSize entries; when a spill directory is set, full
+// batches are written to hash-partitioned files and each partition is later
+// re-aggregated on its own, so peak memory is one batch plus one partition.
+type ChunkedAggregator struct {
+	batchSize  int
+	spillDir   string
+	partitions int
+
+	dailyTotals map[string]float64
+	buffer      map[string]map[string]float64
+	buffered    int
+	spilled     bool
+	rows        int
+}
+
+// NewChunkedAggregator creates an aggregator; an empty spillDir keeps all aggregates in memory
+func NewChunkedAggregator(batchSize int, spillDir string, partitions int) *ChunkedAggregator {
+	if batchSize < 1 {
+		batchSize = 100000
+	}
+	if partitions < 1 {
+		partitions = 16
+	}
+
+	return &ChunkedAggregator{
+		batchSize:   batchSize,
+		spillDir:    spillDir,
+		partitions:  partitions,
+		dailyTotals: make(map[string]float64),
+		buffer:      make(map[string]map[string]float64),
+	}
+}
+
+// Add folds a single cost row into the aggregates
+func (ca *ChunkedAggregator) Add(cost models.CostData) error {
+	ca.rows++
+	ca.dailyTotals[cost.Date] += cost.Cost
+
+	key := compositeKey(cost)
+	if ca.buffer[key] == nil {
+		ca.buffer[key] = make(map[string]float64)
+	}
+	if _, exists := ca.buffer[key][cost.Date]; !exists {
+		ca.buffered++
+	}
+	ca.buffer[key][cost.Date] += cost.Cost
+
+	if ca.spillDir != "" && ca.buffered >= ca.batchSize {
+		return ca.spill()
+	}
+	return nil
+}
+
+// Rows returns the number of rows added
+func (ca *ChunkedAggregator) Rows() int {
+	return ca.rows
+}
+
+// DailyTotals returns the total cost per day, newest first
+func (ca *ChunkedAggregator) DailyTotals() []models.DailyCost {
+	totals := make([]models.DailyCost, 0, len(ca.dailyTotals))
+	for date, cost := range ca.dailyTotals {
+		totals = append(totals, models.DailyCost{Date: date, TotalCost: cost})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].Date > totals[j].Date
+	})
+	return totals
+}
+
+// EachKey calls fn with the per-day costs of every composite key, one spill
+// partition at a time. Spill files are removed once they have been processed.
+func (ca *ChunkedAggregator) EachKey(fn func(key string, daily map[string]float64) error) error {
+	if !ca.spilled {
+		return eachKey(ca.buffer, fn)
+	}
+
+	// Flush the remainder so every key is read back from exactly one partition
+	if err := ca.spill(); err != nil {
+		return err
+	}
+	for partition := 0; partition < ca.partitions; partition++ {
+		path := ca.partitionPath(partition)
+		aggregates, err := readPartition(path)
+		if err != nil {
+			return err
+		}
+		if err := eachKey(aggregates, fn); err != nil {
+			return err
+		}
+		os.Remove(path)
+	}
+	return nil
+}
+
+// spill appends the buffered aggregates to their partition files and clears the buffer
+func (ca *ChunkedAggregator) spill() error {
+	if err := os.MkdirAll(ca.spillDir, 0755); err != nil {
+		return fmt.Errorf("failed to create spill directory: %v", err)
+	}
+
+	files := make([]*os.File, ca.partitions)
+	writers := make([]*bufio.Writer, ca.partitions)
+	defer func() {
+		for _, file := range files {
+			if file != nil {
+				file.Close()
+			}
+		}
+	}()
+
+	for key, daily := range ca.buffer {
+		partition := ca.partitionOf(key)
+		if files[partition] == nil {
+			file, err := os.OpenFile(ca.partitionPath(partition), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to open spill file: %v", err)
+			}
+			files[partition] = file
+			writers[partition] = bufio.NewWriter(file)
+		}
+
+		encoder := json.NewEncoder(writers[partition])
+		for date, cost := range daily {
+			if err := encoder.Encode(spilledAggregate{Key: key, Date: date, Cost: cost}); err != nil {
+				return fmt.Errorf("failed to write spill file: %v", err)
+			}
+		}
+	}
+
+	for _, writer := range writers {
+		if writer != nil {
+			if err := writer.Flush(); err != nil {
+				return fmt.Errorf("failed to write spill file: %v", err)
+			}
+		}
+	}
+
+	if !ca.spilled {
+		log.Printf("💽 Spilling composite aggregates to %s", ca.spillDir)
+	}
+	ca.spilled = true
+	ca.buffer = make(map[string]map[string]float64)
+	ca.buffered = 0
+	return nil
+}
+
+func (ca *ChunkedAggregator) partitionOf(key string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(ca.partitions))
+}
+
+func (ca *ChunkedAggregator) partitionPath(partition int) string {
+	return filepath.Join(ca.spillDir, fmt.Sprintf("composite_%03d.jsonl", partition))
+}
+
+// readPartition re-aggregates the partial aggregates in one spill file
+func readPartition(path string) (map[string]map[string]float64, error) {
+	aggregates := make(map[string]map[string]float64)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return aggregates, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill file: %v", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var aggregate spilledAggregate
+		if err := decoder.Decode(&aggregate); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read spill file: %v", err)
+		}
+		if aggregates[aggregate.Key] == nil {
+			aggregates[aggregate.Key] = make(map[string]float64)
+		}
+		aggregates[aggregate.Key][aggregate.Date] += aggregate.Cost
+	}
+	return aggregates, nil
+}
+
+// eachKey calls fn for each key in sorted order
+func eachKey(aggregates map[string]map[string]float64, fn func(key string, daily map[string]float64) error) error {
+	keys := make([]string, 0, len(aggregates))
+	for key := range aggregates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := fn(key, aggregates[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compositeKey identifies a service/SKU/project/region combination
+func compositeKey(cost models.CostData) string {
+	return cost.Service + "|" + cost.SKU + "|" + cost.ProjectID + "|" + cost.Region
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"runtime"
+	"testing"
+)
+
+// syntheticRows calls fn for n rows spread over keys composite keys and 30 days,
+// generating them on the fly so the source itself holds no memory
+func syntheticRows(n, keys int, fn func(models.CostData) error) error {
+	for i := 0; i < n; i++ {
+		key := i % keys
+		err := fn(models.CostData{
+			Date:      fmt.Sprintf("2024-03-%02d", i/keys%30+1),
+			Service:   fmt.Sprintf("service-%d", key%10),
+			SKU:       fmt.Sprintf("sku-%d", key),
+			ProjectID: "proj-1",
+			Region:    "us-east1",
+			Cost:      1,
+			UsageUnit: "hour",
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectKeys returns the per-day costs of every key the aggregator produces
+func collectKeys(t testing.TB, aggregator *ChunkedAggregator) map[string]map[string]float64 {
+	t.Helper()
+	keys := make(map[string]map[string]float64)
+	err := aggregator.EachKey(func(key string, daily map[string]float64) error {
+		if keys[key] != nil {
+			t.Errorf("key %s returned twice", key)
+		}
+		keys[key] = daily
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachKey: %v", err)
+	}
+	return keys
+}
+
+func TestChunkedAggregatorSpillMatchesInMemory(t *testing.T) {
+	inMemory := NewChunkedAggregator(100, "", 4)
+	spilling := NewChunkedAggregator(100, t.TempDir(), 4)
+	for _, aggregator := range []*ChunkedAggregator{inMemory, spilling} {
+		if err := syntheticRows(20000, 50, aggregator.Add); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	if !spilling.spilled {
+		t.Fatal("aggregator with a spill directory never spilled")
+	}
+
+	want, got := collectKeys(t, inMemory), collectKeys(t, spilling)
+	if len(got) != 50 || len(want) != 50 {
+		t.Fatalf("got %d and %d keys, want 50", len(got), len(want))
+	}
+	for key, daily := range want {
+		for date, cost := range daily {
+			if got[key][date] != cost {
+				t.Errorf("%s on %s = %v, want %v", key, date, got[key][date], cost)
+			}
+		}
+	}
+
+	totals := spilling.DailyTotals()
+	if len(totals) != 30 || totals[0].Date != "2024-03-30" {
+		t.Errorf("got %d daily totals starting %v, want 30 newest first", len(totals), totals)
+	}
+	if spilling.Rows() != 20000 {
+		t.Errorf("Rows() = %d, want 20000", spilling.Rows())
+	}
+}
+
+// peakHeap samples the live heap every 10000 calls to sample, remembering the peak
+type peakHeap struct {
+	calls int
+	peak  uint64
+}
+
+func (p *peakHeap) sample() {
+	p.calls++
+	if p.calls%10000 != 0 {
+		return
+	}
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if stats.HeapAlloc > p.peak {
+		p.peak = stats.HeapAlloc
+	}
+}
+
+func (p *peakHeap) report(b *testing.B) {
+	b.ReportMetric(float64(p.peak)/(1<<20), "peak-heap-MB")
+}
+
+const benchmarkRows, benchmarkKeys = 500000, 5000
+
+// BenchmarkAggregateInMemory is the all-in-memory path: every row is held before
+// ProcessCompositeData aggregates them
+func BenchmarkAggregateInMemory(b *testing.B) {
+	dp := NewDataProcessor()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		var peak peakHeap
+		var rows []models.CostData
+		syntheticRows(benchmarkRows, benchmarkKeys, func(cost models.CostData) error {
+			rows = append(rows, cost)
+			peak.sample()
+			return nil
+		})
+		composite := dp.ProcessCompositeData(nil, nil, rows)
+		peak.sample()
+		peak.report(b)
+		runtime.KeepAlive(composite)
+	}
+}
+
+// BenchmarkAggregateChunked streams the same rows through a spilling aggregator
+func BenchmarkAggregateChunked(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		var peak peakHeap
+		aggregator := NewChunkedAggregator(10000, b.TempDir(), 16)
+		syntheticRows(benchmarkRows, benchmarkKeys, func(cost models.CostData) error {
+			peak.sample()
+			return aggregator.Add(cost)
+		})
+		aggregator.EachKey(func(string, map[string]float64) error {
+			peak.sample()
+			return nil
+		})
+		peak.report(b)
+	}
+}
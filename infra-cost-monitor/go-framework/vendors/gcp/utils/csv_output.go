@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"sort"
+	"strconv"
+)
+
+// Pivot dimensions supported by SaveWideCSV
+const (
+	PivotService  = "service"
+	PivotProject  = "project"
+	PivotSKU      = "sku"
+	PivotRegion   = "region"
+	PivotCostType = "cost_type"
+)
+
+// otherColumn collects the cost of columns beyond the column cap
+const otherColumn = "Other"
+
+// CSVOutput handles CSV file output operations
+type CSVOutput struct {
+	dimension  string
+	maxColumns int
+}
+
+// NewCSVOutput creates a CSV output handler pivoting on dimension, keeping the
+// top maxColumns values by total cost and folding the rest into "Other"
+func NewCSVOutput(dimension string, maxColumns int) *CSVOutput {
+	if dimension == "" {
+		dimension = PivotService
+	}
+	return &CSVOutput{
+		dimension:  dimension,
+		maxColumns: maxColumns,
+	}
+}
+
+// PivotValue returns the value of a pivot dimension for a cost record
+func PivotValue(cost models.CostData, dimension string) (string, error) {
+	switch dimension {
+	case PivotService:
+		return cost.Service, nil
+	case PivotProject:
+		return cost.ProjectID, nil
+	case PivotSKU:
+		return cost.SKU, nil
+	case PivotRegion:
+		return cost.Region, nil
+	case PivotCostType:
+		return cost.CostType, nil
+	}
+	return "", fmt.Errorf("unknown pivot dimension %q", dimension)
+}
+
+// SaveWideCSV pivots long-format cost records into a date x dimension matrix,
+// one row per date, filling missing cells with 0
+func (co *CSVOutput) SaveWideCSV(costs []models.CostData, filename string) error {
+	log.Printf("💾 Saving wide CSV by %s to %s", co.dimension, filename)
+
+	cells := make(map[string]map[string]float64)
+	columnTotals := make(map[string]float64)
+	for _, cost := range costs {
+		column, err := PivotValue(cost, co.dimension)
+		if err != nil {
+			return err
+		}
+		if cells[cost.Date] == nil {
+			cells[cost.Date] = make(map[string]float64)
+		}
+		cells[cost.Date][column] += cost.Cost
+		columnTotals[column] += cost.Cost
+	}
+
+	// Keep the most expensive columns; the rest are summed into Other
+	columns := make([]string, 0, len(columnTotals))
+	for column := range columnTotals {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if columnTotals[columns[i]] != columnTotals[columns[j]] {
+			return columnTotals[columns[i]] > columnTotals[columns[j]]
+		}
+		return columns[i] < columns[j]
+	})
+	folded := make(map[string]bool)
+	if co.maxColumns > 0 && len(columns) > co.maxColumns {
+		for _, column := range columns[co.maxColumns:] {
+			folded[column] = true
+		}
+		columns = append(columns[:co.maxColumns:co.maxColumns], otherColumn)
+	}
+
+	dates := make([]string, 0, len(cells))
+	for date := range cells {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	file, err := CreateAtomic(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(append([]string{"date"}, columns...)); err != nil {
+		return err
+	}
+	for _, date := range dates {
+		other := 0.0
+		for column, cost := range cells[date] {
+			if folded[column] {
+				other += cost
+			}
+		}
+
+		record := []string{date}
+		for _, column := range columns {
+			cost := cells[date][column]
+			if column == otherColumn && len(folded) > 0 {
+				cost = other
+			}
+			record = append(record, strconv.FormatFloat(cost, 'f', 2, 64))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// SaveCompositeData saves composite cost data as CSV, one row per record with
+// columns named after the JSON fields
+func (co *CSVOutput) SaveCompositeData(data []models.CostData, filename string) error {
+	log.Printf("💾 Saving composite data to %s", filename)
+
+	header := []string{"date", "service", "sku", "project_id", "project_name", "region", "cost", "credits", "usage_amount", "usage_unit", "currency", "cost_type", "vendor"}
+	rows := make([][]string, 0, len(data))
+	for _, cost := range data {
+		rows = append(rows, []string{
+			cost.Date,
+			cost.Service,
+			cost.SKU,
+			cost.ProjectID,
+			cost.ProjectName,
+			cost.Region,
+			csvMoney(cost.Cost),
+			csvMoney(cost.Credits),
+			csvQuantity(cost.UsageAmount),
+			cost.UsageUnit,
+			cost.Currency,
+			cost.CostType,
+			cost.Vendor,
+		})
+	}
+	return writeCSV(filename, header, rows)
+}
+
+// SaveDailyTotals saves daily totals as CSV
+func (co *CSVOutput) SaveDailyTotals(data []models.DailyCost, filename string) error {
+	log.Printf("💾 Saving daily totals to %s", filename)
+
+	rows := make([][]string, 0, len(data))
+	for _, day := range data {
+		rows = append(rows, []string{day.Date, csvMoney(day.TotalCost)})
+	}
+	return writeCSV(filename, []string{"date", "total_cost"}, rows)
+}
+
+// SaveMTDData saves MTD data as CSV
+func (co *CSVOutput) SaveMTDData(data []models.MTDCost, filename string) error {
+	log.Printf("💾 Saving MTD data to %s", filename)
+
+	rows := make([][]string, 0, len(data))
+	for _, month := range data {
+		rows = append(rows, []string{month.Month, csvMoney(month.Cost), strconv.Itoa(month.Days)})
+	}
+	return writeCSV(filename, []string{"month", "cost", "days"}, rows)
+}
+
+// SaveAnomalies saves anomalies as CSV; links and attribution trees are left to the JSON output
+func (co *CSVOutput) SaveAnomalies(data []models.Anomaly, filename string) error {
+	log.Printf("💾 Saving anomalies to %s", filename)
+
+	header := []string{"id", "date", "test_name", "type", "category", "service", "sku", "project_id", "composite_key", "cost_type", "vendor", "cost_impact", "description", "severity", "detected_at", "current_value", "previous_value", "threshold", "delta", "percentage_diff", "confidence"}
+	rows := make([][]string, 0, len(data))
+	for _, anomaly := range data {
+		rows = append(rows, []string{
+			anomaly.ID,
+			anomaly.Date,
+			anomaly.TestName,
+			anomaly.Type,
+			anomaly.Category,
+			anomaly.Service,
+			anomaly.SKU,
+			anomaly.ProjectID,
+			anomaly.CompositeKey,
+			anomaly.CostType,
+			anomaly.Vendor,
+			csvMoney(anomaly.CostImpact),
+			anomaly.Description,
+			anomaly.Severity,
+			anomaly.DetectedAt,
+			csvMoney(anomaly.CurrentValue),
+			csvMoney(anomaly.PreviousValue),
+			csvMoney(anomaly.Threshold),
+			csvMoney(anomaly.Delta),
+			strconv.FormatFloat(anomaly.PercentageDiff, 'f', 2, 64),
+			anomaly.Confidence,
+		})
+	}
+	return writeCSV(filename, header, rows)
+}
+
+// writeCSV writes a header row and rows; encoding/csv quotes fields containing
+// commas, quotes or newlines
+func writeCSV(filename string, header []string, rows [][]string) error {
+	file, err := CreateAtomic(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// csvMoney formats a cost with fixed two-decimal precision
+func csvMoney(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// csvQuantity formats a usage amount with fixed six-decimal precision, enough for per-byte and per-second units
+func csvQuantity(value float64) string {
+	return strconv.FormatFloat(value, 'f', 6, 64)
+}
@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"encoding/csv"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// jsonTags returns the JSON field names of a struct type
+func jsonTags(v interface{}) map[string]bool {
+	tags := make(map[string]bool)
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			tags[name] = true
+		}
+	}
+	return tags
+}
+
+// readCSV reads back a CSV file written by CSVOutput
+func readCSV(t *testing.T, path string) ([]string, [][]string) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(records) == 0 {
+		t.Fatalf("%s has no header row", path)
+	}
+	return records[0], records[1:]
+}
+
+// parseFloat parses a numeric CSV cell
+func parseFloat(t *testing.T, s string) float64 {
+	t.Helper()
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		t.Fatalf("ParseFloat(%q): %v", s, err)
+	}
+	return f
+}
+
+func TestSaveCompositeDataRoundTrips(t *testing.T) {
+	data := []models.CostData{
+		{Date: "2024-03-01", Service: "Compute Engine", SKU: "N2 Core", ProjectID: "prod", ProjectName: "Production", Region: "us-central1", Cost: 123.45, Credits: -10.5, UsageAmount: 36.125, UsageUnit: "hour", Currency: "INR", CostType: "regular", Vendor: "gcp"},
+		{Date: "2024-03-01", Service: "BigQuery", SKU: "Analysis, \"on-demand\"", ProjectID: "analytics", ProjectName: "Analytics\nTeam", Region: "eu", Cost: 0.01, UsageAmount: 0.000001, UsageUnit: "byte", Currency: "USD", Vendor: "gcp"},
+	}
+	path := filepath.Join(t.TempDir(), "composite_data.csv")
+	if err := NewCSVOutput(PivotService, 0).SaveCompositeData(data, path); err != nil {
+		t.Fatalf("SaveCompositeData: %v", err)
+	}
+
+	header, rows := readCSV(t, path)
+	tags := jsonTags(models.CostData{})
+	for _, name := range header {
+		if !tags[name] {
+			t.Errorf("header %q is not a CostData JSON field", name)
+		}
+	}
+	if len(rows) != len(data) {
+		t.Fatalf("rows = %d, want %d", len(rows), len(data))
+	}
+
+	for i, row := range rows {
+		cells := make(map[string]string, len(header))
+		for j, name := range header {
+			cells[name] = row[j]
+		}
+		got := models.CostData{
+			Date:        cells["date"],
+			Service:     cells["service"],
+			SKU:         cells["sku"],
+			ProjectID:   cells["project_id"],
+			ProjectName: cells["project_name"],
+			Region:      cells["region"],
+			Cost:        parseFloat(t, cells["cost"]),
+			Credits:     parseFloat(t, cells["credits"]),
+			UsageAmount: parseFloat(t, cells["usage_amount"]),
+			UsageUnit:   cells["usage_unit"],
+			Currency:    cells["currency"],
+			CostType:    cells["cost_type"],
+			Vendor:      cells["vendor"],
+		}
+		if got != data[i] {
+			t.Errorf("row %d = %+v, want %+v", i, got, data[i])
+		}
+	}
+}
+
+func TestCSVHeadersMatchJSONTags(t *testing.T) {
+	dir := t.TempDir()
+	out := NewCSVOutput(PivotService, 0)
+	tests := []struct {
+		name  string
+		model interface{}
+		save  func(path string) error
+	}{
+		{"daily totals", models.DailyCost{}, func(path string) error {
+			return out.SaveDailyTotals([]models.DailyCost{{Date: "2024-03-01", TotalCost: 10}}, path)
+		}},
+		{"mtd data", models.MTDCost{}, func(path string) error {
+			return out.SaveMTDData([]models.MTDCost{{Month: "2024-03", Cost: 10, Days: 1}}, path)
+		}},
+		{"anomalies", models.Anomaly{}, func(path string) error {
+			return out.SaveAnomalies([]models.Anomaly{{Date: "2024-03-01", TestName: "test"}}, path)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".csv")
+			if err := tt.save(path); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			header, _ := readCSV(t, path)
+			tags := jsonTags(tt.model)
+			for _, name := range header {
+				if !tags[name] {
+					t.Errorf("header %q is not a JSON field of %T", name, tt.model)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveWideCSV(t *testing.T) {
+	costs := []models.CostData{
+		{Date: "2024-03-02", Service: "Compute", ProjectID: "prod", Cost: 100},
+		{Date: "2024-03-01", Service: "Compute", ProjectID: "prod", Cost: 90},
+		{Date: "2024-03-01", Service: "Compute", ProjectID: "dev", Cost: 10},
+		{Date: "2024-03-01", Service: "Storage", ProjectID: "prod", Cost: 50},
+		{Date: "2024-03-02", Service: "Networking", ProjectID: "dev", Cost: 5},
+		{Date: "2024-03-01", Service: "BigQuery", ProjectID: "dev", Cost: 3},
+	}
+	tests := []struct {
+		name       string
+		dimension  string
+		maxColumns int
+		header     []string
+		rows       [][]string
+	}{
+		{
+			"top services plus Other",
+			PivotService, 2,
+			[]string{"date", "Compute", "Storage", "Other"},
+			[][]string{{"2024-03-01", "100.00", "50.00", "3.00"}, {"2024-03-02", "100.00", "0.00", "5.00"}},
+		},
+		{
+			"uncapped services",
+			"", 0,
+			[]string{"date", "Compute", "Storage", "Networking", "BigQuery"},
+			[][]string{{"2024-03-01", "100.00", "50.00", "0.00", "3.00"}, {"2024-03-02", "100.00", "0.00", "5.00", "0.00"}},
+		},
+		{
+			"cap above the column count",
+			PivotProject, 5,
+			[]string{"date", "prod", "dev"},
+			[][]string{{"2024-03-01", "140.00", "13.00"}, {"2024-03-02", "100.00", "5.00"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "wide.csv")
+			if err := NewCSVOutput(tt.dimension, tt.maxColumns).SaveWideCSV(costs, path); err != nil {
+				t.Fatalf("SaveWideCSV: %v", err)
+			}
+			header, rows := readCSV(t, path)
+			if !reflect.DeepEqual(header, tt.header) {
+				t.Errorf("header = %v, want %v", header, tt.header)
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("rows = %v, want %v", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestSaveWideCSVRejectsUnknownDimension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wide.csv")
+	err := NewCSVOutput("zone", 0).SaveWideCSV([]models.CostData{{Date: "2024-03-01", Cost: 1}}, path)
+	if err == nil || !strings.Contains(err.Error(), "unknown pivot dimension") {
+		t.Errorf("SaveWideCSV() error = %v, want an unknown dimension error", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s exists after a failed pivot (stat error %v)", path, err)
+	}
+}
@@ -1,96 +1,146 @@
 package utils
 
 import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
 	"infra-cost-monitor/go-framework/vendors/gcp/models"
 	"log"
-	"time"
+	"sort"
 )
 
 // DataProcessor processes cost data into various formats
-type DataProcessor struct{}
+type DataProcessor struct {
+	summation         string
+	percentiles       []float64
+	serviceThresholds detectors.ServiceThresholds
+}
 
 // NewDataProcessor creates a new data processor
 func NewDataProcessor() *DataProcessor {
-	return &DataProcessor{}
+	return &DataProcessor{
+		serviceThresholds: detectors.DefaultServiceThresholds(),
+	}
 }
 
-// ProcessCompositeData processes cost data into composite format
+// SetServiceThresholds sets the per-service spike thresholds DetectAnomalies judges each service by
+func (dp *DataProcessor) SetServiceThresholds(thresholds detectors.ServiceThresholds) {
+	dp.serviceThresholds = thresholds
+}
+
+// SetSummation sets how summary totals accumulate costs (see models.SummationKahan)
+func (dp *DataProcessor) SetSummation(mode string) {
+	dp.summation = mode
+}
+
+// SetPercentiles sets the daily cost percentiles (0-1) reported in the summary
+func (dp *DataProcessor) SetPercentiles(ps []float64) {
+	dp.percentiles = ps
+}
+
+// ProcessCompositeData aggregates cost data into one record per (date, service, SKU,
+// project, region), summing cost, credits and usage. Rows with different usage
+// units, charge types or currencies are kept in separate records so usage is never
+// summed across units, amounts are never summed across unconverted currencies and
+// separated refunds stay distinguishable. Groups keep the order
+// in which they were first seen.
 func (dp *DataProcessor) ProcessCompositeData(dailyCosts []models.DailyCost, mtdCosts []models.MTDCost, dimensionalCosts []models.CostData) []models.CostData {
 	log.Println("🔄 Processing composite data...")
 	
-	// For now, return the dimensional costs as composite data
-	// In a real implementation, this would aggregate data by service/SKU/project/region
-	return dimensionalCosts
+	type groupKey struct {
+		date, service, sku, projectID, region, usageUnit, costType, currency string
+	}
+	
+	index := make(map[groupKey]int)
+	var composite []models.CostData
+	for _, cost := range dimensionalCosts {
+		key := groupKey{cost.Date, cost.Service, cost.SKU, cost.ProjectID, cost.Region, cost.UsageUnit, cost.CostType, cost.Currency}
+		if i, exists := index[key]; exists {
+			composite[i].Cost += cost.Cost
+			composite[i].Credits += cost.Credits
+			composite[i].UsageAmount += cost.UsageAmount
+			continue
+		}
+		index[key] = len(composite)
+		composite = append(composite, cost)
+	}
+	
+	log.Printf("✅ Aggregated %d cost rows into %d composite records", len(dimensionalCosts), len(composite))
+	return composite
 }
 
-// ProcessDailyTotals processes daily costs into total format
+// ProcessDailyTotals processes daily costs into total format, newest first
 func (dp *DataProcessor) ProcessDailyTotals(dailyCosts []models.DailyCost) []models.DailyCost {
 	log.Println("🔄 Processing daily totals...")
+	models.SortDailyCostsDesc(dailyCosts)
 	return dailyCosts
 }
 
-// DetectAnomalies detects anomalies in cost data
-func (dp *DataProcessor) DetectAnomalies(dailyCosts []models.DailyCost, mtdCosts []models.MTDCost) []models.Anomaly {
-	log.Println("🔍 Detecting anomalies...")
+// ProcessCostTypeTotals splits cost data into a daily total series per charge type, newest first
+func (dp *DataProcessor) ProcessCostTypeTotals(costs []models.CostData) map[string][]models.DailyCost {
+	log.Println("🔄 Processing daily totals by charge type...")
 	
-	var anomalies []models.Anomaly
-	
-	// Check for daily cost spikes
-	if len(dailyCosts) >= 2 {
-		current := dailyCosts[0].TotalCost
-		previous := dailyCosts[1].TotalCost
-		
-		if previous > 0 {
-			increase := current - previous
-			percentage := (increase / previous) * 100
-			
-			// Detect spike if increase > 50% or > $1000
-			if percentage > 50 || increase > 1000 {
-				anomaly := models.Anomaly{
-					Date:        dailyCosts[0].Date,
-					Service:     "daily_total",
-					CostImpact:  increase,
-					Description: "Daily cost spike detected",
-					Severity:    "HIGH",
-					DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
-				}
-				anomalies = append(anomalies, anomaly)
-			}
+	totals := make(map[string]map[string]float64)
+	for _, cost := range costs {
+		if totals[cost.CostType] == nil {
+			totals[cost.CostType] = make(map[string]float64)
 		}
+		totals[cost.CostType][cost.Date] += cost.Cost
 	}
 	
-	// Check for monthly cost spikes
-	if len(mtdCosts) >= 2 {
-		current := mtdCosts[0].Cost
-		previous := mtdCosts[1].Cost
-		
-		if previous > 0 {
-			increase := current - previous
-			percentage := (increase / previous) * 100
-			
-			// Detect spike if increase > 30% or > $5000
-			if percentage > 30 || increase > 5000 {
-				anomaly := models.Anomaly{
-					Date:        mtdCosts[0].Month,
-					Service:     "monthly_total",
-					CostImpact:  increase,
-					Description: "Monthly cost spike detected",
-					Severity:    "HIGH",
-					DetectedAt:  time.Now().Format("2006-01-02 15:04:05"),
-				}
-				anomalies = append(anomalies, anomaly)
-			}
+	series := make(map[string][]models.DailyCost)
+	for costType, byDate := range totals {
+		for date, total := range byDate {
+			series[costType] = append(series[costType], models.DailyCost{
+				Date:      date,
+				TotalCost: total,
+			})
 		}
+		sort.Slice(series[costType], func(i, j int) bool {
+			return series[costType][i].Date > series[costType][j].Date
+		})
+	}
+	
+	return series
+}
+
+// DetectAnomalies detects anomalies in cost data using the built-in detectors;
+// services in the composite data are judged against the per-service thresholds
+func (dp *DataProcessor) DetectAnomalies(dailyCosts []models.DailyCost, mtdCosts []models.MTDCost, compositeData []models.CostData) []models.Anomaly {
+	log.Println("🔍 Detecting anomalies...")
+	
+	models.SortDailyCostsDesc(dailyCosts)
+	series := detectors.Series{
+		Daily:     dailyCosts,
+		MTD:       mtdCosts,
+		Composite: compositeData,
 	}
+	registry := detectors.NewDefaultRegistry()
+	registry.Register("service_spike", &detectors.ServiceSpikeDetector{Thresholds: dp.serviceThresholds})
+	anomalies := registry.Run(series, nil)
 	
 	log.Printf("✅ Detected %d anomalies", len(anomalies))
 	return anomalies
 }
 
+// DetectAnomaliesZScore flags every day whose total is more than threshold
+// standard deviations from the series mean
+func (dp *DataProcessor) DetectAnomaliesZScore(dailyCosts []models.DailyCost, threshold float64) []models.Anomaly {
+	log.Println("🔍 Detecting z-score anomalies...")
+	
+	series := detectors.Series{
+		Daily: dailyCosts,
+	}
+	anomalies := models.AssignIDs((&detectors.ZScoreDetector{}).Detect(series, detectors.Params{"threshold": threshold}))
+	
+	log.Printf("✅ Detected %d z-score anomalies", len(anomalies))
+	return anomalies
+}
+
 // GenerateSummary generates summary statistics
 func (dp *DataProcessor) GenerateSummary(compositeData []models.CostData, dailyTotals []models.DailyCost, mtdCosts []models.MTDCost, anomalies []models.Anomaly) models.Summary {
 	log.Println("📊 Generating summary...")
 	
+	models.SortDailyCostsDesc(dailyTotals)
 	summary := models.Summary{
 		TotalAnomalies:   len(anomalies),
 		TotalCostImpact:  0,
@@ -101,9 +151,11 @@ func (dp *DataProcessor) GenerateSummary(compositeData []models.CostData, dailyT
 	}
 	
 	// Calculate total cost impact from anomalies
+	costImpact := models.NewAccumulator(dp.summation)
 	for _, anomaly := range anomalies {
-		summary.TotalCostImpact += anomaly.CostImpact
+		costImpact.Add(anomaly.CostImpact)
 	}
+	summary.TotalCostImpact = costImpact.Total()
 	
 	// Get current month cost
 	if len(mtdCosts) > 0 {
@@ -122,6 +174,32 @@ func (dp *DataProcessor) GenerateSummary(compositeData []models.CostData, dailyT
 		summary.CurrentDateCost = dailyTotals[0].TotalCost
 	}
 	
+	// Break down cost by charge type so tax true-ups and credit expiries are visible
+	costTypeBreakdown := models.NewBreakdown(dp.summation)
+	for _, cost := range compositeData {
+		costTypeBreakdown.Add(cost.CostType, cost.Cost)
+	}
+	summary.CostTypeBreakdown = costTypeBreakdown.Totals()
+	
+	// Daily cost percentile profile, computed with a single sort
+	if len(dp.percentiles) > 0 && len(dailyTotals) > 0 {
+		costs := make([]float64, len(dailyTotals))
+		for i, day := range dailyTotals {
+			costs[i] = day.TotalCost
+		}
+		summary.DailyCostPercentiles = make(map[string]float64)
+		for p, value := range detectors.Percentiles(costs, dp.percentiles) {
+			summary.DailyCostPercentiles[fmt.Sprintf("p%g", p*100)] = value
+		}
+	}
+	
+	// Round reported costs consistently when drift-free summation is enabled
+	if dp.summation == models.SummationKahan || dp.summation == models.SummationMinorUnits {
+		summary.CurrentMonthCost = models.RoundMoney(summary.CurrentMonthCost)
+		summary.LastMonthCost = models.RoundMoney(summary.LastMonthCost)
+		summary.CurrentDateCost = models.RoundMoney(summary.CurrentDateCost)
+	}
+	
 	log.Println("✅ Summary generated")
 	return summary
 } 
\ No newline at end of file
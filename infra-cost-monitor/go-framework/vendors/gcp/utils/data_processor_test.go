@@ -0,0 +1,250 @@
+package utils
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestProcessCompositeData(t *testing.T) {
+	vm := models.CostData{Date: "2024-03-01", Service: "Compute", SKU: "VM", ProjectID: "proj-1", Region: "us-east1", UsageUnit: "hour"}
+	row := func(change func(c *models.CostData), cost, usage float64) models.CostData {
+		c := vm
+		c.Cost, c.UsageAmount = cost, usage
+		change(&c)
+		return c
+	}
+	same := func(c *models.CostData) {}
+
+	tests := []struct {
+		name  string
+		input []models.CostData
+		want  []models.CostData
+	}{
+		{
+			name:  "duplicate keys collapse into one summed record",
+			input: []models.CostData{row(same, 1.5, 10), row(same, 2.5, 20), row(same, 1, 5)},
+			want:  []models.CostData{row(same, 5, 35)},
+		},
+		{
+			name: "different dates, projects and regions stay distinct",
+			input: []models.CostData{
+				row(same, 1, 1),
+				row(func(c *models.CostData) { c.Date = "2024-03-02" }, 2, 2),
+				row(func(c *models.CostData) { c.ProjectID = "proj-2" }, 3, 3),
+				row(func(c *models.CostData) { c.Region = "europe-west1" }, 4, 4),
+				row(same, 5, 5),
+			},
+			want: []models.CostData{
+				row(same, 6, 6),
+				row(func(c *models.CostData) { c.Date = "2024-03-02" }, 2, 2),
+				row(func(c *models.CostData) { c.ProjectID = "proj-2" }, 3, 3),
+				row(func(c *models.CostData) { c.Region = "europe-west1" }, 4, 4),
+			},
+		},
+		{
+			name: "different usage units are split",
+			input: []models.CostData{
+				row(same, 1, 10),
+				row(func(c *models.CostData) { c.UsageUnit = "gibibyte" }, 2, 20),
+				row(same, 3, 30),
+			},
+			want: []models.CostData{
+				row(same, 4, 40),
+				row(func(c *models.CostData) { c.UsageUnit = "gibibyte" }, 2, 20),
+			},
+		},
+		{
+			name: "INR and USD amounts are never summed",
+			input: []models.CostData{
+				row(func(c *models.CostData) { c.Currency = "INR" }, 830, 1),
+				row(func(c *models.CostData) { c.Currency = "USD" }, 10, 1),
+				row(func(c *models.CostData) { c.Currency = "INR" }, 170, 1),
+			},
+			want: []models.CostData{
+				row(func(c *models.CostData) { c.Currency = "INR" }, 1000, 2),
+				row(func(c *models.CostData) { c.Currency = "USD" }, 10, 1),
+			},
+		},
+		{
+			name:  "no rows",
+			input: nil,
+			want:  nil,
+		},
+	}
+
+	dp := NewDataProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dp.ProcessCompositeData(nil, nil, tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d records, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("record %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateSummaryRoundsDriftFreeTotals(t *testing.T) {
+	var anomalies []models.Anomaly
+	var costs []models.CostData
+	for i := 0; i < 100000; i++ {
+		anomalies = append(anomalies, models.Anomaly{CostImpact: 0.01})
+		costs = append(costs, models.CostData{CostType: "usage", Cost: 0.01})
+	}
+	daily := []models.DailyCost{{Date: "2024-03-01", TotalCost: 12.345}}
+
+	for _, mode := range []string{models.SummationKahan, models.SummationMinorUnits} {
+		t.Run(mode, func(t *testing.T) {
+			dp := NewDataProcessor()
+			dp.SetSummation(mode)
+			summary := dp.GenerateSummary(costs, daily, nil, anomalies)
+
+			if summary.TotalCostImpact != 1000 {
+				t.Errorf("TotalCostImpact = %v, want 1000", summary.TotalCostImpact)
+			}
+			if got := summary.CostTypeBreakdown["usage"]; got != 1000 {
+				t.Errorf("usage breakdown = %v, want 1000", got)
+			}
+			if summary.CurrentDateCost != 12.35 {
+				t.Errorf("CurrentDateCost = %v, want 12.35", summary.CurrentDateCost)
+			}
+		})
+	}
+}
+
+// outlierSeries returns 30 days alternating between 99 and 101 with one day at 200
+func outlierSeries() []models.DailyCost {
+	var daily []models.DailyCost
+	for day := 30; day >= 1; day-- {
+		cost := 99.0 + float64(day%2)*2
+		if day == 15 {
+			cost = 200
+		}
+		daily = append(daily, models.DailyCost{Date: fmt.Sprintf("2024-03-%02d", day), TotalCost: cost})
+	}
+	return daily
+}
+
+func TestDetectAnomaliesZScoreFlagsOutlier(t *testing.T) {
+	dp := NewDataProcessor()
+
+	anomalies := dp.DetectAnomaliesZScore(outlierSeries(), 3)
+	if len(anomalies) != 1 {
+		t.Fatalf("got %d anomalies, want 1", len(anomalies))
+	}
+	anomaly := anomalies[0]
+	if anomaly.Date != "2024-03-15" {
+		t.Errorf("flagged %s, want the outlier on 2024-03-15", anomaly.Date)
+	}
+	if !strings.Contains(anomaly.Description, "z-score") {
+		t.Errorf("description %q does not report the z-score", anomaly.Description)
+	}
+	if anomaly.Severity != models.SeverityCritical {
+		t.Errorf("severity = %s, want %s for a z-score above 5", anomaly.Severity, models.SeverityCritical)
+	}
+	if anomaly.ID == "" {
+		t.Error("anomaly has no ID")
+	}
+
+	if anomalies := dp.DetectAnomaliesZScore(outlierSeries(), 10); len(anomalies) != 0 {
+		t.Errorf("got %d anomalies with a threshold above the outlier's z-score, want 0", len(anomalies))
+	}
+}
+
+func TestDetectAnomaliesUsesServiceThresholds(t *testing.T) {
+	var composite []models.CostData
+	for _, service := range []string{"BigQuery", "Static IP"} {
+		composite = append(composite,
+			models.CostData{Date: "2024-05-09", Service: service, Cost: 100},
+			models.CostData{Date: "2024-05-10", Service: service, Cost: 130},
+		)
+	}
+
+	dp := NewDataProcessor()
+	dp.SetServiceThresholds(detectors.ServiceThresholds{
+		Default:  detectors.Threshold{Percentage: 50, Absolute: 1000},
+		Services: map[string]detectors.Threshold{"Static*": {Percentage: 10, Absolute: 1000}},
+	})
+	var spiked []string
+	for _, anomaly := range dp.DetectAnomalies(nil, nil, composite) {
+		if anomaly.Type == "service_spike" {
+			spiked = append(spiked, anomaly.Service)
+		}
+	}
+	if len(spiked) != 1 || spiked[0] != "Static IP" {
+		t.Errorf("service spikes = %v, want only Static IP flagged for the same 30%% rise", spiked)
+	}
+}
+
+func TestProcessCostTypeTotals(t *testing.T) {
+	costs := []models.CostData{
+		{Date: "2024-03-01", Service: "Compute", CostType: "usage", Cost: 100},
+		{Date: "2024-03-01", Service: "Storage", CostType: "usage", Cost: 50},
+		{Date: "2024-03-02", Service: "Compute", CostType: "usage", Cost: 120},
+		{Date: "2024-03-01", Service: "Compute", CostType: "credit", Cost: -40},
+		{Date: "2024-03-02", Service: "Compute", CostType: "tax", Cost: 12},
+	}
+
+	got := NewDataProcessor().ProcessCostTypeTotals(costs)
+
+	want := map[string][]models.DailyCost{
+		"usage":  {{Date: "2024-03-02", TotalCost: 120}, {Date: "2024-03-01", TotalCost: 150}},
+		"credit": {{Date: "2024-03-01", TotalCost: -40}},
+		"tax":    {{Date: "2024-03-02", TotalCost: 12}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got series for %d charge types, want %d: %+v", len(got), len(want), got)
+	}
+	for costType, series := range want {
+		if len(got[costType]) != len(series) {
+			t.Errorf("%s series = %+v, want %+v", costType, got[costType], series)
+			continue
+		}
+		for i := range series {
+			if got[costType][i] != series[i] {
+				t.Errorf("%s day %d = %+v, want %+v", costType, i, got[costType][i], series[i])
+			}
+		}
+	}
+}
+
+func TestGenerateSummaryReportsThePercentileProfile(t *testing.T) {
+	var daily []models.DailyCost
+	for day := 20; day >= 1; day-- {
+		daily = append(daily, models.DailyCost{Date: fmt.Sprintf("2024-03-%02d", day), TotalCost: float64(day * 10)})
+	}
+
+	dp := NewDataProcessor()
+	dp.SetPercentiles([]float64{0.5, 0.9, 0.95, 0.99})
+	summary := dp.GenerateSummary(nil, daily, nil, nil)
+
+	want := map[string]float64{"p50": 110, "p90": 190, "p95": 200, "p99": 200}
+	if len(summary.DailyCostPercentiles) != len(want) {
+		t.Errorf("profile = %v, want %v", summary.DailyCostPercentiles, want)
+	}
+	for key, value := range want {
+		if summary.DailyCostPercentiles[key] != value {
+			t.Errorf("%s = %v, want %v", key, summary.DailyCostPercentiles[key], value)
+		}
+	}
+
+	dp.SetPercentiles(nil)
+	if profile := dp.GenerateSummary(nil, daily, nil, nil).DailyCostPercentiles; profile != nil {
+		t.Errorf("profile = %v with no percentiles configured, want none", profile)
+	}
+}
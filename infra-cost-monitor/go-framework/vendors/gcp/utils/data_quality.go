@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"sort"
+	"time"
+)
+
+// DataQualityChecker verifies billing data looks complete before detection runs
+type DataQualityChecker struct {
+	cfg config.DataQualityConfig
+	now func() time.Time
+}
+
+// NewDataQualityChecker creates a new data quality checker
+func NewDataQualityChecker(cfg config.DataQualityConfig) *DataQualityChecker {
+	return &DataQualityChecker{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// Check runs the freshness, volume and cost checks against the latest day of data
+func (dq *DataQualityChecker) Check(dailyCosts []models.DailyCost, compositeData []models.CostData) []models.DataQualityCheck {
+	log.Println("🩺 Running data quality checks...")
+
+	checks := []models.DataQualityCheck{
+		dq.checkFreshness(dailyCosts),
+		dq.checkVolume(compositeData),
+		dq.checkCost(dailyCosts),
+	}
+
+	for _, check := range checks {
+		status := "✅"
+		if !check.Passed {
+			status = "❌"
+		}
+		log.Printf("%s %s: %s", status, check.Name, check.Message)
+	}
+	return checks
+}
+
+// Passed reports whether every check passed
+func (dq *DataQualityChecker) Passed(checks []models.DataQualityCheck) bool {
+	for _, check := range checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// DeferIncomplete removes days that the completeness criterion deems still loading
+// from the series used for alerting, returning the deferred dates so they can be
+// recorded and evaluated on a later run
+func (dq *DataQualityChecker) DeferIncomplete(series detectors.Series) (detectors.Series, []string) {
+	if dq.cfg.Completeness == "" || dq.cfg.Completeness == config.CompletenessNone {
+		return series, nil
+	}
+
+	present := make(map[string]bool)
+	for _, day := range series.Daily {
+		present[day.Date] = true
+	}
+
+	today := dq.now().UTC().Truncate(24 * time.Hour)
+	deferred := make(map[string]bool)
+	for date := range present {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		complete := true
+		switch dq.cfg.Completeness {
+		case config.CompletenessNextDay:
+			complete = present[day.AddDate(0, 0, 1).Format("2006-01-02")]
+		case config.CompletenessSettled:
+			complete = today.Sub(day).Hours()/24 >= float64(dq.cfg.SettleDays)
+		}
+		if !complete {
+			deferred[date] = true
+		}
+	}
+	if len(deferred) == 0 {
+		return series, nil
+	}
+
+	dates := make([]string, 0, len(deferred))
+	for date := range deferred {
+		dates = append(dates, date)
+		log.Printf("⏸️  Deferring evaluation of %s until its data is complete (%s)", date, dq.cfg.Completeness)
+	}
+	sort.Strings(dates)
+
+	guarded := detectors.Series{MTD: series.MTD}
+	for _, day := range series.Daily {
+		if !deferred[day.Date] {
+			guarded.Daily = append(guarded.Daily, day)
+		}
+	}
+	for _, cost := range series.Composite {
+		if !deferred[cost.Date] {
+			guarded.Composite = append(guarded.Composite, cost)
+		}
+	}
+	return guarded, dates
+}
+
+// checkFreshness verifies the latest date present is recent enough
+func (dq *DataQualityChecker) checkFreshness(dailyCosts []models.DailyCost) models.DataQualityCheck {
+	check := models.DataQualityCheck{Name: "freshness", Threshold: float64(dq.cfg.MaxLagDays)}
+
+	latest := ""
+	for _, day := range dailyCosts {
+		if day.Date > latest {
+			latest = day.Date
+		}
+	}
+	latestDate, err := time.Parse("2006-01-02", latest)
+	if err != nil {
+		check.Message = "no dated records found"
+		return check
+	}
+
+	today := dq.now().UTC().Truncate(24 * time.Hour)
+	lag := today.Sub(latestDate).Hours() / 24
+	check.Value = lag
+	check.Passed = lag <= float64(dq.cfg.MaxLagDays)
+	check.Message = fmt.Sprintf("latest date %s is %.0f days old (max %d)", latest, lag, dq.cfg.MaxLagDays)
+	return check
+}
+
+// checkVolume compares the latest day's row count to the rolling average
+func (dq *DataQualityChecker) checkVolume(compositeData []models.CostData) models.DataQualityCheck {
+	check := models.DataQualityCheck{Name: "volume", Threshold: dq.cfg.MinVolumeRatio}
+
+	counts := make(map[string]float64)
+	for _, record := range compositeData {
+		counts[record.Date]++
+	}
+	latest, average, ok := latestVersusAverage(counts, dq.cfg.RollingDays)
+	if !ok {
+		check.Passed = true
+		check.Message = "not enough history to compare row counts"
+		return check
+	}
+
+	check.Value = latest / average
+	check.Passed = check.Value >= dq.cfg.MinVolumeRatio
+	check.Message = fmt.Sprintf("latest day has %.0f rows vs rolling average %.1f (ratio %.2f, min %.2f)", latest, average, check.Value, dq.cfg.MinVolumeRatio)
+	return check
+}
+
+// checkCost compares the latest day's total cost to the rolling average
+func (dq *DataQualityChecker) checkCost(dailyCosts []models.DailyCost) models.DataQualityCheck {
+	check := models.DataQualityCheck{Name: "cost", Threshold: dq.cfg.MinCostRatio}
+
+	totals := make(map[string]float64)
+	for _, day := range dailyCosts {
+		totals[day.Date] += day.TotalCost
+	}
+	latest, average, ok := latestVersusAverage(totals, dq.cfg.RollingDays)
+	if !ok {
+		check.Passed = true
+		check.Message = "not enough history to compare total cost"
+		return check
+	}
+
+	check.Value = latest / average
+	check.Passed = check.Value >= dq.cfg.MinCostRatio
+	check.Message = fmt.Sprintf("latest day cost %.2f vs rolling average %.2f (ratio %.2f, min %.2f)", latest, average, check.Value, dq.cfg.MinCostRatio)
+	return check
+}
+
+// latestVersusAverage returns the latest date's value and the average of the preceding days
+func latestVersusAverage(byDate map[string]float64, rollingDays int) (float64, float64, bool) {
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	if len(dates) < 2 {
+		return 0, 0, false
+	}
+
+	previous := dates[1:]
+	if rollingDays > 0 && len(previous) > rollingDays {
+		previous = previous[:rollingDays]
+	}
+
+	sum := 0.0
+	for _, date := range previous {
+		sum += byDate[date]
+	}
+	average := sum / float64(len(previous))
+	if average <= 0 {
+		return 0, 0, false
+	}
+	return byDate[dates[0]], average, true
+}
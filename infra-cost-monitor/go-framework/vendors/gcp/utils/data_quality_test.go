@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"fmt"
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// qualityData returns ten days to 2024-03-10 of 100 in cost over 10 rows a day,
+// with the latest day's cost and row count given
+func qualityData(latestCost float64, latestRows int) ([]models.DailyCost, []models.CostData) {
+	var daily []models.DailyCost
+	var composite []models.CostData
+	for day := 10; day >= 1; day-- {
+		date := fmt.Sprintf("2024-03-%02d", day)
+		cost, rows := 100.0, 10
+		if day == 10 {
+			cost, rows = latestCost, latestRows
+		}
+		daily = append(daily, models.DailyCost{Date: date, TotalCost: cost})
+		for i := 0; i < rows; i++ {
+			composite = append(composite, models.CostData{Date: date, SKU: fmt.Sprintf("sku-%d", i), Cost: cost / float64(rows)})
+		}
+	}
+	return daily, composite
+}
+
+func TestDataQualityChecks(t *testing.T) {
+	cfg := config.DataQualityConfig{Enabled: true, MaxLagDays: 2, RollingDays: 7, MinVolumeRatio: 0.5, MinCostRatio: 0.5}
+	tests := []struct {
+		name       string
+		today      string
+		latestCost float64
+		latestRows int
+		failed     string
+	}{
+		{"complete data", "2024-03-12", 100, 10, ""},
+		{"stale data", "2024-03-13", 100, 10, "freshness"},
+		{"partial row count", "2024-03-11", 100, 4, "volume"},
+		{"partial cost", "2024-03-11", 40, 10, "cost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewDataQualityChecker(cfg)
+			today, err := time.Parse("2006-01-02", tt.today)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			checker.now = func() time.Time { return today }
+
+			checks := checker.Check(qualityData(tt.latestCost, tt.latestRows))
+			if len(checks) != 3 {
+				t.Fatalf("got %d checks, want 3", len(checks))
+			}
+			for _, check := range checks {
+				if want := check.Name != tt.failed; check.Passed != want {
+					t.Errorf("%s passed = %v, want %v (%s)", check.Name, check.Passed, want, check.Message)
+				}
+			}
+			if passed := checker.Passed(checks); passed != (tt.failed == "") {
+				t.Errorf("Passed() = %v, want %v", passed, tt.failed == "")
+			}
+		})
+	}
+}
+
+func TestDataQualityPassesWithoutHistory(t *testing.T) {
+	checker := NewDataQualityChecker(config.DataQualityConfig{Enabled: true, MaxLagDays: 2, MinVolumeRatio: 0.5, MinCostRatio: 0.5})
+	checker.now = func() time.Time { return time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC) }
+
+	daily := []models.DailyCost{{Date: "2024-03-10", TotalCost: 1}}
+	composite := []models.CostData{{Date: "2024-03-10", Cost: 1}}
+	if checks := checker.Check(daily, composite); !checker.Passed(checks) {
+		t.Errorf("checks = %+v, want a single day to pass the rolling comparisons", checks)
+	}
+}
+
+func TestDeferIncomplete(t *testing.T) {
+	tests := []struct {
+		name         string
+		completeness string
+		settleDays   int
+		deferred     []string
+	}{
+		{"none", config.CompletenessNone, 0, nil},
+		{"unset", "", 0, nil},
+		{"next day", config.CompletenessNextDay, 0, []string{"2024-03-10"}},
+		{"yesterday is a day old", config.CompletenessSettled, 1, nil},
+		{"settled after two days", config.CompletenessSettled, 2, []string{"2024-03-10"}},
+		{"settled after three days", config.CompletenessSettled, 3, []string{"2024-03-09", "2024-03-10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewDataQualityChecker(config.DataQualityConfig{Completeness: tt.completeness, SettleDays: tt.settleDays})
+			checker.now = func() time.Time { return time.Date(2024, 3, 11, 6, 0, 0, 0, time.UTC) }
+			daily, composite := qualityData(100, 10)
+			mtd := []models.MTDCost{{Month: "2024-03", Cost: 1000, Days: 10}}
+
+			guarded, deferred := checker.DeferIncomplete(detectors.Series{Daily: daily, Composite: composite, MTD: mtd})
+			if !reflect.DeepEqual(deferred, tt.deferred) {
+				t.Errorf("deferred = %v, want %v", deferred, tt.deferred)
+			}
+			if want := len(daily) - len(tt.deferred); len(guarded.Daily) != want {
+				t.Errorf("kept %d daily totals, want %d", len(guarded.Daily), want)
+			}
+			if want := 10 * (len(daily) - len(tt.deferred)); len(guarded.Composite) != want {
+				t.Errorf("kept %d composite rows, want %d", len(guarded.Composite), want)
+			}
+			for _, day := range guarded.Daily {
+				for _, date := range tt.deferred {
+					if day.Date == date {
+						t.Errorf("deferred day %s still evaluated", date)
+					}
+				}
+			}
+			if len(guarded.MTD) != 1 {
+				t.Errorf("MTD = %+v, want it passed through", guarded.MTD)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"sort"
+)
+
+// diffTolerance is the smallest cost change, half a cent, that counts as changed
+const diffTolerance = 0.005
+
+// Diff compares two runs' composite data by composite key. Each key's cost is
+// summed over all the dates in its run; keys in only one run are added or
+// removed, and keys whose total moved by at least half a cent are changed.
+// Entries are ordered by the size of their delta, largest first.
+func Diff(prev, curr []models.CostData) models.CostDiff {
+	previous := totalsByKey(prev)
+	current := totalsByKey(curr)
+
+	var diff models.CostDiff
+	for key, cost := range previous {
+		diff.PreviousTotal += cost.Cost
+		if _, exists := current[key]; !exists {
+			diff.Removed++
+			diff.Entries = append(diff.Entries, diffEntry(key, models.DiffRemoved, cost, cost.Cost, 0))
+		}
+	}
+	for key, cost := range current {
+		diff.CurrentTotal += cost.Cost
+		before, exists := previous[key]
+		switch {
+		case !exists:
+			diff.Added++
+			diff.Entries = append(diff.Entries, diffEntry(key, models.DiffAdded, cost, 0, cost.Cost))
+		case math.Abs(cost.Cost-before.Cost) >= diffTolerance:
+			diff.Changed++
+			diff.Entries = append(diff.Entries, diffEntry(key, models.DiffChanged, cost, before.Cost, cost.Cost))
+		default:
+			diff.Unchanged++
+		}
+	}
+	diff.Delta = diff.CurrentTotal - diff.PreviousTotal
+
+	sort.Slice(diff.Entries, func(i, j int) bool {
+		di, dj := math.Abs(diff.Entries[i].Delta), math.Abs(diff.Entries[j].Delta)
+		if di != dj {
+			return di > dj
+		}
+		return diff.Entries[i].CompositeKey < diff.Entries[j].CompositeKey
+	})
+	return diff
+}
+
+// totalsByKey sums cost per composite key, keeping the first record's dimensions
+func totalsByKey(costs []models.CostData) map[string]models.CostData {
+	totals := make(map[string]models.CostData)
+	for _, cost := range costs {
+		key := cost.CompositeKey()
+		total, exists := totals[key]
+		if !exists {
+			total = cost
+			total.Cost = 0
+		}
+		total.Cost += cost.Cost
+		totals[key] = total
+	}
+	return totals
+}
+
+// diffEntry builds the diff entry of a composite key
+func diffEntry(key, status string, cost models.CostData, previous, current float64) models.CostDiffEntry {
+	entry := models.CostDiffEntry{
+		CompositeKey: key,
+		Status:       status,
+		Service:      cost.Service,
+		SKU:          cost.SKU,
+		ProjectID:    cost.ProjectID,
+		Region:       cost.Region,
+		PreviousCost: previous,
+		CurrentCost:  current,
+		Delta:        current - previous,
+	}
+	if previous != 0 {
+		entry.Percentage = (current - previous) / math.Abs(previous) * 100
+	}
+	return entry
+}
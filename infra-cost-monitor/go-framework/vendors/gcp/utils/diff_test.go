@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+func TestDiffAddedRemovedAndChangedKeys(t *testing.T) {
+	vm := models.CostData{Service: "Compute", SKU: "VM", ProjectID: "prod", Region: "us-east1"}
+	at := func(c models.CostData, date string, cost float64) models.CostData {
+		c.Date, c.Cost = date, cost
+		return c
+	}
+	disk := models.CostData{Service: "Storage", SKU: "Disk", ProjectID: "prod", Region: "us-east1"}
+	query := models.CostData{Service: "BigQuery", SKU: "Analysis", ProjectID: "analytics", Region: "eu"}
+	ip := models.CostData{Service: "Networking", SKU: "Static IP", ProjectID: "prod", Region: "us-east1"}
+
+	prev := []models.CostData{
+		at(vm, "2024-05-08", 50), at(vm, "2024-05-09", 50),
+		at(disk, "2024-05-09", 30),
+		at(ip, "2024-05-09", 7.2),
+	}
+	curr := []models.CostData{
+		at(vm, "2024-05-09", 60), at(vm, "2024-05-10", 65),
+		at(query, "2024-05-10", 12),
+		// Within half a cent of the previous run is unchanged
+		at(ip, "2024-05-10", 7.203),
+	}
+
+	diff := Diff(prev, curr)
+	if diff.Added != 1 || diff.Removed != 1 || diff.Changed != 1 || diff.Unchanged != 1 {
+		t.Errorf("counts = %d added, %d removed, %d changed, %d unchanged, want 1 of each", diff.Added, diff.Removed, diff.Changed, diff.Unchanged)
+	}
+	if diff.PreviousTotal != 137.2 || diff.CurrentTotal != 144.203 {
+		t.Errorf("totals = %v -> %v, want 137.2 -> 144.203", diff.PreviousTotal, diff.CurrentTotal)
+	}
+
+	// Entries are ordered by the size of their delta
+	want := []models.CostDiffEntry{
+		{CompositeKey: disk.CompositeKey(), Status: models.DiffRemoved, Service: "Storage", SKU: "Disk", ProjectID: "prod", Region: "us-east1", PreviousCost: 30, Delta: -30, Percentage: -100},
+		{CompositeKey: vm.CompositeKey(), Status: models.DiffChanged, Service: "Compute", SKU: "VM", ProjectID: "prod", Region: "us-east1", PreviousCost: 100, CurrentCost: 125, Delta: 25, Percentage: 25},
+		{CompositeKey: query.CompositeKey(), Status: models.DiffAdded, Service: "BigQuery", SKU: "Analysis", ProjectID: "analytics", Region: "eu", CurrentCost: 12, Delta: 12},
+	}
+	if len(diff.Entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(diff.Entries), len(want), diff.Entries)
+	}
+	for i := range want {
+		if diff.Entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, diff.Entries[i], want[i])
+		}
+	}
+}
+
+func TestDiffOfIdenticalRuns(t *testing.T) {
+	costs := []models.CostData{{Date: "2024-05-10", Service: "Compute", Cost: 10}}
+	diff := Diff(costs, costs)
+	if len(diff.Entries) != 0 || diff.Unchanged != 1 || diff.Delta != 0 {
+		t.Errorf("diff = %+v, want one unchanged key and no entries", diff)
+	}
+
+	if diff := Diff(nil, nil); len(diff.Entries) != 0 || diff.Unchanged != 0 {
+		t.Errorf("diff of empty runs = %+v, want nothing", diff)
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadForecastCSV loads a forecast series from a CSV file with date and expected_cost columns
+func LoadForecastCSV(filename string) ([]models.ForecastPoint, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var forecast []models.ForecastPoint
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("forecast line %d: expected date and expected_cost columns", i+1)
+		}
+
+		// Skip the header row
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			continue
+		}
+
+		expected, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("forecast line %d: invalid expected_cost %q", i+1, record[1])
+		}
+
+		forecast = append(forecast, models.ForecastPoint{
+			Date:         strings.TrimSpace(record[0]),
+			ExpectedCost: expected,
+		})
+	}
+
+	return forecast, nil
+}
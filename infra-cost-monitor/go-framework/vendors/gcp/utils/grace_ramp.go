@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"time"
+)
+
+// GraceRamp suppresses or downgrades anomalies for recently onboarded projects
+type GraceRamp struct {
+	onboarding config.OnboardingConfig
+}
+
+// NewGraceRamp creates a new grace ramp from the onboarding configuration
+func NewGraceRamp(onboarding config.OnboardingConfig) *GraceRamp {
+	return &GraceRamp{
+		onboarding: onboarding,
+	}
+}
+
+// Apply marks anomalies within a project's grace window and suppresses or downgrades them
+func (gr *GraceRamp) Apply(anomalies []models.Anomaly) []models.Anomaly {
+	var result []models.Anomaly
+	for _, anomaly := range anomalies {
+		if !gr.inGraceWindow(anomaly) {
+			result = append(result, anomaly)
+			continue
+		}
+
+		anomaly.InGraceWindow = true
+		if gr.onboarding.Mode == config.GraceModeSuppress {
+			log.Printf("🔕 Suppressed anomaly for %s within onboarding grace window: %s", anomaly.Project(), anomaly.Description)
+			continue
+		}
+
+		anomaly.Severity = models.SeverityLow
+		result = append(result, anomaly)
+	}
+	return result
+}
+
+// inGraceWindow reports whether the anomaly date falls within its project's grace
+// period. Anomalies without a ProjectID are matched on their composite key's project.
+func (gr *GraceRamp) inGraceWindow(anomaly models.Anomaly) bool {
+	project := anomaly.Project()
+	onboarded, exists := gr.onboarding.Projects[project]
+	if project == "" || !exists {
+		return false
+	}
+
+	onboardDate, err := time.Parse("2006-01-02", onboarded)
+	if err != nil {
+		return false
+	}
+	anomalyDate, err := time.Parse("2006-01-02", anomaly.Date)
+	if err != nil {
+		return false
+	}
+
+	graceEnd := onboardDate.AddDate(0, 0, gr.onboarding.GraceDays)
+	return anomalyDate.Before(graceEnd)
+}
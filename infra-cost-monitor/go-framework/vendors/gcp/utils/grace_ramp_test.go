@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+func TestGraceRampRecentlyOnboardedProject(t *testing.T) {
+	onboarding := config.OnboardingConfig{
+		GraceDays: 14,
+		Mode:      config.GraceModeDowngrade,
+		Projects:  map[string]string{"new-project": "2024-03-01"},
+	}
+	newKey := models.CostData{Service: "Compute", SKU: "VM", ProjectID: "new-project", Region: "us-east1"}.CompositeKey()
+	oldKey := models.CostData{Service: "Compute", SKU: "VM", ProjectID: "old-project", Region: "us-east1"}.CompositeKey()
+
+	tests := []struct {
+		name    string
+		anomaly models.Anomaly
+		grace   bool
+	}{
+		{"project ID within the window", models.Anomaly{Date: "2024-03-10", ProjectID: "new-project"}, true},
+		{"composite key within the window", models.Anomaly{Date: "2024-03-10", CompositeKey: newKey}, true},
+		{"composite key after the window", models.Anomaly{Date: "2024-03-15", CompositeKey: newKey}, false},
+		{"project not onboarded recently", models.Anomaly{Date: "2024-03-10", CompositeKey: oldKey}, false},
+		{"no project", models.Anomaly{Date: "2024-03-10", Service: "Compute"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.anomaly.Severity = models.SeverityCritical
+			got := NewGraceRamp(onboarding).Apply([]models.Anomaly{tt.anomaly})
+			if len(got) != 1 {
+				t.Fatalf("got %d anomalies, want 1", len(got))
+			}
+			if got[0].InGraceWindow != tt.grace {
+				t.Errorf("InGraceWindow = %v, want %v", got[0].InGraceWindow, tt.grace)
+			}
+			wantSeverity := models.SeverityCritical
+			if tt.grace {
+				wantSeverity = models.SeverityLow
+			}
+			if got[0].Severity != wantSeverity {
+				t.Errorf("severity = %s, want %s", got[0].Severity, wantSeverity)
+			}
+		})
+	}
+
+	onboarding.Mode = config.GraceModeSuppress
+	anomalies := []models.Anomaly{
+		{Date: "2024-03-10", CompositeKey: newKey},
+		{Date: "2024-03-10", CompositeKey: oldKey},
+	}
+	if got := NewGraceRamp(onboarding).Apply(anomalies); len(got) != 1 || got[0].CompositeKey != oldKey {
+		t.Errorf("suppress mode kept %v, want only the anomaly for old-project", got)
+	}
+}
@@ -1,18 +1,77 @@
 package utils
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
-// JSONOutput handles JSON file output operations
-type JSONOutput struct{}
+// JSONOutput handles JSON file output operations. Files are written atomically,
+// files named *.gz are written gzip-compressed, and loaders transparently
+// decompress gzip files.
+type JSONOutput struct {
+	gzipLevel int
+}
 
 // NewJSONOutput creates a new JSON output handler
 func NewJSONOutput() *JSONOutput {
-	return &JSONOutput{}
+	return &JSONOutput{
+		gzipLevel: gzip.DefaultCompression,
+	}
+}
+
+// SetGzipLevel sets the compression level used for *.gz files, from
+// gzip.BestSpeed (1) to gzip.BestCompression (9)
+func (jo *JSONOutput) SetGzipLevel(level int) {
+	jo.gzipLevel = level
+}
+
+// writeFile atomically writes data to filename, gzip-compressing it when the name ends in .gz
+func (jo *JSONOutput) writeFile(filename string, data []byte) error {
+	if !strings.HasSuffix(filename, ".gz") {
+		return WriteFileAtomic(filename, data)
+	}
+
+	file, err := CreateAtomic(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	writer, err := gzip.NewWriterLevel(file, jo.gzipLevel)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// readFile reads filename, decompressing it when it starts with the gzip magic bytes
+func readFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil || len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %v", filename, err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
 }
 
 // SaveCompositeData saves composite cost data to JSON file
@@ -24,7 +83,7 @@ func (jo *JSONOutput) SaveCompositeData(data []models.CostData, filename string)
 		return err
 	}
 	
-	return os.WriteFile(filename, jsonData, 0644)
+	return jo.writeFile(filename, jsonData)
 }
 
 // SaveDailyTotals saves daily totals to JSON file
@@ -36,7 +95,7 @@ func (jo *JSONOutput) SaveDailyTotals(data []models.DailyCost, filename string)
 		return err
 	}
 	
-	return os.WriteFile(filename, jsonData, 0644)
+	return jo.writeFile(filename, jsonData)
 }
 
 // SaveMTDData saves MTD data to JSON file
@@ -48,7 +107,7 @@ func (jo *JSONOutput) SaveMTDData(data []models.MTDCost, filename string) error
 		return err
 	}
 	
-	return os.WriteFile(filename, jsonData, 0644)
+	return jo.writeFile(filename, jsonData)
 }
 
 // SaveAnomalies saves anomalies to JSON file
@@ -60,7 +119,35 @@ func (jo *JSONOutput) SaveAnomalies(data []models.Anomaly, filename string) erro
 		return err
 	}
 	
-	return os.WriteFile(filename, jsonData, 0644)
+	return jo.writeFile(filename, jsonData)
+}
+
+// SaveAnomaliesBySeverity saves one anomalies_<severity>.json file per severity present in dir
+func (jo *JSONOutput) SaveAnomaliesBySeverity(data []models.Anomaly, dir string) error {
+	bySeverity := make(map[string][]models.Anomaly)
+	for _, anomaly := range data {
+		bySeverity[anomaly.Severity] = append(bySeverity[anomaly.Severity], anomaly)
+	}
+
+	for severity, anomalies := range bySeverity {
+		filename := filepath.Join(dir, "anomalies_"+strings.ToLower(severity)+".json")
+		if err := jo.SaveAnomalies(anomalies, filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveMultiVendorSummary saves the cross-vendor summary to JSON file
+func (jo *JSONOutput) SaveMultiVendorSummary(data models.MultiVendorSummary, filename string) error {
+	log.Printf("💾 Saving multi-vendor summary to %s", filename)
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return jo.writeFile(filename, jsonData)
 }
 
 // SaveSummary saves summary to JSON file
@@ -72,53 +159,241 @@ func (jo *JSONOutput) SaveSummary(data models.Summary, filename string) error {
 		return err
 	}
 	
-	return os.WriteFile(filename, jsonData, 0644)
+	return jo.writeFile(filename, jsonData)
+}
+
+// SaveProjectAllocations saves per-project allocation views to JSON file
+func (jo *JSONOutput) SaveProjectAllocations(data []models.ProjectAllocation, filename string) error {
+	log.Printf("💾 Saving project allocations to %s", filename)
+	
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	
+	return jo.writeFile(filename, jsonData)
+}
+
+// SaveForecastVariance saves actual versus forecast variance to JSON file
+func (jo *JSONOutput) SaveForecastVariance(data []models.ForecastVariance, filename string) error {
+	log.Printf("💾 Saving forecast variance to %s", filename)
+	
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	
+	return jo.writeFile(filename, jsonData)
+}
+
+// SaveUnitCostTrends saves unit cost trends grouped by usage unit to JSON file
+func (jo *JSONOutput) SaveUnitCostTrends(data map[string][]models.UnitCostTrend, filename string) error {
+	log.Printf("💾 Saving unit cost trends to %s", filename)
+	
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	
+	return jo.writeFile(filename, jsonData)
+}
+
+// SaveRunReport saves the run report to JSON file
+func (jo *JSONOutput) SaveRunReport(data models.RunReport, filename string) error {
+	log.Printf("💾 Saving run report to %s", filename)
+	
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	
+	return jo.writeFile(filename, jsonData)
+}
+
+// SaveSelfCost saves the monitor's own query cost report to JSON file
+func (jo *JSONOutput) SaveSelfCost(data models.SelfCostReport, filename string) error {
+	log.Printf("💾 Saving self-cost report to %s", filename)
+	
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	
+	return jo.writeFile(filename, jsonData)
 }
 
-// LoadCompositeData loads composite data from JSON file
+// SaveTrendReport saves a trend report to JSON file
+func (jo *JSONOutput) SaveTrendReport(data models.TrendReport, filename string) error {
+	log.Printf("💾 Saving trend report to %s", filename)
+	
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	
+	return jo.writeFile(filename, jsonData)
+}
+
+// SaveCostDiff saves a run-to-run cost diff to JSON file
+func (jo *JSONOutput) SaveCostDiff(data models.CostDiff, filename string) error {
+	log.Printf("💾 Saving cost diff to %s", filename)
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return jo.writeFile(filename, jsonData)
+}
+
+// JSONArrayWriter writes a JSON array one element at a time, so large outputs
+// never need to be held in memory as a slice
+type JSONArrayWriter struct {
+	file     *AtomicFile
+	gzip     *gzip.Writer
+	writer   *bufio.Writer
+	elements int
+}
+
+// StreamArray creates filename and returns a writer for a JSON array in it;
+// names ending in .gz are gzip-compressed. Close must be called to finish the
+// array, and the file only appears under filename once it has.
+func (jo *JSONOutput) StreamArray(filename string) (*JSONArrayWriter, error) {
+	log.Printf("💾 Streaming JSON array to %s", filename)
+
+	file, err := CreateAtomic(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	aw := &JSONArrayWriter{file: file}
+	if strings.HasSuffix(filename, ".gz") {
+		aw.gzip, err = gzip.NewWriterLevel(file, jo.gzipLevel)
+		if err != nil {
+			file.Abort()
+			return nil, err
+		}
+		aw.writer = bufio.NewWriter(aw.gzip)
+	} else {
+		aw.writer = bufio.NewWriter(file)
+	}
+
+	if _, err := aw.writer.WriteString("["); err != nil {
+		file.Abort()
+		return nil, err
+	}
+	return aw, nil
+}
+
+// Write appends one element to the array
+func (aw *JSONArrayWriter) Write(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n  "
+	if aw.elements == 0 {
+		separator = "\n  "
+	}
+	if _, err := aw.writer.WriteString(separator); err != nil {
+		return err
+	}
+	if _, err := aw.writer.Write(data); err != nil {
+		return err
+	}
+	aw.elements++
+	return nil
+}
+
+// Close terminates the array and moves the file into place
+func (aw *JSONArrayWriter) Close() error {
+	defer aw.file.Abort()
+
+	closing := "\n]\n"
+	if aw.elements == 0 {
+		closing = "]\n"
+	}
+	if _, err := aw.writer.WriteString(closing); err != nil {
+		return err
+	}
+	if err := aw.writer.Flush(); err != nil {
+		return err
+	}
+	if aw.gzip != nil {
+		if err := aw.gzip.Close(); err != nil {
+			return err
+		}
+	}
+	return aw.file.Commit()
+}
+
+// LoadCompositeData loads composite data from JSON file, rejecting unknown
+// fields and records without a date or service or with a negative cost
 func (jo *JSONOutput) LoadCompositeData(filename string) ([]models.CostData, error) {
-	data, err := os.ReadFile(filename)
+	data, err := readFile(filename)
 	if err != nil {
 		return nil, err
 	}
 	
 	var compositeData []models.CostData
-	err = json.Unmarshal(data, &compositeData)
-	return compositeData, err
+	if err := decodeStrict(filename, data, &compositeData); err != nil {
+		return nil, err
+	}
+	return compositeData, validateCostData(filename, compositeData)
 }
 
-// LoadDailyTotals loads daily totals from JSON file
+// LoadDailyTotals loads daily totals from JSON file, newest first
 func (jo *JSONOutput) LoadDailyTotals(filename string) ([]models.DailyCost, error) {
-	data, err := os.ReadFile(filename)
+	data, err := readFile(filename)
 	if err != nil {
 		return nil, err
 	}
 	
 	var dailyTotals []models.DailyCost
-	err = json.Unmarshal(data, &dailyTotals)
-	return dailyTotals, err
+	if err := decodeStrict(filename, data, &dailyTotals); err != nil {
+		return nil, err
+	}
+	models.SortDailyCostsDesc(dailyTotals)
+	return dailyTotals, validateDailyCosts(filename, dailyTotals)
 }
 
 // LoadMTDData loads MTD data from JSON file
 func (jo *JSONOutput) LoadMTDData(filename string) ([]models.MTDCost, error) {
-	data, err := os.ReadFile(filename)
+	data, err := readFile(filename)
 	if err != nil {
 		return nil, err
 	}
 	
 	var mtdData []models.MTDCost
-	err = json.Unmarshal(data, &mtdData)
-	return mtdData, err
+	if err := decodeStrict(filename, data, &mtdData); err != nil {
+		return nil, err
+	}
+	return mtdData, validateMTDCosts(filename, mtdData)
+}
+
+// LoadSummary loads a run summary from JSON file
+func (jo *JSONOutput) LoadSummary(filename string) (models.Summary, error) {
+	var summary models.Summary
+	data, err := readFile(filename)
+	if err != nil {
+		return summary, err
+	}
+
+	err = decodeStrict(filename, data, &summary)
+	return summary, err
 }
 
 // LoadAnomalies loads anomalies from JSON file
 func (jo *JSONOutput) LoadAnomalies(filename string) ([]models.Anomaly, error) {
-	data, err := os.ReadFile(filename)
+	data, err := readFile(filename)
 	if err != nil {
 		return nil, err
 	}
 	
 	var anomalies []models.Anomaly
-	err = json.Unmarshal(data, &anomalies)
-	return anomalies, err
-} 
\ No newline at end of file
+	if err := decodeStrict(filename, data, &anomalies); err != nil {
+		return nil, err
+	}
+	return anomalies, validateAnomalies(filename, anomalies)
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestLoadDailyTotalsSortsNewestFirst(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daily_total_data.json")
+	unsorted := `[
+		{"date": "2024-03-02", "total_cost": 1000},
+		{"date": "2024-03-04", "total_cost": 3000},
+		{"date": "2024-03-01", "total_cost": 1000},
+		{"date": "2024-03-03", "total_cost": 1000}
+	]`
+	if err := os.WriteFile(path, []byte(unsorted), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	daily, err := NewJSONOutput().LoadDailyTotals(path)
+	if err != nil {
+		t.Fatalf("LoadDailyTotals: %v", err)
+	}
+	for i, want := range []string{"2024-03-04", "2024-03-03", "2024-03-02", "2024-03-01"} {
+		if daily[i].Date != want {
+			t.Fatalf("day %d = %s, want %s", i, daily[i].Date, want)
+		}
+	}
+
+	// The spike detector compares the latest day with the one before it
+	var spike *models.Anomaly
+	anomalies := NewDataProcessor().DetectAnomalies(daily, nil, nil)
+	for i := range anomalies {
+		if anomalies[i].Type == "daily_spike" {
+			spike = &anomalies[i]
+		}
+	}
+	if spike == nil {
+		t.Fatal("no daily spike detected")
+	}
+	if spike.Date != "2024-03-04" || spike.CurrentValue != 3000 || spike.PreviousValue != 1000 {
+		t.Errorf("spike on %s compares %v to %v, want 2024-03-04 comparing 3000 to 1000", spike.Date, spike.CurrentValue, spike.PreviousValue)
+	}
+}
+
+func TestDetectAnomaliesSortsUnsortedDailyTotals(t *testing.T) {
+	// Oldest first: read as given, the latest day would look like a drop
+	daily := []models.DailyCost{
+		{Date: "2024-03-01", TotalCost: 1000},
+		{Date: "2024-03-02", TotalCost: 1000},
+		{Date: "2024-03-03", TotalCost: 3000},
+	}
+
+	types := make(map[string]bool)
+	for _, anomaly := range NewDataProcessor().DetectAnomalies(daily, nil, nil) {
+		types[anomaly.Type] = true
+		if anomaly.Type == "daily_spike" && anomaly.Date != "2024-03-03" {
+			t.Errorf("spike flagged on %s, want 2024-03-03", anomaly.Date)
+		}
+	}
+	if !types["daily_spike"] {
+		t.Error("no daily spike detected")
+	}
+	if types["daily_drop"] {
+		t.Error("unsorted input was read as a drop")
+	}
+}
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func TestCompositeDataGzipRoundTrip(t *testing.T) {
+	data := []models.CostData{
+		{Date: "2024-03-02", Service: "Compute Engine", SKU: "N2 Core", ProjectID: "prod", Region: "us-central1", Cost: 123.45, Credits: -10.5, UsageAmount: 36, UsageUnit: "hour", Currency: "INR", Vendor: "gcp"},
+		{Date: "2024-03-01", Service: "BigQuery", SKU: "Analysis", ProjectID: "analytics", Region: "eu", Cost: 0.01, UsageAmount: 1e-6, UsageUnit: "byte", Currency: "USD", Vendor: "gcp"},
+	}
+
+	for _, level := range []int{gzip.BestSpeed, gzip.DefaultCompression, gzip.BestCompression} {
+		t.Run(strconv.Itoa(level), func(t *testing.T) {
+			dir := t.TempDir()
+			out := NewJSONOutput()
+			out.SetGzipLevel(level)
+
+			path := filepath.Join(dir, "composite_data.json.gz")
+			if err := out.SaveCompositeData(data, path); err != nil {
+				t.Fatalf("SaveCompositeData: %v", err)
+			}
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if !bytes.HasPrefix(raw, gzipMagic) {
+				t.Fatalf("%s is not gzip-compressed", path)
+			}
+
+			loaded, err := out.LoadCompositeData(path)
+			if err != nil {
+				t.Fatalf("LoadCompositeData: %v", err)
+			}
+			if !reflect.DeepEqual(loaded, data) {
+				t.Errorf("loaded %+v, want %+v", loaded, data)
+			}
+
+			// Detection goes by the magic bytes, not the extension
+			renamed := filepath.Join(dir, "composite_data.json")
+			if err := os.Rename(path, renamed); err != nil {
+				t.Fatalf("Rename: %v", err)
+			}
+			loaded, err = out.LoadCompositeData(renamed)
+			if err != nil {
+				t.Fatalf("LoadCompositeData: %v", err)
+			}
+			if !reflect.DeepEqual(loaded, data) {
+				t.Errorf("loaded %+v from a renamed file, want %+v", loaded, data)
+			}
+		})
+	}
+}
+
+func TestSetGzipLevelRejectsInvalidLevel(t *testing.T) {
+	out := NewJSONOutput()
+	out.SetGzipLevel(42)
+	path := filepath.Join(t.TempDir(), "composite_data.json.gz")
+	if err := out.SaveCompositeData(nil, path); err == nil {
+		t.Error("SaveCompositeData with gzip level 42 succeeded, want an error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Stat = %v, want the failed write to leave no file", err)
+	}
+}
+
+func TestSaveAnomaliesBySeverity(t *testing.T) {
+	anomalies := []models.Anomaly{
+		{ID: "a1", Severity: models.SeverityHigh},
+		{ID: "a2", Severity: models.SeverityMedium},
+		{ID: "a3", Severity: models.SeverityHigh},
+	}
+	dir := t.TempDir()
+	if err := NewJSONOutput().SaveAnomaliesBySeverity(anomalies, dir); err != nil {
+		t.Fatalf("SaveAnomaliesBySeverity: %v", err)
+	}
+
+	want := map[string][]string{
+		"anomalies_high.json":   {"a1", "a3"},
+		"anomalies_medium.json": {"a2"},
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("wrote %d files, want one per severity present", len(entries))
+	}
+	for name, ids := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("ReadFile: %v", err)
+			continue
+		}
+		var saved []models.Anomaly
+		if err := json.Unmarshal(data, &saved); err != nil {
+			t.Fatalf("Unmarshal %s: %v", name, err)
+		}
+		var got []string
+		for _, anomaly := range saved {
+			got = append(got, anomaly.ID)
+		}
+		if !reflect.DeepEqual(got, ids) {
+			t.Errorf("%s holds %v, want %v", name, got, ids)
+		}
+	}
+}
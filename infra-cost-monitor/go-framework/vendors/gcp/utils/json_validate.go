@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"strings"
+	"time"
+)
+
+// maxListedRecords caps how many bad records a validation error lists
+const maxListedRecords = 10
+
+// decodeStrict decodes a JSON document into v, rejecting a null document,
+// fields the model doesn't have and trailing data after the document
+func decodeStrict(filename string, data []byte, v interface{}) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return fmt.Errorf("%s: document is null", filename)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("%s: unexpected data after the JSON document", filename)
+	}
+	return nil
+}
+
+// recordErrors collects the problems of individual records of a file
+type recordErrors struct {
+	filename string
+	problems []string
+	bad      int
+}
+
+// add records the problems of the record at index, if it has any
+func (re *recordErrors) add(index int, problems []string) {
+	if len(problems) == 0 {
+		return
+	}
+	re.bad++
+	if len(re.problems) < maxListedRecords {
+		re.problems = append(re.problems, fmt.Sprintf("record %d: %s", index, strings.Join(problems, ", ")))
+	}
+}
+
+// err returns an error listing the bad records, or nil if there were none
+func (re *recordErrors) err() error {
+	if re.bad == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("%s: %d invalid records: %s", re.filename, re.bad, strings.Join(re.problems, "; "))
+	if re.bad > len(re.problems) {
+		message += fmt.Sprintf("; and %d more", re.bad-len(re.problems))
+	}
+	return fmt.Errorf("%s", message)
+}
+
+// checkDate reports a missing or malformed date field
+func checkDate(field, value, layout string) []string {
+	if value == "" {
+		return []string{"missing " + field}
+	}
+	if _, err := time.Parse(layout, value); err != nil {
+		return []string{fmt.Sprintf("invalid %s %q", field, value)}
+	}
+	return nil
+}
+
+// validateCostData checks composite and dimensional cost records. Negative costs
+// are only valid on the refund rows split out by the separate negative cost mode.
+func validateCostData(filename string, records []models.CostData) error {
+	errs := recordErrors{filename: filename}
+	for i, record := range records {
+		problems := checkDate("date", record.Date, "2006-01-02")
+		if record.Service == "" {
+			problems = append(problems, "missing service")
+		}
+		if record.Cost < 0 && record.CostType != RefundsCostType {
+			problems = append(problems, fmt.Sprintf("negative cost %.2f", record.Cost))
+		}
+		errs.add(i, problems)
+	}
+	return errs.err()
+}
+
+// validateDailyCosts checks daily total records
+func validateDailyCosts(filename string, records []models.DailyCost) error {
+	errs := recordErrors{filename: filename}
+	for i, record := range records {
+		problems := checkDate("date", record.Date, "2006-01-02")
+		if record.TotalCost < 0 {
+			problems = append(problems, fmt.Sprintf("negative total_cost %.2f", record.TotalCost))
+		}
+		errs.add(i, problems)
+	}
+	return errs.err()
+}
+
+// validateMTDCosts checks month-to-date records
+func validateMTDCosts(filename string, records []models.MTDCost) error {
+	errs := recordErrors{filename: filename}
+	for i, record := range records {
+		problems := checkDate("month", record.Month, "2006-01")
+		if record.Cost < 0 {
+			problems = append(problems, fmt.Sprintf("negative cost %.2f", record.Cost))
+		}
+		if record.Days < 0 {
+			problems = append(problems, fmt.Sprintf("negative days %d", record.Days))
+		}
+		errs.add(i, problems)
+	}
+	return errs.err()
+}
+
+// validateAnomalies checks anomaly records
+func validateAnomalies(filename string, records []models.Anomaly) error {
+	errs := recordErrors{filename: filename}
+	for i, record := range records {
+		var problems []string
+		if record.Date == "" {
+			problems = append(problems, "missing date")
+		}
+		if record.TestName == "" {
+			problems = append(problems, "missing test_name")
+		}
+		if record.Severity == "" {
+			problems = append(problems, "missing severity")
+		}
+		errs.add(i, problems)
+	}
+	return errs.err()
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeJSON writes a JSON document to a file in a temporary directory
+func writeJSON(t *testing.T, name, document string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(document), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestLoadCompositeDataRejectsAMalformedRecord(t *testing.T) {
+	path := writeJSON(t, "composite_data.json", `[
+		{"date": "2024-05-09", "service": "Compute", "cost": 10},
+		{"date": "", "service": "Storage", "cost": -5},
+		{"date": "2024-05-10", "service": "Compute", "cost": 12}
+	]`)
+
+	records, err := NewJSONOutput().LoadCompositeData(path)
+	if err == nil {
+		t.Fatal("LoadCompositeData accepted a record without a date and with a negative cost")
+	}
+	for _, want := range []string{"1 invalid records", "record 1: missing date, negative cost -5.00"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "record 0") || strings.Contains(err.Error(), "record 2") {
+		t.Errorf("error %q lists valid records", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("got %d records, want all 3 returned alongside the error", len(records))
+	}
+}
+
+func TestLoadCompositeDataRejectsMalformedDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		err      string
+	}{
+		{"unknown field", `[{"date": "2024-05-09", "service": "Compute", "costs": 10}]`, `unknown field "costs"`},
+		{"null document", `null`, "document is null"},
+		{"trailing data", `[] []`, "unexpected data after the JSON document"},
+		{"malformed date", `[{"date": "09/05/2024", "service": "Compute"}]`, `invalid date "09/05/2024"`},
+		{"missing service", `[{"date": "2024-05-09"}]`, "missing service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJSONOutput().LoadCompositeData(writeJSON(t, "composite_data.json", tt.document))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoadCompositeDataAcceptsRefundRows(t *testing.T) {
+	path := writeJSON(t, "composite_data.json", fmt.Sprintf(`[{"date": "2024-05-09", "service": "Compute", "cost": -5, "cost_type": %q}]`, RefundsCostType))
+	if _, err := NewJSONOutput().LoadCompositeData(path); err != nil {
+		t.Errorf("LoadCompositeData rejected a separated refund row: %v", err)
+	}
+}
+
+func TestValidationErrorCapsListedRecords(t *testing.T) {
+	records := make([]string, maxListedRecords+3)
+	for i := range records {
+		records[i] = `{"service": "Compute"}`
+	}
+	path := writeJSON(t, "composite_data.json", "["+strings.Join(records, ",")+"]")
+
+	_, err := NewJSONOutput().LoadCompositeData(path)
+	if err == nil {
+		t.Fatal("LoadCompositeData accepted records without dates")
+	}
+	if got := strings.Count(err.Error(), "missing date"); got != maxListedRecords {
+		t.Errorf("error lists %d records, want %d", got, maxListedRecords)
+	}
+	if !strings.Contains(err.Error(), "13 invalid records") || !strings.HasSuffix(err.Error(), "and 3 more") {
+		t.Errorf("error %q does not count the unlisted records", err)
+	}
+}
+
+func TestLoadersValidateTheirRecords(t *testing.T) {
+	jo := NewJSONOutput()
+	tests := []struct {
+		name     string
+		document string
+		load     func(path string) error
+		err      string
+	}{
+		{"daily totals", `[{"date": "2024-05-09", "total_cost": 1}, {"date": "2024-05-10", "total_cost": -1}]`, func(path string) error {
+			_, err := jo.LoadDailyTotals(path)
+			return err
+		}, "negative total_cost"},
+		{"MTD data", `[{"month": "2024-5", "cost": 1, "days": 1}]`, func(path string) error {
+			_, err := jo.LoadMTDData(path)
+			return err
+		}, `invalid month "2024-5"`},
+		{"anomalies", `[{"date": "2024-05-09", "test_name": "Daily Spike Detector"}]`, func(path string) error {
+			_, err := jo.LoadAnomalies(path)
+			return err
+		}, "record 0: missing severity"},
+		{"summary", `{"total_anomalies": 1, "surprise": true}`, func(path string) error {
+			_, err := jo.LoadSummary(path)
+			return err
+		}, `unknown field "surprise"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.load(writeJSON(t, "data.json", tt.document))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
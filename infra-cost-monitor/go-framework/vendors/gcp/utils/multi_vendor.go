@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"sort"
+)
+
+// TagVendor sets the vendor on each cost record
+func TagVendor(costs []models.CostData, vendor string) []models.CostData {
+	for i := range costs {
+		costs[i].Vendor = vendor
+	}
+	return costs
+}
+
+// SortedVendors returns the vendor names of a per-vendor series map in a stable order
+func SortedVendors(byVendor map[string]detectors.Series) []string {
+	vendors := make([]string, 0, len(byVendor))
+	for vendor := range byVendor {
+		vendors = append(vendors, vendor)
+	}
+	sort.Strings(vendors)
+	return vendors
+}
+
+// MergeSeries combines per-vendor series into one, summing daily and monthly totals by date
+func MergeSeries(byVendor map[string]detectors.Series) detectors.Series {
+	dailyTotals := make(map[string]float64)
+	mtdTotals := make(map[string]*models.MTDCost)
+	var merged detectors.Series
+
+	for _, vendor := range SortedVendors(byVendor) {
+		series := byVendor[vendor]
+		for _, day := range series.Daily {
+			dailyTotals[day.Date] += day.TotalCost
+		}
+		for _, month := range series.MTD {
+			total, exists := mtdTotals[month.Month]
+			if !exists {
+				total = &models.MTDCost{Month: month.Month}
+				mtdTotals[month.Month] = total
+			}
+			total.Cost += month.Cost
+			if month.Days > total.Days {
+				total.Days = month.Days
+			}
+		}
+		merged.Composite = append(merged.Composite, series.Composite...)
+	}
+
+	// Newest first, matching the order the monitors return
+	for date, cost := range dailyTotals {
+		merged.Daily = append(merged.Daily, models.DailyCost{Date: date, TotalCost: cost})
+	}
+	sort.Slice(merged.Daily, func(i, j int) bool {
+		return merged.Daily[i].Date > merged.Daily[j].Date
+	})
+	for _, month := range mtdTotals {
+		merged.MTD = append(merged.MTD, *month)
+	}
+	sort.Slice(merged.MTD, func(i, j int) bool {
+		return merged.MTD[i].Month > merged.MTD[j].Month
+	})
+	return merged
+}
+
+// NewMultiVendorSummary merges per-vendor summaries with totals and shares from vendor-tagged costs
+func NewMultiVendorSummary(summaries map[string]models.Summary, costs []models.CostData) models.MultiVendorSummary {
+	multi := models.MultiVendorSummary{
+		VendorCosts:      make(map[string]float64),
+		VendorShares:     make(map[string]float64),
+		ServiceBreakdown: make(map[string]float64),
+		Vendors:          summaries,
+	}
+
+	for _, summary := range summaries {
+		multi.TotalAnomalies += summary.TotalAnomalies
+		multi.TotalCostImpact += summary.TotalCostImpact
+	}
+
+	for _, cost := range costs {
+		multi.VendorCosts[cost.Vendor] += cost.Cost
+		multi.ServiceBreakdown[cost.Vendor+"/"+cost.Service] += cost.Cost
+		multi.TotalCost += cost.Cost
+	}
+
+	if multi.TotalCost != 0 {
+		for vendor, cost := range multi.VendorCosts {
+			multi.VendorShares[vendor] = cost / multi.TotalCost
+		}
+	}
+	return multi
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// vendorSeries returns GCP and AWS series that overlap on 2024-03-02 and 2024-03
+func vendorSeries() map[string]detectors.Series {
+	return map[string]detectors.Series{
+		"gcp": {
+			Daily:     []models.DailyCost{{Date: "2024-03-02", TotalCost: 100}, {Date: "2024-03-01", TotalCost: 90}},
+			MTD:       []models.MTDCost{{Month: "2024-03", Cost: 190, Days: 2}},
+			Composite: []models.CostData{{Date: "2024-03-02", Service: "Compute", Cost: 100, Vendor: "gcp"}},
+		},
+		"aws": {
+			Daily:     []models.DailyCost{{Date: "2024-03-03", TotalCost: 50}, {Date: "2024-03-02", TotalCost: 40}},
+			MTD:       []models.MTDCost{{Month: "2024-03", Cost: 90, Days: 3}, {Month: "2024-02", Cost: 300, Days: 29}},
+			Composite: []models.CostData{{Date: "2024-03-02", Service: "EC2", Cost: 40, Vendor: "aws"}},
+		},
+	}
+}
+
+func TestMergeSeries(t *testing.T) {
+	merged := MergeSeries(vendorSeries())
+
+	wantDaily := []models.DailyCost{{Date: "2024-03-03", TotalCost: 50}, {Date: "2024-03-02", TotalCost: 140}, {Date: "2024-03-01", TotalCost: 90}}
+	if !reflect.DeepEqual(merged.Daily, wantDaily) {
+		t.Errorf("daily = %+v, want %+v", merged.Daily, wantDaily)
+	}
+	wantMTD := []models.MTDCost{{Month: "2024-03", Cost: 280, Days: 3}, {Month: "2024-02", Cost: 300, Days: 29}}
+	if !reflect.DeepEqual(merged.MTD, wantMTD) {
+		t.Errorf("MTD = %+v, want %+v", merged.MTD, wantMTD)
+	}
+	if len(merged.Composite) != 2 || merged.Composite[0].Vendor != "aws" || merged.Composite[1].Vendor != "gcp" {
+		t.Errorf("composite = %+v, want both vendors' records in vendor order", merged.Composite)
+	}
+}
+
+func TestSortedVendors(t *testing.T) {
+	if got := SortedVendors(vendorSeries()); !reflect.DeepEqual(got, []string{"aws", "gcp"}) {
+		t.Errorf("SortedVendors() = %v, want [aws gcp]", got)
+	}
+}
+
+func TestNewMultiVendorSummary(t *testing.T) {
+	costs := append(
+		TagVendor([]models.CostData{{Service: "Compute", Cost: 60}, {Service: "Storage", Cost: 15}}, "gcp"),
+		TagVendor([]models.CostData{{Service: "EC2", Cost: 25}}, "aws")...,
+	)
+	summaries := map[string]models.Summary{
+		"gcp": {TotalAnomalies: 2, TotalCostImpact: 30},
+		"aws": {TotalAnomalies: 1, TotalCostImpact: 5},
+	}
+
+	multi := NewMultiVendorSummary(summaries, costs)
+
+	if multi.TotalCost != 100 || multi.TotalAnomalies != 3 || multi.TotalCostImpact != 35 {
+		t.Errorf("totals = %v cost, %d anomalies, %v impact, want 100, 3 and 35", multi.TotalCost, multi.TotalAnomalies, multi.TotalCostImpact)
+	}
+	wantShares := map[string]float64{"gcp": 0.75, "aws": 0.25}
+	for vendor, want := range wantShares {
+		if math.Abs(multi.VendorShares[vendor]-want) > 1e-9 {
+			t.Errorf("%s share = %v, want %v", vendor, multi.VendorShares[vendor], want)
+		}
+	}
+	wantServices := map[string]float64{"gcp/Compute": 60, "gcp/Storage": 15, "aws/EC2": 25}
+	if !reflect.DeepEqual(multi.ServiceBreakdown, wantServices) {
+		t.Errorf("service breakdown = %v, want %v", multi.ServiceBreakdown, wantServices)
+	}
+	if len(multi.Vendors) != 2 {
+		t.Errorf("vendor summaries = %v, want both kept", multi.Vendors)
+	}
+}
+
+func TestNewMultiVendorSummaryWithNoCost(t *testing.T) {
+	multi := NewMultiVendorSummary(nil, nil)
+	if multi.TotalCost != 0 || len(multi.VendorShares) != 0 {
+		t.Errorf("summary = %+v, want no shares without cost", multi)
+	}
+}
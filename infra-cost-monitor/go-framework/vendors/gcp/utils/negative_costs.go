@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+)
+
+// RefundsCostType is the charge type given to negative-cost rows in separate mode
+const RefundsCostType = "refunds_credits"
+
+// NegativeCostHandler treats negative-cost rows (refunds, credit line items)
+// according to the configured mode so they don't distort detection baselines
+type NegativeCostHandler struct {
+	mode      string
+	negatives []models.CostData
+}
+
+// NewNegativeCostHandler creates a new handler for the given mode (net, exclude or separate)
+func NewNegativeCostHandler(mode string) *NegativeCostHandler {
+	return &NegativeCostHandler{
+		mode: mode,
+	}
+}
+
+// Apply returns the rows to aggregate. Net keeps negatives as-is, exclude drops
+// them and separate keeps them under their own charge type.
+func (h *NegativeCostHandler) Apply(costs []models.CostData) []models.CostData {
+	h.negatives = nil
+	var result []models.CostData
+	for _, cost := range costs {
+		if cost.Cost < 0 {
+			h.negatives = append(h.negatives, cost)
+			switch h.mode {
+			case config.NegativeCostsExclude:
+				continue
+			case config.NegativeCostsSeparate:
+				cost.CostType = RefundsCostType
+			}
+		}
+		result = append(result, cost)
+	}
+
+	if len(h.negatives) > 0 {
+		log.Printf("➖ Found %d negative-cost rows totalling %.2f (mode: %s)", len(h.negatives), h.Total(), h.mode)
+	}
+	return result
+}
+
+// Total returns the sum of the negative-cost rows seen by Apply
+func (h *NegativeCostHandler) Total() float64 {
+	var total float64
+	for _, cost := range h.negatives {
+		total += cost.Cost
+	}
+	return total
+}
+
+// Costs returns the rows used for detection, without the separated refund rows
+func (h *NegativeCostHandler) Costs(costs []models.CostData) []models.CostData {
+	if h.mode != config.NegativeCostsSeparate {
+		return costs
+	}
+
+	var result []models.CostData
+	for _, cost := range costs {
+		if cost.CostType != RefundsCostType {
+			result = append(result, cost)
+		}
+	}
+	return result
+}
+
+// Series returns the series used for detection. Outside net mode the negative
+// rows are added back to the daily and monthly totals and dropped from the composite.
+func (h *NegativeCostHandler) Series(series detectors.Series) detectors.Series {
+	if h.mode == config.NegativeCostsNet || len(h.negatives) == 0 {
+		return series
+	}
+
+	negativeDaily := make(map[string]float64)
+	negativeMonthly := make(map[string]float64)
+	for _, cost := range h.negatives {
+		negativeDaily[cost.Date] += cost.Cost
+		if len(cost.Date) >= 7 {
+			negativeMonthly[cost.Date[:7]] += cost.Cost
+		}
+	}
+
+	filtered := detectors.Series{
+		Daily: make([]models.DailyCost, len(series.Daily)),
+		MTD:   make([]models.MTDCost, len(series.MTD)),
+	}
+	for i, day := range series.Daily {
+		day.TotalCost -= negativeDaily[day.Date]
+		filtered.Daily[i] = day
+	}
+	for i, month := range series.MTD {
+		month.Cost -= negativeMonthly[month.Month]
+		filtered.MTD[i] = month
+	}
+	for _, cost := range series.Composite {
+		if cost.CostType != RefundsCostType {
+			filtered.Composite = append(filtered.Composite, cost)
+		}
+	}
+	return filtered
+}
+
+// Annotate records the active mode and the total of refunds and credits in the summary
+func (h *NegativeCostHandler) Annotate(summary *models.Summary) {
+	summary.NegativeCostMode = h.mode
+	summary.RefundsAndCredits = h.Total()
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/config"
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"testing"
+)
+
+// refundRows returns two days of usage with a refund on the second
+func refundRows() []models.CostData {
+	return []models.CostData{
+		{Date: "2024-03-01", Service: "Compute", CostType: "regular", Cost: 100},
+		{Date: "2024-03-02", Service: "Compute", CostType: "regular", Cost: 100},
+		{Date: "2024-03-02", Service: "Compute", CostType: "adjustment", Cost: -80},
+	}
+}
+
+func TestNegativeCostHandlerModes(t *testing.T) {
+	tests := []struct {
+		mode          string
+		rows          int
+		refundRows    int
+		detectionRows int
+		latestDaily   float64
+		mtd           float64
+	}{
+		// Net keeps the refund in every total, so the latest day looks like a drop
+		{config.NegativeCostsNet, 3, 0, 3, 20, 120},
+		{config.NegativeCostsExclude, 2, 0, 2, 100, 200},
+		{config.NegativeCostsSeparate, 3, 1, 2, 100, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			handler := NewNegativeCostHandler(tt.mode)
+			rows := handler.Apply(refundRows())
+
+			refunds := 0
+			for _, row := range rows {
+				if row.CostType == RefundsCostType {
+					refunds++
+					if row.Cost != -80 {
+						t.Errorf("refund row = %+v, want the -80 adjustment", row)
+					}
+				}
+			}
+			if len(rows) != tt.rows || refunds != tt.refundRows {
+				t.Errorf("Apply() kept %d rows with %d refund rows, want %d with %d", len(rows), refunds, tt.rows, tt.refundRows)
+			}
+			if handler.Total() != -80 {
+				t.Errorf("Total() = %v, want -80 in every mode", handler.Total())
+			}
+			if got := handler.Costs(rows); len(got) != tt.detectionRows {
+				t.Errorf("Costs() kept %d rows, want %d", len(got), tt.detectionRows)
+			}
+
+			series := handler.Series(detectors.Series{
+				Daily:     []models.DailyCost{{Date: "2024-03-02", TotalCost: 20}, {Date: "2024-03-01", TotalCost: 100}},
+				MTD:       []models.MTDCost{{Month: "2024-03", Cost: 120, Days: 2}},
+				Composite: rows,
+			})
+			if series.Daily[0].TotalCost != tt.latestDaily || series.Daily[1].TotalCost != 100 {
+				t.Errorf("daily = %+v, want the latest day at %v", series.Daily, tt.latestDaily)
+			}
+			if series.MTD[0].Cost != tt.mtd {
+				t.Errorf("MTD = %v, want %v", series.MTD[0].Cost, tt.mtd)
+			}
+			if len(series.Composite) != tt.detectionRows {
+				t.Errorf("composite has %d rows, want %d", len(series.Composite), tt.detectionRows)
+			}
+
+			var summary models.Summary
+			handler.Annotate(&summary)
+			if summary.NegativeCostMode != tt.mode || summary.RefundsAndCredits != -80 {
+				t.Errorf("summary notes mode %q and refunds %v, want %q and -80", summary.NegativeCostMode, summary.RefundsAndCredits, tt.mode)
+			}
+		})
+	}
+}
+
+func TestNegativeCostHandlerWithoutNegatives(t *testing.T) {
+	handler := NewNegativeCostHandler(config.NegativeCostsExclude)
+	rows := handler.Apply(refundRows()[:2])
+	if len(rows) != 2 || handler.Total() != 0 {
+		t.Errorf("Apply() = %d rows totalling %v negative, want 2 and 0", len(rows), handler.Total())
+	}
+	series := detectors.Series{Daily: []models.DailyCost{{Date: "2024-03-02", TotalCost: 100}}}
+	if got := handler.Series(series); got.Daily[0].TotalCost != 100 {
+		t.Errorf("daily = %+v, want it unchanged", got.Daily)
+	}
+}
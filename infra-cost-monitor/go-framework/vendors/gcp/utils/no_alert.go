@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"log"
+	"sort"
+)
+
+// NoAlertFilter removes no-alert projects (sandboxes, experiments) from detection
+// while leaving them in the totals reported in the summary
+type NoAlertFilter struct {
+	projects map[string]bool
+}
+
+// NewNoAlertFilter creates a new filter for the given project IDs
+func NewNoAlertFilter(projects []string) *NoAlertFilter {
+	set := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		set[project] = true
+	}
+	return &NoAlertFilter{
+		projects: set,
+	}
+}
+
+// Excluded reports whether the project is a no-alert project
+func (f *NoAlertFilter) Excluded(projectID string) bool {
+	return projectID != "" && f.projects[projectID]
+}
+
+// Costs returns the cost records that do not belong to a no-alert project
+func (f *NoAlertFilter) Costs(costs []models.CostData) []models.CostData {
+	if len(f.projects) == 0 {
+		return costs
+	}
+
+	var result []models.CostData
+	for _, cost := range costs {
+		if !f.Excluded(cost.ProjectID) {
+			result = append(result, cost)
+		}
+	}
+	return result
+}
+
+// Series returns the series used for detection: composite records of no-alert projects
+// are dropped and their cost is subtracted from the daily and monthly totals
+func (f *NoAlertFilter) Series(series detectors.Series) detectors.Series {
+	if len(f.projects) == 0 {
+		return series
+	}
+
+	excludedDaily := make(map[string]float64)
+	excludedMonthly := make(map[string]float64)
+	for _, cost := range series.Composite {
+		if !f.Excluded(cost.ProjectID) {
+			continue
+		}
+		excludedDaily[cost.Date] += cost.Cost
+		if len(cost.Date) >= 7 {
+			excludedMonthly[cost.Date[:7]] += cost.Cost
+		}
+	}
+
+	filtered := detectors.Series{
+		Daily:     make([]models.DailyCost, len(series.Daily)),
+		MTD:       make([]models.MTDCost, len(series.MTD)),
+		Composite: f.Costs(series.Composite),
+	}
+	for i, day := range series.Daily {
+		day.TotalCost -= excludedDaily[day.Date]
+		filtered.Daily[i] = day
+	}
+	for i, month := range series.MTD {
+		month.Cost -= excludedMonthly[month.Month]
+		filtered.MTD[i] = month
+	}
+	return filtered
+}
+
+// Anomalies drops any remaining anomalies raised for a no-alert project
+func (f *NoAlertFilter) Anomalies(anomalies []models.Anomaly) []models.Anomaly {
+	if len(f.projects) == 0 {
+		return anomalies
+	}
+
+	var result []models.Anomaly
+	for _, anomaly := range anomalies {
+		if project := anomaly.Project(); f.Excluded(project) {
+			log.Printf("🔕 Skipping anomaly for no-alert project %s: %s", project, anomaly.Description)
+			continue
+		}
+		result = append(result, anomaly)
+	}
+	return result
+}
+
+// Annotate records how much of the total cost comes from no-alert projects
+func (f *NoAlertFilter) Annotate(summary *models.Summary, costs []models.CostData) {
+	if len(f.projects) == 0 {
+		return
+	}
+
+	var total, excluded float64
+	for _, cost := range costs {
+		total += cost.Cost
+		if f.Excluded(cost.ProjectID) {
+			excluded += cost.Cost
+		}
+	}
+
+	summary.NoAlertProjects = make([]string, 0, len(f.projects))
+	for project := range f.projects {
+		summary.NoAlertProjects = append(summary.NoAlertProjects, project)
+	}
+	sort.Strings(summary.NoAlertProjects)
+	summary.NoAlertCost = excluded
+	if total != 0 {
+		summary.NoAlertCostPercentage = excluded / total * 100
+	}
+}
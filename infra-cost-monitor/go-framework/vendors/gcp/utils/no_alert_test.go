@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"reflect"
+	"testing"
+)
+
+// sandboxSeries returns two days of prod and sandbox spend with matching totals
+func sandboxSeries() detectors.Series {
+	return detectors.Series{
+		Daily: []models.DailyCost{{Date: "2024-03-02", TotalCost: 1100}, {Date: "2024-03-01", TotalCost: 150}},
+		MTD:   []models.MTDCost{{Month: "2024-03", Cost: 1250, Days: 2}},
+		Composite: []models.CostData{
+			{Date: "2024-03-02", ProjectID: "prod", Cost: 100},
+			{Date: "2024-03-02", ProjectID: "sandbox", Cost: 1000},
+			{Date: "2024-03-01", ProjectID: "prod", Cost: 100},
+			{Date: "2024-03-01", ProjectID: "sandbox", Cost: 50},
+		},
+	}
+}
+
+func TestNoAlertFilterSeries(t *testing.T) {
+	filtered := NewNoAlertFilter([]string{"sandbox"}).Series(sandboxSeries())
+
+	wantDaily := []models.DailyCost{{Date: "2024-03-02", TotalCost: 100}, {Date: "2024-03-01", TotalCost: 100}}
+	if !reflect.DeepEqual(filtered.Daily, wantDaily) {
+		t.Errorf("daily = %+v, want %+v", filtered.Daily, wantDaily)
+	}
+	if filtered.MTD[0].Cost != 200 || filtered.MTD[0].Days != 2 {
+		t.Errorf("MTD = %+v, want 200 over 2 days", filtered.MTD[0])
+	}
+	for _, cost := range filtered.Composite {
+		if cost.ProjectID == "sandbox" {
+			t.Errorf("composite keeps a no-alert record %+v", cost)
+		}
+	}
+	if len(filtered.Composite) != 2 {
+		t.Errorf("composite has %d records, want the 2 prod records", len(filtered.Composite))
+	}
+
+	// The input is left intact for the totals
+	if original := sandboxSeries(); original.Daily[0].TotalCost != 1100 {
+		t.Errorf("input daily total changed to %v", original.Daily[0].TotalCost)
+	}
+}
+
+func TestNoAlertFilterWithoutProjectsIsANoOp(t *testing.T) {
+	filter := NewNoAlertFilter(nil)
+	series := sandboxSeries()
+	if filtered := filter.Series(series); !reflect.DeepEqual(filtered, series) {
+		t.Errorf("Series() = %+v, want the input unchanged", filtered)
+	}
+	if filter.Excluded("") {
+		t.Error("Excluded(\"\") = true, want records without a project kept")
+	}
+	var summary models.Summary
+	filter.Annotate(&summary, series.Composite)
+	if summary.NoAlertProjects != nil || summary.NoAlertCost != 0 {
+		t.Errorf("summary = %+v, want no no-alert note", summary)
+	}
+}
+
+func TestNoAlertFilterAnomalies(t *testing.T) {
+	anomalies := []models.Anomaly{
+		{ID: "a1", ProjectID: "sandbox"},
+		{ID: "a2", CompositeKey: "Compute|VM|sandbox|us-east1"},
+		{ID: "a3", ProjectID: "prod"},
+		{ID: "a4", Service: "daily_total"},
+	}
+	var kept []string
+	for _, anomaly := range NewNoAlertFilter([]string{"sandbox"}).Anomalies(anomalies) {
+		kept = append(kept, anomaly.ID)
+	}
+	if !reflect.DeepEqual(kept, []string{"a3", "a4"}) {
+		t.Errorf("kept %v, want [a3 a4]", kept)
+	}
+}
+
+func TestNoAlertFilterAnnotatesTheSummary(t *testing.T) {
+	var summary models.Summary
+	NewNoAlertFilter([]string{"sandbox", "experiments"}).Annotate(&summary, sandboxSeries().Composite)
+
+	if !reflect.DeepEqual(summary.NoAlertProjects, []string{"experiments", "sandbox"}) {
+		t.Errorf("no-alert projects = %v, want [experiments sandbox]", summary.NoAlertProjects)
+	}
+	if summary.NoAlertCost != 1050 {
+		t.Errorf("no-alert cost = %v, want 1050", summary.NoAlertCost)
+	}
+	if summary.NoAlertCostPercentage != 1050.0/1250*100 {
+		t.Errorf("no-alert share = %v%%, want %v%%", summary.NoAlertCostPercentage, 1050.0/1250*100)
+	}
+}
@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Trend metrics that can be read from saved run outputs. daily_total comes from
+// daily_total_data.json files; the rest from summary.json files, dated by when
+// the file was written.
+const (
+	TrendDailyTotal      = "daily_total"
+	TrendCurrentMonth    = "current_month_cost"
+	TrendCurrentDate     = "current_date_cost"
+	TrendTotalCostImpact = "total_cost_impact"
+	TrendTotalAnomalies  = "total_anomalies"
+)
+
+// TrendMetrics lists the supported trend metrics
+var TrendMetrics = []string{TrendDailyTotal, TrendCurrentMonth, TrendCurrentDate, TrendTotalCostImpact, TrendTotalAnomalies}
+
+// LoadTrendSeries walks dir for saved run outputs and returns the metric's value per
+// date over the last days days, oldest first. Later files win for the same date.
+func LoadTrendSeries(dir, metric string, days int) ([]models.TrendPoint, error) {
+	filename := "summary.json"
+	if metric == TrendDailyTotal {
+		filename = "daily_total_data.json"
+	}
+
+	type dated struct {
+		value   float64
+		modTime time.Time
+	}
+	values := make(map[string]dated)
+	record := func(date string, value float64, modTime time.Time) {
+		if existing, exists := values[date]; !exists || !modTime.Before(existing.modTime) {
+			values[date] = dated{value: value, modTime: modTime}
+		}
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != filename {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if metric == TrendDailyTotal {
+			var dailyTotals []models.DailyCost
+			if err := json.Unmarshal(data, &dailyTotals); err != nil {
+				return fmt.Errorf("failed to parse %s: %v", path, err)
+			}
+			for _, day := range dailyTotals {
+				record(day.Date, day.TotalCost, info.ModTime())
+			}
+			return nil
+		}
+
+		var summary models.Summary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+		value, err := summaryMetric(summary, metric)
+		if err != nil {
+			return err
+		}
+		record(info.ModTime().Format("2006-01-02"), value, info.ModTime())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var points []models.TrendPoint
+	for date, entry := range values {
+		points = append(points, models.TrendPoint{Date: date, Value: entry.value})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Date < points[j].Date
+	})
+
+	// Keep the window ending at the newest date
+	if days > 0 && len(points) > 0 {
+		newest, err := time.Parse("2006-01-02", points[len(points)-1].Date)
+		if err == nil {
+			cutoff := newest.AddDate(0, 0, -days+1).Format("2006-01-02")
+			start := sort.Search(len(points), func(i int) bool {
+				return points[i].Date >= cutoff
+			})
+			points = points[start:]
+		}
+	}
+	return points, nil
+}
+
+// summaryMetric reads a trend metric from a saved summary
+func summaryMetric(summary models.Summary, metric string) (float64, error) {
+	switch metric {
+	case TrendCurrentMonth:
+		return summary.CurrentMonthCost, nil
+	case TrendCurrentDate:
+		return summary.CurrentDateCost, nil
+	case TrendTotalCostImpact:
+		return summary.TotalCostImpact, nil
+	case TrendTotalAnomalies:
+		return float64(summary.TotalAnomalies), nil
+	}
+	return 0, fmt.Errorf("unknown trend metric %q", metric)
+}
+
+// AnalyzeTrend computes growth statistics for a series ordered oldest first: the
+// least-squares slope per day, the annualized compound growth rate and the
+// volatility (standard deviation of day-over-day percentage changes).
+func AnalyzeTrend(metric string, points []models.TrendPoint) models.TrendReport {
+	report := models.TrendReport{
+		Metric: metric,
+		Points: points,
+	}
+	if len(points) == 0 {
+		return report
+	}
+	report.From = points[0].Date
+	report.To = points[len(points)-1].Date
+
+	// Regress on days since the first point so gaps in saved runs are respected
+	first, err := time.Parse("2006-01-02", report.From)
+	if err != nil {
+		return report
+	}
+	n := float64(len(points))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, point := range points {
+		date, err := time.Parse("2006-01-02", point.Date)
+		if err != nil {
+			continue
+		}
+		x := date.Sub(first).Hours() / 24
+		sumX += x
+		sumY += point.Value
+		sumXY += x * point.Value
+		sumXX += x * x
+	}
+	report.Mean = sumY / n
+	if denominator := n*sumXX - sumX*sumX; denominator != 0 {
+		report.SlopePerDay = (n*sumXY - sumX*sumY) / denominator
+	}
+
+	// Compound growth from the first to the last value, annualized
+	spanDays := daysBetween(report.From, report.To)
+	firstValue, lastValue := points[0].Value, points[len(points)-1].Value
+	if spanDays > 0 && firstValue > 0 && lastValue > 0 {
+		report.CAGR = (math.Pow(lastValue/firstValue, 365/spanDays) - 1) * 100
+	}
+
+	// Volatility of day-over-day changes
+	var changes []float64
+	for i := 1; i < len(points); i++ {
+		if points[i-1].Value != 0 {
+			changes = append(changes, (points[i].Value-points[i-1].Value)/points[i-1].Value*100)
+		}
+	}
+	if len(changes) > 1 {
+		var mean float64
+		for _, change := range changes {
+			mean += change
+		}
+		mean /= float64(len(changes))
+		var variance float64
+		for _, change := range changes {
+			variance += (change - mean) * (change - mean)
+		}
+		report.Volatility = math.Sqrt(variance / float64(len(changes)-1))
+	}
+	return report
+}
+
+// daysBetween returns the number of days between two YYYY-MM-DD dates
+func daysBetween(from, to string) float64 {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return 0
+	}
+	return end.Sub(start).Hours() / 24
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"encoding/json"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// saveRun writes v as name in a run directory under dir, modified at modTime
+func saveRun(t *testing.T, dir, run, name string, v interface{}, modTime time.Time) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, run), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	path := filepath.Join(dir, run, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+}
+
+func TestLoadTrendSeriesDailyTotals(t *testing.T) {
+	dir := t.TempDir()
+	older := time.Date(2024, 3, 3, 6, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC)
+	saveRun(t, dir, "run-1", "daily_total_data.json", []models.DailyCost{{Date: "2024-03-02", TotalCost: 90}, {Date: "2024-03-01", TotalCost: 80}, {Date: "2024-02-28", TotalCost: 70}}, older)
+	// The later run restates 2024-03-02 and wins
+	saveRun(t, dir, "run-2", "daily_total_data.json", []models.DailyCost{{Date: "2024-03-03", TotalCost: 110}, {Date: "2024-03-02", TotalCost: 100}}, newer)
+	saveRun(t, dir, "run-2", "summary.json", models.Summary{CurrentDateCost: 9999}, newer)
+
+	points, err := LoadTrendSeries(dir, TrendDailyTotal, 3)
+	if err != nil {
+		t.Fatalf("LoadTrendSeries: %v", err)
+	}
+	want := []models.TrendPoint{{Date: "2024-03-01", Value: 80}, {Date: "2024-03-02", Value: 100}, {Date: "2024-03-03", Value: 110}}
+	if !reflect.DeepEqual(points, want) {
+		t.Errorf("points = %+v, want %+v", points, want)
+	}
+}
+
+func TestLoadTrendSeriesSummaryMetrics(t *testing.T) {
+	dir := t.TempDir()
+	saveRun(t, dir, "run-1", "summary.json", models.Summary{CurrentMonthCost: 1000, TotalAnomalies: 2}, time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC))
+	saveRun(t, dir, "run-2", "summary.json", models.Summary{CurrentMonthCost: 1500, TotalAnomalies: 5}, time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC))
+
+	points, err := LoadTrendSeries(dir, TrendTotalAnomalies, 0)
+	if err != nil {
+		t.Fatalf("LoadTrendSeries: %v", err)
+	}
+	want := []models.TrendPoint{{Date: "2024-03-01", Value: 2}, {Date: "2024-03-02", Value: 5}}
+	if !reflect.DeepEqual(points, want) {
+		t.Errorf("points = %+v, want %+v dated by when each summary was written", points, want)
+	}
+
+	if _, err := LoadTrendSeries(dir, "margin", 0); err == nil {
+		t.Error("LoadTrendSeries() with an unknown metric returned no error")
+	}
+}
+
+func TestAnalyzeTrend(t *testing.T) {
+	tests := []struct {
+		name       string
+		points     []models.TrendPoint
+		slope      float64
+		cagr       float64
+		volatility float64
+	}{
+		{
+			"doubling over a year",
+			[]models.TrendPoint{{Date: "2023-03-01", Value: 100}, {Date: "2024-02-29", Value: 200}},
+			100.0 / 365, 100, 0,
+		},
+		{
+			"flat",
+			[]models.TrendPoint{{Date: "2024-03-01", Value: 50}, {Date: "2024-03-02", Value: 50}, {Date: "2024-03-03", Value: 50}},
+			0, 0, 0,
+		},
+		{
+			"gaps are measured in days",
+			[]models.TrendPoint{{Date: "2024-03-01", Value: 10}, {Date: "2024-03-03", Value: 30}, {Date: "2024-03-04", Value: 40}},
+			10, (math.Pow(4, 365.0/3) - 1) * 100, math.Sqrt(2 * (250.0 / 3) * (250.0 / 3)),
+		},
+		{
+			"alternating",
+			[]models.TrendPoint{{Date: "2024-03-01", Value: 100}, {Date: "2024-03-02", Value: 200}, {Date: "2024-03-03", Value: 100}},
+			0, 0, math.Sqrt(2 * 75 * 75),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := AnalyzeTrend("daily_total", tt.points)
+			if report.From != tt.points[0].Date || report.To != tt.points[len(tt.points)-1].Date {
+				t.Errorf("window = %s to %s, want the first and last points", report.From, report.To)
+			}
+			check := func(name string, got, want float64) {
+				if math.Abs(got-want) > 1e-6*math.Max(1, math.Abs(want)) {
+					t.Errorf("%s = %v, want %v", name, got, want)
+				}
+			}
+			check("slope", report.SlopePerDay, tt.slope)
+			check("CAGR", report.CAGR, tt.cagr)
+			check("volatility", report.Volatility, tt.volatility)
+		})
+	}
+}
+
+func TestAnalyzeTrendWithNoPoints(t *testing.T) {
+	report := AnalyzeTrend("daily_total", nil)
+	if report.From != "" || report.Mean != 0 {
+		t.Errorf("report = %+v, want an empty report", report)
+	}
+}
@@ -0,0 +1,110 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"log"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultDir is where fixtures are read from when no directory is given
+const DefaultDir = "mock-data/input"
+
+// Provider is a provider.CostProvider serving fixture JSON instead of querying a
+// cloud vendor, so the pipeline can run end-to-end offline. The directory holds
+// daily_costs.json, mtd_costs.json and dimensional_costs.json; files may be gzipped.
+// Windows are counted back from the newest fixture date, not today, so fixtures
+// do not age out.
+type Provider struct {
+	dir        string
+	jsonOutput *utils.JSONOutput
+}
+
+// NewProvider creates a new mock provider reading fixtures from dir
+func NewProvider(dir string) *Provider {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &Provider{
+		dir:        dir,
+		jsonOutput: utils.NewJSONOutput(),
+	}
+}
+
+// Name returns the vendor name
+func (p *Provider) Name() string {
+	return "mock"
+}
+
+// DailyCosts returns the fixture's last days days of totals, newest first
+func (p *Provider) DailyCosts(ctx context.Context, days int) ([]models.DailyCost, error) {
+	log.Printf("📊 Loading mock daily cost data from %s...", p.dir)
+
+	dailyCosts, err := p.jsonOutput.LoadDailyTotals(p.path("daily_costs.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mock daily costs: %v", err)
+	}
+	if len(dailyCosts) > days {
+		dailyCosts = dailyCosts[:days]
+	}
+
+	log.Printf("✅ Loaded %d mock daily cost records", len(dailyCosts))
+	return dailyCosts, nil
+}
+
+// MTDCosts returns the fixture's cost per month, newest month first
+func (p *Provider) MTDCosts(ctx context.Context) ([]models.MTDCost, error) {
+	log.Printf("📊 Loading mock MTD cost data from %s...", p.dir)
+
+	mtdCosts, err := p.jsonOutput.LoadMTDData(p.path("mtd_costs.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mock MTD costs: %v", err)
+	}
+	sort.Slice(mtdCosts, func(i, j int) bool {
+		return mtdCosts[i].Month > mtdCosts[j].Month
+	})
+
+	log.Printf("✅ Loaded %d mock MTD cost records", len(mtdCosts))
+	return mtdCosts, nil
+}
+
+// DimensionalCosts returns the fixture's dimensional rows within days days of its newest date
+func (p *Provider) DimensionalCosts(ctx context.Context, days int) ([]models.CostData, error) {
+	log.Printf("📊 Loading mock dimensional cost data from %s...", p.dir)
+
+	rows, err := p.jsonOutput.LoadCompositeData(p.path("dimensional_costs.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mock dimensional costs: %v", err)
+	}
+
+	var latest string
+	for _, row := range rows {
+		if row.Date > latest {
+			latest = row.Date
+		}
+	}
+	newest, err := time.Parse("2006-01-02", latest)
+	if err != nil {
+		return rows, nil
+	}
+	cutoff := newest.AddDate(0, 0, -days).Format("2006-01-02")
+
+	var dimensionalCosts []models.CostData
+	for _, row := range rows {
+		if row.Date >= cutoff {
+			dimensionalCosts = append(dimensionalCosts, row)
+		}
+	}
+
+	log.Printf("✅ Loaded %d mock dimensional cost records", len(dimensionalCosts))
+	return dimensionalCosts, nil
+}
+
+// path returns the path of a fixture in the fixture directory
+func (p *Provider) path(name string) string {
+	return filepath.Join(p.dir, name)
+}
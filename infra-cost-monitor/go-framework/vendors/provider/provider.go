@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"context"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+)
+
+// CostProvider fetches cost data from a cloud vendor's billing source in the
+// shared cost models, so processing and detection do not depend on the vendor
+type CostProvider interface {
+	// Name returns the vendor name (gcp, aws, ...)
+	Name() string
+
+	// DailyCosts returns the total cost per day over the last days days, newest first
+	DailyCosts(ctx context.Context, days int) ([]models.DailyCost, error)
+
+	// MTDCosts returns the cost per month, newest month first
+	MTDCosts(ctx context.Context) ([]models.MTDCost, error)
+
+	// DimensionalCosts returns cost rows by date, service, SKU, project and region over the last days days
+	DimensionalCosts(ctx context.Context, days int) ([]models.CostData, error)
+}
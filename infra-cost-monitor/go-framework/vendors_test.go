@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"infra-cost-monitor/go-framework/vendors/gcp/detectors"
+	"infra-cost-monitor/go-framework/vendors/gcp/models"
+	"infra-cost-monitor/go-framework/vendors/gcp/utils"
+	"infra-cost-monitor/go-framework/vendors/provider"
+)
+
+// fakeProvider is a provider.CostProvider serving canned costs, failing with err when set
+type fakeProvider struct {
+	daily       []models.DailyCost
+	mtd         []models.MTDCost
+	dimensional []models.CostData
+	err         error
+}
+
+var _ provider.CostProvider = (*fakeProvider)(nil)
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) DailyCosts(ctx context.Context, days int) ([]models.DailyCost, error) {
+	return p.daily, p.err
+}
+
+func (p *fakeProvider) MTDCosts(ctx context.Context) ([]models.MTDCost, error) {
+	return p.mtd, nil
+}
+
+func (p *fakeProvider) DimensionalCosts(ctx context.Context, days int) ([]models.CostData, error) {
+	return p.dimensional, nil
+}
+
+// newFakeProvider returns a provider whose latest day triples the one before it
+func newFakeProvider() *fakeProvider {
+	return &fakeProvider{
+		daily: []models.DailyCost{
+			{Date: "2024-05-03", TotalCost: 300},
+			{Date: "2024-05-02", TotalCost: 100},
+			{Date: "2024-05-01", TotalCost: 100},
+		},
+		mtd: []models.MTDCost{{Month: "2024-05", Cost: 500, Days: 3}},
+		dimensional: []models.CostData{
+			{Date: "2024-05-03", Service: "Lambda", SKU: "Requests", ProjectID: "acct-1", Region: "us-east-1", Cost: 200},
+			{Date: "2024-05-03", Service: "Lambda", SKU: "Requests", ProjectID: "acct-1", Region: "us-east-1", Cost: 100},
+			{Date: "2024-05-02", Service: "Lambda", SKU: "Requests", ProjectID: "acct-1", Region: "us-east-1", Cost: 100},
+		},
+	}
+}
+
+func TestFetchVendorSeriesRunsDetectorsOnAnyProvider(t *testing.T) {
+	processor := utils.NewDataProcessor()
+	series, err := fetchVendorSeries(context.Background(), newFakeProvider(), processor, 30)
+	if err != nil {
+		t.Fatalf("fetchVendorSeries: %v", err)
+	}
+	if len(series.Daily) != 3 || len(series.MTD) != 1 {
+		t.Fatalf("series has %d days and %d months, want 3 and 1", len(series.Daily), len(series.MTD))
+	}
+	if len(series.Composite) != 2 {
+		t.Fatalf("got %d composite records, want the latest day's rows merged into 2", len(series.Composite))
+	}
+	for _, cost := range series.Composite {
+		if cost.Vendor != "fake" {
+			t.Errorf("composite record tagged %q, want fake", cost.Vendor)
+		}
+	}
+
+	registry := detectors.NewRegistry()
+	registry.Register("daily_spike", &detectors.DailySpikeDetector{})
+	anomalies := registry.Run(series, nil)
+	if len(anomalies) != 1 || anomalies[0].Date != "2024-05-03" {
+		t.Fatalf("anomalies = %+v, want a spike on 2024-05-03", anomalies)
+	}
+	anomalies[0].Vendor = "fake"
+
+	other := models.Anomaly{Vendor: "gcp", CostImpact: 1000}
+	summary := vendorSummary(processor, "fake", series, append(anomalies, other))
+	if summary.TotalAnomalies != 1 {
+		t.Errorf("summary counts %d anomalies, want only the fake vendor's 1", summary.TotalAnomalies)
+	}
+}
+
+func TestFetchVendorSeriesNamesTheFailingProvider(t *testing.T) {
+	fake := newFakeProvider()
+	fake.err = errors.New("throttled")
+	_, err := fetchVendorSeries(context.Background(), fake, utils.NewDataProcessor(), 30)
+	if err == nil || !strings.Contains(err.Error(), "fake daily costs") || !strings.Contains(err.Error(), "throttled") {
+		t.Errorf("got error %v, want one naming the fake provider's daily costs", err)
+	}
+}